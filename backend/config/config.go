@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"sync"
 
 	"godad-backend/utils"
@@ -18,9 +20,16 @@ type Config struct {
 	Database      DatabaseConfig
 	JWT           JWTConfig
 	OSS           OSSConfig
+	Storage       StorageConfig
+	Image         ImageConfig
 	Server        ServerConfig
 	RateLimit     RateLimitConfig
 	Observability ObservabilityConfig
+	Worker        WorkerConfig
+	Trending      TrendingConfig
+	Comment       CommentConfig
+	Spam          SpamConfig
+	Poll          PollConfig
 }
 
 // DatabaseConfig 数据库配置
@@ -36,8 +45,9 @@ type DatabaseConfig struct {
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret      string
-	ExpireHours int
+	Secret             string
+	ExpireHours        int
+	RefreshExpireHours int // 刷新令牌（登录会话）有效期
 }
 
 // OSSConfig 阿里云OSS配置
@@ -49,6 +59,40 @@ type OSSConfig struct {
 	CustomDomain    string // 自定义域名，用于生成访问URL
 }
 
+// StorageConfig 文件存储配置
+type StorageConfig struct {
+	Driver          string // 存储驱动：oss / local / s3
+	SignedURLExpire int    // 签名URL默认有效期（秒）
+	Local           LocalStorageConfig
+	S3              S3StorageConfig
+}
+
+// LocalStorageConfig 本地磁盘存储配置（开发环境使用，由 Gin 提供文件访问）
+type LocalStorageConfig struct {
+	Root      string // 文件存放目录
+	URLPrefix string // 访问路径前缀
+	BaseURL   string // 访问地址，用于生成完整URL
+}
+
+// S3StorageConfig S3 兼容存储配置（AWS S3、MinIO 等）
+type S3StorageConfig struct {
+	Endpoint        string // 服务地址，如 127.0.0.1:9000
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	BucketName      string
+	UseSSL          bool
+	PathStyle       bool   // 使用路径风格访问（MinIO 需开启）
+	PublicURL       string // 公开访问地址，为空时按 Endpoint 拼接
+}
+
+// ImageConfig 上传图片处理配置
+type ImageConfig struct {
+	Variants     string // 衍生尺寸，格式 名称:宽x高[:crop]，逗号分隔；宽或高为 0 表示不限
+	JPEGQuality  int    // JPEG 重新编码质量（1-100）
+	MaxDimension int    // 原图最长边上限（像素），超过时等比缩小
+}
+
 // ServerConfig 服务器配置
 type ServerConfig struct {
 	Host        string
@@ -91,6 +135,43 @@ type ObservabilityConfig struct {
 	OTLPInsecure         bool
 }
 
+// WorkerConfig 后台任务配置
+type WorkerConfig struct {
+	ViewCountFlushSeconds int  // 文章浏览量回写间隔（秒）
+	SchedulerEnabled      bool // 是否在本实例启动定时任务调度器
+}
+
+// TrendingConfig 热度排行配置
+type TrendingConfig struct {
+	HalfLifeHours float64 // 热度半衰期（小时），互动产生的热度每经过一个半衰期减半
+	HotThreshold  float64 // 论坛帖子自动标记为热门的热度阈值，低于一半时自动取消
+}
+
+// CommentConfig 评论先审后发配置，命中任一规则的评论进入待审核队列
+type CommentConfig struct {
+	ModerationEnabled bool     // 是否启用先审后发
+	NewAccountHours   int      // 注册不满该小时数的账号需审核，0 表示不限制
+	MinLevel          int64    // 积分等级低于该值需审核，0 表示不限制
+	HoldLinks         bool     // 内容包含链接时需审核
+	FlaggedWords      []string // 命中关键词时需审核（不区分大小写）
+}
+
+// SpamConfig 垃圾内容检测配置
+type SpamConfig struct {
+	Enabled              bool // 是否启用垃圾内容评分
+	HoldScore            int  // 评分达到该值的内容自动进入待审核
+	DuplicateWindowHours int  // 近似重复检测的时间窗口（小时）
+	VelocityWindowMins   int  // 发布频率统计窗口（分钟）
+	LowTrustAccountDays  int  // 注册不满该天数的账号视为低信任账号
+	LowTrustMaxPerWindow int  // 低信任账号在频率窗口内最多可发布的帖子与评论数
+}
+
+// PollConfig 帖子投票配置
+type PollConfig struct {
+	HideResults bool // 发起人未指定时，投票前是否隐藏实时结果
+	MaxDays     int  // 截止时间距发帖的最长天数
+}
+
 // LoadConfig 加载配置
 func LoadConfig() *Config {
 	config := &Config{}
@@ -116,6 +197,7 @@ func LoadConfig() *Config {
 	// JWT配置
 	config.JWT.Secret = utils.GetEnv("JWT_SECRET", "")
 	config.JWT.ExpireHours = utils.GetEnvAsInt("JWT_EXPIRE_HOURS", 24)
+	config.JWT.RefreshExpireHours = utils.GetEnvAsInt("JWT_REFRESH_EXPIRE_HOURS", 7*24)
 	if config.JWT.Secret == "" {
 		if config.Server.Environment == "production" {
 			log.Fatal("JWT_SECRET must be provided in production environment")
@@ -131,6 +213,30 @@ func LoadConfig() *Config {
 	config.OSS.BucketName = utils.GetEnv("OSS_BUCKET_NAME", "godad")
 	config.OSS.CustomDomain = utils.GetEnv("OSS_CUSTOM_DOMAIN", "")
 
+	// 文件存储配置：未显式指定驱动时，配置了 OSS 密钥则使用 OSS，否则使用本地磁盘
+	defaultDriver := "local"
+	if config.OSS.AccessKeyID != "" {
+		defaultDriver = "oss"
+	}
+	config.Storage.Driver = utils.GetEnv("STORAGE_DRIVER", defaultDriver)
+	config.Storage.SignedURLExpire = utils.GetEnvAsInt("STORAGE_SIGNED_URL_EXPIRE", 3600)
+	config.Storage.Local.Root = utils.GetEnv("UPLOAD_PATH", "./uploads")
+	config.Storage.Local.URLPrefix = utils.GetEnv("STORAGE_LOCAL_URL_PREFIX", "/uploads")
+	config.Storage.Local.BaseURL = utils.GetEnv("STORAGE_LOCAL_BASE_URL", fmt.Sprintf("http://127.0.0.1:%d", config.Server.Port))
+	config.Storage.S3.Endpoint = utils.GetEnv("S3_ENDPOINT", "127.0.0.1:9000")
+	config.Storage.S3.Region = utils.GetEnv("S3_REGION", "us-east-1")
+	config.Storage.S3.AccessKeyID = utils.GetEnv("S3_ACCESS_KEY_ID", "")
+	config.Storage.S3.SecretAccessKey = utils.GetEnv("S3_SECRET_ACCESS_KEY", "")
+	config.Storage.S3.BucketName = utils.GetEnv("S3_BUCKET_NAME", "godad")
+	config.Storage.S3.UseSSL = utils.GetEnvAsBool("S3_USE_SSL", false)
+	config.Storage.S3.PathStyle = utils.GetEnvAsBool("S3_PATH_STYLE", true)
+	config.Storage.S3.PublicURL = utils.GetEnv("S3_PUBLIC_URL", "")
+
+	// 图片处理配置
+	config.Image.Variants = utils.GetEnv("IMAGE_VARIANTS", "thumbnail:240x240:crop,medium:960x0,cover:1200x630:crop")
+	config.Image.JPEGQuality = utils.GetEnvAsInt("IMAGE_JPEG_QUALITY", 85)
+	config.Image.MaxDimension = utils.GetEnvAsInt("IMAGE_MAX_DIMENSION", 2560)
+
 	// 速率限制配置
 	config.RateLimit.General.RequestsPerSecond = utils.GetEnvAsFloat("RATE_LIMIT_GENERAL_RPS", 10.0)
 	config.RateLimit.General.BurstSize = utils.GetEnvAsInt("RATE_LIMIT_GENERAL_BURST", 20)
@@ -148,6 +254,37 @@ func LoadConfig() *Config {
 	config.Observability.OTLPExporterEndpoint = utils.GetEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
 	config.Observability.OTLPInsecure = utils.GetEnvAsBool("OTEL_EXPORTER_OTLP_INSECURE", false)
 
+	// 后台任务配置
+	config.Worker.ViewCountFlushSeconds = utils.GetEnvAsInt("VIEW_COUNT_FLUSH_SECONDS", 60)
+	config.Worker.SchedulerEnabled = utils.GetEnvAsBool("SCHEDULER_ENABLED", true)
+
+	// 热度排行配置
+	config.Trending.HalfLifeHours = utils.GetEnvAsFloat("TRENDING_HALF_LIFE_HOURS", 24)
+	config.Trending.HotThreshold = utils.GetEnvAsFloat("TRENDING_HOT_THRESHOLD", 50)
+
+	// 垃圾内容检测配置
+	config.Spam.Enabled = utils.GetEnvAsBool("SPAM_DETECTION_ENABLED", true)
+	config.Spam.HoldScore = utils.GetEnvAsInt("SPAM_HOLD_SCORE", 60)
+	config.Spam.DuplicateWindowHours = utils.GetEnvAsInt("SPAM_DUPLICATE_WINDOW_HOURS", 72)
+	config.Spam.VelocityWindowMins = utils.GetEnvAsInt("SPAM_VELOCITY_WINDOW_MINUTES", 10)
+	config.Spam.LowTrustAccountDays = utils.GetEnvAsInt("SPAM_LOW_TRUST_ACCOUNT_DAYS", 3)
+	config.Spam.LowTrustMaxPerWindow = utils.GetEnvAsInt("SPAM_LOW_TRUST_MAX_PER_WINDOW", 3)
+
+	// 帖子投票配置
+	config.Poll.HideResults = utils.GetEnvAsBool("FORUM_POLL_HIDE_RESULTS", true)
+	config.Poll.MaxDays = utils.GetEnvAsInt("FORUM_POLL_MAX_DAYS", 90)
+
+	// 评论审核配置
+	config.Comment.ModerationEnabled = utils.GetEnvAsBool("COMMENT_MODERATION_ENABLED", false)
+	config.Comment.NewAccountHours = utils.GetEnvAsInt("COMMENT_MODERATION_NEW_ACCOUNT_HOURS", 24)
+	config.Comment.MinLevel = int64(utils.GetEnvAsInt("COMMENT_MODERATION_MIN_LEVEL", 0))
+	config.Comment.HoldLinks = utils.GetEnvAsBool("COMMENT_MODERATION_HOLD_LINKS", true)
+	for _, word := range strings.Split(utils.GetEnv("COMMENT_MODERATION_FLAGGED_WORDS", ""), ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			config.Comment.FlaggedWords = append(config.Comment.FlaggedWords, strings.ToLower(word))
+		}
+	}
+
 	return config
 }
 
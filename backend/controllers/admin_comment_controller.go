@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"strconv"
+
+	"godad-backend/middleware"
+	"godad-backend/models"
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminCommentController 评论审核管理控制器
+type AdminCommentController struct {
+	commentService *services.CommentService
+}
+
+// NewAdminCommentController 创建评论审核管理控制器
+func NewAdminCommentController(commentService *services.CommentService) *AdminCommentController {
+	return &AdminCommentController{commentService: commentService}
+}
+
+// ListPending 待审核评论列表
+// @Summary 待审核评论列表
+// @Description 分页获取命中先审后发规则的评论，按提交时间先后排列
+// @Tags 管理员
+// @Produce json
+// @Security ApiKeyAuth
+// @Param article_id query int false "文章ID"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(20)
+// @Success 200 {object} utils.Response "获取成功"
+// @Router /api/admin/comments/pending [get]
+func (cc *AdminCommentController) ListPending(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(ctx.DefaultQuery("size", "20"))
+	articleID, _ := strconv.ParseUint(ctx.DefaultQuery("article_id", "0"), 10, 64)
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	comments, total, err := cc.commentService.GetPendingComments(uint(articleID), page, size)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+
+	type pendingComment struct {
+		*models.CommentResponse
+		ArticleTitle string `json:"article_title"`
+	}
+	items := make([]pendingComment, len(comments))
+	for i, comment := range comments {
+		items[i] = pendingComment{CommentResponse: comment.ToResponse(false), ArticleTitle: comment.Article.Title}
+	}
+	utils.SuccessPage(ctx, items, total, page, size)
+}
+
+// Approve 批量通过评论
+// @Summary 批量通过待审核评论
+// @Description 通过后评论公开展示，计入文章评论数并补发评论、回复与@通知；非待审核状态的ID会被忽略
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.CommentReviewRequest true "评论ID列表"
+// @Success 200 {object} utils.Response "审核完成"
+// @Router /api/admin/comments/pending/approve [post]
+func (cc *AdminCommentController) Approve(ctx *gin.Context) {
+	cc.review(ctx, true)
+}
+
+// Reject 批量驳回评论
+// @Summary 批量驳回待审核评论
+// @Description 驳回后评论转为隐藏状态，并以站内信告知作者原因；非待审核状态的ID会被忽略
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.CommentReviewRequest true "评论ID列表与驳回原因"
+// @Success 200 {object} utils.Response "审核完成"
+// @Router /api/admin/comments/pending/reject [post]
+func (cc *AdminCommentController) Reject(ctx *gin.Context) {
+	cc.review(ctx, false)
+}
+
+func (cc *AdminCommentController) review(ctx *gin.Context, approve bool) {
+	userID, ok := middleware.GetCurrentUserID(ctx)
+	if !ok {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	var req models.CommentReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数格式错误: "+err.Error())
+		return
+	}
+
+	var count int
+	var err error
+	if approve {
+		count, err = cc.commentService.ApproveComments(req.IDs, userID)
+	} else {
+		count, err = cc.commentService.RejectComments(req.IDs, req.Reason, userID)
+	}
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(ctx, "审核完成", gin.H{"processed": count, "skipped": len(req.IDs) - count})
+}
@@ -307,10 +307,10 @@ func (ac *AdminController) UpdateUserStatus(c *gin.Context) {
 	}
 
 	var req struct {
-		Status int8 `json:"status" binding:"required"`
+		Status *int8 `json:"status" binding:"required"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindJSON(&req); err != nil || (*req.Status != 0 && *req.Status != 1) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":  400,
 			"error": "参数错误",
@@ -328,7 +328,19 @@ func (ac *AdminController) UpdateUserStatus(c *gin.Context) {
 		return
 	}
 
-	// TODO: 实现用户状态更新功能
+	// 禁用用户会同时吊销其所有登录会话，已签发的令牌立即失效
+	if err := ac.userService.UpdateUserStatus(uint(userID), *req.Status); err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "用户不存在" {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"code":  status,
+			"error": err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "更新成功",
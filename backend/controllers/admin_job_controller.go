@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"godad-backend/middleware"
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminJobController 定时任务管理控制器
+type AdminJobController struct {
+	scheduler *services.JobScheduler
+}
+
+// NewAdminJobController 创建定时任务管理控制器
+func NewAdminJobController(scheduler *services.JobScheduler) *AdminJobController {
+	return &AdminJobController{scheduler: scheduler}
+}
+
+// ListJobs 任务列表
+// @Summary 定时任务列表
+// @Description 列出全部定时任务、下次执行时间与最近一次执行结果
+// @Tags 管理员
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]services.JobInfo} "获取成功"
+// @Router /api/admin/jobs [get]
+func (jc *AdminJobController) ListJobs(ctx *gin.Context) {
+	jobs, err := jc.scheduler.ListJobs()
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.Success(ctx, jobs)
+}
+
+// GetJob 任务详情
+// @Summary 定时任务详情
+// @Description 获取单个定时任务的状态与最近一次执行结果
+// @Tags 管理员
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "任务名称"
+// @Success 200 {object} utils.Response{data=services.JobInfo} "获取成功"
+// @Router /api/admin/jobs/{name} [get]
+func (jc *AdminJobController) GetJob(ctx *gin.Context) {
+	job, err := jc.scheduler.GetJob(ctx.Param("name"))
+	if err != nil {
+		jc.handleError(ctx, err)
+		return
+	}
+	utils.Success(ctx, job)
+}
+
+// ListRuns 任务执行历史
+// @Summary 定时任务执行历史
+// @Description 分页获取任务执行记录，按开始时间倒序
+// @Tags 管理员
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "任务名称"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(20)
+// @Success 200 {object} utils.Response "获取成功"
+// @Router /api/admin/jobs/{name}/runs [get]
+func (jc *AdminJobController) ListRuns(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(ctx.DefaultQuery("size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	runs, total, err := jc.scheduler.ListRuns(ctx.Param("name"), page, size)
+	if err != nil {
+		jc.handleError(ctx, err)
+		return
+	}
+	utils.SuccessPage(ctx, runs, total, page, size)
+}
+
+// RunJob 手动触发任务
+// @Summary 手动执行定时任务
+// @Description 立即在后台执行任务，返回本次执行记录；任务正在任一实例上运行时拒绝执行
+// @Tags 管理员
+// @Produce json
+// @Security ApiKeyAuth
+// @Param name path string true "任务名称"
+// @Success 200 {object} utils.Response{data=models.JobRun} "已触发"
+// @Router /api/admin/jobs/{name}/run [post]
+func (jc *AdminJobController) RunJob(ctx *gin.Context) {
+	userID, ok := middleware.GetCurrentUserID(ctx)
+	if !ok {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	run, err := jc.scheduler.RunNow(ctx.Param("name"), userID)
+	if err != nil {
+		jc.handleError(ctx, err)
+		return
+	}
+	utils.SuccessWithMessage(ctx, "任务已触发", run)
+}
+
+func (jc *AdminJobController) handleError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrJobNotFound):
+		utils.Error(ctx, utils.CodeNotFound, err.Error())
+	case errors.Is(err, services.ErrJobRunning):
+		utils.Error(ctx, utils.CodeBadRequest, err.Error())
+	default:
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+	}
+}
@@ -0,0 +1,299 @@
+package controllers
+
+import (
+	"strconv"
+
+	"godad-backend/middleware"
+	"godad-backend/models"
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminSensitiveWordController 敏感词库管理控制器
+type AdminSensitiveWordController struct {
+	wordService *services.SensitiveWordService
+}
+
+// NewAdminSensitiveWordController 创建敏感词库管理控制器
+func NewAdminSensitiveWordController(wordService *services.SensitiveWordService) *AdminSensitiveWordController {
+	return &AdminSensitiveWordController{wordService: wordService}
+}
+
+// ListCategories 敏感词分类列表
+// @Summary 敏感词分类列表
+// @Description 列出全部分类及其处理方式（block 拒绝 / mask 打码 / review 转人工审核）与词数
+// @Tags 管理员
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.SensitiveWordCategory} "获取成功"
+// @Router /api/admin/sensitive-words/categories [get]
+func (wc *AdminSensitiveWordController) ListCategories(ctx *gin.Context) {
+	categories, err := wc.wordService.ListCategories()
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.Success(ctx, categories)
+}
+
+// CreateCategory 创建敏感词分类
+// @Summary 创建敏感词分类
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.SensitiveCategoryRequest true "分类信息"
+// @Success 200 {object} utils.Response{data=models.SensitiveWordCategory} "创建成功"
+// @Router /api/admin/sensitive-words/categories [post]
+func (wc *AdminSensitiveWordController) CreateCategory(ctx *gin.Context) {
+	var req models.SensitiveCategoryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数格式错误: "+err.Error())
+		return
+	}
+	category, err := wc.wordService.CreateCategory(&req)
+	if err != nil {
+		wc.handleError(ctx, err)
+		return
+	}
+	utils.Success(ctx, category)
+}
+
+// UpdateCategory 更新敏感词分类
+// @Summary 更新敏感词分类
+// @Description 修改处理方式或停用分类后立即生效
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "分类ID"
+// @Param request body models.SensitiveCategoryRequest true "分类信息"
+// @Success 200 {object} utils.Response{data=models.SensitiveWordCategory} "更新成功"
+// @Router /api/admin/sensitive-words/categories/{id} [put]
+func (wc *AdminSensitiveWordController) UpdateCategory(ctx *gin.Context) {
+	id, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的分类ID")
+		return
+	}
+	var req models.SensitiveCategoryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数格式错误: "+err.Error())
+		return
+	}
+	category, err := wc.wordService.UpdateCategory(id, &req)
+	if err != nil {
+		wc.handleError(ctx, err)
+		return
+	}
+	utils.Success(ctx, category)
+}
+
+// DeleteCategory 删除敏感词分类
+// @Summary 删除敏感词分类
+// @Description 分类下仍有敏感词时不能删除
+// @Tags 管理员
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "分类ID"
+// @Success 200 {object} utils.Response "删除成功"
+// @Router /api/admin/sensitive-words/categories/{id} [delete]
+func (wc *AdminSensitiveWordController) DeleteCategory(ctx *gin.Context) {
+	id, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的分类ID")
+		return
+	}
+	if err := wc.wordService.DeleteCategory(id); err != nil {
+		wc.handleError(ctx, err)
+		return
+	}
+	utils.SuccessWithMessage(ctx, "删除成功", nil)
+}
+
+// ListWords 敏感词列表
+// @Summary 敏感词列表
+// @Tags 管理员
+// @Produce json
+// @Security ApiKeyAuth
+// @Param category_id query int false "分类ID"
+// @Param keyword query string false "关键字（匹配词或其他写法）"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(20)
+// @Success 200 {object} utils.Response "获取成功"
+// @Router /api/admin/sensitive-words [get]
+func (wc *AdminSensitiveWordController) ListWords(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(ctx.DefaultQuery("size", "20"))
+	categoryID, _ := strconv.ParseUint(ctx.DefaultQuery("category_id", "0"), 10, 64)
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	words, total, err := wc.wordService.ListWords(uint(categoryID), ctx.Query("keyword"), page, size)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.SuccessPage(ctx, words, total, page, size)
+}
+
+// CreateWord 添加敏感词
+// @Summary 添加敏感词
+// @Description 可同时提供逗号分隔的其他写法（如谐音、缩写），与原词同等匹配
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.SensitiveWordRequest true "敏感词"
+// @Success 200 {object} utils.Response{data=models.SensitiveWord} "添加成功"
+// @Router /api/admin/sensitive-words [post]
+func (wc *AdminSensitiveWordController) CreateWord(ctx *gin.Context) {
+	userID, ok := middleware.GetCurrentUserID(ctx)
+	if !ok {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+	var req models.SensitiveWordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数格式错误: "+err.Error())
+		return
+	}
+	word, err := wc.wordService.CreateWord(&req, userID)
+	if err != nil {
+		wc.handleError(ctx, err)
+		return
+	}
+	utils.Success(ctx, word)
+}
+
+// ImportWords 批量导入敏感词
+// @Summary 批量导入敏感词
+// @Description 每行一个词，可用 "词|写法1,写法2" 附带其他写法；已存在的词跳过
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.SensitiveWordImportRequest true "敏感词列表"
+// @Success 200 {object} utils.Response "导入完成"
+// @Router /api/admin/sensitive-words/import [post]
+func (wc *AdminSensitiveWordController) ImportWords(ctx *gin.Context) {
+	userID, ok := middleware.GetCurrentUserID(ctx)
+	if !ok {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+	var req models.SensitiveWordImportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数格式错误: "+err.Error())
+		return
+	}
+	added, skipped, err := wc.wordService.ImportWords(&req, userID)
+	if err != nil {
+		wc.handleError(ctx, err)
+		return
+	}
+	utils.SuccessWithMessage(ctx, "导入完成", gin.H{"added": added, "skipped": skipped})
+}
+
+// UpdateWord 更新敏感词
+// @Summary 更新敏感词
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "敏感词ID"
+// @Param request body models.SensitiveWordRequest true "敏感词"
+// @Success 200 {object} utils.Response{data=models.SensitiveWord} "更新成功"
+// @Router /api/admin/sensitive-words/{id} [put]
+func (wc *AdminSensitiveWordController) UpdateWord(ctx *gin.Context) {
+	id, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的敏感词ID")
+		return
+	}
+	var req models.SensitiveWordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数格式错误: "+err.Error())
+		return
+	}
+	word, err := wc.wordService.UpdateWord(id, &req)
+	if err != nil {
+		wc.handleError(ctx, err)
+		return
+	}
+	utils.Success(ctx, word)
+}
+
+// DeleteWord 删除敏感词
+// @Summary 删除敏感词
+// @Tags 管理员
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "敏感词ID"
+// @Success 200 {object} utils.Response "删除成功"
+// @Router /api/admin/sensitive-words/{id} [delete]
+func (wc *AdminSensitiveWordController) DeleteWord(ctx *gin.Context) {
+	id, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的敏感词ID")
+		return
+	}
+	if err := wc.wordService.DeleteWord(id); err != nil {
+		wc.handleError(ctx, err)
+		return
+	}
+	utils.SuccessWithMessage(ctx, "删除成功", nil)
+}
+
+// Check 检测文本
+// @Summary 敏感词检测
+// @Description 使用当前词库检测一段文本，返回命中详情与打码预览，不会保存任何内容
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.SensitiveCheckRequest true "待检测文本"
+// @Success 200 {object} utils.Response "检测完成"
+// @Router /api/admin/sensitive-words/check [post]
+func (wc *AdminSensitiveWordController) Check(ctx *gin.Context) {
+	var req models.SensitiveCheckRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数格式错误: "+err.Error())
+		return
+	}
+	result, masked := services.GetSensitiveFilter().Check(req.Text)
+	utils.Success(ctx, gin.H{"result": result, "masked": masked})
+}
+
+// Reload 重新加载词库
+// @Summary 重新加载敏感词库
+// @Description 从数据库重建词库并通知所有实例热加载（直接修改数据库后使用）
+// @Tags 管理员
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response "已重新加载"
+// @Router /api/admin/sensitive-words/reload [post]
+func (wc *AdminSensitiveWordController) Reload(ctx *gin.Context) {
+	if err := services.GetSensitiveFilter().Reload(); err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.SuccessWithMessage(ctx, "已重新加载", nil)
+}
+
+func (wc *AdminSensitiveWordController) handleError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case "敏感词分类不存在", "敏感词不存在":
+		utils.Error(ctx, utils.CodeNotFound, err.Error())
+	case "分类名称已存在", "分类名称不能为空", "敏感词已存在", "分类下仍有敏感词", "不支持的处理方式", "敏感词不能只包含空白或符号":
+		utils.Error(ctx, utils.CodeBadRequest, err.Error())
+	default:
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+	}
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"godad-backend/middleware"
+	"godad-backend/models"
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminSpamController 垃圾内容检测管理控制器
+type AdminSpamController struct {
+	spamService *services.SpamService
+}
+
+// NewAdminSpamController 创建垃圾内容检测管理控制器
+func NewAdminSpamController(spamService *services.SpamService) *AdminSpamController {
+	return &AdminSpamController{spamService: spamService}
+}
+
+// ListChecks 评分记录列表
+// @Summary 垃圾内容评分记录
+// @Description 分页获取帖子与评论的垃圾内容评分及各项信号说明，可按拦截状态、内容类型、最低分数筛选
+// @Tags 管理员
+// @Produce json
+// @Security ApiKeyAuth
+// @Param target_type query string false "内容类型 forum_post/comment"
+// @Param review_status query string false "审核状态 held/released/confirmed"
+// @Param min_score query int false "最低分数"
+// @Param user_id query int false "发布者ID"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(20)
+// @Success 200 {object} utils.Response "获取成功"
+// @Router /api/admin/spam/checks [get]
+func (sc *AdminSpamController) ListChecks(ctx *gin.Context) {
+	var req models.SpamCheckListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数格式错误: "+err.Error())
+		return
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Size < 1 {
+		req.Size = 20
+	}
+
+	checks, total, err := sc.spamService.ListChecks(&req)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.SuccessPage(ctx, checks, total, req.Page, req.Size)
+}
+
+// GetCheck 评分记录详情
+// @Summary 垃圾内容评分详情
+// @Description 返回评分明细及检测窗口内的近似重复内容
+// @Tags 管理员
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "记录ID"
+// @Success 200 {object} utils.Response{data=services.SpamCheckDetail} "获取成功"
+// @Router /api/admin/spam/checks/{id} [get]
+func (sc *AdminSpamController) GetCheck(ctx *gin.Context) {
+	id, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的记录ID")
+		return
+	}
+	detail, err := sc.spamService.GetCheck(id)
+	if err != nil {
+		sc.handleError(ctx, err)
+		return
+	}
+	utils.Success(ctx, detail)
+}
+
+// Review 处理被拦截的内容
+// @Summary 处理被拦截的内容
+// @Description release 放行发布；confirm 确认为垃圾内容（评论驳回并通知作者，帖子删除）
+// @Tags 管理员
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "记录ID"
+// @Param request body models.SpamReviewRequest true "处理动作"
+// @Success 200 {object} utils.Response "处理成功"
+// @Router /api/admin/spam/checks/{id}/review [post]
+func (sc *AdminSpamController) Review(ctx *gin.Context) {
+	userID, ok := middleware.GetCurrentUserID(ctx)
+	if !ok {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+	id, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的记录ID")
+		return
+	}
+	var req models.SpamReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数格式错误: "+err.Error())
+		return
+	}
+	if err := sc.spamService.Review(id, req.Action, userID); err != nil {
+		sc.handleError(ctx, err)
+		return
+	}
+	utils.SuccessWithMessage(ctx, "处理成功", nil)
+}
+
+func (sc *AdminSpamController) handleError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case "评分记录不存在", "帖子不存在":
+		utils.Error(ctx, utils.CodeNotFound, err.Error())
+	case "该内容不在待审核状态", "不支持的内容类型":
+		utils.Error(ctx, utils.CodeBadRequest, err.Error())
+	default:
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+	}
+}
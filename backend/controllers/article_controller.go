@@ -3,6 +3,7 @@ package controllers
 import (
 	"strconv"
 
+	"godad-backend/config"
 	"godad-backend/middleware"
 	"godad-backend/models"
 	"godad-backend/services"
@@ -14,12 +15,16 @@ import (
 // ArticleController 文章控制器
 type ArticleController struct {
 	articleService *services.ArticleService
+	relatedService *services.RelatedArticleService
+	seriesService  *services.SeriesService
 }
 
 // NewArticleController 创建文章控制器实例
 func NewArticleController() *ArticleController {
 	return &ArticleController{
 		articleService: services.NewArticleService(),
+		relatedService: services.NewRelatedArticleService(config.GetDB()),
+		seriesService:  services.NewSeriesService(config.GetDB()),
 	}
 }
 
@@ -39,6 +44,10 @@ func (c *ArticleController) CreateArticle(ctx *gin.Context) {
 
 	article, err := c.articleService.CreateArticle(userID, &req)
 	if err != nil {
+		if err == services.ErrSensitiveContent {
+			utils.Error(ctx, utils.CodeBadRequest, err.Error())
+			return
+		}
 		utils.Error(ctx, utils.CodeInternalError, err.Error())
 		return
 	}
@@ -68,6 +77,10 @@ func (c *ArticleController) UpdateArticle(ctx *gin.Context) {
 
 	article, err := c.articleService.UpdateArticle(uint(articleID), userID, &req)
 	if err != nil {
+		if err == services.ErrSensitiveContent {
+			utils.Error(ctx, utils.CodeBadRequest, err.Error())
+			return
+		}
 		utils.Error(ctx, utils.CodeInternalError, err.Error())
 		return
 	}
@@ -155,7 +168,11 @@ func (c *ArticleController) GetArticle(ctx *gin.Context) {
 		return
 	}
 
-	utils.Success(ctx, article.ToResponse(true))
+	resp := article.ToResponse(true)
+	if navs, err := c.seriesService.GetArticleNavigation(article.ID); err == nil && len(navs) > 0 {
+		resp.Series = navs
+	}
+	utils.Success(ctx, resp)
 }
 
 // GetArticleList 获取文章列表
@@ -312,4 +329,320 @@ func (c *ArticleController) GetHotArticles(ctx *gin.Context) {
 	}
 
 	utils.Success(ctx, responses)
-}
\ No newline at end of file
+}
+
+// GetRelatedArticles 获取相似文章
+// @Summary 获取相似文章
+// @Description 按内容相似度、同分类与共同收藏综合排序，结果不足时以同分类热门文章补足
+// @Tags 文章管理
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param limit query int false "返回数量" default(6)
+// @Success 200 {object} utils.Response{data=[]models.ArticleResponse}
+// @Failure 404 {object} utils.Response
+// @Router /api/articles/{id}/related [get]
+func (c *ArticleController) GetRelatedArticles(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的文章ID")
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "6"))
+	if err != nil || limit <= 0 || limit > 10 {
+		limit = 6
+	}
+
+	articles, err := c.relatedService.GetRelatedArticles(uint(id), limit)
+	if err != nil {
+		if err.Error() == "文章不存在" {
+			utils.Error(ctx, utils.CodeNotFound, err.Error())
+		} else {
+			utils.Error(ctx, utils.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	utils.Success(ctx, articleResponses(articles))
+}
+
+// GetArticleRevisions 获取文章历史版本列表
+// @Summary 获取文章历史版本列表
+// @Description 仅作者或管理员可查看，按版本号倒序
+// @Tags 文章管理
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "文章ID"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response{data=[]models.ArticleRevisionResponse}
+// @Router /api/articles/{id}/revisions [get]
+func (c *ArticleController) GetArticleRevisions(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	articleID, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "文章ID格式错误")
+		return
+	}
+
+	page, size := utils.ParsePaginationParams(ctx)
+	revisions, total, err := c.articleService.GetArticleRevisions(articleID, userID, page, size)
+	if err != nil {
+		respondArticleEditError(ctx, err)
+		return
+	}
+
+	responses := make([]*models.ArticleRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		responses = append(responses, revision.ToResponse())
+	}
+	utils.SuccessPage(ctx, responses, total, page, size)
+}
+
+// GetArticleRevision 获取文章指定版本的内容
+// @Summary 获取文章指定版本
+// @Tags 文章管理
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "文章ID"
+// @Param revisionId path int true "版本ID"
+// @Success 200 {object} utils.Response{data=models.ArticleRevision}
+// @Router /api/articles/{id}/revisions/{revisionId} [get]
+func (c *ArticleController) GetArticleRevision(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	articleID, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "文章ID格式错误")
+		return
+	}
+	revisionID, err := utils.ParseUintParam(ctx, "revisionId")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "版本ID格式错误")
+		return
+	}
+
+	revision, err := c.articleService.GetArticleRevision(articleID, revisionID, userID)
+	if err != nil {
+		respondArticleEditError(ctx, err)
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"revision": revision.ToResponse(),
+		"content":  revision.Content,
+	})
+}
+
+// DiffArticleRevisions 比较文章的两个版本
+// @Summary 比较文章版本
+// @Description 返回正文的行级差异；不传 to 时与最新版本比较
+// @Tags 文章管理
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "文章ID"
+// @Param from query int true "起始版本ID"
+// @Param to query int false "目标版本ID"
+// @Success 200 {object} utils.Response{data=services.ArticleRevisionDiff}
+// @Router /api/articles/{id}/revisions/diff [get]
+func (c *ArticleController) DiffArticleRevisions(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	articleID, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "文章ID格式错误")
+		return
+	}
+
+	fromID, err := strconv.ParseUint(ctx.Query("from"), 10, 32)
+	if err != nil || fromID == 0 {
+		utils.Error(ctx, utils.CodeBadRequest, "起始版本ID格式错误")
+		return
+	}
+	var toID uint64
+	if to := ctx.Query("to"); to != "" {
+		if toID, err = strconv.ParseUint(to, 10, 32); err != nil {
+			utils.Error(ctx, utils.CodeBadRequest, "目标版本ID格式错误")
+			return
+		}
+	}
+
+	diff, err := c.articleService.DiffArticleRevisions(articleID, uint(fromID), uint(toID), userID)
+	if err != nil {
+		respondArticleEditError(ctx, err)
+		return
+	}
+
+	utils.Success(ctx, diff)
+}
+
+// RestoreArticleRevision 将文章恢复到指定版本
+// @Summary 恢复文章版本
+// @Description 恢复后会生成一个新版本，原有版本记录保留
+// @Tags 文章管理
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "文章ID"
+// @Param revisionId path int true "版本ID"
+// @Success 200 {object} utils.Response{data=models.ArticleResponse}
+// @Router /api/articles/{id}/revisions/{revisionId}/restore [post]
+func (c *ArticleController) RestoreArticleRevision(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	articleID, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "文章ID格式错误")
+		return
+	}
+	revisionID, err := utils.ParseUintParam(ctx, "revisionId")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "版本ID格式错误")
+		return
+	}
+
+	article, err := c.articleService.RestoreArticleRevision(articleID, revisionID, userID)
+	if err != nil {
+		respondArticleEditError(ctx, err)
+		return
+	}
+
+	utils.Success(ctx, article.ToResponse(true))
+}
+
+// respondArticleEditError 文章编辑类操作（版本、自动保存）的错误响应
+func respondArticleEditError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case "无权限编辑此文章":
+		utils.Error(ctx, utils.CodeForbidden, err.Error())
+	case "文章不存在", "版本不存在":
+		utils.Error(ctx, utils.CodeNotFound, err.Error())
+	default:
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+	}
+}
+
+// autosaveArticleID 自动保存路由的文章ID，/articles/autosave 对应新文章（0）
+func autosaveArticleID(ctx *gin.Context) (uint, bool) {
+	if ctx.Param("id") == "" {
+		return 0, true
+	}
+	articleID, err := utils.ParseUintParam(ctx, "id")
+	if err != nil || articleID == 0 {
+		utils.Error(ctx, utils.CodeBadRequest, "文章ID格式错误")
+		return 0, false
+	}
+	return articleID, true
+}
+
+// AutosaveArticle 自动保存草稿
+// @Summary 自动保存草稿
+// @Description 保存编辑中的内容，不校验必填字段，也不会修改文章本身和更新时间
+// @Tags 文章管理
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int false "文章ID，新文章使用 /api/articles/autosave"
+// @Param request body models.ArticleAutosaveRequest true "草稿内容"
+// @Success 200 {object} utils.Response{data=models.ArticleDraft}
+// @Router /api/articles/{id}/autosave [put]
+func (c *ArticleController) AutosaveArticle(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+	articleID, ok := autosaveArticleID(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.ArticleAutosaveRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	draft, err := c.articleService.AutosaveArticleDraft(userID, articleID, &req)
+	if err != nil {
+		respondArticleEditError(ctx, err)
+		return
+	}
+
+	utils.Success(ctx, draft)
+}
+
+// GetArticleDraft 获取自动保存的草稿
+// @Summary 获取自动保存的草稿
+// @Tags 文章管理
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int false "文章ID，新文章使用 /api/articles/autosave"
+// @Success 200 {object} utils.Response{data=models.ArticleDraft}
+// @Router /api/articles/{id}/autosave [get]
+func (c *ArticleController) GetArticleDraft(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+	articleID, ok := autosaveArticleID(ctx)
+	if !ok {
+		return
+	}
+
+	draft, err := c.articleService.GetArticleDraft(userID, articleID)
+	if err != nil {
+		if err.Error() == "草稿不存在" {
+			utils.Error(ctx, utils.CodeNotFound, err.Error())
+		} else {
+			utils.Error(ctx, utils.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	utils.Success(ctx, draft)
+}
+
+// DiscardArticleDraft 丢弃自动保存的草稿
+// @Summary 丢弃自动保存的草稿
+// @Tags 文章管理
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int false "文章ID，新文章使用 /api/articles/autosave"
+// @Success 200 {object} utils.Response
+// @Router /api/articles/{id}/autosave [delete]
+func (c *ArticleController) DiscardArticleDraft(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+	articleID, ok := autosaveArticleID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.articleService.DiscardArticleDraft(userID, articleID); err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+
+	utils.Success(ctx, nil)
+}
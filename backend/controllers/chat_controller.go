@@ -6,8 +6,10 @@ import (
 	"godad-backend/services"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 )
 
 type ChatController struct {
@@ -383,4 +385,127 @@ func (cc *ChatController) CheckMessageLimit(c *gin.Context) {
 			"daily_limit":    3,
 		},
 	})
-}
\ No newline at end of file
+}
+const (
+	// chatWSWriteWait 单次写入超时
+	chatWSWriteWait = 10 * time.Second
+	// chatWSPongWait 等待客户端 pong 的超时
+	chatWSPongWait = 60 * time.Second
+	// chatWSPingPeriod 发送 ping 的间隔，需小于 chatWSPongWait
+	chatWSPingPeriod = 50 * time.Second
+	// chatWSMaxMessageSize 客户端单帧最大字节数
+	chatWSMaxMessageSize = 4096
+)
+
+var chatWSUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     middleware.IsOriginAllowed,
+}
+
+// chatWSInbound 客户端发送的 WebSocket 帧
+type chatWSInbound struct {
+	Type           string `json:"type"` // typing / read / ping
+	ConversationID uint   `json:"conversation_id"`
+	Typing         bool   `json:"typing"`
+}
+
+// WebSocket 聊天实时通道
+// 服务端推送 message（新消息）、read（已读回执）、typing（正在输入）事件；
+// 客户端可发送 typing、read 帧，发送消息仍走 POST /api/chat/messages
+func (cc *ChatController) WebSocket(c *gin.Context) {
+	userID, _ := middleware.GetCurrentUserID(c)
+
+	conn, err := chatWSUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 已向客户端写入错误响应
+		return
+	}
+
+	hub := services.GetChatHub()
+	client := hub.Register(userID)
+	done := make(chan struct{})
+
+	go cc.writeWebSocket(conn, client, done)
+	cc.readWebSocket(conn, userID)
+
+	hub.Unregister(client)
+	close(done)
+}
+
+// readWebSocket 读取客户端帧，直到连接关闭
+func (cc *ChatController) readWebSocket(conn *websocket.Conn, userID uint) {
+	defer conn.Close()
+
+	conn.SetReadLimit(chatWSMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(chatWSPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(chatWSPongWait))
+	})
+
+	// 缓存已校验过的会话 -> 对方用户ID，避免每个输入事件都查询数据库
+	peers := make(map[uint]uint)
+	peerOf := func(conversationID uint) (uint, error) {
+		if peerID, ok := peers[conversationID]; ok {
+			return peerID, nil
+		}
+		peerID, err := cc.chatService.GetConversationPeer(conversationID, userID)
+		if err != nil {
+			return 0, err
+		}
+		peers[conversationID] = peerID
+		return peerID, nil
+	}
+
+	for {
+		var frame chatWSInbound
+		if err := conn.ReadJSON(&frame); err != nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(chatWSPongWait))
+
+		switch frame.Type {
+		case "ping":
+			// 应用层心跳，仅用于刷新读超时
+		case services.ChatEventTyping:
+			peerID, err := peerOf(frame.ConversationID)
+			if err != nil {
+				continue
+			}
+			cc.chatService.SendTypingEvent(frame.ConversationID, userID, peerID, frame.Typing)
+		case services.ChatEventRead:
+			if _, err := peerOf(frame.ConversationID); err != nil {
+				continue
+			}
+			cc.chatService.MarkMessagesAsRead(frame.ConversationID, userID)
+		}
+	}
+}
+
+// writeWebSocket 将事件写入连接并定期发送 ping
+func (cc *ChatController) writeWebSocket(conn *websocket.Conn, client *services.ChatClient, done <-chan struct{}) {
+	ticker := time.NewTicker(chatWSPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case <-done:
+			conn.SetWriteDeadline(time.Now().Add(chatWSWriteWait))
+			conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
+		case payload := <-client.Send:
+			conn.SetWriteDeadline(time.Now().Add(chatWSWriteWait))
+			if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(chatWSWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"strconv"
+
+	"godad-backend/middleware"
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeedController 首页信息流控制器
+type FeedController struct {
+	feedService *services.FeedService
+}
+
+// NewFeedController 创建信息流控制器
+func NewFeedController(feedService *services.FeedService) *FeedController {
+	return &FeedController{feedService: feedService}
+}
+
+// GetFeed 获取个性化信息流
+// @Summary 个性化信息流
+// @Description 合并关注作者的文章与帖子、关注标签的文章以及热门内容，使用游标分页
+// @Tags 信息流
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param cursor query string false "上一页返回的 next_cursor，首页不传"
+// @Param size query int false "每页数量" default(20)
+// @Success 200 {object} utils.Response{data=services.FeedPage}
+// @Router /api/feed [get]
+func (fc *FeedController) GetFeed(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	size, err := strconv.Atoi(ctx.DefaultQuery("size", "20"))
+	if err != nil || size <= 0 || size > 50 {
+		size = 20
+	}
+
+	page, err := fc.feedService.GetFeed(userID, ctx.Query("cursor"), size)
+	if err != nil {
+		if err.Error() == "无效的分页游标" {
+			utils.Error(ctx, utils.CodeBadRequest, err.Error())
+		} else {
+			utils.Error(ctx, utils.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	utils.Success(ctx, page)
+}
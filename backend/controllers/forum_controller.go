@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"log"
 	"strconv"
 
 	"godad-backend/middleware"
@@ -81,11 +82,17 @@ func (c *ForumController) CreatePost(ctx *gin.Context) {
 	// 调用服务层创建帖子
 	post, err := c.forumService.CreatePost(&req, userID)
 	if err != nil {
+		if err == services.ErrSensitiveContent || err == services.ErrSpamThrottled || err == services.ErrBountyInsufficientPoints {
+			utils.Error(ctx, utils.CodeBadRequest, err.Error())
+			return
+		}
 		utils.Error(ctx, utils.CodeInternalServerError, "创建帖子失败: "+err.Error())
 		return
 	}
 
-	utils.SuccessWithMessage(ctx, "创建帖子成功", post.ToResponse(true))
+	resp := post.ToResponse(true)
+	c.attachPolls(userID, resp)
+	utils.SuccessWithMessage(ctx, "创建帖子成功", resp)
 }
 
 // GetPostList 获取帖子列表
@@ -99,6 +106,8 @@ func (c *ForumController) CreatePost(ctx *gin.Context) {
 // @Param topic query string false "话题分类"
 // @Param keyword query string false "搜索关键词"
 // @Param sort query string false "排序方式" default("created_at desc")
+// @Param post_type query string false "帖子类型 discussion/question"
+// @Param filter query string false "问答筛选 unanswered/solved"
 // @Success 200 {object} utils.Response{data=utils.PagedResponse{items=[]models.ForumPostResponse}}
 // @Failure 400 {object} utils.Response
 // @Failure 500 {object} utils.Response
@@ -134,6 +143,12 @@ func (c *ForumController) GetPostList(ctx *gin.Context) {
 	for _, post := range posts {
 		postResponses = append(postResponses, *post.ToResponse(false))
 	}
+	viewerID, _ := middleware.GetCurrentUserID(ctx)
+	respPtrs := make([]*models.ForumPostResponse, len(postResponses))
+	for i := range postResponses {
+		respPtrs[i] = &postResponses[i]
+	}
+	c.attachPolls(viewerID, respPtrs...)
 
 	// 构造分页响应
 	response := utils.PagedResponse{
@@ -232,7 +247,10 @@ func (c *ForumController) GetPost(ctx *gin.Context) {
 		return
 	}
 
-	utils.SuccessWithMessage(ctx, "获取帖子详情成功", post.ToResponse(true))
+	resp := post.ToResponse(true)
+	viewerID, _ := middleware.GetCurrentUserID(ctx)
+	c.attachPolls(viewerID, resp)
+	utils.SuccessWithMessage(ctx, "获取帖子详情成功", resp)
 }
 
 // UpdatePost 更新帖子
@@ -293,6 +311,8 @@ func (c *ForumController) UpdatePost(ctx *gin.Context) {
             utils.Error(ctx, utils.CodeForbidden, "无权限修改此帖子")
         } else if err.Error() == "帖子已锁定，无法编辑" {
             utils.Error(ctx, utils.CodeForbidden, "帖子已锁定，仅管理员可编辑")
+        } else if err == services.ErrSensitiveContent {
+            utils.Error(ctx, utils.CodeBadRequest, err.Error())
         } else {
             utils.Error(ctx, utils.CodeInternalServerError, "更新帖子失败: "+err.Error())
         }
@@ -340,6 +360,8 @@ func (c *ForumController) DeletePost(ctx *gin.Context) {
 			utils.Error(ctx, utils.CodeNotFound, "帖子不存在")
 		} else if err.Error() == "无权限删除此帖子" {
 			utils.Error(ctx, utils.CodeForbidden, "无权限删除此帖子")
+		} else if err.Error() == "悬赏中的问题已有回答，无法删除" {
+			utils.Error(ctx, utils.CodeBadRequest, err.Error())
 		} else {
 			utils.Error(ctx, utils.CodeInternalServerError, "删除帖子失败: "+err.Error())
 		}
@@ -349,6 +371,128 @@ func (c *ForumController) DeletePost(ctx *gin.Context) {
 	utils.SuccessWithMessage(ctx, "删除帖子成功", nil)
 }
 
+// AcceptAnswer 采纳答案
+// @Summary 采纳答案
+// @Description 问答帖的提问者采纳一条回复为答案，悬赏积分转给回答者
+// @Tags 论坛管理
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "帖子ID"
+// @Param request body models.ForumPostAcceptRequest true "被采纳的回复"
+// @Success 200 {object} utils.Response{data=models.ForumPostResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /api/forum/posts/{id}/accept [post]
+func (c *ForumController) AcceptAnswer(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "用户未登录")
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的帖子ID")
+		return
+	}
+
+	var req models.ForumPostAcceptRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数格式错误: "+err.Error())
+		return
+	}
+
+	post, err := c.forumService.AcceptAnswer(uint(id), req.ReplyID, userID)
+	if err != nil {
+		switch err.Error() {
+		case "帖子不存在", "回复不存在":
+			utils.Error(ctx, utils.CodeNotFound, err.Error())
+		case "只有提问者可以采纳答案":
+			utils.Error(ctx, utils.CodeForbidden, err.Error())
+		case "只有问答帖可以采纳答案", "该问题已采纳答案", "不能采纳自己的回复":
+			utils.Error(ctx, utils.CodeBadRequest, err.Error())
+		default:
+			utils.Error(ctx, utils.CodeInternalServerError, "采纳答案失败: "+err.Error())
+		}
+		return
+	}
+
+	utils.SuccessWithMessage(ctx, "采纳答案成功", post.ToResponse(false))
+}
+
+// VotePoll 参与帖子投票
+// @Summary 参与帖子投票
+// @Description 对帖子附带的投票进行投票，每人只能投一次
+// @Tags 论坛管理
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "帖子ID"
+// @Param request body models.ForumPollVoteRequest true "所选选项"
+// @Success 200 {object} utils.Response{data=models.ForumPollResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /api/forum/posts/{id}/poll/vote [post]
+func (c *ForumController) VotePoll(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "用户未登录")
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的帖子ID")
+		return
+	}
+
+	var req models.ForumPollVoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数格式错误: "+err.Error())
+		return
+	}
+
+	poll, err := c.forumService.VotePoll(uint(id), userID, req.OptionIDs)
+	if err != nil {
+		switch err.Error() {
+		case "帖子不存在", "该帖子没有投票":
+			utils.Error(ctx, utils.CodeNotFound, err.Error())
+		case "投票已截止", "投票选项不存在", "单选投票只能选择一项", "超过该投票允许的最多选项数", "您已参与过该投票":
+			utils.Error(ctx, utils.CodeBadRequest, err.Error())
+		default:
+			utils.Error(ctx, utils.CodeInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.SuccessWithMessage(ctx, "投票成功", poll)
+}
+
+// attachPolls 为帖子响应附加投票，结果是否可见取决于 viewerID 是否已投票
+func (c *ForumController) attachPolls(viewerID uint, resps ...*models.ForumPostResponse) {
+	if len(resps) == 0 {
+		return
+	}
+	ids := make([]uint, len(resps))
+	for i, resp := range resps {
+		ids[i] = resp.ID
+	}
+	polls, err := c.forumService.LoadPolls(ids, viewerID)
+	if err != nil {
+		log.Printf("加载帖子投票失败: %v", err)
+		return
+	}
+	for _, resp := range resps {
+		resp.Poll = polls[resp.ID]
+	}
+}
+
 // GetTopics 获取话题列表
 // @Summary 获取话题列表
 // @Description 获取所有有效的话题分类
@@ -426,6 +570,10 @@ func (c *ForumController) CreateReply(ctx *gin.Context) {
 	// 调用服务层创建回复
 	reply, err := c.forumService.CreateReply(&req, userID)
 	if err != nil {
+		if err == services.ErrSensitiveContent {
+			utils.Error(ctx, utils.CodeBadRequest, err.Error())
+			return
+		}
 		utils.Error(ctx, utils.CodeInternalServerError, "创建回复失败: "+err.Error())
 		return
 	}
@@ -609,6 +757,8 @@ func (c *ForumController) DeleteReply(ctx *gin.Context) {
 			utils.Error(ctx, utils.CodeNotFound, "回复不存在")
 		} else if err.Error() == "无权限删除此回复" {
 			utils.Error(ctx, utils.CodeForbidden, "无权限删除此回复")
+		} else if err.Error() == "已被采纳的回复无法删除" {
+			utils.Error(ctx, utils.CodeBadRequest, err.Error())
 		} else {
 			utils.Error(ctx, utils.CodeInternalServerError, "删除回复失败: "+err.Error())
 		}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocalStorageController 本地磁盘存储的文件访问
+type LocalStorageController struct {
+	storage *services.LocalStorage
+}
+
+// NewLocalStorageController 创建本地存储文件访问控制器
+func NewLocalStorageController(storage *services.LocalStorage) *LocalStorageController {
+	return &LocalStorageController{storage: storage}
+}
+
+// ServeFile 提供文件访问
+// 与公共读的 OSS 存储桶一致，直接访问无需鉴权；带 expires/signature 参数的签名地址会校验签名与有效期
+func (sc *LocalStorageController) ServeFile(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("filepath"), "/")
+
+	if signature := ctx.Query("signature"); signature != "" || ctx.Query("expires") != "" {
+		if err := sc.storage.VerifySignature(key, ctx.Query("expires"), signature); err != nil {
+			utils.Error(ctx, utils.CodeForbidden, err.Error())
+			return
+		}
+	}
+
+	if _, err := sc.storage.Stat(key); err != nil {
+		if errors.Is(err, services.ErrStorageObjectNotFound) {
+			utils.Error(ctx, utils.CodeNotFound, "文件不存在")
+			return
+		}
+		utils.Error(ctx, utils.CodeInternalError, "读取文件失败")
+		return
+	}
+
+	path, err := sc.storage.FilePath(key)
+	if err != nil {
+		utils.Error(ctx, utils.CodeNotFound, "文件不存在")
+		return
+	}
+
+	// 上传文件名为 UUID，内容不会变化
+	ctx.Header("Cache-Control", "public, max-age=86400")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	http.ServeFile(ctx.Writer, ctx.Request, path)
+}
@@ -7,9 +7,13 @@ import (
     "strings"
     "time"
 
+    "github.com/gin-contrib/sse"
     "github.com/gin-gonic/gin"
 )
 
+// streamHeartbeatInterval SSE 心跳间隔
+const streamHeartbeatInterval = 25 * time.Second
+
 type NotificationController struct {
 	notificationService *services.NotificationService
 }
@@ -106,39 +110,96 @@ func (c *NotificationController) GetNotificationStatsByType(ctx *gin.Context) {
     })
 }
 
-// Stream 通知SSE流（开发阶段：定时推送未读数）
+// Stream 通知SSE流
+// 事件：
+//   - notification：新通知的完整内容，事件ID为通知ID，断线重连时根据 Last-Event-ID 补发
+//   - broadcast：系统广播
+//   - message：未读统计（兼容旧版客户端），随通知事件一起下发，不在每个连接上回查数据库
+// 空闲时每隔一段时间发送注释行作为心跳，防止代理断开连接
 func (c *NotificationController) Stream(ctx *gin.Context) {
     userID, exists := ctx.Get("user_id")
     if !exists {
         ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
         return
     }
+    uid := userID.(uint)
 
     // 设置SSE头
     ctx.Header("Content-Type", "text/event-stream")
     ctx.Header("Cache-Control", "no-cache")
     ctx.Header("Connection", "keep-alive")
+    ctx.Header("X-Accel-Buffering", "no")
+
+    // 长连接不受服务器写超时限制
+    _ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{})
+
+    // 先订阅再补发，避免补发期间产生的通知丢失
+    hub := services.GetNotificationHub()
+    sub := hub.Subscribe(uid)
+    defer hub.Unsubscribe(sub)
 
-    ticker := time.NewTicker(10 * time.Second)
-    defer ticker.Stop()
+    // 断线重连补发
+    lastEventID := ctx.GetHeader("Last-Event-ID")
+    if lastEventID == "" {
+        lastEventID = ctx.Query("last_event_id")
+    }
+    var lastSentID uint
+    if id, err := strconv.ParseUint(lastEventID, 10, 64); err == nil && id > 0 {
+        missed, err := c.notificationService.GetNotificationsSince(uid, uint(id), 100)
+        if err == nil {
+            for i := range missed {
+                ctx.Render(-1, sse.Event{
+                    Id:    strconv.FormatUint(uint64(missed[i].ID), 10),
+                    Event: "notification",
+                    Data:  missed[i],
+                })
+                lastSentID = missed[i].ID
+            }
+        }
+    }
 
-    // 首次推送
-    if stats, err := c.notificationService.GetNotificationStats(userID.(uint)); err == nil {
+    // 首次推送未读统计，之后由事件携带的统计或广播增量维护
+    stats, err := c.notificationService.GetNotificationStats(uid)
+    if err == nil {
         ctx.SSEvent("message", stats)
-        ctx.Writer.Flush()
     }
+    ctx.Writer.Flush()
+
+    heartbeat := time.NewTicker(streamHeartbeatInterval)
+    defer heartbeat.Stop()
 
     // 循环推送，直到客户端断开
     for {
         select {
         case <-ctx.Request.Context().Done():
             return
-        case <-ticker.C:
-            stats, err := c.notificationService.GetNotificationStats(userID.(uint))
-            if err != nil {
+        case <-heartbeat.C:
+            if _, err := ctx.Writer.WriteString(": heartbeat\n\n"); err != nil {
+                return
+            }
+            ctx.Writer.Flush()
+        case event := <-sub.C:
+            // 补发阶段已推送过的通知不重复推送
+            if event.Event == "notification" && event.ID != 0 && event.ID <= lastSentID {
                 continue
             }
-            ctx.SSEvent("message", stats)
+            sseEvent := sse.Event{Event: event.Event, Data: event.Data}
+            if event.ID != 0 {
+                sseEvent.Id = strconv.FormatUint(uint64(event.ID), 10)
+            }
+            ctx.Render(-1, sseEvent)
+            switch {
+            case event.Stats != nil:
+                // 同一用户的多个连接共享事件，复制后再本地维护
+                latest := *event.Stats
+                stats = &latest
+                ctx.SSEvent("message", stats)
+            case event.Event == "broadcast" && stats != nil:
+                // 广播为每个用户新增一条未读系统通知
+                stats.UnreadCount++
+                stats.TotalCount++
+                ctx.SSEvent("message", stats)
+            }
             ctx.Writer.Flush()
         }
     }
@@ -175,6 +175,11 @@ func (c *PasswordResetController) ResetPassword(ctx *gin.Context) {
 		return
 	}
 
+	// 重置密码后吊销该用户的全部登录会话
+	if err := services.NewSessionService(c.db).RevokeAllUserSessions(user.ID, models.SessionRevokePasswordChange, ""); err != nil {
+		log.Printf("重置密码后吊销会话失败 user=%d: %v", user.ID, err)
+	}
+
 	utils.Success(ctx, gin.H{"message": "密码重置成功"})
 }
 
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"strconv"
+
+	"godad-backend/middleware"
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecommendationController 个性化推荐控制器
+type RecommendationController struct {
+	recommendationService *services.RecommendationService
+}
+
+// NewRecommendationController 创建推荐控制器
+func NewRecommendationController(recommendationService *services.RecommendationService) *RecommendationController {
+	return &RecommendationController{recommendationService: recommendationService}
+}
+
+// GetRecommendations 获取个性化推荐
+// @Summary 个性化推荐
+// @Description 根据点赞与收藏的协同过滤推荐文章，没有互动记录时推荐热门文章；不含自己的文章与屏蔽用户的文章
+// @Tags 推荐
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param limit query int false "返回数量" default(20)
+// @Success 200 {object} utils.Response{data=[]services.RecommendationItem}
+// @Router /api/recommendations [get]
+func (rc *RecommendationController) GetRecommendations(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 50 {
+		limit = 20
+	}
+
+	items, err := rc.recommendationService.GetRecommendations(userID, limit)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.Success(ctx, items)
+}
+
+// DismissRecommendation 标记推荐文章为不感兴趣
+// @Summary 不感兴趣
+// @Tags 推荐
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "文章ID"
+// @Success 200 {object} utils.Response
+// @Router /api/recommendations/{id}/dismiss [post]
+func (rc *RecommendationController) DismissRecommendation(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	articleID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的文章ID")
+		return
+	}
+
+	if err := rc.recommendationService.DismissArticle(userID, uint(articleID)); err != nil {
+		if err.Error() == "文章不存在" {
+			utils.Error(ctx, utils.CodeNotFound, err.Error())
+		} else {
+			utils.Error(ctx, utils.CodeInternalError, err.Error())
+		}
+		return
+	}
+	utils.Success(ctx, nil)
+}
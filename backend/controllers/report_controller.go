@@ -106,6 +106,7 @@ func (c *ReportController) AdminList(ctx *gin.Context) {
     }
 
     // 构造视图
+    moderation := services.NewModerationService(config.GetDB())
     viewItems := make([]models.ReportWithDetails, 0, len(items))
     for _, r := range items {
         v := models.ReportWithDetails{ Report: r }
@@ -130,6 +131,9 @@ func (c *ReportController) AdminList(ctx *gin.Context) {
             if title, ok := postTitles[r.TargetID]; ok {
                 v.TargetTitle = title
             }
+        } else if title, _, err := moderation.TargetInfo(r.TargetType, r.TargetID); err == nil {
+            // 回复/评论显示内容摘要
+            v.TargetTitle = title
         }
         viewItems = append(viewItems, v)
     }
@@ -139,64 +143,40 @@ func (c *ReportController) AdminList(ctx *gin.Context) {
 }
 
 // AdminUpdateStatus 处理举报（管理员）
+// 举报成立时执行处理动作（下架/删除/锁帖/隐藏/禁言/封禁/警告），并通知内容作者
 func (c *ReportController) AdminUpdateStatus(ctx *gin.Context) {
     id64, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
     if err != nil { utils.Error(ctx, utils.CodeBadRequest, "无效ID"); return }
     var body struct {
-        Status      string `json:"status"`
-        HandledNote string `json:"handled_note"`
-        Action      string `json:"action"` // 处理动作：article: unpublish/delete/warning; forum_post: lock/delete/warning
+        Status        string `json:"status"`
+        HandledNote   string `json:"handled_note"`
+        Action        string `json:"action"`         // 处理动作，见 models.ModerationAction
+        DurationHours int    `json:"duration_hours"` // 禁言/封禁时长（小时），封禁为0表示永久
     }
     if err := ctx.ShouldBindJSON(&body); err != nil { utils.Error(ctx, utils.CodeBadRequest, "参数错误"); return }
     adminID, _ := middleware.GetCurrentUserID(ctx)
-    // 校验动作
-    action := strings.ToLower(strings.TrimSpace(body.Action))
-    if body.Status == "reviewed" { // 仅通过时强制需要动作
-        // 获取举报以确认类型
-        var r models.Report
-        if err := config.GetDB().Where("id = ?", uint(id64)).First(&r).Error; err != nil {
-            utils.Error(ctx, utils.CodeBadRequest, "举报不存在"); return
-        }
-        t := strings.ToLower(r.TargetType)
-        valid := map[string]map[string]bool{
-            "article":    {"unpublish": true, "delete": true, "warning": true},
-            "forum_post": {"lock": true, "delete": true, "warning": true},
-        }
-        if _, ok := valid[t][action]; !ok {
-            utils.Error(ctx, utils.CodeBadRequest, "无效处理动作"); return
+
+    var moderation *services.ModerationRequest
+    if body.Status == "reviewed" { // 仅通过时需要动作
+        moderation = &services.ModerationRequest{
+            Action:        models.ModerationAction(strings.ToLower(strings.TrimSpace(body.Action))),
+            DurationHours: body.DurationHours,
+            Note:          body.HandledNote,
         }
     }
 
-    updated, err := c.svc.UpdateStatus(uint(id64), body.Status, adminID, body.HandledNote, action)
-    if err != nil { utils.Error(ctx, utils.CodeInternalServerError, err.Error()); return }
-
-    // 执行处理动作
-    if updated.Status == "reviewed" {
-        t := strings.ToLower(updated.TargetType)
-        switch t {
-        case "article":
-            switch action {
-            case "unpublish":
-                // 使用文章服务以便同时清理缓存
-                s := services.NewArticleService()
-                st := int8(2)
-                _, _ = s.UpdateArticle(updated.TargetID, adminID, &models.ArticleUpdateRequest{ Status: &st })
-            case "delete":
-                _ = services.NewArticleService().DeleteArticle(updated.TargetID, adminID)
-            case "warning":
-                // 仅通知，不改动资源
-            }
-        case "forum_post":
-            fs := services.NewForumService()
-            switch action {
-            case "lock":
-                _ = fs.AdminSetPostLock(updated.TargetID, true)
-            case "delete":
-                _ = fs.AdminDeletePost(updated.TargetID)
-            case "warning":
-                // 仅通知
-            }
+    updated, err := c.svc.UpdateStatus(uint(id64), body.Status, adminID, body.HandledNote, moderation)
+    if err != nil {
+        msg := err.Error()
+        code := utils.CodeInternalServerError
+        switch msg {
+        case "举报不存在", "被举报内容不存在":
+            code = utils.CodeNotFound
+        case "无效状态", "无效处理动作", "处理时长无效", "禁言需要指定时长", "举报已处理", "不能禁言或封禁管理员":
+            code = utils.CodeBadRequest
         }
+        utils.Error(ctx, code, msg)
+        return
     }
 
     // 发送系统通知给举报人
@@ -206,37 +186,22 @@ func (c *ReportController) AdminUpdateStatus(ctx *gin.Context) {
         notif := services.NewNotificationService(db)
         // 获取目标标题
         targetTitle := fmt.Sprintf("#%d", r.TargetID)
-        var targetAuthorID uint
-        if strings.ToLower(r.TargetType) == "article" {
-            var a models.Article
-            if err := db.Select("id, title, author_id").Where("id = ?", r.TargetID).First(&a).Error; err == nil {
-                targetTitle = a.Title
-                targetAuthorID = a.AuthorID
-            }
-        } else if strings.ToLower(r.TargetType) == "forum_post" {
-            var p models.ForumPost
-            if err := db.Select("id, title, author_id").Where("id = ?", r.TargetID).First(&p).Error; err == nil {
-                targetTitle = p.Title
-                targetAuthorID = p.AuthorID
-            }
+        if title, _, err := services.NewModerationService(db).TargetInfo(r.TargetType, r.TargetID); err == nil && title != "" {
+            targetTitle = title
         }
         // 组装消息
         resultText := "已处理"
         if r.Status == "reviewed" { resultText = "已通过" } else if r.Status == "rejected" { resultText = "已驳回" }
         note := strings.TrimSpace(r.HandledNote)
         if len(note) > 120 { note = note[:120] + "..." }
-        typeMap := map[string]string{"article":"文章","forum_post":"社区帖子"}
         // 包含举报理由与处理动作
         var extra []string
         if r.Reason != "" { extra = append(extra, "原因："+r.Reason) }
         if r.HandledAction != "" {
-            actMap := map[string]string{"unpublish":"下架","delete":"删除","lock":"锁定","warning":"警告"}
-            act := r.HandledAction
-            if v, ok := actMap[strings.ToLower(act)]; ok { act = v }
-            extra = append(extra, "动作："+act)
+            extra = append(extra, "动作："+models.ModerationAction(r.HandledAction).Label())
         }
         extras := strings.Join(extra, "，")
-        base := fmt.Sprintf("您对%s《%s》的举报%s", typeMap[strings.ToLower(r.TargetType)], targetTitle, resultText)
+        base := fmt.Sprintf("您对%s《%s》的举报%s", services.ReportTargetLabel(r.TargetType), targetTitle, resultText)
         msg := base
         if extras != "" { msg = msg + "（" + extras + "）" }
         if note != "" { msg = msg + "。备注：" + note }
@@ -251,36 +216,6 @@ func (c *ReportController) AdminUpdateStatus(ctx *gin.Context) {
             IsRead:     false,
         }
         _ = notif.CreateNotification(n)
-
-        // 若举报成立（reviewed），通知被举报内容的作者
-        if r.Status == "reviewed" && targetAuthorID != 0 {
-            // 被举报作者通知，包含原因与动作
-            var extraB []string
-            if r.Reason != "" { extraB = append(extraB, "原因："+r.Reason) }
-            if r.HandledAction != "" {
-                actMap := map[string]string{"unpublish":"下架","delete":"删除","lock":"锁定","warning":"警告"}
-                act := r.HandledAction
-                if v, ok := actMap[strings.ToLower(act)]; ok { act = v }
-                extraB = append(extraB, "已执行："+act)
-            }
-            extrasB := strings.Join(extraB, "，")
-            authorBase := fmt.Sprintf("您的%s《%s》被举报并经审核确认存在违规", typeMap[strings.ToLower(r.TargetType)], targetTitle)
-            authorMsg := authorBase
-            if extrasB != "" { authorMsg = authorMsg + "（" + extrasB + "）" }
-            if note != "" { authorMsg = authorMsg + "。处理备注：" + note }
-            // 引导申诉说明（前端会在消息正文内提供“申诉”按钮）
-            authorMsg = authorMsg + "。如对此结果有疑问，可在本通知中点击“申诉”发起复核"
-            n2 := &models.Notification{
-                ReceiverID: targetAuthorID,
-                ActorID:    adminID,
-                Type:       models.NotificationTypeModeration,
-                Title:      "内容违规处理通知",
-                ResourceID: r.TargetID,
-                Message:    authorMsg,
-                IsRead:     false,
-            }
-            _ = notif.CreateNotification(n2)
-        }
     }(updated)
 
     utils.Success(ctx, nil)
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchController 搜索控制器
+type SearchController struct {
+	searchService *services.SearchService
+}
+
+// NewSearchController 创建搜索控制器实例
+func NewSearchController() *SearchController {
+	return &SearchController{
+		searchService: services.NewSearchService(),
+	}
+}
+
+// Search 全文搜索
+// @Summary 全文搜索
+// @Description 搜索文章、论坛帖子和资源，按相关度与发布时间综合排序，返回高亮片段
+// @Tags 搜索
+// @Produce json
+// @Param keyword query string true "关键词"
+// @Param type query string false "文档类型，逗号分隔：article,forum_post,resource；全部无效时返回400"
+// @Param category_id query int false "文章分类ID（指定后仅搜索文章）"
+// @Param sort query string false "排序：relevance（默认）/ latest"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response{data=services.SearchResult} "搜索成功"
+// @Router /api/search [get]
+func (sc *SearchController) Search(ctx *gin.Context) {
+	var req services.SearchRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "请求参数错误")
+		return
+	}
+
+	result, err := sc.searchService.Search(&req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSearchType) {
+			utils.Error(ctx, utils.CodeBadRequest, err.Error())
+			return
+		}
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// Suggestions 搜索建议
+// @Summary 搜索建议
+// @Description 根据输入的关键词返回文章标题建议
+// @Tags 搜索
+// @Produce json
+// @Param keyword query string true "关键词"
+// @Param limit query int false "数量" default(5)
+// @Success 200 {object} utils.Response{data=[]string} "获取成功"
+// @Router /api/search/suggestions [get]
+func (sc *SearchController) Suggestions(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "5"))
+	if limit > 20 {
+		limit = 20
+	}
+
+	suggestions, err := sc.searchService.GetSearchSuggestions(ctx.Query("keyword"), limit)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+
+	utils.Success(ctx, suggestions)
+}
+
+// HotKeywords 热门搜索词
+// @Summary 热门搜索词
+// @Tags 搜索
+// @Produce json
+// @Param limit query int false "数量" default(10)
+// @Success 200 {object} utils.Response{data=[]string} "获取成功"
+// @Router /api/search/hot [get]
+func (sc *SearchController) HotKeywords(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if limit > 50 {
+		limit = 50
+	}
+
+	keywords, err := sc.searchService.GetHotKeywords(limit)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+
+	utils.Success(ctx, keywords)
+}
@@ -0,0 +1,377 @@
+package controllers
+
+import (
+	"strconv"
+
+	"godad-backend/middleware"
+	"godad-backend/models"
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeriesController 文章系列控制器
+type SeriesController struct {
+	seriesService *services.SeriesService
+}
+
+// NewSeriesController 创建系列控制器
+func NewSeriesController(seriesService *services.SeriesService) *SeriesController {
+	return &SeriesController{seriesService: seriesService}
+}
+
+// ListSeries 系列列表
+// @Summary 系列列表
+// @Tags 文章系列
+// @Produce json
+// @Param owner_id query int false "创建者ID"
+// @Param kind query string false "类型：author(作者连载)、editorial(编辑专题)"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response{data=[]models.SeriesResponse}
+// @Router /api/series [get]
+func (sc *SeriesController) ListSeries(ctx *gin.Context) {
+	kind := ctx.Query("kind")
+	if kind != "" && kind != models.SeriesKindAuthor && kind != models.SeriesKindEditorial {
+		utils.Error(ctx, utils.CodeBadRequest, "类型参数无效，支持：author, editorial")
+		return
+	}
+	var ownerID uint
+	if ctx.Query("owner_id") != "" {
+		id, err := strconv.ParseUint(ctx.Query("owner_id"), 10, 32)
+		if err != nil {
+			utils.Error(ctx, utils.CodeBadRequest, "无效的用户ID")
+			return
+		}
+		ownerID = uint(id)
+	}
+
+	page, size := utils.ParsePaginationParams(ctx)
+	series, total, err := sc.seriesService.ListSeries(ownerID, kind, page, size)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.SuccessPage(ctx, seriesResponses(series), total, page, size)
+}
+
+// GetSeries 系列页：系列信息及按顺序排列的文章
+// @Summary 系列详情
+// @Description 创建者与管理员可以看到未发布的文章
+// @Tags 文章系列
+// @Produce json
+// @Param id path int true "系列ID"
+// @Success 200 {object} utils.Response
+// @Router /api/series/{id} [get]
+func (sc *SeriesController) GetSeries(ctx *gin.Context) {
+	seriesID, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的系列ID")
+		return
+	}
+
+	series, err := sc.seriesService.GetSeries(seriesID)
+	if err != nil {
+		respondSeriesError(ctx, err)
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(ctx)
+	items, err := sc.seriesService.GetSeriesArticles(series, userID)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+
+	articles := make([]*models.SeriesArticleResponse, 0, len(items))
+	for i := range items {
+		articles = append(articles, &models.SeriesArticleResponse{
+			Position: items[i].Position,
+			Article:  items[i].Article.ToResponse(false),
+		})
+	}
+	seriesResp := series.ToResponse()
+	seriesResp.IsFollowing = sc.seriesService.IsFollowingSeries(userID, series.ID)
+
+	utils.Success(ctx, gin.H{
+		"series":   seriesResp,
+		"articles": articles,
+	})
+}
+
+// CreateSeries 创建系列
+// @Summary 创建系列
+// @Description editorial 为 true 时创建编辑专题，仅管理员可用
+// @Tags 文章系列
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body models.SeriesCreateRequest true "系列信息"
+// @Success 200 {object} utils.Response{data=models.SeriesResponse}
+// @Router /api/series [post]
+func (sc *SeriesController) CreateSeries(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	var req models.SeriesCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	series, err := sc.seriesService.CreateSeries(userID, &req)
+	if err != nil {
+		respondSeriesError(ctx, err)
+		return
+	}
+	utils.Success(ctx, series.ToResponse())
+}
+
+// UpdateSeries 更新系列
+// @Summary 更新系列
+// @Tags 文章系列
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "系列ID"
+// @Param request body models.SeriesUpdateRequest true "系列信息"
+// @Success 200 {object} utils.Response{data=models.SeriesResponse}
+// @Router /api/series/{id} [put]
+func (sc *SeriesController) UpdateSeries(ctx *gin.Context) {
+	userID, seriesID, ok := seriesTarget(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.SeriesUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	series, err := sc.seriesService.UpdateSeries(seriesID, userID, &req)
+	if err != nil {
+		respondSeriesError(ctx, err)
+		return
+	}
+	utils.Success(ctx, series.ToResponse())
+}
+
+// DeleteSeries 删除系列
+// @Summary 删除系列
+// @Description 只删除系列本身，其中的文章不受影响
+// @Tags 文章系列
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "系列ID"
+// @Success 200 {object} utils.Response
+// @Router /api/series/{id} [delete]
+func (sc *SeriesController) DeleteSeries(ctx *gin.Context) {
+	userID, seriesID, ok := seriesTarget(ctx)
+	if !ok {
+		return
+	}
+	if err := sc.seriesService.DeleteSeries(seriesID, userID); err != nil {
+		respondSeriesError(ctx, err)
+		return
+	}
+	utils.Success(ctx, nil)
+}
+
+// AddSeriesArticle 向系列添加文章
+// @Summary 添加系列文章
+// @Description 作者连载只能添加创建者自己的文章；不指定位置时追加到末尾
+// @Tags 文章系列
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "系列ID"
+// @Param request body models.SeriesAddArticleRequest true "文章与位置"
+// @Success 200 {object} utils.Response
+// @Router /api/series/{id}/articles [post]
+func (sc *SeriesController) AddSeriesArticle(ctx *gin.Context) {
+	userID, seriesID, ok := seriesTarget(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.SeriesAddArticleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := sc.seriesService.AddArticle(seriesID, userID, &req); err != nil {
+		respondSeriesError(ctx, err)
+		return
+	}
+	utils.Success(ctx, nil)
+}
+
+// RemoveSeriesArticle 从系列移除文章
+// @Summary 移除系列文章
+// @Tags 文章系列
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "系列ID"
+// @Param articleId path int true "文章ID"
+// @Success 200 {object} utils.Response
+// @Router /api/series/{id}/articles/{articleId} [delete]
+func (sc *SeriesController) RemoveSeriesArticle(ctx *gin.Context) {
+	userID, seriesID, ok := seriesTarget(ctx)
+	if !ok {
+		return
+	}
+	articleID, err := utils.ParseUintParam(ctx, "articleId")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的文章ID")
+		return
+	}
+
+	if err := sc.seriesService.RemoveArticle(seriesID, articleID, userID); err != nil {
+		respondSeriesError(ctx, err)
+		return
+	}
+	utils.Success(ctx, nil)
+}
+
+// ReorderSeriesArticles 调整系列文章顺序
+// @Summary 调整系列文章顺序
+// @Description article_ids 需包含系列中的全部文章
+// @Tags 文章系列
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "系列ID"
+// @Param request body models.SeriesReorderRequest true "新的文章顺序"
+// @Success 200 {object} utils.Response
+// @Router /api/series/{id}/articles/order [put]
+func (sc *SeriesController) ReorderSeriesArticles(ctx *gin.Context) {
+	userID, seriesID, ok := seriesTarget(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.SeriesReorderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := sc.seriesService.ReorderArticles(seriesID, userID, req.ArticleIDs); err != nil {
+		respondSeriesError(ctx, err)
+		return
+	}
+	utils.Success(ctx, nil)
+}
+
+// FollowSeries 关注系列
+// @Summary 关注系列
+// @Description 系列有新文章发布时会收到通知
+// @Tags 文章系列
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "系列ID"
+// @Success 200 {object} utils.Response
+// @Router /api/series/{id}/follow [post]
+func (sc *SeriesController) FollowSeries(ctx *gin.Context) {
+	userID, seriesID, ok := seriesTarget(ctx)
+	if !ok {
+		return
+	}
+	if err := sc.seriesService.FollowSeries(userID, seriesID); err != nil {
+		respondSeriesError(ctx, err)
+		return
+	}
+	utils.Success(ctx, nil)
+}
+
+// UnfollowSeries 取消关注系列
+// @Summary 取消关注系列
+// @Tags 文章系列
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "系列ID"
+// @Success 200 {object} utils.Response
+// @Router /api/series/{id}/follow [delete]
+func (sc *SeriesController) UnfollowSeries(ctx *gin.Context) {
+	userID, seriesID, ok := seriesTarget(ctx)
+	if !ok {
+		return
+	}
+	if err := sc.seriesService.UnfollowSeries(userID, seriesID); err != nil {
+		respondSeriesError(ctx, err)
+		return
+	}
+	utils.Success(ctx, nil)
+}
+
+// GetFollowedSeries 我关注的系列
+// @Summary 我关注的系列
+// @Tags 文章系列
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response{data=[]models.SeriesResponse}
+// @Router /api/series/following [get]
+func (sc *SeriesController) GetFollowedSeries(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	page, size := utils.ParsePaginationParams(ctx)
+	series, total, err := sc.seriesService.GetFollowedSeries(userID, page, size)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	responses := seriesResponses(series)
+	for _, resp := range responses {
+		resp.IsFollowing = true
+	}
+	utils.SuccessPage(ctx, responses, total, page, size)
+}
+
+// seriesTarget 解析当前用户与路径中的系列ID，失败时已写入响应
+func seriesTarget(ctx *gin.Context) (uint, uint, bool) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return 0, 0, false
+	}
+	seriesID, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的系列ID")
+		return 0, 0, false
+	}
+	return userID, seriesID, true
+}
+
+func seriesResponses(series []models.Series) []*models.SeriesResponse {
+	responses := make([]*models.SeriesResponse, 0, len(series))
+	for i := range series {
+		responses = append(responses, series[i].ToResponse())
+	}
+	return responses
+}
+
+// respondSeriesError 系列相关错误的响应
+func respondSeriesError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case "系列不存在", "文章不存在":
+		utils.Error(ctx, utils.CodeNotFound, err.Error())
+	case "无权限管理此系列", "只有管理员可以创建专题", "只能添加自己的文章":
+		utils.Error(ctx, utils.CodeForbidden, err.Error())
+	case "系列标题不能为空", "文章已在该系列中", "文章不在该系列中", "文章列表与系列不一致", "已关注该系列", "未关注该系列":
+		utils.Error(ctx, utils.CodeBadRequest, err.Error())
+	default:
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+	}
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SyndicationController 订阅源与站点地图控制器
+type SyndicationController struct {
+	syndicationService *services.SyndicationService
+}
+
+// NewSyndicationController 创建订阅源控制器
+func NewSyndicationController(syndicationService *services.SyndicationService) *SyndicationController {
+	return &SyndicationController{syndicationService: syndicationService}
+}
+
+// GetSiteFeed 全站订阅源
+// @Summary 全站订阅源
+// @Description 最新发布的文章，format 为 atom 或 rss；支持 ETag / Last-Modified 条件请求
+// @Tags 订阅源
+// @Produce xml
+// @Param format path string true "格式 atom/rss"
+// @Success 200 {string} string "XML"
+// @Success 304 {string} string "未修改"
+// @Router /api/feeds/{format} [get]
+func (sc *SyndicationController) GetSiteFeed(ctx *gin.Context) {
+	doc, err := sc.syndicationService.GetSiteFeed(ctx.Param("format"))
+	sc.respond(ctx, doc, err)
+}
+
+// GetCategoryFeed 分类订阅源
+// @Summary 分类订阅源
+// @Tags 订阅源
+// @Produce xml
+// @Param slug path string true "分类别名"
+// @Param format path string true "格式 atom/rss"
+// @Success 200 {string} string "XML"
+// @Router /api/feeds/categories/{slug}/{format} [get]
+func (sc *SyndicationController) GetCategoryFeed(ctx *gin.Context) {
+	doc, err := sc.syndicationService.GetCategoryFeed(ctx.Param("slug"), ctx.Param("format"))
+	sc.respond(ctx, doc, err)
+}
+
+// GetAuthorFeed 作者订阅源
+// @Summary 作者订阅源
+// @Tags 订阅源
+// @Produce xml
+// @Param id path int true "作者ID"
+// @Param format path string true "格式 atom/rss"
+// @Success 200 {string} string "XML"
+// @Router /api/feeds/authors/{id}/{format} [get]
+func (sc *SyndicationController) GetAuthorFeed(ctx *gin.Context) {
+	authorID, err := utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的用户ID")
+		return
+	}
+	doc, err := sc.syndicationService.GetAuthorFeed(authorID, ctx.Param("format"))
+	sc.respond(ctx, doc, err)
+}
+
+// GetTopicFeed 论坛话题订阅源
+// @Summary 论坛话题订阅源
+// @Description topic 为 All 时包含全部话题
+// @Tags 订阅源
+// @Produce xml
+// @Param topic path string true "话题"
+// @Param format path string true "格式 atom/rss"
+// @Success 200 {string} string "XML"
+// @Router /api/feeds/topics/{topic}/{format} [get]
+func (sc *SyndicationController) GetTopicFeed(ctx *gin.Context) {
+	doc, err := sc.syndicationService.GetTopicFeed(ctx.Param("topic"), ctx.Param("format"))
+	sc.respond(ctx, doc, err)
+}
+
+// GetSitemapIndex 站点地图索引
+// @Summary 站点地图索引
+// @Tags 订阅源
+// @Produce xml
+// @Success 200 {string} string "XML"
+// @Router /sitemap.xml [get]
+func (sc *SyndicationController) GetSitemapIndex(ctx *gin.Context) {
+	doc, err := sc.syndicationService.GetSitemapIndex()
+	sc.respond(ctx, doc, err)
+}
+
+// GetSitemapPage 站点地图分页
+// @Summary 站点地图分页
+// @Tags 订阅源
+// @Produce xml
+// @Param type path string true "类型 articles/posts"
+// @Param page path string true "页码，如 1.xml"
+// @Success 200 {string} string "XML"
+// @Router /sitemaps/{type}/{page} [get]
+func (sc *SyndicationController) GetSitemapPage(ctx *gin.Context) {
+	page, err := strconv.Atoi(strings.TrimSuffix(ctx.Param("page"), ".xml"))
+	if err != nil {
+		utils.Error(ctx, utils.CodeNotFound, "站点地图不存在")
+		return
+	}
+	doc, err := sc.syndicationService.GetSitemapPage(ctx.Param("type"), page)
+	sc.respond(ctx, doc, err)
+}
+
+// respond 输出 XML 文档，命中 If-None-Match / If-Modified-Since 时返回 304
+func (sc *SyndicationController) respond(ctx *gin.Context, doc *services.SyndicationDocument, err error) {
+	if err != nil {
+		switch err.Error() {
+		case "分类不存在", "用户不存在", "站点地图不存在":
+			utils.Error(ctx, utils.CodeNotFound, err.Error())
+		case "无效的话题分类", "不支持的订阅格式":
+			utils.Error(ctx, utils.CodeBadRequest, err.Error())
+		default:
+			utils.Error(ctx, utils.CodeInternalError, err.Error())
+		}
+		return
+	}
+
+	ctx.Header("ETag", doc.ETag)
+	ctx.Header("Last-Modified", doc.LastModified.Format(http.TimeFormat))
+	ctx.Header("Cache-Control", "public, max-age=300")
+
+	if notModified(ctx, doc) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	ctx.Data(http.StatusOK, doc.ContentType, []byte(doc.Body))
+}
+
+// notModified 判断条件请求，If-None-Match 存在时优先于 If-Modified-Since
+func notModified(ctx *gin.Context, doc *services.SyndicationDocument) bool {
+	if match := ctx.GetHeader("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == doc.ETag {
+				return true
+			}
+		}
+		return false
+	}
+	if since := ctx.GetHeader("If-Modified-Since"); since != "" {
+		if t, err := http.ParseTime(since); err == nil && !doc.LastModified.After(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"strconv"
+
+	"godad-backend/middleware"
+	"godad-backend/models"
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagController 标签控制器
+type TagController struct {
+	tagService *services.TagService
+}
+
+// NewTagController 创建标签控制器
+func NewTagController(tagService *services.TagService) *TagController {
+	return &TagController{tagService: tagService}
+}
+
+// ListTags 按文章数获取标签列表
+// @Summary 标签列表
+// @Tags 标签
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response{data=[]models.TagResponse}
+// @Router /api/tags [get]
+func (tc *TagController) ListTags(ctx *gin.Context) {
+	page, size := utils.ParsePaginationParams(ctx)
+	tags, total, err := tc.tagService.GetPopularTags(page, size)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.SuccessPage(ctx, tagResponses(tags), total, page, size)
+}
+
+// SuggestTags 标签自动补全
+// @Summary 标签自动补全
+// @Tags 标签
+// @Produce json
+// @Param q query string true "输入前缀"
+// @Param limit query int false "返回数量" default(10)
+// @Success 200 {object} utils.Response{data=[]models.TagResponse}
+// @Router /api/tags/suggest [get]
+func (tc *TagController) SuggestTags(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 20 {
+		limit = 10
+	}
+	tags, err := tc.tagService.SuggestTags(ctx.Query("q"), limit)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.Success(ctx, tagResponses(tags))
+}
+
+// GetTag 标签页：标签信息及其下的文章
+// @Summary 标签页
+// @Tags 标签
+// @Produce json
+// @Param slug path string true "标签别名"
+// @Param sort query string false "排序：new(最新)、hot(最热)" default(new)
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response
+// @Router /api/tags/{slug} [get]
+func (tc *TagController) GetTag(ctx *gin.Context) {
+	tag, err := tc.tagService.GetTagBySlug(ctx.Param("slug"))
+	if err != nil {
+		respondTagError(ctx, err)
+		return
+	}
+
+	sort := ctx.DefaultQuery("sort", "new")
+	if sort != "new" && sort != "hot" {
+		utils.Error(ctx, utils.CodeBadRequest, "排序参数无效，支持：new, hot")
+		return
+	}
+
+	page, size := utils.ParsePaginationParams(ctx)
+	articles, total, err := tc.tagService.GetTagArticles(tag.ID, sort, page, size)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(ctx)
+	tagResp := tag.ToResponse()
+	tagResp.IsFollowing = tc.tagService.IsFollowingTag(userID, tag.ID)
+
+	utils.Success(ctx, gin.H{
+		"tag":      tagResp,
+		"articles": utils.NewPaginationResponse(articleResponses(articles), total, page, size),
+	})
+}
+
+// FollowTag 关注标签
+// @Summary 关注标签
+// @Tags 标签
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param slug path string true "标签别名"
+// @Success 200 {object} utils.Response
+// @Router /api/tags/{slug}/follow [post]
+func (tc *TagController) FollowTag(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+	tag, err := tc.tagService.GetTagBySlug(ctx.Param("slug"))
+	if err != nil {
+		respondTagError(ctx, err)
+		return
+	}
+	if err := tc.tagService.FollowTag(userID, tag.ID); err != nil {
+		respondTagError(ctx, err)
+		return
+	}
+	utils.Success(ctx, nil)
+}
+
+// UnfollowTag 取消关注标签
+// @Summary 取消关注标签
+// @Tags 标签
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param slug path string true "标签别名"
+// @Success 200 {object} utils.Response
+// @Router /api/tags/{slug}/follow [delete]
+func (tc *TagController) UnfollowTag(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+	tag, err := tc.tagService.GetTagBySlug(ctx.Param("slug"))
+	if err != nil {
+		respondTagError(ctx, err)
+		return
+	}
+	if err := tc.tagService.UnfollowTag(userID, tag.ID); err != nil {
+		respondTagError(ctx, err)
+		return
+	}
+	utils.Success(ctx, nil)
+}
+
+// GetFollowedTags 我关注的标签
+// @Summary 我关注的标签
+// @Tags 标签
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} utils.Response{data=[]models.TagResponse}
+// @Router /api/tags/following [get]
+func (tc *TagController) GetFollowedTags(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+	tags, err := tc.tagService.GetFollowedTags(userID)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	responses := tagResponses(tags)
+	for _, resp := range responses {
+		resp.IsFollowing = true
+	}
+	utils.Success(ctx, responses)
+}
+
+// GetFollowedTagArticles 我关注的标签下的最新文章
+// @Summary 关注标签的文章
+// @Tags 标签
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response{data=[]models.ArticleResponse}
+// @Router /api/tags/following/articles [get]
+func (tc *TagController) GetFollowedTagArticles(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+	page, size := utils.ParsePaginationParams(ctx)
+	articles, total, err := tc.tagService.GetFollowedTagArticles(userID, page, size)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.SuccessPage(ctx, articleResponses(articles), total, page, size)
+}
+
+func tagResponses(tags []models.Tag) []*models.TagResponse {
+	responses := make([]*models.TagResponse, 0, len(tags))
+	for i := range tags {
+		responses = append(responses, tags[i].ToResponse())
+	}
+	return responses
+}
+
+func articleResponses(articles []*models.Article) []*models.ArticleResponse {
+	responses := make([]*models.ArticleResponse, 0, len(articles))
+	for _, article := range articles {
+		responses = append(responses, article.ToResponse(false))
+	}
+	return responses
+}
+
+// respondTagError 标签相关错误的响应
+func respondTagError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case "标签不存在":
+		utils.Error(ctx, utils.CodeNotFound, err.Error())
+	case "已关注该标签", "未关注该标签":
+		utils.Error(ctx, utils.CodeBadRequest, err.Error())
+	default:
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+	}
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"strconv"
+
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrendingController 热度排行控制器
+type TrendingController struct {
+	trendingService *services.TrendingService
+}
+
+// NewTrendingController 创建热度排行控制器
+func NewTrendingController(trendingService *services.TrendingService) *TrendingController {
+	return &TrendingController{trendingService: trendingService}
+}
+
+// GetTrendingArticles 获取热门文章
+// @Summary 热门文章
+// @Description 按近期点赞、评论、收藏与浏览计算的时间衰减热度排序
+// @Tags 热度排行
+// @Produce json
+// @Param limit query int false "数量" default(20)
+// @Success 200 {object} utils.Response{data=[]services.TrendingArticleItem}
+// @Router /api/trending/articles [get]
+func (tc *TrendingController) GetTrendingArticles(ctx *gin.Context) {
+	items, err := tc.trendingService.GetTrendingArticles(trendingLimit(ctx))
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.Success(ctx, items)
+}
+
+// GetTrendingForumPosts 获取热门帖子
+// @Summary 热门帖子
+// @Description 按近期点赞、回复与浏览计算的时间衰减热度排序
+// @Tags 热度排行
+// @Produce json
+// @Param limit query int false "数量" default(20)
+// @Success 200 {object} utils.Response{data=[]services.TrendingForumPostItem}
+// @Router /api/trending/forum-posts [get]
+func (tc *TrendingController) GetTrendingForumPosts(ctx *gin.Context) {
+	items, err := tc.trendingService.GetTrendingForumPosts(trendingLimit(ctx))
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+	utils.Success(ctx, items)
+}
+
+func trendingLimit(ctx *gin.Context) int {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 50 {
+		limit = 20
+	}
+	return limit
+}
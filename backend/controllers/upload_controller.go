@@ -3,6 +3,7 @@ package controllers
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"godad-backend/middleware"
 	"godad-backend/models"
@@ -31,7 +32,7 @@ func NewUploadController() *UploadController {
 
 // UploadImage 上传图片
 // @Summary 上传图片
-// @Description 上传图片文件到阿里云OSS
+// @Description 上传图片文件到配置的存储后端（OSS / S3 / 本地磁盘）
 // @Tags 文件上传
 // @Accept multipart/form-data
 // @Produce json
@@ -258,4 +259,52 @@ func (c *UploadController) GetUpload(ctx *gin.Context) {
 	}
 
 	utils.Success(ctx, upload.ToResponse())
-}
\ No newline at end of file
+}
+
+// GetSignedURL 获取上传文件的临时访问地址
+// @Summary 获取上传文件的临时访问地址
+// @Description 为当前用户上传的文件生成带签名的临时访问地址
+// @Tags 文件上传
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "上传记录ID"
+// @Param expires query int false "有效期（秒），默认使用 STORAGE_SIGNED_URL_EXPIRE"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/upload/{id}/signed-url [get]
+func (c *UploadController) GetSignedURL(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "用户未登录")
+		return
+	}
+
+	uploadID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "上传记录ID格式错误")
+		return
+	}
+
+	var expires time.Duration
+	if v := ctx.Query("expires"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds <= 0 || seconds > 7*24*3600 {
+			utils.Error(ctx, utils.CodeBadRequest, "有效期需在 1 秒到 7 天之间")
+			return
+		}
+		expires = time.Duration(seconds) * time.Second
+	}
+
+	signedURL, expiresAt, err := c.uploadService.GetSignedURL(uint(uploadID), userID, expires)
+	if err != nil {
+		utils.Error(ctx, utils.CodeNotFound, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"url":        signedURL,
+		"expires_at": expiresAt,
+	})
+}
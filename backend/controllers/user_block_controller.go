@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"strconv"
+
+	"godad-backend/middleware"
+	"godad-backend/models"
+	"godad-backend/services"
+	"godad-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserBlockController 用户屏蔽控制器
+type UserBlockController struct {
+	blockService *services.UserBlockService
+}
+
+// NewUserBlockController 创建用户屏蔽控制器
+func NewUserBlockController(blockService *services.UserBlockService) *UserBlockController {
+	return &UserBlockController{blockService: blockService}
+}
+
+// GetBlockedUsers 获取屏蔽列表
+// @Summary 屏蔽列表
+// @Tags 用户屏蔽
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "页码" default(1)
+// @Param size query int false "每页数量" default(10)
+// @Success 200 {object} utils.Response{data=[]models.UserBlockResponse}
+// @Router /api/blocks [get]
+func (bc *UserBlockController) GetBlockedUsers(ctx *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return
+	}
+
+	page, size := utils.ParsePaginationParams(ctx)
+	blocks, total, err := bc.blockService.GetBlockedUsers(userID, page, size)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+		return
+	}
+
+	responses := make([]*models.UserBlockResponse, 0, len(blocks))
+	for _, block := range blocks {
+		responses = append(responses, block.ToResponse())
+	}
+	utils.SuccessPage(ctx, responses, total, page, size)
+}
+
+// BlockUser 屏蔽用户
+// @Summary 屏蔽用户
+// @Description 被屏蔽用户的内容不再出现在信息流与推荐中，同时取消对其的关注
+// @Tags 用户屏蔽
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "用户ID"
+// @Success 200 {object} utils.Response
+// @Router /api/blocks/{id} [post]
+func (bc *UserBlockController) BlockUser(ctx *gin.Context) {
+	userID, targetID, ok := blockTarget(ctx)
+	if !ok {
+		return
+	}
+	if err := bc.blockService.BlockUser(userID, targetID); err != nil {
+		respondBlockError(ctx, err)
+		return
+	}
+	utils.Success(ctx, nil)
+}
+
+// UnblockUser 取消屏蔽
+// @Summary 取消屏蔽
+// @Tags 用户屏蔽
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "用户ID"
+// @Success 200 {object} utils.Response
+// @Router /api/blocks/{id} [delete]
+func (bc *UserBlockController) UnblockUser(ctx *gin.Context) {
+	userID, targetID, ok := blockTarget(ctx)
+	if !ok {
+		return
+	}
+	if err := bc.blockService.UnblockUser(userID, targetID); err != nil {
+		respondBlockError(ctx, err)
+		return
+	}
+	utils.Success(ctx, nil)
+}
+
+// blockTarget 解析当前用户与目标用户ID，失败时已写入响应
+func blockTarget(ctx *gin.Context) (uint, uint, bool) {
+	userID, exists := middleware.GetCurrentUserID(ctx)
+	if !exists {
+		utils.Error(ctx, utils.CodeUnauthorized, "未登录")
+		return 0, 0, false
+	}
+	targetID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的用户ID")
+		return 0, 0, false
+	}
+	return userID, uint(targetID), true
+}
+
+// respondBlockError 屏蔽相关错误的响应
+func respondBlockError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case "用户不存在":
+		utils.Error(ctx, utils.CodeNotFound, err.Error())
+	case "不能屏蔽自己", "已屏蔽该用户", "未屏蔽该用户":
+		utils.Error(ctx, utils.CodeBadRequest, err.Error())
+	default:
+		utils.Error(ctx, utils.CodeInternalError, err.Error())
+	}
+}
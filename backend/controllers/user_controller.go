@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
 
+	"godad-backend/config"
 	"godad-backend/container"
 	"godad-backend/middleware"
 	"godad-backend/models"
@@ -16,6 +18,7 @@ import (
 type UserController struct {
 	userService    *services.UserService
 	articleService *services.ArticleService
+	sessionService *services.SessionService
 }
 
 // NewUserController 创建用户控制器实例（兼容旧版本）
@@ -23,6 +26,7 @@ func NewUserController() *UserController {
 	return &UserController{
 		userService:    services.NewUserService(),
 		articleService: services.NewArticleService(),
+		sessionService: services.NewSessionService(config.GetDB()),
 	}
 }
 
@@ -32,6 +36,7 @@ func NewUserControllerWithDI() *UserController {
 	return &UserController{
 		userService:    c.GetUserService(),
 		articleService: c.GetArticleService(),
+		sessionService: services.NewSessionService(config.GetDB()),
 	}
 }
 
@@ -90,20 +95,16 @@ func (c *UserController) Login(ctx *gin.Context) {
 		return
 	}
 
-	// 生成JWT令牌（access + refresh）
-	token, err := middleware.GenerateToken(user)
+	// 创建登录会话
+	session, err := c.sessionService.CreateSession(user.ID, ctx.Request.UserAgent(), ctx.ClientIP())
 	if err != nil {
-		utils.Error(ctx, utils.CodeInternalError, "生成令牌失败")
+		utils.Error(ctx, utils.CodeInternalError, "创建登录会话失败")
 		return
 	}
-	refresh, err := middleware.GenerateRefreshToken(user)
-	if err != nil {
-		utils.Error(ctx, utils.CodeInternalError, "生成刷新令牌失败")
-		return
-	}
-	csrfToken, err := middleware.SetAuthCookies(ctx, token, refresh)
-	if err != nil {
-		utils.Error(ctx, utils.CodeInternalError, "设置认证 Cookie 失败")
+
+	// 生成JWT令牌（access + refresh）
+	token, csrfToken, ok := c.issueTokens(ctx, user, session)
+	if !ok {
 		return
 	}
 
@@ -215,6 +216,12 @@ func (c *UserController) ChangePassword(ctx *gin.Context) {
 		return
 	}
 
+	// 修改密码后下线其他设备，保留当前会话
+	if err := c.sessionService.RevokeAllUserSessions(userID, models.SessionRevokePasswordChange, middleware.GetCurrentSessionID(ctx)); err != nil {
+		utils.Error(ctx, utils.CodeInternalError, "密码已修改，但下线其他设备失败")
+		return
+	}
+
 	utils.SuccessWithMessage(ctx, "密码修改成功", nil)
 }
 
@@ -257,36 +264,60 @@ func (c *UserController) CheckNickname(ctx *gin.Context) {
 
 // RefreshToken 刷新令牌
 // @Summary 刷新令牌
-// @Description 刷新用户的JWT令牌
+// @Description 使用刷新令牌（Cookie 或请求体）轮换访问令牌和刷新令牌；刷新令牌被重复使用时吊销整个会话
 // @Tags 用户管理
 // @Accept json
 // @Produce json
-// @Security ApiKeyAuth
+// @Param request body map[string]string false "刷新令牌（未携带 refresh_token Cookie 时使用）"
 // @Success 200 {object} utils.Response{data=map[string]string} "刷新成功"
 // @Failure 401 {object} utils.Response "未授权"
-// @Router /api/user/refresh-token [post]
+// @Router /api/auth/refresh-token [post]
 func (c *UserController) RefreshToken(ctx *gin.Context) {
-	// 从中间件获取当前用户信息
-	user, err := middleware.GetCurrentUser(ctx)
-	if err != nil {
-		utils.Error(ctx, utils.CodeUnauthorized, "未授权")
+	refreshToken, err := ctx.Cookie("refresh_token")
+	if err != nil || refreshToken == "" {
+		var req struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		_ = ctx.ShouldBindJSON(&req)
+		refreshToken = req.RefreshToken
+	}
+	if refreshToken == "" {
+		utils.Error(ctx, utils.CodeUnauthorized, "缺少刷新令牌")
 		return
 	}
 
-	// 生成新的JWT令牌
-	token, err := middleware.GenerateToken(user)
-	if err != nil {
-		utils.Error(ctx, utils.CodeInternalError, "生成令牌失败")
+	claims, err := middleware.ParseToken(refreshToken)
+	if err != nil || claims.TokenType != middleware.TokenTypeRefresh || claims.SessionID == "" {
+		utils.Error(ctx, utils.CodeUnauthorized, "刷新令牌无效")
 		return
 	}
-	refresh, err := middleware.GenerateRefreshToken(user)
+
+	// 轮换会话令牌（检测刷新令牌重放）
+	session, err := c.sessionService.RotateSession(claims.SessionID, claims.ID, ctx.Request.UserAgent(), ctx.ClientIP())
 	if err != nil {
-		utils.Error(ctx, utils.CodeInternalError, "生成刷新令牌失败")
+		switch {
+		case errors.Is(err, services.ErrRefreshConcurrent):
+			utils.Error(ctx, utils.CodeBadRequest, err.Error())
+		case errors.Is(err, services.ErrSessionRevoked), errors.Is(err, services.ErrRefreshTokenReused):
+			middleware.ClearAuthCookies(ctx)
+			utils.Error(ctx, utils.CodeUnauthorized, err.Error())
+		default:
+			utils.Error(ctx, utils.CodeInternalError, "刷新令牌失败")
+		}
 		return
 	}
-	csrfToken, err := middleware.SetAuthCookies(ctx, token, refresh)
-	if err != nil {
-		utils.Error(ctx, utils.CodeInternalError, "设置认证 Cookie 失败")
+
+	// 用户被禁用时不再签发新令牌
+	user, err := c.userService.GetUserByID(session.UserID)
+	if err != nil || user.Status != 1 {
+		_ = c.sessionService.RevokeSession(session.SessionID, models.SessionRevokeBanned)
+		middleware.ClearAuthCookies(ctx)
+		utils.Error(ctx, utils.CodeUnauthorized, "用户不存在或已被禁用")
+		return
+	}
+
+	token, csrfToken, ok := c.issueTokens(ctx, user, session)
+	if !ok {
 		return
 	}
 
@@ -296,6 +327,26 @@ func (c *UserController) RefreshToken(ctx *gin.Context) {
 	})
 }
 
+// issueTokens 为会话签发访问令牌和刷新令牌并写入 Cookie
+func (c *UserController) issueTokens(ctx *gin.Context, user *models.User, session *models.UserSession) (string, string, bool) {
+	token, err := middleware.GenerateToken(user, session)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, "生成令牌失败")
+		return "", "", false
+	}
+	refresh, err := middleware.GenerateRefreshToken(user, session)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, "生成刷新令牌失败")
+		return "", "", false
+	}
+	csrfToken, err := middleware.SetAuthCookies(ctx, token, refresh)
+	if err != nil {
+		utils.Error(ctx, utils.CodeInternalError, "设置认证 Cookie 失败")
+		return "", "", false
+	}
+	return token, csrfToken, true
+}
+
 // GetUserByID 根据ID获取用户信息（公开信息）
 // @Summary 根据ID获取用户信息
 // @Description 获取指定用户的公开信息
@@ -440,7 +491,7 @@ func (c *UserController) SearchPublicUsers(ctx *gin.Context) {
 
 // Logout 用户登出
 // @Summary 用户登出
-// @Description 用户登出接口（客户端删除token即可）
+// @Description 用户登出接口，吊销当前会话并清除 Cookie
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -448,11 +499,105 @@ func (c *UserController) SearchPublicUsers(ctx *gin.Context) {
 // @Success 200 {object} utils.Response "登出成功"
 // @Router /api/user/logout [post]
 func (c *UserController) Logout(ctx *gin.Context) {
+	// 吊销当前会话，已签发的令牌随之失效
+	if err := c.sessionService.RevokeSession(middleware.GetCurrentSessionID(ctx), models.SessionRevokeLogout); err != nil {
+		utils.Error(ctx, utils.CodeInternalError, "登出失败")
+		return
+	}
+
 	// 使用中间件的导出函数清除 Cookie
 	middleware.ClearAuthCookies(ctx)
 	utils.SuccessWithMessage(ctx, "登出成功", nil)
 }
 
+// ListSessions 获取当前用户的登录会话
+// @Summary 获取登录设备列表
+// @Description 获取当前用户所有有效的登录会话
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.UserSessionResponse} "获取成功"
+// @Failure 401 {object} utils.Response "未授权"
+// @Router /api/user/sessions [get]
+func (c *UserController) ListSessions(ctx *gin.Context) {
+	eh := utils.NewErrorHandler()
+
+	userID, ok := eh.RequireAuth(ctx)
+	if !ok {
+		return
+	}
+
+	sessions, err := c.sessionService.ListActiveSessions(userID)
+	if err != nil {
+		eh.HandleServiceError(ctx, err)
+		return
+	}
+
+	currentSID := middleware.GetCurrentSessionID(ctx)
+	responses := make([]models.UserSessionResponse, 0, len(sessions))
+	for i := range sessions {
+		responses = append(responses, sessions[i].ToResponse(currentSID))
+	}
+
+	utils.Success(ctx, responses)
+}
+
+// RevokeSession 下线指定会话
+// @Summary 下线登录设备
+// @Description 吊销当前用户的指定会话
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "会话ID"
+// @Success 200 {object} utils.Response "操作成功"
+// @Failure 404 {object} utils.Response "会话不存在"
+// @Router /api/user/sessions/{id} [delete]
+func (c *UserController) RevokeSession(ctx *gin.Context) {
+	eh := utils.NewErrorHandler()
+
+	userID, ok := eh.RequireAuth(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(ctx, utils.CodeBadRequest, "无效的会话ID")
+		return
+	}
+
+	if err := c.sessionService.RevokeUserSession(userID, uint(id)); err != nil {
+		eh.HandleServiceError(ctx, err)
+		return
+	}
+
+	utils.SuccessWithMessage(ctx, "会话已下线", nil)
+}
+
+// RevokeOtherSessions 下线除当前会话外的所有会话
+// @Summary 下线其他设备
+// @Description 吊销当前用户除当前会话外的所有会话
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response "操作成功"
+// @Router /api/user/sessions [delete]
+func (c *UserController) RevokeOtherSessions(ctx *gin.Context) {
+	eh := utils.NewErrorHandler()
+
+	userID, ok := eh.RequireAuth(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.sessionService.RevokeAllUserSessions(userID, models.SessionRevokeUser, middleware.GetCurrentSessionID(ctx)); err != nil {
+		eh.HandleServiceError(ctx, err)
+		return
+	}
+
+	utils.SuccessWithMessage(ctx, "其他设备已下线", nil)
+}
+
 // GenerateRandomNickname 生成随机昵称
 // @Summary 生成随机昵称
 // @Description 为用户生成一个随机的、可爱的昵称
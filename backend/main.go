@@ -70,6 +70,16 @@ func main() {
 		log.Printf("跳过数据库自动迁移（环境=%s, AutoMigrate=%v）", cfg.Server.Environment, cfg.Database.AutoMigrate)
 	}
 
+	// 启动浏览量回写任务
+	viewCountFlusher := services.NewViewCountFlusher(config.GetDB(), time.Duration(cfg.Worker.ViewCountFlushSeconds)*time.Second)
+	viewCountFlusher.Start()
+
+	// 启动定时任务调度器（多实例部署时由 Redis 锁保证同一任务只在一个实例上执行）
+	jobScheduler := services.GetJobScheduler()
+	if cfg.Worker.SchedulerEnabled {
+		jobScheduler.Start()
+	}
+
 	// 根据环境设置Gin运行模式
 	switch cfg.Server.Environment {
 	case "production":
@@ -120,5 +130,10 @@ func main() {
 		log.Fatal("服务器强制关闭:", err)
 	}
 
+	// 停止后台任务（会执行最后一次浏览量回写）
+	viewCountFlusher.Stop()
+	// 等待正在执行的定时任务结束
+	jobScheduler.Stop()
+
 	log.Println("服务器已关闭")
 }
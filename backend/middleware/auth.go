@@ -11,6 +11,7 @@ import (
 
 	"godad-backend/config"
 	"godad-backend/models"
+	"godad-backend/services"
 	"godad-backend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -19,9 +20,11 @@ import (
 
 // Claims JWT声明结构
 type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"` // 登录会话标识
+	TokenType string `json:"typ,omitempty"` // access / refresh
 	jwt.RegisteredClaims
 }
 
@@ -31,6 +34,12 @@ const (
 	roleUser           = "user"
 )
 
+// 令牌类型
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
 // getRoleString 将角色数字转换为字符串
 func getRoleString(role int8) string {
 	switch role {
@@ -45,61 +54,39 @@ func getRoleString(role int8) string {
 	}
 }
 
-// GenerateToken 生成JWT令牌
-func GenerateToken(user *models.User) (string, error) {
-	cfg := config.GetConfig()
-
-	// 设置过期时间
-	expirationTime := time.Now().Add(time.Duration(cfg.JWT.ExpireHours) * time.Hour)
-
-	// 创建声明
+// signToken 为指定会话签发令牌，令牌ID(jti)使用会话上记录的值
+func signToken(user *models.User, session *models.UserSession, tokenType, jti string, ttl time.Duration) (string, error) {
+	now := time.Now()
 	claims := &Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     getRoleString(user.Role),
+		UserID:    user.ID,
+		Username:  user.Username,
+		Role:      getRoleString(user.Role),
+		SessionID: session.SessionID,
+		TokenType: tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "godad-backend",
 			Subject:   strconv.Itoa(int(user.ID)),
 		},
 	}
 
-	// 创建令牌
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(config.GetConfig().JWT.Secret))
+}
 
-	// 签名令牌
-	tokenString, err := token.SignedString([]byte(config.GetConfig().JWT.Secret))
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
+// GenerateToken 生成JWT访问令牌
+func GenerateToken(user *models.User, session *models.UserSession) (string, error) {
+	cfg := config.GetConfig()
+	return signToken(user, session, TokenTypeAccess, session.AccessJTI, time.Duration(cfg.JWT.ExpireHours)*time.Hour)
 }
 
 // GenerateRefreshToken 生成刷新令牌（有效期更长）
-func GenerateRefreshToken(user *models.User) (string, error) {
-	// 默认7天有效期
-	expirationTime := time.Now().Add(7 * 24 * time.Hour)
-	claims := &Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     getRoleString(user.Role),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "godad-backend",
-			Subject:   strconv.Itoa(int(user.ID)),
-		},
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(config.GetConfig().JWT.Secret))
-	if err != nil {
-		return "", err
-	}
-	return tokenString, nil
+func GenerateRefreshToken(user *models.User, session *models.UserSession) (string, error) {
+	cfg := config.GetConfig()
+	return signToken(user, session, TokenTypeRefresh, session.RefreshJTI, time.Duration(cfg.JWT.RefreshExpireHours)*time.Hour)
 }
 
 // ParseToken 解析JWT令牌
@@ -124,32 +111,45 @@ func ParseToken(tokenString string) (*Claims, error) {
 	return nil, jwt.ErrInvalidKey
 }
 
+// extractToken 优先从 Authorization 头获取令牌，其次从 Cookie 获取
+func extractToken(c *gin.Context) string {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader != "" {
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			return parts[1]
+		}
+	}
+	if cookie, err := c.Cookie("access_token"); err == nil {
+		return cookie
+	}
+	return ""
+}
+
+// setClaims 将用户信息存储到上下文
+func setClaims(c *gin.Context, claims *Claims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("role", claims.Role)
+	c.Set("session_id", claims.SessionID)
+	c.Set("claims", claims)
+}
+
 // AuthMiddleware JWT认证中间件
 func AuthMiddleware() gin.HandlerFunc {
+	sessionService := services.NewSessionService(config.GetDB())
+
 	return func(c *gin.Context) {
-		// 优先从 Authorization 头获取，其次从 Cookie 获取
-		var tokenString string
-		authHeader := c.GetHeader("Authorization")
-		if authHeader != "" {
-			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) == 2 && parts[0] == "Bearer" {
-				tokenString = parts[1]
-			}
-		}
-		if tokenString == "" {
-			if cookie, err := c.Cookie("access_token"); err == nil {
-				tokenString = cookie
-			}
-		}
+		tokenString := extractToken(c)
 		if tokenString == "" {
 			utils.Error(c, utils.CodeUnauthorized, "缺少认证令牌")
 			c.Abort()
 			return
 		}
 
-		// 解析令牌
+		// 解析令牌（刷新令牌不能用于访问接口）
 		claims, err := ParseToken(tokenString)
-		if err != nil {
+		if err != nil || claims.TokenType != TokenTypeAccess {
 			utils.Error(c, utils.CodeUnauthorized, "认证令牌无效")
 			c.Abort()
 			return
@@ -162,11 +162,20 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 将用户信息存储到上下文
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
-		c.Set("claims", claims)
+		// 检查会话是否已被吊销（登出、下线设备、封禁等），以及令牌是否因刷新轮换而失效
+		active, err := sessionService.IsAccessTokenActive(claims.SessionID, claims.ID)
+		if err != nil {
+			utils.Error(c, utils.CodeInternalError, "校验登录状态失败")
+			c.Abort()
+			return
+		}
+		if !active {
+			utils.Error(c, utils.CodeUnauthorized, "认证令牌已失效")
+			c.Abort()
+			return
+		}
+
+		setClaims(c, claims)
 
 		c.Next()
 	}
@@ -174,20 +183,10 @@ func AuthMiddleware() gin.HandlerFunc {
 
 // OptionalAuthMiddleware 可选认证中间件（不强制要求认证）
 func OptionalAuthMiddleware() gin.HandlerFunc {
+	sessionService := services.NewSessionService(config.GetDB())
+
 	return func(c *gin.Context) {
-		var tokenString string
-		authHeader := c.GetHeader("Authorization")
-		if authHeader != "" {
-			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) == 2 && parts[0] == "Bearer" {
-				tokenString = parts[1]
-			}
-		}
-		if tokenString == "" {
-			if cookie, err := c.Cookie("access_token"); err == nil {
-				tokenString = cookie
-			}
-		}
+		tokenString := extractToken(c)
 		if tokenString == "" {
 			c.Next()
 			return
@@ -195,7 +194,7 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 
 		// 解析令牌
 		claims, err := ParseToken(tokenString)
-		if err != nil {
+		if err != nil || claims.TokenType != TokenTypeAccess {
 			c.Next()
 			return
 		}
@@ -206,11 +205,13 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 将用户信息存储到上下文
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
-		c.Set("claims", claims)
+		// 已吊销的会话或令牌按未登录处理
+		if active, err := sessionService.IsAccessTokenActive(claims.SessionID, claims.ID); err != nil || !active {
+			c.Next()
+			return
+		}
+
+		setClaims(c, claims)
 
 		c.Next()
 	}
@@ -269,41 +270,11 @@ func GetCurrentUser(c *gin.Context) (*models.User, error) {
 	return &user, nil
 }
 
-// RefreshToken 刷新令牌
-func RefreshToken(c *gin.Context) {
-	// 基于 refresh_token Cookie 刷新 access_token
-	refreshCookie, err := c.Cookie("refresh_token")
-	if err != nil || refreshCookie == "" {
-		utils.Error(c, utils.CodeUnauthorized, "缺少刷新令牌")
-		return
-	}
-	claims, err := ParseToken(refreshCookie)
-	if err != nil || claims.ExpiresAt.Time.Before(time.Now()) {
-		utils.Error(c, utils.CodeUnauthorized, "刷新令牌无效或过期")
-		return
-	}
-	// 从数据库获取用户确保有效
-	db := config.GetDB()
-	var user models.User
-	if err := db.Where("id = ? AND status = ?", claims.UserID, 1).First(&user).Error; err != nil {
-		utils.Error(c, utils.CodeUnauthorized, "用户不存在或已被禁用")
-		return
-	}
-	access, err := GenerateToken(&user)
-	if err != nil {
-		utils.Error(c, utils.CodeInternalError, "生成令牌失败")
-		return
-	}
-	// 写入新的 access_token Cookie
-	csrfToken, err := SetAuthCookies(c, access, refreshCookie)
-	if err != nil {
-		utils.Error(c, utils.CodeInternalError, "设置认证 Cookie 失败")
-		return
-	}
-	utils.SuccessWithMessage(c, "刷新成功", gin.H{
-		"user":       user.ToResponse(),
-		"csrf_token": csrfToken,
-	})
+// GetCurrentSessionID 获取当前请求的登录会话标识
+func GetCurrentSessionID(c *gin.Context) string {
+	sessionID, _ := c.Get("session_id")
+	sid, _ := sessionID.(string)
+	return sid
 }
 
 func generateCSRFToken() (string, error) {
@@ -347,7 +318,7 @@ func SetAuthCookies(c *gin.Context, accessToken, refreshToken string) (string, e
 	// 获取JWT配置的过期时间
 	cfg := config.GetConfig()
 	accessTokenExpiry := time.Now().Add(time.Duration(cfg.JWT.ExpireHours) * time.Hour)
-	refreshTokenExpiry := time.Now().Add(7 * 24 * time.Hour) // 7天
+	refreshTokenExpiry := time.Now().Add(time.Duration(cfg.JWT.RefreshExpireHours) * time.Hour)
 
 	csrfToken, err := generateCSRFToken()
 	if err != nil {
@@ -371,7 +342,7 @@ func SetAuthCookies(c *gin.Context, accessToken, refreshToken string) (string, e
 		Value:    refreshToken,
 		Path:     "/",
 		Expires:  refreshTokenExpiry,
-		MaxAge:   int(7 * 24 * time.Hour / time.Second), // 7天
+		MaxAge:   int(time.Duration(cfg.JWT.RefreshExpireHours) * time.Hour / time.Second),
 		HttpOnly: httpOnly,
 		Secure:   secure,
 		SameSite: sameSite,
@@ -3,6 +3,7 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"godad-backend/config"
@@ -47,6 +48,22 @@ func containsOrigin(origins []string, target string) bool {
 	return false
 }
 
+// IsOriginAllowed 判断请求来源是否在允许的跨域列表中（用于 WebSocket 握手校验）
+// 非浏览器客户端不携带 Origin，视为允许；同源请求也视为允许
+func IsOriginAllowed(r *http.Request) bool {
+	origin := strings.TrimRight(strings.TrimSpace(r.Header.Get("Origin")), "/")
+	if origin == "" {
+		return true
+	}
+
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	allowedOrigins, allowWildcard := buildAllowedOrigins()
+	return allowWildcard || containsOrigin(allowedOrigins, origin)
+}
+
 // CORSMiddleware 提供基于配置的跨域控制
 func CORSMiddleware() gin.HandlerFunc {
 	allowedOrigins, allowWildcard := buildAllowedOrigins()
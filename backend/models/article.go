@@ -6,13 +6,25 @@ import (
 	"gorm.io/gorm"
 )
 
+// 文章状态
+const (
+	ArticleStatusDraft     int8 = 0 // 草稿
+	ArticleStatusPublished int8 = 1 // 已发布
+	ArticleStatusOffline   int8 = 2 // 已下架
+	ArticleStatusScheduled int8 = 3 // 定时发布，到达 PublishAt 后由后台任务发布
+)
+
 // Article 文章模型
 type Article struct {
 	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
 	Title       string         `json:"title" gorm:"type:varchar(200);not null;comment:文章标题"`
 	Slug        string         `json:"slug" gorm:"type:varchar(200);uniqueIndex;not null;comment:文章别名"`
 	Summary     string         `json:"summary" gorm:"type:varchar(500);comment:文章摘要"`
-	Content     string         `json:"content" gorm:"type:longtext;not null;comment:文章内容"`
+	Content     string         `json:"content" gorm:"type:longtext;not null;comment:文章内容（源文）"`
+	ContentFormat string       `json:"content_format" gorm:"type:varchar(20);not null;default:html;comment:正文格式 html/markdown"`
+	ContentHTML string         `json:"content_html" gorm:"type:longtext;comment:过滤后的正文HTML"`
+	ContentImages []string     `json:"content_images" gorm:"type:text;serializer:json;comment:正文引用的图片"`
+	TOC         []*ContentHeading `json:"toc" gorm:"column:toc;type:text;serializer:json;comment:正文目录"`
 	CoverImage  string         `json:"cover_image" gorm:"type:varchar(255);comment:封面图片"`
 	AuthorID    uint           `json:"author_id" gorm:"not null;index;comment:作者ID"`
 	CategoryID  uint           `json:"category_id" gorm:"not null;index;comment:分类ID"`
@@ -22,7 +34,8 @@ type Article struct {
 	FavoriteCount int64        `json:"favorite_count" gorm:"type:bigint;default:0;comment:收藏次数"`
 	IsTop       bool           `json:"is_top" gorm:"type:boolean;default:false;comment:是否置顶"`
 	IsRecommend bool           `json:"is_recommend" gorm:"type:boolean;default:false;comment:是否推荐"`
-	Status      int8           `json:"status" gorm:"type:tinyint;default:1;comment:状态 0-草稿 1-已发布 2-已下架"`
+	Status      int8           `json:"status" gorm:"type:tinyint;default:1;comment:状态 0-草稿 1-已发布 2-已下架 3-定时发布"`
+	PublishAt   *time.Time     `json:"publish_at" gorm:"index;comment:定时发布时间"`
 	PublishedAt *time.Time     `json:"published_at" gorm:"comment:发布时间"`
 	CreatedAt   time.Time      `json:"created_at" gorm:"comment:创建时间"`
 	UpdatedAt   time.Time      `json:"updated_at" gorm:"comment:更新时间"`
@@ -33,6 +46,7 @@ type Article struct {
 	Category  Category   `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
 	Comments  []Comment  `json:"comments,omitempty" gorm:"foreignKey:ArticleID"`
 	Favorites []Favorite `json:"favorites,omitempty" gorm:"foreignKey:ArticleID"`
+	Tags      []Tag      `json:"tags,omitempty" gorm:"many2many:article_tags"`
 }
 
 // ArticleCreateRequest 文章创建请求
@@ -41,11 +55,14 @@ type ArticleCreateRequest struct {
 	Slug        string `json:"slug" binding:"required,min=1,max=200" example:"how-to-develop-reading-habits"`
 	Summary     string `json:"summary" binding:"max=500" example:"本文介绍了培养孩子阅读习惯的几个有效方法"`
 	Content     string `json:"content" binding:"required,min=1" example:"文章内容..."`
+	ContentFormat string `json:"content_format" binding:"omitempty,oneof=html markdown" example:"markdown"` // 正文格式，默认 html
 	CoverImage  string `json:"cover_image" binding:"max=255" example:"https://example.com/cover.jpg"`
 	CategoryID  uint   `json:"category_id" binding:"required,min=1" example:"1"`
 	IsTop       bool   `json:"is_top" example:"false"`
 	IsRecommend bool   `json:"is_recommend" example:"false"`
-	Status      int8   `json:"status" binding:"min=0,max=2" example:"1"`
+	Status      int8   `json:"status" binding:"min=0,max=3" example:"1"`
+	PublishAt   *time.Time `json:"publish_at" example:"2024-01-01T08:00:00+08:00"` // 定时发布时间，Status 为 3 时必填
+	Tags        []string   `json:"tags" binding:"max=10" example:"早教,阅读"`
 }
 
 // ArticleUpdateRequest 文章更新请求
@@ -54,11 +71,14 @@ type ArticleUpdateRequest struct {
 	Slug        string `json:"slug" binding:"min=1,max=200"`
 	Summary     string `json:"summary" binding:"max=500"`
 	Content     string `json:"content" binding:"min=1"`
+	ContentFormat string `json:"content_format" binding:"omitempty,oneof=html markdown"` // 为空时沿用原格式
 	CoverImage  string `json:"cover_image" binding:"max=255"`
 	CategoryID  uint   `json:"category_id" binding:"min=1"`
 	IsTop       *bool  `json:"is_top"`
 	IsRecommend *bool  `json:"is_recommend"`
-	Status      *int8  `json:"status" binding:"omitempty,min=0,max=2"`
+	Status      *int8  `json:"status" binding:"omitempty,min=0,max=3"`
+	PublishAt   *time.Time `json:"publish_at"` // 定时发布时间，Status 为 3 时必填（已是定时状态时可只改时间）
+	Tags        []string   `json:"tags" binding:"max=10"` // 为 nil 时不修改标签，空数组表示清空
 }
 
 // ArticleListRequest 文章列表请求
@@ -68,7 +88,7 @@ type ArticleListRequest struct {
 	CategoryID uint   `form:"category_id" example:"1"`
 	AuthorID   uint   `form:"author_id" example:"1"`
 	Keyword    string `form:"keyword" example:"育儿"`
-	Status     int8   `form:"status" binding:"omitempty,min=0,max=2" example:"1"`
+	Status     int8   `form:"status" binding:"omitempty,min=0,max=3" example:"1"`
 	IsTop      *bool  `form:"is_top" example:"true"`
 	IsRecommend *bool `form:"is_recommend" example:"true"`
 	Sort       string `form:"sort" example:"created_at desc"`
@@ -81,6 +101,10 @@ type ArticleResponse struct {
 	Slug          string            `json:"slug"`
 	Summary       string            `json:"summary"`
 	Content       string            `json:"content,omitempty"` // 列表时不返回内容
+	ContentFormat string            `json:"content_format"`
+	ContentHTML   string            `json:"content_html,omitempty"` // 过滤后的正文，前端直接渲染
+	ContentImages []string          `json:"content_images,omitempty"`
+	TOC           []*ContentHeading `json:"toc,omitempty"`
 	CoverImage    string            `json:"cover_image"`
 	AuthorID      uint              `json:"author_id"`
 	CategoryID    uint              `json:"category_id"`
@@ -91,11 +115,14 @@ type ArticleResponse struct {
 	IsTop         bool              `json:"is_top"`
 	IsRecommend   bool              `json:"is_recommend"`
 	Status        int8              `json:"status"`
+	PublishAt     *time.Time        `json:"publish_at,omitempty"`
 	PublishedAt   *time.Time        `json:"published_at"`
 	CreatedAt     time.Time         `json:"created_at"`
 	UpdatedAt     time.Time         `json:"updated_at"`
 	Author        *UserResponse     `json:"author,omitempty"`
 	Category      *CategoryResponse `json:"category,omitempty"`
+	Tags          []*TagResponse    `json:"tags"`
+	Series        []*ArticleSeriesNav `json:"series,omitempty"` // 所属系列及前后导航，仅详情返回
 }
 
 // ToResponse 转换为响应格式
@@ -105,6 +132,7 @@ func (a *Article) ToResponse(includeContent bool) *ArticleResponse {
 		Title:         a.Title,
 		Slug:          a.Slug,
 		Summary:       a.Summary,
+		ContentFormat: a.ContentFormat,
 		CoverImage:    a.CoverImage,
 		AuthorID:      a.AuthorID,
 		CategoryID:    a.CategoryID,
@@ -115,6 +143,7 @@ func (a *Article) ToResponse(includeContent bool) *ArticleResponse {
 		IsTop:         a.IsTop,
 		IsRecommend:   a.IsRecommend,
 		Status:        a.Status,
+		PublishAt:     a.PublishAt,
 		PublishedAt:   a.PublishedAt,
 		CreatedAt:     a.CreatedAt,
 		UpdatedAt:     a.UpdatedAt,
@@ -123,6 +152,9 @@ func (a *Article) ToResponse(includeContent bool) *ArticleResponse {
 	// 根据需要包含内容
 	if includeContent {
 		resp.Content = a.Content
+		resp.ContentHTML = a.ContentHTML
+		resp.ContentImages = a.ContentImages
+		resp.TOC = a.TOC
 	}
 
 	// 包含关联数据
@@ -132,6 +164,10 @@ func (a *Article) ToResponse(includeContent bool) *ArticleResponse {
 	if a.Category.ID != 0 {
 		resp.Category = a.Category.ToResponse()
 	}
+	resp.Tags = make([]*TagResponse, 0, len(a.Tags))
+	for i := range a.Tags {
+		resp.Tags = append(resp.Tags, a.Tags[i].ToResponse())
+	}
 
 	return resp
 }
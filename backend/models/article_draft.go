@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// ArticleDraft 文章自动保存的草稿
+// 每个用户对每篇文章只保留一份（ArticleID 为 0 表示尚未创建的新文章），
+// 与文章本身分开存储，自动保存不会修改已发布的内容，也不会改变文章的 updated_at
+type ArticleDraft struct {
+	ID            uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID        uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_article_drafts_user_article,priority:1;comment:用户ID"`
+	ArticleID     uint      `json:"article_id" gorm:"not null;default:0;uniqueIndex:idx_article_drafts_user_article,priority:2;index;comment:文章ID，0 表示新文章"`
+	Title         string    `json:"title" gorm:"type:varchar(200);comment:文章标题"`
+	Summary       string    `json:"summary" gorm:"type:varchar(500);comment:文章摘要"`
+	Content       string    `json:"content" gorm:"type:longtext;comment:文章内容"`
+	ContentFormat string    `json:"content_format" gorm:"type:varchar(20);not null;default:html;comment:正文格式 html/markdown"`
+	CoverImage    string    `json:"cover_image" gorm:"type:varchar(255);comment:封面图片"`
+	CategoryID    uint      `json:"category_id" gorm:"not null;default:0;comment:分类ID"`
+	SavedAt       time.Time `json:"saved_at" gorm:"not null;comment:保存时间"`
+}
+
+func (ArticleDraft) TableName() string { return "article_drafts" }
+
+// ArticleAutosaveRequest 自动保存请求，字段均可为空，仅限制长度
+type ArticleAutosaveRequest struct {
+	Title         string `json:"title" binding:"max=200"`
+	Summary       string `json:"summary" binding:"max=500"`
+	Content       string `json:"content" binding:"max=100000"`
+	ContentFormat string `json:"content_format" binding:"omitempty,oneof=html markdown"`
+	CoverImage    string `json:"cover_image" binding:"max=255"`
+	CategoryID    uint   `json:"category_id"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// 文章版本来源
+const (
+	ArticleRevisionActionCreate  = "create"  // 创建文章
+	ArticleRevisionActionUpdate  = "update"  // 编辑文章
+	ArticleRevisionActionRestore = "restore" // 恢复历史版本
+)
+
+// ArticleRevision 文章历史版本，每次标题、摘要或正文变化时保存一份快照
+type ArticleRevision struct {
+	ID            uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ArticleID     uint      `json:"article_id" gorm:"not null;uniqueIndex:idx_article_revisions_article_version,priority:1;comment:文章ID"`
+	Version       int       `json:"version" gorm:"not null;uniqueIndex:idx_article_revisions_article_version,priority:2;comment:版本号"`
+	Title         string    `json:"title" gorm:"type:varchar(200);not null;comment:文章标题"`
+	Summary       string    `json:"summary" gorm:"type:varchar(500);comment:文章摘要"`
+	Content       string    `json:"content" gorm:"type:longtext;not null;comment:文章内容"`
+	ContentFormat string    `json:"content_format" gorm:"type:varchar(20);not null;default:html;comment:正文格式 html/markdown"`
+	EditorID      uint      `json:"editor_id" gorm:"not null;index;comment:编辑者ID"`
+	Action        string    `json:"action" gorm:"type:varchar(20);not null;comment:来源 create/update/restore"`
+	RestoredFrom  *int      `json:"restored_from" gorm:"comment:恢复自的版本号"`
+	CreatedAt     time.Time `json:"created_at" gorm:"comment:创建时间"`
+
+	// 关联关系
+	Editor User `json:"editor,omitempty" gorm:"foreignKey:EditorID"`
+}
+
+func (ArticleRevision) TableName() string { return "article_revisions" }
+
+// ArticleRevisionResponse 版本列表项（不含正文）
+type ArticleRevisionResponse struct {
+	ID           uint          `json:"id"`
+	ArticleID    uint          `json:"article_id"`
+	Version      int           `json:"version"`
+	Title        string        `json:"title"`
+	Summary      string        `json:"summary"`
+	Action       string        `json:"action"`
+	RestoredFrom *int          `json:"restored_from"`
+	Editor       *UserResponse `json:"editor,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// ToResponse 转换为列表响应
+func (r *ArticleRevision) ToResponse() *ArticleRevisionResponse {
+	resp := &ArticleRevisionResponse{
+		ID:           r.ID,
+		ArticleID:    r.ArticleID,
+		Version:      r.Version,
+		Title:        r.Title,
+		Summary:      r.Summary,
+		Action:       r.Action,
+		RestoredFrom: r.RestoredFrom,
+		CreatedAt:    r.CreatedAt,
+	}
+	if r.Editor.ID != 0 {
+		resp.Editor = r.Editor.ToResponse()
+	}
+	return resp
+}
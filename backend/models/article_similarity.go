@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ArticleVector 文章正文的 TF-IDF 词向量（已归一化），由相似文章计算离线生成
+type ArticleVector struct {
+	ArticleID uint      `gorm:"primaryKey;autoIncrement:false;comment:文章ID"`
+	Terms     string    `gorm:"type:mediumtext;comment:词项权重 JSON"`
+	UpdatedAt time.Time `gorm:"comment:更新时间"`
+}
+
+func (ArticleVector) TableName() string { return "article_vectors" }
+
+// ArticleTermStat 词项的文档频率，用于计算 IDF；只在全量计算时重建，单篇刷新沿用现有统计
+type ArticleTermStat struct {
+	Term     string `gorm:"type:varchar(64);primaryKey;comment:词项"`
+	DocCount int    `gorm:"not null;default:0;comment:包含该词项的文章数"`
+}
+
+func (ArticleTermStat) TableName() string { return "article_term_stats" }
+
+// ArticleRelation 预计算的相似文章，Score 为内容、分类与共同收藏三类信号的加权和
+type ArticleRelation struct {
+	ID              uint      `json:"-" gorm:"primaryKey;autoIncrement"`
+	ArticleID       uint      `json:"article_id" gorm:"not null;uniqueIndex:idx_article_relations_pair,priority:1;comment:文章ID"`
+	RelatedID       uint      `json:"related_id" gorm:"not null;uniqueIndex:idx_article_relations_pair,priority:2;index;comment:相似文章ID"`
+	Score           float64   `json:"score" gorm:"not null;default:0;comment:综合得分"`
+	ContentScore    float64   `json:"content_score" gorm:"not null;default:0;comment:内容相似度"`
+	CategoryScore   float64   `json:"category_score" gorm:"not null;default:0;comment:同分类得分"`
+	CofavoriteScore float64   `json:"cofavorite_score" gorm:"not null;default:0;comment:共同收藏得分"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"comment:更新时间"`
+}
+
+func (ArticleRelation) TableName() string { return "article_relations" }
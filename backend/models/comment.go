@@ -9,13 +9,19 @@ import (
 // Comment 评论模型
 type Comment struct {
 	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
-	Content   string         `json:"content" gorm:"type:text;not null;comment:评论内容"`
+	Content   string         `json:"content" gorm:"type:text;not null;comment:评论内容（源文）"`
+	ContentFormat string     `json:"content_format" gorm:"type:varchar(20);not null;default:markdown;comment:正文格式 html/markdown"`
+	ContentHTML string       `json:"content_html" gorm:"type:text;comment:过滤后的评论HTML"`
 	UserID    uint           `json:"user_id" gorm:"not null;index;comment:用户ID"`
 	ArticleID uint           `json:"article_id" gorm:"not null;index;comment:文章ID"`
 	ParentID  *uint          `json:"parent_id" gorm:"index;comment:父评论ID"`
 	ReplyToID *uint          `json:"reply_to_id" gorm:"index;comment:回复的评论ID"`
 	LikeCount int64          `json:"like_count" gorm:"type:bigint;default:0;comment:点赞次数"`
-	Status    int8           `json:"status" gorm:"type:tinyint;default:1;comment:状态 0-已删除 1-正常 2-待审核"`
+	Status    int8           `json:"status" gorm:"type:tinyint;default:1;comment:状态 0-已删除 1-正常 2-待审核 3-已隐藏"`
+	ModerationReason string  `json:"moderation_reason" gorm:"type:varchar(255);comment:进入审核或被驳回的原因"`
+	ReviewedBy *uint         `json:"reviewed_by" gorm:"comment:审核管理员ID"`
+	ReviewedAt *time.Time    `json:"reviewed_at" gorm:"comment:审核时间"`
+	PendingMentions []uint   `json:"-" gorm:"type:text;serializer:json;comment:待审核期间暂存的@用户ID"`
 	CreatedAt time.Time      `json:"created_at" gorm:"comment:创建时间"`
 	UpdatedAt time.Time      `json:"updated_at" gorm:"comment:更新时间"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index;comment:删除时间"`
@@ -28,9 +34,18 @@ type Comment struct {
 	Replies  []Comment `json:"replies,omitempty" gorm:"foreignKey:ParentID"`
 }
 
+// 评论状态
+const (
+	CommentStatusDeleted int8 = 0 // 已删除
+	CommentStatusNormal  int8 = 1 // 正常
+	CommentStatusPending int8 = 2 // 待审核
+	CommentStatusHidden  int8 = 3 // 已隐藏（违规处理）
+)
+
 // CommentCreateRequest 评论创建请求
 type CommentCreateRequest struct {
     Content   string `json:"content" binding:"required,min=1,max=1000" example:"这篇文章很有用，谢谢分享！"`
+    ContentFormat string `json:"content_format" binding:"omitempty,oneof=html markdown" example:"markdown"` // 正文格式，默认 markdown
     ArticleID uint   `json:"article_id" binding:"required,min=1" example:"1"`
     ParentID  *uint  `json:"parent_id" example:"1"`
     ReplyToID *uint  `json:"reply_to_id" example:"2"`
@@ -40,9 +55,16 @@ type CommentCreateRequest struct {
 // CommentUpdateRequest 评论更新请求
 type CommentUpdateRequest struct {
     Content string `json:"content" binding:"required,min=1,max=1000"`
+    ContentFormat string `json:"content_format" binding:"omitempty,oneof=html markdown"` // 为空时沿用原格式
     Mentions []uint `json:"mentions"` // 可选：编辑时更新@列表（v1可忽略）
 }
 
+// CommentReviewRequest 批量审核评论请求
+type CommentReviewRequest struct {
+	IDs    []uint `json:"ids" binding:"required,min=1,max=100" example:"[1,2,3]"`
+	Reason string `json:"reason" binding:"max=255" example:"包含广告链接"` // 驳回原因，审核通过时忽略
+}
+
 // CommentListRequest 评论列表请求
 type CommentListRequest struct {
 	Page      int  `form:"page" binding:"min=1" example:"1"`
@@ -58,12 +80,15 @@ type CommentListRequest struct {
 type CommentResponse struct {
 	ID        uint              `json:"id"`
 	Content   string            `json:"content"`
+	ContentFormat string        `json:"content_format"`
+	ContentHTML string          `json:"content_html"`
 	UserID    uint              `json:"user_id"`
 	ArticleID uint              `json:"article_id"`
 	ParentID  *uint             `json:"parent_id"`
 	ReplyToID *uint             `json:"reply_to_id"`
 	LikeCount int64             `json:"like_count"`
 	Status    int8              `json:"status"`
+	ModerationReason string     `json:"moderation_reason,omitempty"`
 	CreatedAt time.Time         `json:"created_at"`
 	UpdatedAt time.Time         `json:"updated_at"`
 	User      *UserResponse     `json:"user,omitempty"`
@@ -77,12 +102,15 @@ func (c *Comment) ToResponse(includeReplies bool) *CommentResponse {
 	resp := &CommentResponse{
 		ID:        c.ID,
 		Content:   c.Content,
+		ContentFormat: c.ContentFormat,
+		ContentHTML: c.ContentHTML,
 		UserID:    c.UserID,
 		ArticleID: c.ArticleID,
 		ParentID:  c.ParentID,
 		ReplyToID: c.ReplyToID,
 		LikeCount: c.LikeCount,
 		Status:    c.Status,
+		ModerationReason: c.ModerationReason,
 		CreatedAt: c.CreatedAt,
 		UpdatedAt: c.UpdatedAt,
 	}
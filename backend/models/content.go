@@ -0,0 +1,14 @@
+package models
+
+// 正文格式
+const (
+	ContentFormatHTML     = "html"     // 富文本编辑器产出的 HTML
+	ContentFormatMarkdown = "markdown" // Markdown 源文
+)
+
+// ContentHeading 正文目录项，ID 与渲染结果中标题的 id 属性一致，可用作锚点
+type ContentHeading struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
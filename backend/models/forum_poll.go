@@ -0,0 +1,127 @@
+package models
+
+import "time"
+
+// ForumPoll 帖子投票，每个帖子最多一个
+type ForumPoll struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	PostID         uint       `json:"post_id" gorm:"not null;uniqueIndex;comment:帖子ID"`
+	Question       string     `json:"question" gorm:"type:varchar(200);comment:投票问题，未填写时使用帖子标题"`
+	MultipleChoice bool       `json:"multiple_choice" gorm:"type:boolean;default:false;comment:是否多选"`
+	MaxChoices     int        `json:"max_choices" gorm:"not null;default:1;comment:多选时最多可选项数"`
+	HideResults    bool       `json:"hide_results" gorm:"type:boolean;default:false;comment:投票前是否隐藏实时结果"`
+	ClosesAt       *time.Time `json:"closes_at" gorm:"index;comment:截止时间，为空表示不截止"`
+	VoterCount     int64      `json:"voter_count" gorm:"type:bigint;default:0;comment:参与投票人数"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"comment:创建时间"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"comment:更新时间"`
+
+	// 关联关系
+	Options []ForumPollOption `json:"options,omitempty" gorm:"foreignKey:PollID"`
+}
+
+func (ForumPoll) TableName() string { return "forum_polls" }
+
+// IsClosed 投票是否已截止
+func (p *ForumPoll) IsClosed(now time.Time) bool {
+	return p.ClosesAt != nil && !now.Before(*p.ClosesAt)
+}
+
+// ForumPollOption 投票选项
+type ForumPollOption struct {
+	ID        uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	PollID    uint   `json:"poll_id" gorm:"not null;index;comment:投票ID"`
+	Content   string `json:"content" gorm:"type:varchar(100);not null;comment:选项内容"`
+	SortOrder int    `json:"sort_order" gorm:"not null;default:0;comment:排序"`
+	VoteCount int64  `json:"vote_count" gorm:"type:bigint;default:0;comment:得票数"`
+}
+
+func (ForumPollOption) TableName() string { return "forum_poll_options" }
+
+// ForumPollBallot 用户的一张选票，(poll_id, user_id) 唯一保证每人只能投一次
+type ForumPollBallot struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	PollID    uint      `json:"poll_id" gorm:"not null;uniqueIndex:idx_forum_poll_ballots_poll_user,priority:1;comment:投票ID"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_forum_poll_ballots_poll_user,priority:2;index;comment:投票用户ID"`
+	OptionIDs []uint    `json:"option_ids" gorm:"type:varchar(255);serializer:json;comment:所选选项ID"`
+	CreatedAt time.Time `json:"created_at" gorm:"comment:投票时间"`
+}
+
+func (ForumPollBallot) TableName() string { return "forum_poll_ballots" }
+
+// ForumPollCreateRequest 发帖时附带的投票
+type ForumPollCreateRequest struct {
+	Question       string     `json:"question" binding:"max=200" example:"你家宝宝喝哪个牌子的奶粉？"`
+	Options        []string   `json:"options" binding:"required,min=2,max=10,dive,required,max=100" example:"飞鹤,爱他美,美赞臣"`
+	MultipleChoice bool       `json:"multiple_choice" example:"false"`
+	MaxChoices     int        `json:"max_choices" binding:"omitempty,min=1,max=10" example:"2"` // 多选时最多可选项数，默认不限
+	HideResults    *bool      `json:"hide_results" example:"true"`                              // 投票前是否隐藏结果，默认按系统配置
+	ClosesAt       *time.Time `json:"closes_at" example:"2026-12-31T23:59:59+08:00"`            // 截止时间，为空表示不截止
+}
+
+// ForumPollVoteRequest 投票请求
+type ForumPollVoteRequest struct {
+	OptionIDs []uint `json:"option_ids" binding:"required,min=1,max=10" example:"3"`
+}
+
+// ForumPollOptionResponse 投票选项响应，结果不可见时不返回票数
+type ForumPollOptionResponse struct {
+	ID        uint     `json:"id"`
+	Content   string   `json:"content"`
+	VoteCount *int64   `json:"vote_count,omitempty"`
+	Percent   *float64 `json:"percent,omitempty"` // 占投票人数的百分比
+	Selected  bool     `json:"selected"`          // 当前用户是否选择了该项
+}
+
+// ForumPollResponse 投票响应，内容随当前用户是否已投票而不同
+type ForumPollResponse struct {
+	ID             uint                      `json:"id"`
+	Question       string                    `json:"question"`
+	MultipleChoice bool                      `json:"multiple_choice"`
+	MaxChoices     int                       `json:"max_choices"`
+	ClosesAt       *time.Time                `json:"closes_at"`
+	IsClosed       bool                      `json:"is_closed"`
+	HasVoted       bool                      `json:"has_voted"`
+	ResultsVisible bool                      `json:"results_visible"`
+	VoterCount     *int64                    `json:"voter_count,omitempty"`
+	Options        []ForumPollOptionResponse `json:"options"`
+}
+
+// ToResponse 转换为响应格式；ballot 为当前用户的选票，未投票或未登录时为 nil
+func (p *ForumPoll) ToResponse(ballot *ForumPollBallot, now time.Time) *ForumPollResponse {
+	resp := &ForumPollResponse{
+		ID:             p.ID,
+		Question:       p.Question,
+		MultipleChoice: p.MultipleChoice,
+		MaxChoices:     p.MaxChoices,
+		ClosesAt:       p.ClosesAt,
+		IsClosed:       p.IsClosed(now),
+		HasVoted:       ballot != nil,
+		Options:        make([]ForumPollOptionResponse, len(p.Options)),
+	}
+	resp.ResultsVisible = !p.HideResults || resp.HasVoted || resp.IsClosed
+
+	selected := make(map[uint]bool)
+	if ballot != nil {
+		for _, id := range ballot.OptionIDs {
+			selected[id] = true
+		}
+	}
+	if resp.ResultsVisible {
+		voters := p.VoterCount
+		resp.VoterCount = &voters
+	}
+	for i, opt := range p.Options {
+		item := ForumPollOptionResponse{ID: opt.ID, Content: opt.Content, Selected: selected[opt.ID]}
+		if resp.ResultsVisible {
+			count := opt.VoteCount
+			percent := 0.0
+			if p.VoterCount > 0 {
+				percent = float64(int(float64(count)*1000/float64(p.VoterCount)+0.5)) / 10
+			}
+			item.VoteCount = &count
+			item.Percent = &percent
+		}
+		resp.Options[i] = item
+	}
+	return resp
+}
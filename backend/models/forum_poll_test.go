@@ -0,0 +1,116 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestForumPoll 三个选项、三人参与的投票
+func newTestForumPoll(hideResults bool, closesAt *time.Time) *ForumPoll {
+	return &ForumPoll{
+		ID:          1,
+		Question:    "你家宝宝喝哪个牌子的奶粉？",
+		HideResults: hideResults,
+		ClosesAt:    closesAt,
+		VoterCount:  3,
+		Options: []ForumPollOption{
+			{ID: 11, PollID: 1, Content: "飞鹤", VoteCount: 2},
+			{ID: 12, PollID: 1, Content: "爱他美", VoteCount: 1},
+			{ID: 13, PollID: 1, Content: "美赞臣", VoteCount: 0},
+		},
+	}
+}
+
+// TestForumPollToResponseVisibility 测试隐藏结果的投票在投票前、投票后与截止后的可见性
+func TestForumPollToResponseVisibility(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	ballot := &ForumPollBallot{PollID: 1, UserID: 7, OptionIDs: []uint{12}}
+
+	testCases := []struct {
+		name     string
+		hide     bool
+		closesAt *time.Time
+		ballot   *ForumPollBallot
+		visible  bool
+		closed   bool
+	}{
+		{name: "不隐藏结果", hide: false, visible: true},
+		{name: "隐藏且未投票", hide: true, closesAt: &future, visible: false},
+		{name: "隐藏且已投票", hide: true, ballot: ballot, visible: true},
+		{name: "隐藏但已截止", hide: true, closesAt: &past, visible: true, closed: true},
+		{name: "恰好到截止时间", hide: true, closesAt: &now, visible: true, closed: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := newTestForumPoll(tc.hide, tc.closesAt).ToResponse(tc.ballot, now)
+			assert.Equal(t, tc.visible, resp.ResultsVisible)
+			assert.Equal(t, tc.closed, resp.IsClosed)
+			assert.Equal(t, tc.ballot != nil, resp.HasVoted)
+			require.Len(t, resp.Options, 3)
+
+			if !tc.visible {
+				assert.Nil(t, resp.VoterCount)
+				for _, opt := range resp.Options {
+					assert.Nil(t, opt.VoteCount)
+					assert.Nil(t, opt.Percent)
+				}
+				return
+			}
+			require.NotNil(t, resp.VoterCount)
+			assert.Equal(t, int64(3), *resp.VoterCount)
+			for _, opt := range resp.Options {
+				assert.NotNil(t, opt.VoteCount)
+				assert.NotNil(t, opt.Percent)
+			}
+		})
+	}
+}
+
+// TestForumPollToResponseCounts 测试百分比按投票人数四舍五入到一位小数，以及当前用户的选择标记
+func TestForumPollToResponseCounts(t *testing.T) {
+	poll := newTestForumPoll(false, nil)
+	resp := poll.ToResponse(&ForumPollBallot{OptionIDs: []uint{11, 13}}, time.Now())
+
+	expected := []struct {
+		count    int64
+		percent  float64
+		selected bool
+	}{
+		{count: 2, percent: 66.7, selected: true},
+		{count: 1, percent: 33.3, selected: false},
+		{count: 0, percent: 0, selected: true},
+	}
+	require.Len(t, resp.Options, len(expected))
+	for i, want := range expected {
+		opt := resp.Options[i]
+		assert.Equal(t, poll.Options[i].ID, opt.ID)
+		assert.Equal(t, want.count, *opt.VoteCount)
+		assert.Equal(t, want.percent, *opt.Percent)
+		assert.Equal(t, want.selected, opt.Selected)
+	}
+
+	// 多选时百分比按人数计算，合计可以超过 100
+	poll.MultipleChoice = true
+	poll.VoterCount = 2
+	resp = poll.ToResponse(nil, time.Now())
+	assert.Equal(t, 100.0, *resp.Options[0].Percent)
+	assert.Equal(t, 50.0, *resp.Options[1].Percent)
+	for _, opt := range resp.Options {
+		assert.False(t, opt.Selected)
+	}
+
+	// 无人投票时百分比为 0
+	poll.VoterCount = 0
+	for i := range poll.Options {
+		poll.Options[i].VoteCount = 0
+	}
+	resp = poll.ToResponse(nil, time.Now())
+	for _, opt := range resp.Options {
+		assert.Equal(t, 0.0, *opt.Percent)
+	}
+}
@@ -10,7 +10,9 @@ import (
 type ForumPost struct {
 	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
 	Title       string         `json:"title" gorm:"type:varchar(200);not null;comment:帖子标题"`
-	Content     string         `json:"content" gorm:"type:text;not null;comment:帖子内容"`
+	Content     string         `json:"content" gorm:"type:text;not null;comment:帖子内容（源文）"`
+	ContentFormat string       `json:"content_format" gorm:"type:varchar(20);not null;default:markdown;comment:正文格式 html/markdown"`
+	ContentHTML string         `json:"content_html" gorm:"type:mediumtext;comment:过滤后的正文HTML"`
 	Topic       string         `json:"topic" gorm:"type:varchar(50);not null;index;comment:话题分类"`
 	AuthorID    uint           `json:"author_id" gorm:"not null;index;comment:作者ID"`
 	ViewCount   int64          `json:"view_count" gorm:"type:bigint;default:0;comment:浏览次数"`
@@ -18,8 +20,16 @@ type ForumPost struct {
 	LikeCount   int64          `json:"like_count" gorm:"type:bigint;default:0;comment:点赞次数"`
 	IsTop       bool           `json:"is_top" gorm:"type:boolean;default:false;comment:是否置顶"`
     IsHot       bool           `json:"is_hot" gorm:"type:boolean;default:false;comment:是否热门"`
+    HotManual   bool           `json:"-" gorm:"type:boolean;default:false;comment:热门状态由管理员手动设置，不参与自动维护"`
     IsLocked    bool           `json:"is_locked" gorm:"type:boolean;default:false;comment:是否锁定（仅管理员可编辑）"`
-	Status      int8           `json:"status" gorm:"type:tinyint;default:1;comment:状态 0-草稿 1-已发布 2-已删除"`
+	Status      int8           `json:"status" gorm:"type:tinyint;default:1;comment:状态 0-草稿 1-已发布 2-已删除 3-待审核"`
+	PostType    string         `json:"post_type" gorm:"type:varchar(20);not null;default:discussion;index;comment:帖子类型 discussion/question"`
+	AcceptedReplyID *uint      `json:"accepted_reply_id" gorm:"index;comment:被采纳的回复ID（问答帖）"`
+	SolvedAt    *time.Time     `json:"solved_at" gorm:"comment:采纳答案时间"`
+	BountyPoints int64         `json:"bounty_points" gorm:"type:bigint;default:0;comment:悬赏积分（发帖时从提问者积分中扣除托管）"`
+	BountyStatus string        `json:"bounty_status" gorm:"type:varchar(20);not null;default:none;index;comment:悬赏状态 none/open/awarded/auto_awarded/refunded"`
+	BountyExpiresAt *time.Time `json:"bounty_expires_at" gorm:"index;comment:悬赏到期时间"`
+	BountyReplyID *uint        `json:"bounty_reply_id" gorm:"comment:获得悬赏的回复ID"`
 	LastReplyAt *time.Time     `json:"last_reply_at" gorm:"comment:最后回复时间"`
 	CreatedAt   time.Time      `json:"created_at" gorm:"comment:创建时间"`
 	UpdatedAt   time.Time      `json:"updated_at" gorm:"comment:更新时间"`
@@ -34,13 +44,19 @@ type ForumPost struct {
 type ForumPostCreateRequest struct {
 	Title   string `json:"title" binding:"required,min=1,max=200" example:"新手妈妈求助：宝宝睡眠问题"`
 	Content string `json:"content" binding:"required,min=1,max=10000" example:"我家宝宝4个月了，最近睡眠很不稳定..."`
+	ContentFormat string `json:"content_format" binding:"omitempty,oneof=html markdown" example:"markdown"` // 正文格式，默认 markdown
 	Topic   string `json:"topic" binding:"required,min=1,max=50" example:"Sleep"`
+	PostType string `json:"post_type" binding:"omitempty,oneof=discussion question" example:"question"` // 帖子类型，默认 discussion
+	BountyPoints int64 `json:"bounty_points" binding:"omitempty,min=0,max=10000" example:"50"` // 悬赏积分，仅问答帖可设置
+	BountyDays int `json:"bounty_days" binding:"omitempty,min=1,max=30" example:"7"` // 悬赏有效天数，默认 7 天
+	Poll     *ForumPollCreateRequest `json:"poll"` // 可选，附带投票
 }
 
 // ForumPostUpdateRequest 更新帖子请求
 type ForumPostUpdateRequest struct {
 	Title   string `json:"title" binding:"min=1,max=200"`
 	Content string `json:"content" binding:"min=1,max=10000"`
+	ContentFormat string `json:"content_format" binding:"omitempty,oneof=html markdown"` // 为空时沿用原格式
 	Topic   string `json:"topic" binding:"min=1,max=50"`
 	Status  *int8  `json:"status" binding:"omitempty,min=0,max=2"`
 }
@@ -56,6 +72,8 @@ type ForumPostListRequest struct {
   IsTop    *bool  `form:"is_top" example:"true"`
   IsHot    *bool  `form:"is_hot" example:"true"`
   IsLocked *bool  `form:"is_locked" example:"true"`
+  PostType string `form:"post_type" binding:"omitempty,oneof=discussion question" example:"question"`
+  Filter   string `form:"filter" binding:"omitempty,oneof=unanswered solved" example:"unanswered"` // 问答筛选：unanswered 无人回答，solved 已解决
 }
 
 // AdminForumPostListRequest 管理员帖子列表请求（可查看所有状态）
@@ -78,6 +96,8 @@ type ForumPostResponse struct {
 	ID          uint              `json:"id"`
 	Title       string            `json:"title"`
 	Content     string            `json:"content,omitempty"` // 列表时可能不返回完整内容
+	ContentFormat string          `json:"content_format"`
+	ContentHTML string            `json:"content_html,omitempty"`
 	Topic       string            `json:"topic"`
 	AuthorID    uint              `json:"author_id"`
 	ViewCount   int64             `json:"view_count"`
@@ -87,11 +107,18 @@ type ForumPostResponse struct {
     IsHot       bool              `json:"is_hot"`
     IsLocked    bool              `json:"is_locked"`
 	Status      int8              `json:"status"`
+	PostType    string            `json:"post_type"`
+	AcceptedReplyID *uint         `json:"accepted_reply_id,omitempty"`
+	SolvedAt    *time.Time        `json:"solved_at,omitempty"`
+	BountyPoints int64            `json:"bounty_points"`
+	BountyStatus string           `json:"bounty_status"`
+	BountyExpiresAt *time.Time    `json:"bounty_expires_at,omitempty"`
 	LastReplyAt *time.Time        `json:"last_reply_at"`
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
 	Author      *UserResponse     `json:"author,omitempty"`
 	RecentReply *ForumReplyResponse `json:"recent_reply,omitempty"` // 最新回复
+	Poll        *ForumPollResponse  `json:"poll,omitempty"`         // 附带的投票
 	TimeAgo     string            `json:"time_ago,omitempty"`      // 前端显示用的相对时间
 }
 
@@ -101,6 +128,7 @@ func (fp *ForumPost) ToResponse(includeContent bool) *ForumPostResponse {
 		ID:          fp.ID,
 		Title:       fp.Title,
 		Topic:       fp.Topic,
+		ContentFormat: fp.ContentFormat,
 		AuthorID:    fp.AuthorID,
 		ViewCount:   fp.ViewCount,
 		ReplyCount:  fp.ReplyCount,
@@ -109,6 +137,12 @@ func (fp *ForumPost) ToResponse(includeContent bool) *ForumPostResponse {
         IsHot:       fp.IsHot,
         IsLocked:    fp.IsLocked,
 		Status:      fp.Status,
+		PostType:    fp.PostType,
+		AcceptedReplyID: fp.AcceptedReplyID,
+		SolvedAt:    fp.SolvedAt,
+		BountyPoints: fp.BountyPoints,
+		BountyStatus: fp.BountyStatus,
+		BountyExpiresAt: fp.BountyExpiresAt,
 		LastReplyAt: fp.LastReplyAt,
 		CreatedAt:   fp.CreatedAt,
 		UpdatedAt:   fp.UpdatedAt,
@@ -117,6 +151,7 @@ func (fp *ForumPost) ToResponse(includeContent bool) *ForumPostResponse {
 	// 根据需要包含内容
 	if includeContent {
 		resp.Content = fp.Content
+		resp.ContentHTML = fp.ContentHTML
 	}
 
 	// 包含关联数据
@@ -132,6 +167,37 @@ func (ForumPost) TableName() string {
 	return "forum_posts"
 }
 
+// 帖子状态
+const (
+	ForumPostStatusDraft     int8 = 0 // 草稿
+	ForumPostStatusPublished int8 = 1 // 已发布
+	ForumPostStatusDeleted   int8 = 2 // 已删除
+	ForumPostStatusHeld      int8 = 3 // 待审核（疑似垃圾内容）
+)
+
+// 帖子类型
+const (
+	ForumPostTypeDiscussion = "discussion" // 普通讨论
+	ForumPostTypeQuestion   = "question"   // 问答，可采纳答案并设置悬赏
+)
+
+// 悬赏状态
+const (
+	BountyStatusNone        = "none"         // 未设置悬赏
+	BountyStatusOpen        = "open"         // 悬赏中，积分已托管
+	BountyStatusAwarded     = "awarded"      // 提问者采纳答案，积分已发放
+	BountyStatusAutoAwarded = "auto_awarded" // 到期未采纳，自动发放给最高赞回答
+	BountyStatusRefunded    = "refunded"     // 到期无合适回答或帖子被删除，积分已退还
+)
+
+// QuestionBountyDefaultDays 悬赏默认有效天数
+const QuestionBountyDefaultDays = 7
+
+// ForumPostAcceptRequest 采纳答案请求
+type ForumPostAcceptRequest struct {
+	ReplyID uint `json:"reply_id" binding:"required,min=1" example:"12"`
+}
+
 // Topic 常量定义
 const (
 	TopicAll           = "All"
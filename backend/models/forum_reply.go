@@ -12,9 +12,12 @@ type ForumReply struct {
 	PostID    uint           `json:"post_id" gorm:"not null;index;comment:帖子ID"`
 	AuthorID  uint           `json:"author_id" gorm:"not null;index;comment:回复者ID"`
 	ParentID  *uint          `json:"parent_id" gorm:"index;comment:父回复ID,用于嵌套回复"`
-	Content   string         `json:"content" gorm:"type:text;not null;comment:回复内容"`
+	Content   string         `json:"content" gorm:"type:text;not null;comment:回复内容（源文）"`
+	ContentFormat string     `json:"content_format" gorm:"type:varchar(20);not null;default:markdown;comment:正文格式 html/markdown"`
+	ContentHTML string       `json:"content_html" gorm:"type:mediumtext;comment:过滤后的回复HTML"`
 	LikeCount int64          `json:"like_count" gorm:"type:bigint;default:0;comment:点赞次数"`
 	Status    int8           `json:"status" gorm:"type:tinyint;default:1;comment:状态 0-草稿 1-已发布 2-已删除"`
+	IsAccepted bool          `json:"is_accepted" gorm:"type:boolean;default:false;comment:是否被提问者采纳"`
 	CreatedAt time.Time      `json:"created_at" gorm:"comment:创建时间"`
 	UpdatedAt time.Time      `json:"updated_at" gorm:"comment:更新时间"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index;comment:删除时间"`
@@ -31,6 +34,7 @@ type ForumReplyCreateRequest struct {
 	PostID   uint   `json:"post_id" binding:"required,min=1" example:"1"`
 	ParentID *uint  `json:"parent_id" example:"2"` // 可选，用于嵌套回复
 	Content  string `json:"content" binding:"required,min=1,max=5000" example:"我觉得你可以尝试..."`
+	ContentFormat string `json:"content_format" binding:"omitempty,oneof=html markdown" example:"markdown"` // 正文格式，默认 markdown
 }
 
 // ForumReplyUpdateRequest 更新回复请求
@@ -55,8 +59,11 @@ type ForumReplyResponse struct {
 	AuthorID  uint              `json:"author_id"`
 	ParentID  *uint             `json:"parent_id"`
 	Content   string            `json:"content"`
+	ContentFormat string        `json:"content_format"`
+	ContentHTML string          `json:"content_html"`
 	LikeCount int64             `json:"like_count"`
 	Status    int8              `json:"status"`
+	IsAccepted bool             `json:"is_accepted"`
 	CreatedAt time.Time         `json:"created_at"`
 	UpdatedAt time.Time         `json:"updated_at"`
 	Author    *UserResponse     `json:"author,omitempty"`
@@ -73,8 +80,11 @@ func (fr *ForumReply) ToResponse() *ForumReplyResponse {
 		AuthorID:  fr.AuthorID,
 		ParentID:  fr.ParentID,
 		Content:   fr.Content,
+		ContentFormat: fr.ContentFormat,
+		ContentHTML: fr.ContentHTML,
 		LikeCount: fr.LikeCount,
 		Status:    fr.Status,
+		IsAccepted: fr.IsAccepted,
 		CreatedAt: fr.CreatedAt,
 		UpdatedAt: fr.UpdatedAt,
 	}
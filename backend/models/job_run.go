@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// 任务执行状态
+const (
+	JobRunStatusRunning = "running"
+	JobRunStatusSuccess = "success"
+	JobRunStatusFailed  = "failed"
+)
+
+// 任务触发方式
+const (
+	JobTriggerSchedule = "schedule" // 按计划自动执行
+	JobTriggerManual   = "manual"   // 管理员手动触发
+)
+
+// JobRun 定时任务执行记录
+type JobRun struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	JobName     string     `json:"job_name" gorm:"type:varchar(64);not null;index:idx_job_runs_job_started,priority:1;comment:任务名称"`
+	Trigger     string     `json:"trigger" gorm:"type:varchar(20);not null;comment:触发方式 schedule/manual"`
+	TriggeredBy *uint      `json:"triggered_by" gorm:"comment:手动触发的管理员ID"`
+	Instance    string     `json:"instance" gorm:"type:varchar(128);comment:执行实例"`
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;index;comment:状态 running/success/failed"`
+	Message     string     `json:"message" gorm:"type:text;comment:执行结果或错误信息"`
+	StartedAt   time.Time  `json:"started_at" gorm:"not null;index:idx_job_runs_job_started,priority:2;comment:开始时间"`
+	FinishedAt  *time.Time `json:"finished_at" gorm:"comment:结束时间"`
+	DurationMs  int64      `json:"duration_ms" gorm:"not null;default:0;comment:耗时（毫秒）"`
+}
+
+func (JobRun) TableName() string { return "job_runs" }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"log"
 
 	"gorm.io/gorm"
@@ -10,6 +11,15 @@ import (
 func AutoMigrate(db *gorm.DB) error {
 	log.Println("开始数据库迁移...")
 
+	// 文章标签使用自定义关联表（带创建时间）
+	if err := db.SetupJoinTable(&Article{}, "Tags", &ArticleTag{}); err != nil {
+		log.Printf("设置文章标签关联表失败: %v", err)
+		return err
+	}
+
+	// hot_manual 列新增前设置的热门帖子均由管理员手动标记，迁移后需回填，避免被热度排行自动取消
+	backfillHotManual := db.Migrator().HasTable(&ForumPost{}) && !db.Migrator().HasColumn(&ForumPost{}, "hot_manual")
+
 	// 迁移所有模型
 	err := db.AutoMigrate(
 		&User{},
@@ -28,10 +38,34 @@ func AutoMigrate(db *gorm.DB) error {
 		&ChatDailyLimit{},
 		&ForumPost{},
 		&ForumReply{},
+		&ForumPoll{},
+		&ForumPollOption{},
+		&ForumPollBallot{},
 		&Topic{},
 		&Resource{},
 		&Report{},
 		&Appeal{},
+		&ViewCountFlush{},
+		&UserSession{},
+		&ModerationLog{},
+		&JobRun{},
+		&ArticleRevision{},
+		&ArticleDraft{},
+		&Tag{},
+		&ArticleTag{},
+		&TagFollow{},
+		&ArticleVector{},
+		&ArticleTermStat{},
+		&ArticleRelation{},
+		&UserBlock{},
+		&ArticleNeighbor{},
+		&ArticleDismissal{},
+		&Series{},
+		&SeriesArticle{},
+		&SeriesFollow{},
+		&SensitiveWordCategory{},
+		&SensitiveWord{},
+		&SpamCheck{},
 	)
 
 	if err != nil {
@@ -39,12 +73,25 @@ func AutoMigrate(db *gorm.DB) error {
 		return err
 	}
 
+	if backfillHotManual {
+		if err := db.Exec("UPDATE forum_posts SET hot_manual = is_hot").Error; err != nil {
+			log.Printf("回填帖子手动热门标记失败: %v", err)
+			return err
+		}
+	}
+
 	// 创建索引
 	if err := createIndexes(db); err != nil {
 		log.Printf("创建索引失败: %v", err)
 		return err
 	}
 
+	// 创建全文索引（搜索）
+	if err := createFulltextIndexes(db); err != nil {
+		log.Printf("创建全文索引失败: %v", err)
+		return err
+	}
+
 	// 确保枚举类型字段更新（例如：notifications.type 增加 system）
 	if err := ensureEnumColumns(db); err != nil {
 		log.Printf("更新枚举字段失败: %v", err)
@@ -69,8 +116,8 @@ func AutoMigrate(db *gorm.DB) error {
 
 // ensureEnumColumns 确保枚举字段包含最新取值
 func ensureEnumColumns(db *gorm.DB) error {
-    // MySQL: 扩展 notifications.type 枚举，加入 'system','mention','moderation'
-    db.Exec("ALTER TABLE notifications MODIFY COLUMN type ENUM('like','comment','bookmark','follow','message','system','mention','moderation') NOT NULL")
+    // MySQL: 扩展 notifications.type 枚举，加入 'system','mention','moderation','article'
+    db.Exec("ALTER TABLE notifications MODIFY COLUMN type ENUM('like','comment','bookmark','follow','message','system','mention','moderation','article') NOT NULL")
     // 新增标题列（如果不存在）
     db.Exec("ALTER TABLE notifications ADD COLUMN IF NOT EXISTS title VARCHAR(255) NULL AFTER type")
     // 新增 comment_id 列（如果不存在）
@@ -181,6 +228,48 @@ func createIndexes(db *gorm.DB) error {
     return nil
 }
 
+// fulltextIndexes 搜索使用的全文索引（ngram 分词，支持中文）
+// 每张表需要 (title) 与 (title, 正文) 两个索引，分别用于标题加权与全文匹配
+var fulltextIndexes = []struct {
+	table   string
+	name    string
+	columns string
+}{
+	{"articles", "ft_articles_title", "title"},
+	{"articles", "ft_articles_title_content", "title, content"},
+	{"forum_posts", "ft_forum_posts_title", "title"},
+	{"forum_posts", "ft_forum_posts_title_content", "title, content"},
+	{"resources", "ft_resources_title", "title"},
+	{"resources", "ft_resources_title_description", "title, description"},
+}
+
+// createFulltextIndexes 创建全文索引（仅 MySQL），已存在的索引跳过
+func createFulltextIndexes(db *gorm.DB) error {
+	if db.Dialector.Name() != "mysql" {
+		return nil
+	}
+
+	for _, idx := range fulltextIndexes {
+		var count int64
+		err := db.Raw(`SELECT COUNT(*) FROM information_schema.statistics
+			WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?`, idx.table, idx.name).
+			Scan(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		log.Printf("创建全文索引 %s.%s ...", idx.table, idx.name)
+		sql := fmt.Sprintf("ALTER TABLE %s ADD FULLTEXT INDEX %s (%s) WITH PARSER ngram", idx.table, idx.name, idx.columns)
+		if err := db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("创建全文索引 %s 失败: %v", idx.name, err)
+		}
+	}
+	return nil
+}
+
 // createForeignKeys 创建外键约束
 func createForeignKeys(db *gorm.DB) error {
 	// 文章表外键
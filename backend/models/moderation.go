@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// ModerationAction 举报处理动作
+type ModerationAction string
+
+const (
+	ModerationActionWarning   ModerationAction = "warning"   // 警告（仅通知）
+	ModerationActionUnpublish ModerationAction = "unpublish" // 下架文章
+	ModerationActionDelete    ModerationAction = "delete"    // 删除文章/帖子/回复
+	ModerationActionLock      ModerationAction = "lock"      // 锁定帖子
+	ModerationActionHide      ModerationAction = "hide"      // 隐藏评论
+	ModerationActionMute      ModerationAction = "mute"      // 禁言作者（需指定时长）
+	ModerationActionBan       ModerationAction = "ban"       // 封禁作者（时长为0表示永久）
+	ModerationActionRevert    ModerationAction = "revert"    // 撤销处理（申诉通过）
+)
+
+// 举报对象类型
+const (
+	ReportTargetArticle    = "article"
+	ReportTargetForumPost  = "forum_post"
+	ReportTargetForumReply = "forum_reply"
+	ReportTargetComment    = "comment"
+)
+
+// moderationActionsByTarget 各对象类型允许的处理动作
+var moderationActionsByTarget = map[string][]ModerationAction{
+	ReportTargetArticle:    {ModerationActionUnpublish, ModerationActionDelete, ModerationActionWarning, ModerationActionMute, ModerationActionBan},
+	ReportTargetForumPost:  {ModerationActionLock, ModerationActionDelete, ModerationActionWarning, ModerationActionMute, ModerationActionBan},
+	ReportTargetForumReply: {ModerationActionDelete, ModerationActionWarning, ModerationActionMute, ModerationActionBan},
+	ReportTargetComment:    {ModerationActionHide, ModerationActionWarning, ModerationActionMute, ModerationActionBan},
+}
+
+// IsValidReportTarget 是否为支持举报的对象类型
+func IsValidReportTarget(targetType string) bool {
+	_, ok := moderationActionsByTarget[targetType]
+	return ok
+}
+
+// IsAllowedFor 动作是否适用于指定对象类型
+func (a ModerationAction) IsAllowedFor(targetType string) bool {
+	for _, allowed := range moderationActionsByTarget[targetType] {
+		if allowed == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Label 动作的中文名称
+func (a ModerationAction) Label() string {
+	switch a {
+	case ModerationActionWarning:
+		return "警告"
+	case ModerationActionUnpublish:
+		return "下架"
+	case ModerationActionDelete:
+		return "删除"
+	case ModerationActionLock:
+		return "锁定"
+	case ModerationActionHide:
+		return "隐藏"
+	case ModerationActionMute:
+		return "禁言"
+	case ModerationActionBan:
+		return "封禁"
+	case ModerationActionRevert:
+		return "撤销处理"
+	default:
+		return string(a)
+	}
+}
+
+// ModerationLog 内容治理审计记录
+// 每次执行或撤销处理动作都会写入一条记录，Snapshot 保存执行前状态，用于申诉通过后恢复
+type ModerationLog struct {
+	ID            uint             `json:"id" gorm:"primaryKey;autoIncrement"`
+	ReportID      *uint            `json:"report_id" gorm:"index;comment:关联举报ID"`
+	AppealID      *uint            `json:"appeal_id" gorm:"index;comment:关联申诉ID(撤销记录)"`
+	Action        ModerationAction `json:"action" gorm:"type:varchar(30);not null;comment:处理动作"`
+	TargetType    string           `json:"target_type" gorm:"type:varchar(50);not null;index:idx_moderation_logs_target;comment:对象类型"`
+	TargetID      uint             `json:"target_id" gorm:"not null;index:idx_moderation_logs_target;comment:对象ID"`
+	TargetUserID  uint             `json:"target_user_id" gorm:"index;comment:被处理的用户(内容作者)"`
+	OperatorID    uint             `json:"operator_id" gorm:"not null;index;comment:操作人(管理员)"`
+	Note          string           `json:"note" gorm:"type:varchar(255);comment:处理备注"`
+	DurationHours int              `json:"duration_hours" gorm:"default:0;comment:禁言/封禁时长(小时)"`
+	ExpiresAt     *time.Time       `json:"expires_at" gorm:"comment:禁言/封禁到期时间"`
+	Snapshot      string           `json:"-" gorm:"type:text;comment:执行前状态(JSON)"`
+	RevertOfID    *uint            `json:"revert_of_id" gorm:"index;comment:撤销的原记录ID"`
+	RevertedAt    *time.Time       `json:"reverted_at" gorm:"comment:被撤销时间"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+func (ModerationLog) TableName() string { return "moderation_logs" }
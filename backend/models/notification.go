@@ -17,18 +17,20 @@ const (
     NotificationTypeSystem   NotificationType = "system"   // 系统公告/广播（全员广播）
     NotificationTypeModeration NotificationType = "moderation" // 管理动作通知（违规处理、举报结果等）
     NotificationTypeMention  NotificationType = "mention"  // 提及/@我
+    NotificationTypeArticle  NotificationType = "article"  // 关注的作者发布了新文章
 )
 
 type Notification struct {
     ID         uint             `gorm:"primaryKey" json:"id"`
     ReceiverID uint             `gorm:"not null;index" json:"receiver_id"` // 接收者ID
     ActorID    uint             `gorm:"not null;index" json:"actor_id"`    // 行为发起者ID
-    Type       NotificationType `gorm:"not null;type:enum('like','comment','bookmark','follow','message','system','mention','moderation')" json:"type"`
+    Type       NotificationType `gorm:"not null;type:enum('like','comment','bookmark','follow','message','system','mention','moderation','article')" json:"type"`
     Title      string           `gorm:"type:varchar(255)" json:"title,omitempty"` // 标题（系统通知等）
     ResourceID uint             `gorm:"column:resource_id" json:"resource_id"` // 资源ID（文章ID、会话ID等）
     CommentID  uint             `json:"comment_id,omitempty"`  // 扩展资源ID（用于@提及精确到评论）
     Message    string           `gorm:"type:text" json:"message"`
     IsRead     bool             `gorm:"default:false;index" json:"is_read"`
+    ReplacedBy uint             `gorm:"default:0;index" json:"-"` // 合并重复通知时重新发出的新通知ID，0 表示未被替换
     CreatedAt  time.Time        `json:"created_at"`
     UpdatedAt  time.Time        `json:"updated_at"`
 	DeletedAt  gorm.DeletedAt   `gorm:"index" json:"deleted_at,omitempty"`
@@ -58,6 +60,7 @@ type NotificationTypeStats struct {
     Bookmark    int64 `json:"bookmark"`
     System      int64 `json:"system"`
     Mention     int64 `json:"mention"`
+    Article     int64 `json:"article"`
 }
 
 // NotificationWithDetails 带详细信息的通知
@@ -69,4 +72,5 @@ type NotificationWithDetails struct {
     ArticleTitle   string `json:"article_title,omitempty"`
     ArticleCover   string `json:"article_cover,omitempty"`
     CommentContent string `json:"comment_content,omitempty"`
+    ReplacesID     uint   `gorm:"-" json:"replaces_id,omitempty"` // 实时推送时被本条替换的旧通知ID，客户端据此移除旧条目
 }
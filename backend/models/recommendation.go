@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// ArticleNeighbor 协同过滤得到的相似文章（喜欢这篇的用户也喜欢），由定时任务离线计算
+type ArticleNeighbor struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement"`
+	ArticleID  uint      `gorm:"not null;uniqueIndex:idx_article_neighbors_pair,priority:1;comment:文章ID"`
+	NeighborID uint      `gorm:"not null;uniqueIndex:idx_article_neighbors_pair,priority:2;comment:相似文章ID"`
+	Score      float64   `gorm:"not null;default:0;comment:相似度"`
+	CoUsers    int       `gorm:"not null;default:0;comment:同时互动过两篇文章的用户数"`
+	UpdatedAt  time.Time `gorm:"comment:更新时间"`
+}
+
+func (ArticleNeighbor) TableName() string { return "article_neighbors" }
+
+// ArticleDismissal 用户对推荐文章标记“不感兴趣”，之后不再推荐
+type ArticleDismissal struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_article_dismissals_pair,priority:1;comment:用户ID"`
+	ArticleID uint      `gorm:"not null;uniqueIndex:idx_article_dismissals_pair,priority:2;comment:文章ID"`
+	CreatedAt time.Time `gorm:"comment:创建时间"`
+}
+
+func (ArticleDismissal) TableName() string { return "article_dismissals" }
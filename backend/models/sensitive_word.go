@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// 敏感词分类的处理方式
+const (
+	SensitiveActionBlock  = "block"  // 拒绝提交
+	SensitiveActionMask   = "mask"   // 替换为 *
+	SensitiveActionReview = "review" // 转人工审核
+)
+
+// IsValidSensitiveAction 是否为支持的处理方式
+func IsValidSensitiveAction(action string) bool {
+	switch action {
+	case SensitiveActionBlock, SensitiveActionMask, SensitiveActionReview:
+		return true
+	}
+	return false
+}
+
+// SensitiveWordCategory 敏感词分类，同一分类下的词使用相同的处理方式
+type SensitiveWordCategory struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string    `json:"name" gorm:"type:varchar(50);not null;uniqueIndex;comment:分类名称"`
+	Action      string    `json:"action" gorm:"type:varchar(20);not null;default:block;comment:处理方式 block/mask/review"`
+	Description string    `json:"description" gorm:"type:varchar(255);comment:分类说明"`
+	IsActive    bool      `json:"is_active" gorm:"not null;default:true;comment:是否启用"`
+	WordCount   int64     `json:"word_count" gorm:"-"`
+	CreatedAt   time.Time `json:"created_at" gorm:"comment:创建时间"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"comment:更新时间"`
+}
+
+func (SensitiveWordCategory) TableName() string { return "sensitive_word_categories" }
+
+// SensitiveWord 敏感词，Variants 为管理员录入的其他写法（如谐音、缩写），多个用逗号分隔，与原词同等匹配
+type SensitiveWord struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Word       string    `json:"word" gorm:"type:varchar(100);not null;uniqueIndex;comment:敏感词"`
+	Variants   string    `json:"variants" gorm:"type:varchar(255);comment:其他写法，逗号分隔"`
+	CategoryID uint      `json:"category_id" gorm:"not null;index;comment:分类ID"`
+	IsActive   bool      `json:"is_active" gorm:"not null;default:true;comment:是否启用"`
+	CreatedBy  uint      `json:"created_by" gorm:"comment:添加人ID"`
+	CreatedAt  time.Time `json:"created_at" gorm:"comment:创建时间"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"comment:更新时间"`
+
+	// 关联关系
+	Category SensitiveWordCategory `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+}
+
+func (SensitiveWord) TableName() string { return "sensitive_words" }
+
+// SensitiveCategoryRequest 创建/更新敏感词分类请求
+type SensitiveCategoryRequest struct {
+	Name        string `json:"name" binding:"required,max=50" example:"广告引流"`
+	Action      string `json:"action" binding:"required,oneof=block mask review" example:"review"`
+	Description string `json:"description" binding:"max=255"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+// SensitiveWordRequest 创建/更新敏感词请求
+type SensitiveWordRequest struct {
+	Word       string `json:"word" binding:"required,max=100" example:"加微信"`
+	Variants   string `json:"variants" binding:"max=255" example:"加VX,加威信"`
+	CategoryID uint   `json:"category_id" binding:"required,min=1" example:"1"`
+	IsActive   *bool  `json:"is_active"`
+}
+
+// SensitiveWordImportRequest 批量导入敏感词请求，每行一个词，可用 "|" 附带逗号分隔的其他写法
+type SensitiveWordImportRequest struct {
+	CategoryID uint     `json:"category_id" binding:"required,min=1" example:"1"`
+	Words      []string `json:"words" binding:"required,min=1,max=5000" example:"加微信|加VX,加威信"`
+}
+
+// SensitiveCheckRequest 敏感词检测请求
+type SensitiveCheckRequest struct {
+	Text string `json:"text" binding:"required,max=20000"`
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 系列类型
+const (
+	SeriesKindAuthor    = "author"    // 作者自己的连载
+	SeriesKindEditorial = "editorial" // 管理员策划的专题，可收录任意作者的文章
+)
+
+// Series 文章系列，按顺序组织多篇文章
+type Series struct {
+	ID            uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	Title         string         `json:"title" gorm:"type:varchar(100);not null;comment:系列标题"`
+	Description   string         `json:"description" gorm:"type:varchar(500);comment:系列简介"`
+	CoverImage    string         `json:"cover_image" gorm:"type:varchar(255);comment:封面图片"`
+	Kind          string         `json:"kind" gorm:"type:varchar(20);not null;default:'author';index;comment:类型 author-作者连载 editorial-编辑专题"`
+	OwnerID       uint           `json:"owner_id" gorm:"not null;index;comment:创建者ID"`
+	ArticleCount  int64          `json:"article_count" gorm:"type:bigint;default:0;comment:已发布文章数"`
+	FollowerCount int64          `json:"follower_count" gorm:"type:bigint;default:0;comment:关注人数"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"comment:创建时间"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"comment:更新时间"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index;comment:删除时间"`
+
+	Owner User `json:"owner,omitempty" gorm:"foreignKey:OwnerID"`
+}
+
+func (Series) TableName() string { return "series" }
+
+// SeriesArticle 系列中的文章，Position 从 1 开始连续编号
+// NotifiedAt 记录已通知关注者的时间，保证每篇文章只在首次以已发布状态进入系列时通知一次
+type SeriesArticle struct {
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	SeriesID   uint       `json:"series_id" gorm:"not null;uniqueIndex:idx_series_articles_pair,priority:1;index:idx_series_articles_position,priority:1;comment:系列ID"`
+	ArticleID  uint       `json:"article_id" gorm:"not null;uniqueIndex:idx_series_articles_pair,priority:2;index;comment:文章ID"`
+	Position   int        `json:"position" gorm:"not null;index:idx_series_articles_position,priority:2;comment:顺序"`
+	NotifiedAt *time.Time `json:"-" gorm:"comment:通知关注者的时间"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"comment:加入时间"`
+
+	Article Article `json:"article,omitempty" gorm:"foreignKey:ArticleID"`
+}
+
+func (SeriesArticle) TableName() string { return "series_articles" }
+
+// SeriesFollow 用户关注的系列
+type SeriesFollow struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_series_follows_user_series,priority:1;comment:用户ID"`
+	SeriesID  uint      `json:"series_id" gorm:"not null;uniqueIndex:idx_series_follows_user_series,priority:2;index;comment:系列ID"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (SeriesFollow) TableName() string { return "series_follows" }
+
+// SeriesCreateRequest 创建系列请求
+type SeriesCreateRequest struct {
+	Title       string `json:"title" binding:"required,min=1,max=100" example:"0-6个月睡眠训练"`
+	Description string `json:"description" binding:"max=500"`
+	CoverImage  string `json:"cover_image" binding:"max=255"`
+	Editorial   bool   `json:"editorial"` // 编辑专题，仅管理员可创建
+}
+
+// SeriesUpdateRequest 更新系列请求，字段为空表示不修改
+type SeriesUpdateRequest struct {
+	Title       *string `json:"title" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+	CoverImage  *string `json:"cover_image" binding:"omitempty,max=255"`
+}
+
+// SeriesAddArticleRequest 向系列添加文章
+type SeriesAddArticleRequest struct {
+	ArticleID uint `json:"article_id" binding:"required,min=1"`
+	Position  int  `json:"position" binding:"omitempty,min=1"` // 插入位置，不填时追加到末尾
+}
+
+// SeriesReorderRequest 调整系列文章顺序，需包含系列中的全部文章
+type SeriesReorderRequest struct {
+	ArticleIDs []uint `json:"article_ids" binding:"required,min=1"`
+}
+
+// SeriesResponse 系列响应
+type SeriesResponse struct {
+	ID            uint          `json:"id"`
+	Title         string        `json:"title"`
+	Description   string        `json:"description"`
+	CoverImage    string        `json:"cover_image"`
+	Kind          string        `json:"kind"`
+	OwnerID       uint          `json:"owner_id"`
+	ArticleCount  int64         `json:"article_count"`
+	FollowerCount int64         `json:"follower_count"`
+	IsFollowing   bool          `json:"is_following"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	Owner         *UserResponse `json:"owner,omitempty"`
+}
+
+// ToResponse 转换为响应格式
+func (s *Series) ToResponse() *SeriesResponse {
+	resp := &SeriesResponse{
+		ID:            s.ID,
+		Title:         s.Title,
+		Description:   s.Description,
+		CoverImage:    s.CoverImage,
+		Kind:          s.Kind,
+		OwnerID:       s.OwnerID,
+		ArticleCount:  s.ArticleCount,
+		FollowerCount: s.FollowerCount,
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+	}
+	if s.Owner.ID != 0 {
+		resp.Owner = s.Owner.ToResponse()
+	}
+	return resp
+}
+
+// SeriesArticleResponse 系列中的文章
+type SeriesArticleResponse struct {
+	Position int              `json:"position"`
+	Article  *ArticleResponse `json:"article"`
+}
+
+// SeriesNavArticle 上一篇/下一篇
+type SeriesNavArticle struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
+
+// ArticleSeriesNav 文章在某个系列中的位置与前后导航，只计入已发布的文章
+type ArticleSeriesNav struct {
+	SeriesID uint              `json:"series_id"`
+	Title    string            `json:"title"`
+	Kind     string            `json:"kind"`
+	Position int               `json:"position"`
+	Total    int               `json:"total"`
+	Prev     *SeriesNavArticle `json:"prev"`
+	Next     *SeriesNavArticle `json:"next"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// 垃圾内容检测记录的审核状态
+const (
+	SpamReviewNone      = ""          // 未拦截
+	SpamReviewHeld      = "held"      // 已自动拦截，等待管理员处理
+	SpamReviewReleased  = "released"  // 管理员放行
+	SpamReviewConfirmed = "confirmed" // 管理员确认为垃圾内容
+)
+
+// SpamSignal 评分中的一项信号及其解释
+type SpamSignal struct {
+	Name   string `json:"name"`   // duplicate/link_density/velocity/account_age
+	Score  int    `json:"score"`  // 该项贡献的分数
+	Detail string `json:"detail"` // 中文说明
+}
+
+// SpamCheck 帖子或评论的垃圾内容评分记录，同时作为近似重复检测的指纹库
+// Simhash 按 16 位拆成四段分别建索引：汉明距离不超过 3 的两个指纹至少有一段完全相同
+type SpamCheck struct {
+	ID           uint         `json:"id" gorm:"primaryKey;autoIncrement"`
+	TargetType   string       `json:"target_type" gorm:"type:varchar(20);not null;index:idx_spam_checks_target,priority:1;comment:内容类型 forum_post/comment"`
+	TargetID     uint         `json:"target_id" gorm:"not null;index:idx_spam_checks_target,priority:2;comment:内容ID"`
+	UserID       uint         `json:"user_id" gorm:"not null;index:idx_spam_checks_user_created,priority:1;comment:发布者ID"`
+	Score        int          `json:"score" gorm:"not null;default:0;index;comment:垃圾内容评分 0-100"`
+	Signals      []SpamSignal `json:"signals" gorm:"type:text;serializer:json;comment:评分明细"`
+	Simhash      uint64       `json:"simhash,string" gorm:"type:bigint unsigned;not null;default:0;comment:内容指纹，0 表示内容过短未参与去重"`
+	Band0        uint16       `json:"-" gorm:"not null;default:0;index;comment:指纹第1段"`
+	Band1        uint16       `json:"-" gorm:"not null;default:0;index;comment:指纹第2段"`
+	Band2        uint16       `json:"-" gorm:"not null;default:0;index;comment:指纹第3段"`
+	Band3        uint16       `json:"-" gorm:"not null;default:0;index;comment:指纹第4段"`
+	Excerpt      string       `json:"excerpt" gorm:"type:varchar(255);comment:内容摘录"`
+	ReviewStatus string       `json:"review_status" gorm:"type:varchar(20);not null;default:'';index;comment:审核状态 held/released/confirmed"`
+	ReviewedBy   *uint        `json:"reviewed_by" gorm:"comment:处理管理员ID"`
+	ReviewedAt   *time.Time   `json:"reviewed_at" gorm:"comment:处理时间"`
+	CreatedAt    time.Time    `json:"created_at" gorm:"index:idx_spam_checks_user_created,priority:2;comment:创建时间"`
+
+	// 关联关系
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+func (SpamCheck) TableName() string { return "spam_checks" }
+
+// SpamCheckListRequest 评分记录列表请求
+type SpamCheckListRequest struct {
+	Page         int    `form:"page" binding:"omitempty,min=1"`
+	Size         int    `form:"size" binding:"omitempty,min=1,max=100"`
+	TargetType   string `form:"target_type" binding:"omitempty,oneof=forum_post comment"`
+	ReviewStatus string `form:"review_status" binding:"omitempty,oneof=held released confirmed"`
+	MinScore     int    `form:"min_score" binding:"omitempty,min=0,max=100"`
+	UserID       uint   `form:"user_id"`
+}
+
+// SpamReviewRequest 处理被拦截内容请求
+type SpamReviewRequest struct {
+	Action string `json:"action" binding:"required,oneof=release confirm" example:"release"` // release 放行，confirm 确认为垃圾内容
+}
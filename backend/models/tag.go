@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Tag 文章标签，由作者发布文章时自由填写，不存在时自动创建
+type Tag struct {
+	ID            uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name          string    `json:"name" gorm:"type:varchar(30);uniqueIndex;not null;comment:标签名称"`
+	Slug          string    `json:"slug" gorm:"type:varchar(64);uniqueIndex;not null;comment:标签别名"`
+	ArticleCount  int64     `json:"article_count" gorm:"type:bigint;default:0;index;comment:已发布文章数"`
+	FollowerCount int64     `json:"follower_count" gorm:"type:bigint;default:0;comment:关注人数"`
+	CreatedAt     time.Time `json:"created_at" gorm:"comment:创建时间"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"comment:更新时间"`
+}
+
+func (Tag) TableName() string { return "tags" }
+
+// ArticleTag 文章与标签的关联
+type ArticleTag struct {
+	ArticleID uint      `json:"article_id" gorm:"primaryKey;autoIncrement:false"`
+	TagID     uint      `json:"tag_id" gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (ArticleTag) TableName() string { return "article_tags" }
+
+// TagFollow 用户关注的标签
+type TagFollow struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_tag_follows_user_tag,priority:1;comment:用户ID"`
+	TagID     uint      `json:"tag_id" gorm:"not null;uniqueIndex:idx_tag_follows_user_tag,priority:2;index;comment:标签ID"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (TagFollow) TableName() string { return "tag_follows" }
+
+// TagResponse 标签响应
+type TagResponse struct {
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	Slug          string `json:"slug"`
+	ArticleCount  int64  `json:"article_count"`
+	FollowerCount int64  `json:"follower_count"`
+	IsFollowing   bool   `json:"is_following"`
+}
+
+// ToResponse 转换为响应格式
+func (t *Tag) ToResponse() *TagResponse {
+	return &TagResponse{
+		ID:            t.ID,
+		Name:          t.Name,
+		Slug:          t.Slug,
+		ArticleCount:  t.ArticleCount,
+		FollowerCount: t.FollowerCount,
+	}
+}
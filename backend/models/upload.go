@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,6 +18,9 @@ type Upload struct {
 	FileHash    string         `json:"file_hash" gorm:"type:varchar(64);index;not null;index:idx_user_file_hash;comment:文件哈希值"`
 	StoragePath string         `json:"storage_path" gorm:"type:varchar(500);not null;comment:存储路径"`
 	PublicURL   string         `json:"public_url" gorm:"type:varchar(500);not null;comment:公开访问URL"`
+	Width       int            `json:"width" gorm:"not null;default:0;comment:图片宽度"`
+	Height      int            `json:"height" gorm:"not null;default:0;comment:图片高度"`
+	Variants    *string        `json:"-" gorm:"type:text;comment:图片衍生尺寸(JSON)"`
 	UserID      uint           `json:"user_id" gorm:"not null;index;index:idx_user_file_hash,unique;comment:上传用户ID"`
 	Usage       string         `json:"usage" gorm:"type:varchar(50);comment:用途(avatar,article,comment等)"`
 	Status      int8           `json:"status" gorm:"type:tinyint;default:1;comment:状态 0-已删除 1-正常"`
@@ -28,6 +32,41 @@ type Upload struct {
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
 
+// ImageVariant 图片衍生尺寸（缩略图、中图、封面等）
+type ImageVariant struct {
+	URL         string `json:"url"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	StoragePath string `json:"storage_path"`
+}
+
+// GetVariants 解析衍生尺寸
+func (u *Upload) GetVariants() map[string]ImageVariant {
+	if u.Variants == nil || *u.Variants == "" {
+		return nil
+	}
+	var variants map[string]ImageVariant
+	if err := json.Unmarshal([]byte(*u.Variants), &variants); err != nil {
+		return nil
+	}
+	return variants
+}
+
+// SetVariants 保存衍生尺寸
+func (u *Upload) SetVariants(variants map[string]ImageVariant) error {
+	if len(variants) == 0 {
+		u.Variants = nil
+		return nil
+	}
+	data, err := json.Marshal(variants)
+	if err != nil {
+		return err
+	}
+	value := string(data)
+	u.Variants = &value
+	return nil
+}
+
 // UploadRequest 文件上传请求
 type UploadRequest struct {
 	Usage string `form:"usage" binding:"required" example:"avatar"` // avatar, article, comment
@@ -46,21 +85,24 @@ type UploadListRequest struct {
 
 // UploadResponse 文件上传响应
 type UploadResponse struct {
-	ID          uint          `json:"id"`
-	FileName    string        `json:"file_name"`
-	SystemName  string        `json:"system_name"`
-	FileSize    int64         `json:"file_size"`
-	FileType    string        `json:"file_type"`
-	MimeType    string        `json:"mime_type"`
-	FileHash    string        `json:"file_hash"`
-	StoragePath string        `json:"storage_path"`
-	PublicURL   string        `json:"public_url"`
-	UserID      uint          `json:"user_id"`
-	Usage       string        `json:"usage"`
-	Status      int8          `json:"status"`
-	CreatedAt   time.Time     `json:"created_at"`
-	UpdatedAt   time.Time     `json:"updated_at"`
-	User        *UserResponse `json:"user,omitempty"`
+	ID          uint                    `json:"id"`
+	FileName    string                  `json:"file_name"`
+	SystemName  string                  `json:"system_name"`
+	FileSize    int64                   `json:"file_size"`
+	FileType    string                  `json:"file_type"`
+	MimeType    string                  `json:"mime_type"`
+	FileHash    string                  `json:"file_hash"`
+	StoragePath string                  `json:"storage_path"`
+	PublicURL   string                  `json:"public_url"`
+	Width       int                     `json:"width"`
+	Height      int                     `json:"height"`
+	Variants    map[string]ImageVariant `json:"variants,omitempty"`
+	UserID      uint                    `json:"user_id"`
+	Usage       string                  `json:"usage"`
+	Status      int8                    `json:"status"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+	User        *UserResponse           `json:"user,omitempty"`
 }
 
 // ToResponse 转换为响应格式
@@ -75,6 +117,9 @@ func (u *Upload) ToResponse() *UploadResponse {
 		FileHash:    u.FileHash,
 		StoragePath: u.StoragePath,
 		PublicURL:   u.PublicURL,
+		Width:       u.Width,
+		Height:      u.Height,
+		Variants:    u.GetVariants(),
 		UserID:      u.UserID,
 		Usage:       u.Usage,
 		Status:      u.Status,
@@ -111,4 +156,4 @@ func GetFileTypeFromMime(mimeType string) string {
 	default:
 		return "other"
 	}
-}
\ No newline at end of file
+}
@@ -20,6 +20,8 @@ type User struct {
 	Bio       string         `json:"bio" gorm:"type:text;comment:个人简介"`
 	Status    int8           `json:"status" gorm:"type:tinyint;default:1;comment:状态 0-禁用 1-正常"`
 	Role      int8           `json:"role" gorm:"type:tinyint;default:1;comment:角色 1-普通用户 2-内容管理员 3-系统管理员"`
+	MutedUntil  *time.Time   `json:"muted_until,omitempty" gorm:"comment:禁言到期时间"`
+	BannedUntil *time.Time   `json:"banned_until,omitempty" gorm:"comment:封禁到期时间(为空且禁用表示永久)"`
 	CreatedAt time.Time      `json:"created_at" gorm:"comment:创建时间"`
 	UpdatedAt time.Time      `json:"updated_at" gorm:"comment:更新时间"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index;comment:删除时间"`
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// UserBlock 用户屏蔽关系：被屏蔽用户的内容不会出现在屏蔽者的信息流与推荐中
+type UserBlock struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_blocks_pair,priority:1;comment:屏蔽者ID"`
+	BlockedID uint      `json:"blocked_id" gorm:"not null;uniqueIndex:idx_user_blocks_pair,priority:2;index;comment:被屏蔽用户ID"`
+	CreatedAt time.Time `json:"created_at" gorm:"comment:屏蔽时间"`
+
+	Blocked User `json:"blocked,omitempty" gorm:"foreignKey:BlockedID"`
+}
+
+func (UserBlock) TableName() string { return "user_blocks" }
+
+// UserBlockResponse 屏蔽列表项
+type UserBlockResponse struct {
+	User      *UserResponse `json:"user"`
+	BlockedAt time.Time     `json:"blocked_at"`
+}
+
+// ToResponse 转换为响应格式
+func (b *UserBlock) ToResponse() *UserBlockResponse {
+	return &UserBlockResponse{
+		User:      b.Blocked.ToResponse(),
+		BlockedAt: b.CreatedAt,
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// UserSession 用户登录会话（每台设备一条）
+// access/refresh 令牌都携带会话ID(sid)，令牌ID(jti)记录在会话上，用于吊销与刷新令牌轮换
+type UserSession struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID         uint       `json:"user_id" gorm:"not null;index;comment:用户ID"`
+	SessionID      string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null;comment:会话标识(sid)"`
+	AccessJTI      string     `json:"-" gorm:"type:varchar(64);index;comment:当前访问令牌ID"`
+	RefreshJTI     string     `json:"-" gorm:"type:varchar(64);index;comment:当前刷新令牌ID"`
+	PrevRefreshJTI string     `json:"-" gorm:"type:varchar(64);comment:上一个刷新令牌ID"`
+	UserAgent      string     `json:"user_agent" gorm:"type:varchar(255);comment:客户端UA"`
+	IP             string     `json:"ip" gorm:"type:varchar(64);comment:登录IP"`
+	LastUsedAt     time.Time  `json:"last_used_at" gorm:"comment:最近刷新时间"`
+	RotatedAt      *time.Time `json:"-" gorm:"comment:最近一次刷新令牌轮换时间"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"index;comment:会话过期时间"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty" gorm:"index;comment:吊销时间"`
+	RevokeReason   string     `json:"revoke_reason,omitempty" gorm:"type:varchar(50);comment:吊销原因"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	PrevAccessJTI string `json:"-" gorm:"-"` // 轮换前的访问令牌ID，仅在轮换后用于加入吊销列表
+}
+
+func (UserSession) TableName() string { return "user_sessions" }
+
+// IsActive 会话是否仍然有效
+func (s *UserSession) IsActive() bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(time.Now())
+}
+
+// UserSessionResponse 登录会话响应
+type UserSessionResponse struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	Current    bool      `json:"current"` // 是否为当前请求所用会话
+}
+
+// ToResponse 转换为响应结构
+func (s *UserSession) ToResponse(currentSessionID string) UserSessionResponse {
+	return UserSessionResponse{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+		CreatedAt:  s.CreatedAt,
+		Current:    s.SessionID == currentSessionID,
+	}
+}
+
+// 会话吊销原因
+const (
+	SessionRevokeLogout         = "logout"
+	SessionRevokeUser           = "user_revoked"
+	SessionRevokePasswordChange = "password_changed"
+	SessionRevokeBanned         = "banned"
+	SessionRevokeTokenReuse     = "refresh_token_reuse"
+)
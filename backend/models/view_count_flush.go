@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ViewCountFlush 浏览量回写批次记录
+// 每个批次在回写 articles.view_count 的同一事务中写入，用于重启后识别已落库的批次，避免重复计数
+type ViewCountFlush struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	BatchID      string    `json:"batch_id" gorm:"type:varchar(64);uniqueIndex;not null;comment:批次ID"`
+	ArticleCount int       `json:"article_count" gorm:"not null;default:0;comment:涉及文章数"`
+	TotalViews   int64     `json:"total_views" gorm:"type:bigint;not null;default:0;comment:回写浏览量总数"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index;comment:回写时间"`
+}
+
+func (ViewCountFlush) TableName() string { return "view_count_flushes" }
@@ -14,6 +14,14 @@ func SetupAdminRoutes(router *gin.Engine) {
     adminController := controllers.NewAdminController()
     // 通知服务与控制器
     notificationController := controllers.NewAdminNotificationController(services.NewNotificationService(config.GetDB()))
+    // 定时任务
+    jobController := controllers.NewAdminJobController(services.GetJobScheduler())
+    // 评论审核
+    commentController := controllers.NewAdminCommentController(services.NewCommentService())
+    // 敏感词库
+    sensitiveController := controllers.NewAdminSensitiveWordController(services.NewSensitiveWordService(config.GetDB()))
+    // 垃圾内容检测
+    spamController := controllers.NewAdminSpamController(services.NewSpamService(config.GetDB()))
 
 	// 管理员路由组
 	admin := router.Group("/api/admin")
@@ -36,5 +44,34 @@ func SetupAdminRoutes(router *gin.Engine) {
         // 系统通知
         admin.POST("/notifications/system/broadcast", notificationController.BroadcastSystemNotification)
         admin.GET("/notifications/system/history", notificationController.ListSystemNotifications)
+
+        // 评论审核
+        admin.GET("/comments/pending", commentController.ListPending)
+        admin.POST("/comments/pending/approve", commentController.Approve)
+        admin.POST("/comments/pending/reject", commentController.Reject)
+
+        // 敏感词库
+        admin.GET("/sensitive-words/categories", sensitiveController.ListCategories)
+        admin.POST("/sensitive-words/categories", sensitiveController.CreateCategory)
+        admin.PUT("/sensitive-words/categories/:id", sensitiveController.UpdateCategory)
+        admin.DELETE("/sensitive-words/categories/:id", sensitiveController.DeleteCategory)
+        admin.GET("/sensitive-words", sensitiveController.ListWords)
+        admin.POST("/sensitive-words", sensitiveController.CreateWord)
+        admin.POST("/sensitive-words/import", sensitiveController.ImportWords)
+        admin.POST("/sensitive-words/check", sensitiveController.Check)
+        admin.POST("/sensitive-words/reload", sensitiveController.Reload)
+        admin.PUT("/sensitive-words/:id", sensitiveController.UpdateWord)
+        admin.DELETE("/sensitive-words/:id", sensitiveController.DeleteWord)
+
+        // 垃圾内容检测
+        admin.GET("/spam/checks", spamController.ListChecks)
+        admin.GET("/spam/checks/:id", spamController.GetCheck)
+        admin.POST("/spam/checks/:id/review", spamController.Review)
+
+        // 定时任务
+        admin.GET("/jobs", jobController.ListJobs)
+        admin.GET("/jobs/:name", jobController.GetJob)
+        admin.GET("/jobs/:name/runs", jobController.ListRuns)
+        admin.POST("/jobs/:name/run", jobController.RunJob)
     }
 }
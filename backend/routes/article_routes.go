@@ -25,6 +25,8 @@ func SetupArticleRoutes(router *gin.Engine) {
 		articlePublic.GET("/hot", articleController.GetHotArticles)
 		// 获取文章详情
 		articlePublic.GET("/:id", articleController.GetArticle)
+		// 获取相似文章
+		articlePublic.GET("/:id/related", articleController.GetRelatedArticles)
 	}
 
 	// 需要认证的文章路由
@@ -41,6 +43,18 @@ func SetupArticleRoutes(router *gin.Engine) {
 		articleAuth.POST("/:id/like", articleController.LikeArticle)
 		// 获取当前用户的文章列表
 		articleAuth.GET("/my", articleController.GetMyArticles)
+		// 自动保存草稿（新文章 / 已有文章）
+		articleAuth.GET("/autosave", articleController.GetArticleDraft)
+		articleAuth.PUT("/autosave", articleController.AutosaveArticle)
+		articleAuth.DELETE("/autosave", articleController.DiscardArticleDraft)
+		articleAuth.GET("/:id/autosave", articleController.GetArticleDraft)
+		articleAuth.PUT("/:id/autosave", articleController.AutosaveArticle)
+		articleAuth.DELETE("/:id/autosave", articleController.DiscardArticleDraft)
+		// 文章历史版本（作者或管理员）
+		articleAuth.GET("/:id/revisions", articleController.GetArticleRevisions)
+		articleAuth.GET("/:id/revisions/diff", articleController.DiffArticleRevisions)
+		articleAuth.GET("/:id/revisions/:revisionId", articleController.GetArticleRevision)
+		articleAuth.POST("/:id/revisions/:revisionId/restore", articleController.RestoreArticleRevision)
 	}
 
 	// 公开的分类路由（无需认证）
@@ -26,5 +26,8 @@ func SetupChatRoutes(router *gin.Engine, chatController *controllers.ChatControl
 
 		// 检查消息限制
 		chatGroup.POST("/check-limit", chatController.CheckMessageLimit)
+
+		// WebSocket 实时通道（新消息、已读回执、正在输入）
+		chatGroup.GET("/ws", chatController.WebSocket)
 	}
 }
\ No newline at end of file
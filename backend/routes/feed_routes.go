@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"godad-backend/config"
+	"godad-backend/controllers"
+	"godad-backend/middleware"
+	"godad-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupFeedRoutes 设置信息流路由
+func SetupFeedRoutes(router *gin.Engine) {
+	feedController := controllers.NewFeedController(services.NewFeedService(config.GetDB()))
+
+	router.GET("/api/feed", middleware.AuthMiddleware(), feedController.GetFeed) // 个性化信息流
+}
@@ -19,9 +19,9 @@ func SetupForumRoutes(router *gin.Engine) {
 	forumPublic := v1.Group("/forum")
 	{
 		// 获取帖子列表
-		forumPublic.GET("/posts", forumController.GetPostList)
+		forumPublic.GET("/posts", middleware.OptionalAuthMiddleware(), forumController.GetPostList)
 		// 获取帖子详情
-		forumPublic.GET("/posts/:id", forumController.GetPost)
+		forumPublic.GET("/posts/:id", middleware.OptionalAuthMiddleware(), forumController.GetPost)
 		// 获取帖子回复列表
 		forumPublic.GET("/posts/:id/replies", forumController.GetPostReplies)
 		// 获取话题列表
@@ -44,6 +44,10 @@ func SetupForumRoutes(router *gin.Engine) {
 		forumAuth.POST("/posts/:id/like", forumController.LikePost)
 		// 增加浏览量
 		forumAuth.POST("/posts/:id/view", forumController.IncrementPostView)
+		// 问答帖采纳答案
+		forumAuth.POST("/posts/:id/accept", forumController.AcceptAnswer)
+		// 参与帖子投票
+		forumAuth.POST("/posts/:id/poll/vote", forumController.VotePoll)
 
 		// 回复相关
 		forumAuth.POST("/replies", forumController.CreateReply)
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"godad-backend/config"
+	"godad-backend/controllers"
+	"godad-backend/middleware"
+	"godad-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRecommendationRoutes 设置个性化推荐与用户屏蔽路由
+func SetupRecommendationRoutes(router *gin.Engine) {
+	db := config.GetDB()
+	recommendationController := controllers.NewRecommendationController(services.NewRecommendationService(db))
+	blockController := controllers.NewUserBlockController(services.NewUserBlockService(db))
+
+	recommendations := router.Group("/api/recommendations")
+	recommendations.Use(middleware.AuthMiddleware())
+	{
+		recommendations.GET("", recommendationController.GetRecommendations)                 // 个性化推荐
+		recommendations.POST("/:id/dismiss", recommendationController.DismissRecommendation) // 不感兴趣
+	}
+
+	blocks := router.Group("/api/blocks")
+	blocks.Use(middleware.AuthMiddleware())
+	{
+		blocks.GET("", blockController.GetBlockedUsers)    // 屏蔽列表
+		blocks.POST("/:id", blockController.BlockUser)     // 屏蔽用户
+		blocks.DELETE("/:id", blockController.UnblockUser) // 取消屏蔽
+	}
+}
@@ -61,6 +61,14 @@ func SetupRoutes() *gin.Engine {
 				"points":       "/api/points",
 				"forum":        "/api/forum",
 				"resource":     "/api/resources",
+				"search":       "/api/search",
+				"tag":          "/api/tags",
+				"feed":         "/api/feed",
+				"trending":     "/api/trending",
+				"recommend":    "/api/recommendations",
+				"block":        "/api/blocks",
+				"series":       "/api/series",
+				"feeds":        "/api/feeds",
 			},
 		})
 	})
@@ -117,5 +125,26 @@ func SetupRoutes() *gin.Engine {
 	// 提及(@) 路由
 	SetupMentionRoutes(router)
 
+	// 搜索路由
+	SetupSearchRoutes(router)
+
+	// 标签路由
+	SetupTagRoutes(router)
+
+	// 信息流路由
+	SetupFeedRoutes(router)
+
+	// 热度排行路由
+	SetupTrendingRoutes(router)
+
+	// 个性化推荐与用户屏蔽路由
+	SetupRecommendationRoutes(router)
+
+	// 文章系列路由
+	SetupSeriesRoutes(router)
+
+	// 订阅源与站点地图路由
+	SetupSyndicationRoutes(router)
+
 	return router
 }
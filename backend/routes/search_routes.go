@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"godad-backend/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSearchRoutes 设置搜索路由
+func SetupSearchRoutes(router *gin.Engine) {
+	searchController := controllers.NewSearchController()
+
+	searchGroup := router.Group("/api/search")
+	{
+		searchGroup.GET("", searchController.Search)                  // 全文搜索
+		searchGroup.GET("/suggestions", searchController.Suggestions) // 搜索建议
+		searchGroup.GET("/hot", searchController.HotKeywords)         // 热门搜索词
+	}
+}
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"godad-backend/config"
+	"godad-backend/controllers"
+	"godad-backend/middleware"
+	"godad-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSeriesRoutes 设置文章系列路由
+func SetupSeriesRoutes(router *gin.Engine) {
+	seriesController := controllers.NewSeriesController(services.NewSeriesService(config.GetDB()))
+
+	seriesGroup := router.Group("/api/series")
+	{
+		seriesGroup.GET("", seriesController.ListSeries)                                         // 系列列表
+		seriesGroup.GET("/:id", middleware.OptionalAuthMiddleware(), seriesController.GetSeries) // 系列页
+	}
+
+	seriesAuth := router.Group("/api/series", middleware.AuthMiddleware())
+	{
+		seriesAuth.GET("/following", seriesController.GetFollowedSeries)                    // 我关注的系列
+		seriesAuth.POST("", seriesController.CreateSeries)                                  // 创建系列
+		seriesAuth.PUT("/:id", seriesController.UpdateSeries)                               // 更新系列
+		seriesAuth.DELETE("/:id", seriesController.DeleteSeries)                            // 删除系列
+		seriesAuth.POST("/:id/articles", seriesController.AddSeriesArticle)                 // 添加文章
+		seriesAuth.PUT("/:id/articles/order", seriesController.ReorderSeriesArticles)       // 调整顺序
+		seriesAuth.DELETE("/:id/articles/:articleId", seriesController.RemoveSeriesArticle) // 移除文章
+		seriesAuth.POST("/:id/follow", seriesController.FollowSeries)                       // 关注系列
+		seriesAuth.DELETE("/:id/follow", seriesController.UnfollowSeries)                   // 取消关注
+	}
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"godad-backend/config"
+	"godad-backend/controllers"
+	"godad-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSyndicationRoutes 设置订阅源与站点地图路由
+// 站点地图挂在根路径下，需由前端站点反向代理，使其与收录的页面同域
+func SetupSyndicationRoutes(router *gin.Engine) {
+	syndicationController := controllers.NewSyndicationController(services.NewSyndicationService(config.GetDB()))
+
+	feeds := router.Group("/api/feeds")
+	{
+		feeds.GET("/:format", syndicationController.GetSiteFeed)                      // 全站订阅源 atom/rss
+		feeds.GET("/categories/:slug/:format", syndicationController.GetCategoryFeed) // 分类订阅源
+		feeds.GET("/authors/:id/:format", syndicationController.GetAuthorFeed)        // 作者订阅源
+		feeds.GET("/topics/:topic/:format", syndicationController.GetTopicFeed)       // 论坛话题订阅源
+	}
+
+	router.GET("/sitemap.xml", syndicationController.GetSitemapIndex)         // 站点地图索引
+	router.GET("/sitemaps/:type/:page", syndicationController.GetSitemapPage) // 站点地图分页，如 /sitemaps/articles/1.xml
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"godad-backend/config"
+	"godad-backend/controllers"
+	"godad-backend/middleware"
+	"godad-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTagRoutes 设置标签路由
+func SetupTagRoutes(router *gin.Engine) {
+	tagController := controllers.NewTagController(services.NewTagService(config.GetDB()))
+
+	tagGroup := router.Group("/api/tags")
+	{
+		tagGroup.GET("", tagController.ListTags)                                          // 标签列表（按文章数）
+		tagGroup.GET("/suggest", tagController.SuggestTags)                               // 标签自动补全
+		tagGroup.GET("/:slug", middleware.OptionalAuthMiddleware(), tagController.GetTag) // 标签页
+	}
+
+	tagAuth := router.Group("/api/tags", middleware.AuthMiddleware())
+	{
+		tagAuth.GET("/following", tagController.GetFollowedTags)                 // 我关注的标签
+		tagAuth.GET("/following/articles", tagController.GetFollowedTagArticles) // 关注标签的文章
+		tagAuth.POST("/:slug/follow", tagController.FollowTag)                   // 关注标签
+		tagAuth.DELETE("/:slug/follow", tagController.UnfollowTag)               // 取消关注标签
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"godad-backend/config"
+	"godad-backend/controllers"
+	"godad-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTrendingRoutes 设置热度排行路由
+func SetupTrendingRoutes(router *gin.Engine) {
+	trendingController := controllers.NewTrendingController(services.NewTrendingService(config.GetDB()))
+
+	trending := router.Group("/api/trending")
+	{
+		trending.GET("/articles", trendingController.GetTrendingArticles)      // 热门文章
+		trending.GET("/forum-posts", trendingController.GetTrendingForumPosts) // 热门帖子
+	}
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"godad-backend/controllers"
 	"godad-backend/middleware"
+	"godad-backend/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,6 +29,17 @@ func SetupUploadRoutes(router *gin.Engine) {
 			upload.GET("/my", uploadController.GetMyUploads)
 			// 获取上传文件详情
 			upload.GET("/:id", uploadController.GetUpload)
+			// 获取上传文件的临时访问地址
+			upload.GET("/:id/signed-url", uploadController.GetSignedURL)
+		}
+	}
+
+	// 本地磁盘存储：由服务自身提供文件访问（开发环境）
+	if storage, err := services.GetStorage(); err == nil {
+		if local, ok := storage.(*services.LocalStorage); ok {
+			storageController := controllers.NewLocalStorageController(local)
+			router.GET(local.URLPrefix()+"/*filepath", storageController.ServeFile)
+			router.HEAD(local.URLPrefix()+"/*filepath", storageController.ServeFile)
 		}
 	}
 }
\ No newline at end of file
@@ -30,6 +30,9 @@ func SetupUserRoutes(router *gin.Engine) {
 			authGroup.GET("/profile", userController.GetProfile)           // 获取当前用户信息
 			authGroup.PUT("/profile", userController.UpdateProfile)        // 更新当前用户信息
 			authGroup.POST("/change-password", userController.ChangePassword) // 修改密码
+			authGroup.GET("/sessions", userController.ListSessions)             // 登录设备列表
+			authGroup.DELETE("/sessions/:id", userController.RevokeSession)     // 下线指定设备
+			authGroup.DELETE("/sessions", userController.RevokeOtherSessions)   // 下线其他设备
 		}
 
 		// 管理员路由
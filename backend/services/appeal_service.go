@@ -9,6 +9,7 @@ import (
     "godad-backend/config"
     "godad-backend/models"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 type AppealService struct { db *gorm.DB }
@@ -64,18 +65,9 @@ func (s *AppealService) CreateAppeal(appellantID uint, req *CreateAppealRequest)
     }
 
     // 校验申诉人必须为被举报内容作者
-    targetType := strings.ToLower(report.TargetType)
-    if targetType == "article" {
-        var a models.Article
-        if err := s.db.Select("id, author_id").Where("id = ?", report.TargetID).First(&a).Error; err != nil { return nil, errors.New("文章不存在") }
-        if a.AuthorID != appellantID { return nil, errors.New("无权对该内容发起申诉") }
-    } else if targetType == "forum_post" {
-        var p models.ForumPost
-        if err := s.db.Select("id, author_id").Where("id = ?", report.TargetID).First(&p).Error; err != nil { return nil, errors.New("帖子不存在") }
-        if p.AuthorID != appellantID { return nil, errors.New("无权对该内容发起申诉") }
-    } else {
-        return nil, errors.New("不支持的举报类型")
-    }
+    _, authorID, err := NewModerationService(s.db).TargetInfo(strings.ToLower(report.TargetType), report.TargetID)
+    if err != nil { return nil, err }
+    if authorID != appellantID { return nil, errors.New("无权对该内容发起申诉") }
 
     // 检查是否已申诉
     var exists models.Appeal
@@ -128,20 +120,39 @@ func (s *AppealService) ListAppeals(p *AppealListParams) ([]models.Appeal, int64
     return items, total, nil
 }
 
+// UpdateStatus 处理申诉
+// 申诉通过(reviewed)时在同一事务内撤销关联举报的处理动作并记录撤销；驳回则保持现状
 func (s *AppealService) UpdateStatus(id uint, adminID uint, status string, note string) (*models.Appeal, error) {
     if status != "reviewed" && status != "rejected" { return nil, errors.New("无效状态") }
+    ms := NewModerationService(s.db)
     var a models.Appeal
-    if err := s.db.Where("id = ?", id).First(&a).Error; err != nil { return nil, err }
-    if a.Status != "pending" { return nil, errors.New("申诉已处理") }
-    if err := s.db.Model(&a).Updates(map[string]interface{}{
-        "status": status,
-        "handled_by": adminID,
-        "handled_note": strings.TrimSpace(note),
-    }).Error; err != nil { return nil, err }
+    var reverted *models.ModerationLog
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&a).Error; err != nil { return err }
+        if a.Status != "pending" { return errors.New("申诉已处理") }
+        if err := tx.Model(&a).Updates(map[string]interface{}{
+            "status": status,
+            "handled_by": adminID,
+            "handled_note": strings.TrimSpace(note),
+        }).Error; err != nil { return err }
+        if status == "reviewed" {
+            var err error
+            if reverted, err = ms.Revert(tx, a.ReportID, a.ID, adminID, note); err != nil { return err }
+        }
+        return nil
+    })
+    if err != nil { return nil, err }
+    if reverted != nil {
+        ms.AfterRevert(reverted)
+    }
+
     // 通知申诉人
     ns := NewNotificationService(s.db)
     result := map[string]string{"reviewed":"已通过","rejected":"已驳回"}[status]
     msg := fmt.Sprintf("您的申诉%s", result)
+    if reverted != nil && reverted.Action != models.ModerationActionWarning {
+        msg = msg + "，原处理（" + reverted.Action.Label() + "）已撤销"
+    }
     if note = strings.TrimSpace(note); note != "" { msg = msg + "。备注：" + note }
     _ = ns.CreateNotification(&models.Notification{
         ReceiverID: a.AppellantID,
@@ -155,4 +166,3 @@ func (s *AppealService) UpdateStatus(id uint, adminID uint, status string, note
     })
     return &a, nil
 }
-
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AutosaveArticleDraft 自动保存草稿，articleID 为 0 表示尚未创建的新文章
+// 草稿单独存放，不校验必填字段、不修改文章本身，也不生成历史版本
+func (s *ArticleService) AutosaveArticleDraft(userID, articleID uint, req *models.ArticleAutosaveRequest) (*models.ArticleDraft, error) {
+	if articleID > 0 {
+		if err := s.checkAutosavePermission(articleID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	draft := &models.ArticleDraft{
+		UserID:        userID,
+		ArticleID:     articleID,
+		Title:         req.Title,
+		Summary:       req.Summary,
+		Content:       req.Content,
+		ContentFormat: req.ContentFormat,
+		CoverImage:    req.CoverImage,
+		CategoryID:    req.CategoryID,
+		SavedAt:       time.Now(),
+	}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "article_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"title", "summary", "content", "content_format", "cover_image", "category_id", "saved_at"}),
+	}).Create(draft).Error
+	if err != nil {
+		return nil, fmt.Errorf("自动保存失败: %v", err)
+	}
+	return draft, nil
+}
+
+// GetArticleDraft 获取当前用户的自动保存草稿
+func (s *ArticleService) GetArticleDraft(userID, articleID uint) (*models.ArticleDraft, error) {
+	var draft models.ArticleDraft
+	if err := s.db.Where("user_id = ? AND article_id = ?", userID, articleID).First(&draft).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("草稿不存在")
+		}
+		return nil, fmt.Errorf("查询草稿失败: %v", err)
+	}
+	return &draft, nil
+}
+
+// DiscardArticleDraft 丢弃当前用户的自动保存草稿
+func (s *ArticleService) DiscardArticleDraft(userID, articleID uint) error {
+	if err := s.db.Where("user_id = ? AND article_id = ?", userID, articleID).Delete(&models.ArticleDraft{}).Error; err != nil {
+		return fmt.Errorf("删除草稿失败: %v", err)
+	}
+	return nil
+}
+
+// checkAutosavePermission 轻量的编辑权限检查（作者或管理员），自动保存调用频繁，不加载完整文章
+func (s *ArticleService) checkAutosavePermission(articleID, userID uint) error {
+	var article models.Article
+	if err := s.db.Select("id, author_id").First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("文章不存在")
+		}
+		return fmt.Errorf("查询文章失败: %v", err)
+	}
+	if article.AuthorID == userID {
+		return nil
+	}
+
+	var user models.User
+	if err := s.db.Select("id, role").First(&user, userID).Error; err != nil || user.Role < 2 {
+		return errors.New("无权限编辑此文章")
+	}
+	return nil
+}
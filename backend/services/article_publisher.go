@@ -0,0 +1,91 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"godad-backend/models"
+)
+
+// scheduledPublishBatch 每次最多发布的定时文章数量
+const scheduledPublishBatch = 100
+
+// registerPublishingJobs 注册内容发布相关的定时任务
+func registerPublishingJobs(s *JobScheduler) {
+	articleService := NewArticleService()
+	job := ScheduledJob{
+		Name:        "scheduled_article_publish",
+		Description: "发布已到定时发布时间的文章",
+		Spec:        "* * * * *",
+		Timeout:     time.Minute,
+		Run: func() (string, error) {
+			count, err := articleService.PublishScheduledArticles()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("发布了 %d 篇定时文章", count), nil
+		},
+	}
+	if err := s.Register(job); err != nil {
+		log.Printf("注册定时任务 %s 失败: %v", job.Name, err)
+	}
+}
+
+// PublishScheduledArticles 将到期的定时文章转为已发布，并触发与直接发布相同的后续处理
+func (s *ArticleService) PublishScheduledArticles() (int, error) {
+	now := time.Now()
+	var due []models.Article
+	if err := s.db.Select("id, author_id, publish_at, published_at").
+		Where("status = ? AND publish_at <= ?", models.ArticleStatusScheduled, now).
+		Order("publish_at ASC").
+		Limit(scheduledPublishBatch).
+		Find(&due).Error; err != nil {
+		return 0, fmt.Errorf("查询定时文章失败: %v", err)
+	}
+
+	published := 0
+	for _, article := range due {
+		updates := map[string]interface{}{
+			"status":     models.ArticleStatusPublished,
+			"publish_at": nil,
+		}
+		firstPublish := article.PublishedAt == nil
+		if firstPublish {
+			updates["published_at"] = *article.PublishAt
+		}
+
+		// 带上状态与时间条件，作者在此期间改回草稿或推迟发布时不会被覆盖
+		result := s.db.Model(&models.Article{}).
+			Where("id = ? AND status = ? AND publish_at <= ?", article.ID, models.ArticleStatusScheduled, now).
+			Updates(updates)
+		if result.Error != nil {
+			log.Printf("发布定时文章失败 (id: %d): %v", article.ID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		published++
+
+		s.cacheService.Delete(fmt.Sprintf("article:%d", article.ID))
+		var updated models.Article
+		if err := s.db.First(&updated, article.ID).Error; err == nil {
+			s.indexArticle(&updated)
+		}
+		s.refreshRelatedArticles(article.ID)
+		if err := NewTagService(s.db).RefreshArticleTagCounts(article.ID); err != nil {
+			log.Printf("刷新标签文章数失败 (article: %d): %v", article.ID, err)
+		}
+		if firstPublish {
+			s.onArticlePublished(article.ID, article.AuthorID)
+		} else if err := NewSeriesService(s.db).RefreshArticleSeriesCounts(article.ID); err != nil {
+			log.Printf("刷新系列文章数失败 (article: %d): %v", article.ID, err)
+		}
+	}
+
+	if published > 0 {
+		s.cacheService.DeletePattern("articles:*")
+	}
+	return published, nil
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ArticleRevisionDiff 两个版本之间的差异
+type ArticleRevisionDiff struct {
+	From           *models.ArticleRevisionResponse `json:"from"`
+	To             *models.ArticleRevisionResponse `json:"to"`
+	TitleChanged   bool                            `json:"title_changed"`
+	SummaryChanged bool                            `json:"summary_changed"`
+	Content        *LineDiff                       `json:"content"`
+}
+
+// ensureBaselineRevision 锁定文章行，并为尚无版本记录的文章（版本功能上线前创建）补录当前内容作为初始版本
+// 必须在修改文章内容之前调用，保证第一次编辑前的内容也能恢复
+func ensureBaselineRevision(tx *gorm.DB, articleID uint) error {
+	var article models.Article
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, title, summary, content, content_format, author_id, updated_at").
+		First(&article, articleID).Error; err != nil {
+		return fmt.Errorf("查询文章失败: %v", err)
+	}
+
+	var count int64
+	if err := tx.Model(&models.ArticleRevision{}).Where("article_id = ?", articleID).Count(&count).Error; err != nil {
+		return fmt.Errorf("查询文章版本失败: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	baseline := &models.ArticleRevision{
+		ArticleID:     articleID,
+		Version:       1,
+		Title:         article.Title,
+		Summary:       article.Summary,
+		Content:       article.Content,
+		ContentFormat: article.ContentFormat,
+		EditorID:      article.AuthorID,
+		Action:        models.ArticleRevisionActionCreate,
+		CreatedAt:     article.UpdatedAt,
+	}
+	if err := tx.Create(baseline).Error; err != nil {
+		return fmt.Errorf("保存文章版本失败: %v", err)
+	}
+	return nil
+}
+
+// snapshotArticleRevision 为文章当前内容保存一个新版本；与最新版本内容一致时不重复保存
+func snapshotArticleRevision(tx *gorm.DB, articleID, editorID uint, action string, restoredFrom *int) error {
+	var article models.Article
+	if err := tx.Select("id, title, summary, content, content_format").First(&article, articleID).Error; err != nil {
+		return fmt.Errorf("查询文章失败: %v", err)
+	}
+
+	var latest models.ArticleRevision
+	err := tx.Where("article_id = ?", articleID).Order("version DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("查询文章版本失败: %v", err)
+	}
+	if err == nil && latest.Title == article.Title && latest.Summary == article.Summary && latest.Content == article.Content &&
+		latest.ContentFormat == article.ContentFormat {
+		return nil
+	}
+
+	revision := &models.ArticleRevision{
+		ArticleID:     articleID,
+		Version:       latest.Version + 1,
+		Title:         article.Title,
+		Summary:       article.Summary,
+		Content:       article.Content,
+		ContentFormat: article.ContentFormat,
+		EditorID:      editorID,
+		Action:        action,
+		RestoredFrom:  restoredFrom,
+	}
+	if err := tx.Create(revision).Error; err != nil {
+		return fmt.Errorf("保存文章版本失败: %v", err)
+	}
+	return nil
+}
+
+// GetArticleRevisions 获取文章的版本列表（仅作者或管理员），按版本号倒序
+func (s *ArticleService) GetArticleRevisions(articleID, userID uint, page, size int) ([]*models.ArticleRevision, int64, error) {
+	if _, err := s.getEditableArticle(articleID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	query := s.db.Model(&models.ArticleRevision{}).Where("article_id = ?", articleID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询文章版本失败: %v", err)
+	}
+
+	var revisions []*models.ArticleRevision
+	if err := query.Omit("content").Preload("Editor").
+		Order("version DESC").
+		Offset((page - 1) * size).Limit(size).
+		Find(&revisions).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询文章版本失败: %v", err)
+	}
+	return revisions, total, nil
+}
+
+// GetArticleRevision 获取文章的指定版本（含正文）
+func (s *ArticleService) GetArticleRevision(articleID, revisionID, userID uint) (*models.ArticleRevision, error) {
+	if _, err := s.getEditableArticle(articleID, userID); err != nil {
+		return nil, err
+	}
+	return s.findArticleRevision(articleID, revisionID)
+}
+
+// DiffArticleRevisions 比较文章的两个版本，toID 为 0 时与最新版本比较
+func (s *ArticleService) DiffArticleRevisions(articleID, fromID, toID, userID uint) (*ArticleRevisionDiff, error) {
+	if _, err := s.getEditableArticle(articleID, userID); err != nil {
+		return nil, err
+	}
+
+	from, err := s.findArticleRevision(articleID, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	var to *models.ArticleRevision
+	if toID == 0 {
+		var latest models.ArticleRevision
+		if err := s.db.Preload("Editor").Where("article_id = ?", articleID).Order("version DESC").First(&latest).Error; err != nil {
+			return nil, fmt.Errorf("查询文章版本失败: %v", err)
+		}
+		to = &latest
+	} else if to, err = s.findArticleRevision(articleID, toID); err != nil {
+		return nil, err
+	}
+
+	return &ArticleRevisionDiff{
+		From:           from.ToResponse(),
+		To:             to.ToResponse(),
+		TitleChanged:   from.Title != to.Title,
+		SummaryChanged: from.Summary != to.Summary,
+		Content:        DiffLines(from.Content, to.Content),
+	}, nil
+}
+
+// RestoreArticleRevision 将文章恢复到指定版本
+// 通过 updateArticle 执行，权限校验、缓存清理与搜索索引同步与普通编辑一致，恢复本身也会生成新版本
+func (s *ArticleService) RestoreArticleRevision(articleID, revisionID, userID uint) (*models.Article, error) {
+	revision, err := s.GetArticleRevision(articleID, revisionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &models.ArticleUpdateRequest{
+		Title:         revision.Title,
+		Summary:       revision.Summary,
+		Content:       revision.Content,
+		ContentFormat: revision.ContentFormat,
+	}
+	version := revision.Version
+	return s.updateArticle(articleID, userID, req, models.ArticleRevisionActionRestore, &version)
+}
+
+// findArticleRevision 查询属于指定文章的版本
+func (s *ArticleService) findArticleRevision(articleID, revisionID uint) (*models.ArticleRevision, error) {
+	var revision models.ArticleRevision
+	if err := s.db.Preload("Editor").Where("id = ? AND article_id = ?", revisionID, articleID).First(&revision).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("版本不存在")
+		}
+		return nil, fmt.Errorf("查询文章版本失败: %v", err)
+	}
+	return &revision, nil
+}
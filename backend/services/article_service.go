@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"html"
+	"log"
 	"regexp"
 	"strings"
 	"time"
@@ -49,6 +50,11 @@ func (s *ArticleService) CreateArticle(userID uint, req *models.ArticleCreateReq
 		return nil, err
 	}
 
+	// 禁言期间不能发布内容
+	if err := NewModerationService(s.db).EnsureCanPost(userID); err != nil {
+		return nil, err
+	}
+
 	// 验证分类是否存在
 	if req.CategoryID > 0 {
 		var category models.Category
@@ -60,6 +66,22 @@ func (s *ArticleService) CreateArticle(userID uint, req *models.ArticleCreateReq
 		}
 	}
 
+	// 敏感词检测：block 拒绝提交，mask 直接打码，review 发布后进入举报审核队列
+	screen, err := GetSensitiveFilter().Screen(&req.Title, &req.Summary, &req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// 渲染并过滤正文
+	format := req.ContentFormat
+	if format == "" {
+		format = models.ContentFormatHTML
+	}
+	rendered, err := renderArticleContent(req.Content, format)
+	if err != nil {
+		return nil, err
+	}
+
 	// 生成唯一的slug
 	slug, err := s.generateUniqueSlug(req.Slug, req.Title)
 	if err != nil {
@@ -72,6 +94,10 @@ func (s *ArticleService) CreateArticle(userID uint, req *models.ArticleCreateReq
 		Slug:        slug,
 		Summary:     req.Summary,
 		Content:     req.Content,
+		ContentFormat: format,
+		ContentHTML: rendered.HTML,
+		ContentImages: rendered.Images,
+		TOC:         rendered.TOC,
 		CoverImage:  req.CoverImage,
 		AuthorID:    userID,
 		CategoryID:  req.CategoryID,
@@ -79,57 +105,86 @@ func (s *ArticleService) CreateArticle(userID uint, req *models.ArticleCreateReq
 		IsRecommend: req.IsRecommend,
 		Status:      req.Status,
 	}
+	switch article.Status {
+	case models.ArticleStatusPublished:
+		now := time.Now()
+		article.PublishedAt = &now
+	case models.ArticleStatusScheduled:
+		article.PublishAt = req.PublishAt
+	}
 
-	// 如果没有提供摘要，自动生成
+	// 如果没有提供摘要，自动生成；没有封面时使用正文第一张图片
 	if article.Summary == "" {
-		article.Summary = s.generateSummary(article.Content)
+		article.Summary = s.generateSummary(article.ContentHTML)
+	}
+	if article.CoverImage == "" && len(rendered.Images) > 0 {
+		article.CoverImage = rendered.Images[0]
 	}
 
-	// 保存到数据库
-	if err := s.db.Create(article).Error; err != nil {
-		return nil, fmt.Errorf("创建文章失败: %v", err)
+	// 保存到数据库，同时记录初始版本并清除新文章的自动保存草稿
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(article).Error; err != nil {
+			return fmt.Errorf("创建文章失败: %v", err)
+		}
+		if len(req.Tags) > 0 {
+			if err := NewTagService(s.db).SetArticleTags(tx, article.ID, req.Tags); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("user_id = ? AND article_id = ?", userID, 0).Delete(&models.ArticleDraft{}).Error; err != nil {
+			return fmt.Errorf("清除自动保存草稿失败: %v", err)
+		}
+		return snapshotArticleRevision(tx, article.ID, userID, models.ArticleRevisionActionCreate, nil)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// 预加载关联数据
-	if err := s.db.Preload("Author").Preload("Category").First(article, article.ID).Error; err != nil {
+	if err := s.db.Preload("Author").Preload("Category").Preload("Tags").First(article, article.ID).Error; err != nil {
 		return nil, fmt.Errorf("加载文章数据失败: %v", err)
 	}
 
 	// 清理相关缓存
 	s.cacheService.DeletePattern("articles:*")
-	s.cacheService.DeletePattern("search:*")
+	s.indexArticle(article)
+	fileSensitiveReport(s.db, models.ReportTargetArticle, article.ID, screen)
 
-	// 奖励发布文章积分
-	go func() {
-		err := s.pointsService.AwardPoints(userID, "publish_article", "article", article.ID, "发布文章")
-		if err != nil {
-			fmt.Printf("发布文章积分奖励失败: %v\n", err)
-		}
-	}()
+	// 直接发布时触发发布后的处理（草稿和定时发布的文章在真正发布时处理）
+	if article.Status == models.ArticleStatusPublished {
+		go s.onArticlePublished(article.ID, userID)
+		s.refreshRelatedArticles(article.ID)
+	}
 
 	return article, nil
 }
 
+// onArticlePublished 文章首次发布后的处理：奖励发布积分、通知粉丝与系列关注者、刷新粉丝的信息流
+func (s *ArticleService) onArticlePublished(articleID, authorID uint) {
+	if err := s.pointsService.AwardPoints(authorID, "publish_article", "article", articleID, "发布文章"); err != nil {
+		fmt.Printf("发布文章积分奖励失败: %v\n", err)
+	}
+	if err := NewNotificationService(s.db).NotifyFollowersNewArticle(authorID, articleID); err != nil {
+		log.Printf("通知粉丝新文章失败 (article: %d): %v", articleID, err)
+	}
+	NewFeedService(s.db).InvalidateFollowerFeeds(authorID)
+	NewSeriesService(s.db).OnArticlePublished(articleID)
+}
+
 // UpdateArticle 更新文章
+// 标题、摘要或正文发生变化时会保存一份历史版本
 func (s *ArticleService) UpdateArticle(articleID, userID uint, req *models.ArticleUpdateRequest) (*models.Article, error) {
-	// 获取文章
-	article, err := s.GetArticleByID(articleID, userID, true)
-	if err != nil {
-		return nil, err
-	}
+	return s.updateArticle(articleID, userID, req, models.ArticleRevisionActionUpdate, nil)
+}
 
-	// 检查权限（作者或管理员可以编辑）
-	userService := NewUserService()
-	user, err := userService.GetUserByID(userID)
+// updateArticle 更新文章并记录版本，action/restoredFrom 标记版本来源
+func (s *ArticleService) updateArticle(articleID, userID uint, req *models.ArticleUpdateRequest, action string, restoredFrom *int) (*models.Article, error) {
+	// 获取文章并检查权限（作者或管理员可以编辑）
+	article, err := s.getEditableArticle(articleID, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 只有作者或管理员可以编辑
-	if article.AuthorID != userID && user.Role != 2 {
-		return nil, errors.New("无权限编辑此文章")
-	}
-
 	// 验证输入
 	if err := s.validateUpdateRequest(req); err != nil {
 		return nil, err
@@ -146,16 +201,36 @@ func (s *ArticleService) UpdateArticle(articleID, userID uint, req *models.Artic
 		}
 	}
 
+	// 敏感词检测（恢复历史版本同样需要检测）
+	screen, err := GetSensitiveFilter().Screen(&req.Title, &req.Summary, &req.Content)
+	if err != nil {
+		return nil, err
+	}
+
 	// 更新字段
 	updateData := make(map[string]interface{})
 	if req.Title != "" {
 		updateData["title"] = req.Title
 	}
-	if req.Content != "" {
-		updateData["content"] = req.Content
+	if req.Content != "" || req.ContentFormat != "" {
+		// 只修改格式时按新格式重新渲染原文
+		content, format := req.Content, req.ContentFormat
+		if content == "" {
+			content = article.Content
+		}
+		if format == "" {
+			format = article.ContentFormat
+		}
+		rendered, err := renderArticleContent(content, format)
+		if err != nil {
+			return nil, err
+		}
+		for column, value := range articleContentColumns(content, format, rendered) {
+			updateData[column] = value
+		}
 		updateData["summary"] = req.Summary
 		if req.Summary == "" {
-			updateData["summary"] = s.generateSummary(req.Content)
+			updateData["summary"] = s.generateSummary(rendered.HTML)
 		}
 	}
 	if req.CoverImage != "" {
@@ -167,20 +242,135 @@ func (s *ArticleService) UpdateArticle(articleID, userID uint, req *models.Artic
 	if req.Status != nil {
 		updateData["status"] = *req.Status
 	}
-	updateData["is_top"] = req.IsTop
+	if req.IsTop != nil {
+		updateData["is_top"] = *req.IsTop
+	}
 
-	// 执行更新
-	if err := s.db.Model(article).Updates(updateData).Error; err != nil {
-		return nil, fmt.Errorf("更新文章失败: %v", err)
+	// 定时发布：进入或保持定时状态时需要有效的发布时间，离开定时状态时清除
+	status := article.Status
+	if req.Status != nil {
+		status = *req.Status
+	}
+	if status == models.ArticleStatusScheduled {
+		publishAt := req.PublishAt
+		if publishAt == nil {
+			publishAt = article.PublishAt
+		}
+		if publishAt == nil || !publishAt.After(time.Now()) {
+			return nil, errors.New("定时发布时间必须晚于当前时间")
+		}
+		updateData["publish_at"] = *publishAt
+	} else if article.PublishAt != nil {
+		updateData["publish_at"] = nil
+	}
+
+	// 执行更新，并在同一事务内保存版本快照、清除自动保存草稿
+	// Updates 会把新值写回 article，需在更新前比较
+	clearDrafts := draftFieldsChanged(article, updateData)
+	firstPublish := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureBaselineRevision(tx, articleID); err != nil {
+			return err
+		}
+		if len(updateData) > 0 {
+			if err := tx.Model(article).Updates(updateData).Error; err != nil {
+				return fmt.Errorf("更新文章失败: %v", err)
+			}
+		}
+		// 首次发布时记录发布时间，以 published_at 是否为空判断，避免下架后重新发布重复奖励
+		if status == models.ArticleStatusPublished {
+			result := tx.Model(&models.Article{}).
+				Where("id = ? AND published_at IS NULL", articleID).
+				UpdateColumn("published_at", time.Now())
+			if result.Error != nil {
+				return fmt.Errorf("更新发布时间失败: %v", result.Error)
+			}
+			firstPublish = result.RowsAffected > 0
+		}
+		// 标签变化或状态变化都会影响标签的已发布文章数
+		if req.Tags != nil {
+			if err := NewTagService(s.db).SetArticleTags(tx, articleID, req.Tags); err != nil {
+				return err
+			}
+		} else if req.Status != nil {
+			if err := NewTagService(tx).RefreshArticleTagCounts(articleID); err != nil {
+				return err
+			}
+		}
+		// 只有正文相关字段实际变化时才清除当前编辑者自己的草稿，其他编辑者的草稿保留
+		if clearDrafts {
+			if err := tx.Where("article_id = ? AND user_id = ?", articleID, userID).Delete(&models.ArticleDraft{}).Error; err != nil {
+				return fmt.Errorf("清除自动保存草稿失败: %v", err)
+			}
+		}
+		return snapshotArticleRevision(tx, articleID, userID, action, restoredFrom)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if firstPublish {
+		go s.onArticlePublished(articleID, article.AuthorID)
+	} else if req.Status != nil {
+		if err := NewSeriesService(s.db).RefreshArticleSeriesCounts(articleID); err != nil {
+			log.Printf("刷新系列文章数失败 (article: %d): %v", articleID, err)
+		}
 	}
 
 	// 清理相关缓存
 	s.cacheService.Delete(fmt.Sprintf("article:%d", articleID))
 	s.cacheService.DeletePattern("articles:*")
-	s.cacheService.DeletePattern("search:*")
 
-	// 重新加载文章数据
-	return s.GetArticleByID(articleID, userID, true)
+	// 重新加载文章数据（编辑者已通过权限校验，直接读取，不计入浏览量）
+	var updated models.Article
+	if err := s.db.Preload("Author").Preload("Category").Preload("Tags").First(&updated, articleID).Error; err != nil {
+		return nil, fmt.Errorf("加载更新后的文章失败: %v", err)
+	}
+	s.indexArticle(&updated)
+	s.refreshRelatedArticles(articleID)
+	fileSensitiveReport(s.db, models.ReportTargetArticle, articleID, screen)
+	return &updated, nil
+}
+
+// draftFieldsChanged 更新内容是否改动了自动保存草稿所覆盖的字段
+func draftFieldsChanged(article *models.Article, updateData map[string]interface{}) bool {
+	current := map[string]interface{}{
+		"title":          article.Title,
+		"summary":        article.Summary,
+		"content":        article.Content,
+		"content_format": article.ContentFormat,
+		"cover_image":    article.CoverImage,
+		"category_id":    article.CategoryID,
+	}
+	for column, value := range current {
+		if updated, ok := updateData[column]; ok && updated != value {
+			return true
+		}
+	}
+	return false
+}
+
+// getEditableArticle 获取文章并校验当前用户是否可编辑（作者或管理员），不计入浏览量
+func (s *ArticleService) getEditableArticle(articleID, userID uint) (*models.Article, error) {
+	var article models.Article
+	if err := s.db.First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("文章不存在")
+		}
+		return nil, fmt.Errorf("查询文章失败: %v", err)
+	}
+	if article.AuthorID == userID {
+		return &article, nil
+	}
+
+	// 只有作者或管理员可以编辑
+	user, err := NewUserService().GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role < 2 {
+		return nil, errors.New("无权限编辑此文章")
+	}
+	return &article, nil
 }
 
 // DeleteArticle 删除文章（软删除）
@@ -212,28 +402,56 @@ func (s *ArticleService) DeleteArticle(articleID, userID uint) error {
 	// 清理相关缓存
 	s.cacheService.Delete(fmt.Sprintf("article:%d", articleID))
 	s.cacheService.DeletePattern("articles:*")
-	s.cacheService.DeletePattern("search:*")
+	removeSearchDocument(SearchTypeArticle, articleID)
+	s.refreshRelatedArticles(articleID)
+	if err := NewTagService(s.db).RefreshArticleTagCounts(articleID); err != nil {
+		log.Printf("刷新标签文章数失败 (article: %d): %v", articleID, err)
+	}
+	if err := NewSeriesService(s.db).RefreshArticleSeriesCounts(articleID); err != nil {
+		log.Printf("刷新系列文章数失败 (article: %d): %v", articleID, err)
+	}
 
 	return nil
 }
 
+// refreshRelatedArticles 延迟刷新文章的相似文章（未发布或已删除的文章会被移出推荐），连续保存只计算一次
+func (s *ArticleService) refreshRelatedArticles(articleID uint) {
+	NewRelatedArticleService(s.db).ScheduleRefresh(articleID)
+}
+
+// indexArticle 同步文章到搜索索引（仅已发布文章可被检索）
+func (s *ArticleService) indexArticle(article *models.Article) {
+	createdAt := article.CreatedAt
+	if article.PublishedAt != nil {
+		createdAt = *article.PublishedAt
+	}
+	indexSearchDocument(&SearchDocument{
+		Type:       SearchTypeArticle,
+		ID:         article.ID,
+		Title:      article.Title,
+		Content:    article.Content,
+		CategoryID: article.CategoryID,
+		Visible:    article.Status == 1,
+		CreatedAt:  createdAt,
+	})
+}
+
 // GetArticleByID 根据ID获取文章
 func (s *ArticleService) GetArticleByID(articleID, userID uint, checkOwner bool) (*models.Article, error) {
 	// 尝试从缓存获取（仅对非作者查看的情况）
 	if !checkOwner {
 		if cachedArticle, err := s.cacheService.GetArticle(articleID); err == nil {
-			// 增加浏览量缓存
-			s.cacheService.IncreaseViewCount(articleID)
-			// 更新数据库浏览量（异步）
-			go func() {
-				s.db.Model(&models.Article{}).Where("id = ?", articleID).UpdateColumn("view_count", gorm.Expr("view_count + ?", 1))
-			}()
+			s.recordView(articleID)
+			// 叠加尚未回写数据库的浏览量增量
+			if pending, err := s.cacheService.GetViewCount(articleID); err == nil {
+				cachedArticle.ViewCount += pending
+			}
 			return cachedArticle, nil
 		}
 	}
 
     var article models.Article
-    query := s.db.Preload("Author").Preload("Category")
+    query := s.db.Preload("Author").Preload("Category").Preload("Tags")
 
     // 访问控制：
     // - 未登录：只能看已发布
@@ -264,17 +482,33 @@ func (s *ArticleService) GetArticleByID(articleID, userID uint, checkOwner bool)
 
 	// 如果不是作者查看，增加浏览量
 	if !checkOwner || article.AuthorID != userID {
-		s.db.Model(&article).UpdateColumn("view_count", gorm.Expr("view_count + ?", 1))
-		article.ViewCount++
-		// 缓存文章（仅对已发布的文章）
+		// 缓存文章（仅对已发布的文章），缓存中保存数据库中的浏览量，增量在读取时叠加
 		if article.Status == 1 {
 			s.cacheService.SetArticle(articleID, &article, 30*time.Minute)
 		}
+		s.recordView(articleID)
+		if pending, err := s.cacheService.GetViewCount(articleID); err == nil {
+			article.ViewCount += pending
+		} else {
+			article.ViewCount++
+		}
 	}
 
 	return &article, nil
 }
 
+// recordView 记录一次文章浏览
+// Redis 可用时只累加缓存中的增量，由 ViewCountFlusher 定期批量回写；否则直接更新数据库
+func (s *ArticleService) recordView(articleID uint) {
+	NewTrendingService(s.db).RecordEvent(TrendingArticle, articleID, TrendingEventView)
+	if IsCacheEnabled() {
+		if err := s.cacheService.IncreaseViewCount(articleID); err == nil {
+			return
+		}
+	}
+	s.db.Model(&models.Article{}).Where("id = ?", articleID).UpdateColumn("view_count", gorm.Expr("view_count + ?", 1))
+}
+
 // GetArticleList 获取文章列表
 func (s *ArticleService) GetArticleList(req *models.ArticleListRequest) ([]*models.Article, int64, error) {
 	// 生成缓存key
@@ -315,7 +549,7 @@ func (s *ArticleService) GetArticleList(req *models.ArticleListRequest) ([]*mode
 	var articles []*models.Article
 	var total int64
 
-	query := s.db.Model(&models.Article{}).Preload("Author").Preload("Category")
+	query := s.db.Model(&models.Article{}).Preload("Author").Preload("Category").Preload("Tags")
 
 	// 状态过滤
 	if req.Status != 0 {
@@ -389,7 +623,7 @@ func (s *ArticleService) GetArticleListAdmin(req *models.ArticleListRequest) ([]
     var articles []*models.Article
     var total int64
 
-    query := s.db.Model(&models.Article{}).Preload("Author").Preload("Category")
+    query := s.db.Model(&models.Article{}).Preload("Author").Preload("Category").Preload("Tags")
 
     // 状态过滤：仅当提供了合法状态(0/1/2)时才过滤；否则不过滤
     if req.Status == 0 || req.Status == 1 || req.Status == 2 {
@@ -434,7 +668,7 @@ func (s *ArticleService) GetUserArticles(userID uint, page, size int, status str
 	var articles []*models.Article
 	var total int64
 
-	query := s.db.Model(&models.Article{}).Preload("Category").Where("author_id = ?", userID)
+	query := s.db.Model(&models.Article{}).Preload("Category").Preload("Tags").Where("author_id = ?", userID)
 
 	// 状态过滤
 	if status != "" {
@@ -444,12 +678,16 @@ func (s *ArticleService) GetUserArticles(userID uint, page, size int, status str
 			statusCode = 0
 		case "published":
 			statusCode = 1
+		case "scheduled":
+			statusCode = models.ArticleStatusScheduled
 		default:
 			// 如果是数字字符串，尝试解析
 			if status == "0" {
 				statusCode = 0
 			} else if status == "1" {
 				statusCode = 1
+			} else if status == "3" {
+				statusCode = models.ArticleStatusScheduled
 			} else {
 				return nil, 0, fmt.Errorf("无效的状态参数: %s", status)
 			}
@@ -508,8 +746,11 @@ func (s *ArticleService) validateCreateRequest(req *models.ArticleCreateRequest)
 	if req.Status == 0 {
 		req.Status = 0 // 默认为草稿
 	}
-	if req.Status < 0 || req.Status > 2 {
-		return errors.New("文章状态只能是0(草稿)、1(已发布)或2(已下架)")
+	if req.Status < 0 || req.Status > 3 {
+		return errors.New("文章状态只能是0(草稿)、1(已发布)、2(已下架)或3(定时发布)")
+	}
+	if req.Status == models.ArticleStatusScheduled && (req.PublishAt == nil || !req.PublishAt.After(time.Now())) {
+		return errors.New("定时发布时间必须晚于当前时间")
 	}
 	return nil
 }
@@ -522,8 +763,8 @@ func (s *ArticleService) validateUpdateRequest(req *models.ArticleUpdateRequest)
 	if req.Content != "" && len(req.Content) > 100000 {
 		return errors.New("文章内容不能超过100000个字符")
 	}
-	if req.Status != nil && (*req.Status < 0 || *req.Status > 2) {
-		return errors.New("文章状态只能是0(草稿)、1(已发布)或2(已下架)")
+	if req.Status != nil && (*req.Status < 0 || *req.Status > 3) {
+		return errors.New("文章状态只能是0(草稿)、1(已发布)、2(已下架)或3(定时发布)")
 	}
 	return nil
 }
@@ -622,7 +863,7 @@ func (s *ArticleService) GetHotArticles(period string, limit int) ([]*models.Art
 	var articles []*models.Article
 
 	// 构建基本查询
-	query := s.db.Preload("Author").Preload("Category").
+	query := s.db.Preload("Author").Preload("Category").Preload("Tags").
 		Where("status = ? AND deleted_at IS NULL", 1)
 
 	// 根据时间周期添加条件
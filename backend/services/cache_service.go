@@ -275,28 +275,33 @@ func (c *CacheService) GetSearchResults(keyword string) ([]models.Article, error
 	return articles, nil
 }
 
+// 文章浏览量增量统一记录在一个哈希中（field 为文章ID），由 ViewCountFlusher 定期回写数据库
+const (
+	viewCountPendingKey        = "article:views:pending"
+	viewCountFlushingKeyPrefix = "article:views:flushing:"
+)
+
+// IncreaseViewCount 记录一次文章浏览（尚未回写数据库的增量）
 func (c *CacheService) IncreaseViewCount(articleID uint) error {
 	if !isCacheReady() {
 		return ErrCacheDisabled
 	}
 
-	key := fmt.Sprintf("article:views:%d", articleID)
-	err := rdb.Incr(ctx, key).Err()
+	err := rdb.HIncrBy(ctx, viewCountPendingKey, strconv.FormatUint(uint64(articleID), 10), 1).Err()
 	if err != nil {
 		return fmt.Errorf("增加浏览次数失败: %v", err)
 	}
 
-	rdb.Expire(ctx, key, 24*time.Hour)
 	return nil
 }
 
+// GetViewCount 获取文章尚未回写数据库的浏览量增量
 func (c *CacheService) GetViewCount(articleID uint) (int64, error) {
 	if !isCacheReady() {
 		return 0, ErrCacheDisabled
 	}
 
-	key := fmt.Sprintf("article:views:%d", articleID)
-	count, err := rdb.Get(ctx, key).Result()
+	count, err := rdb.HGet(ctx, viewCountPendingKey, strconv.FormatUint(uint64(articleID), 10)).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return 0, nil
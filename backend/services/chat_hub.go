@@ -0,0 +1,161 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+)
+
+// chatHubChannel Redis 发布/订阅频道，用于多实例之间扇出聊天事件
+const chatHubChannel = "chat:events"
+
+// chatClientBuffer 每个 WebSocket 连接的发送缓冲大小，写满后丢弃事件
+const chatClientBuffer = 64
+
+// 聊天实时事件类型
+const (
+	ChatEventMessage = "message" // 新消息
+	ChatEventRead    = "read"    // 已读回执
+	ChatEventTyping  = "typing"  // 正在输入
+)
+
+// ChatEvent 推送给 WebSocket 客户端的聊天事件
+type ChatEvent struct {
+	Type           string      `json:"type"`
+	ConversationID uint        `json:"conversation_id"`
+	UserID         uint        `json:"user_id"` // 事件发起者
+	Data           interface{} `json:"data,omitempty"`
+}
+
+// chatEnvelope 带接收者列表的事件，经 Redis 转发
+type chatEnvelope struct {
+	Recipients []uint          `json:"recipients"`
+	Event      json.RawMessage `json:"event"`
+}
+
+// ChatClient 单个 WebSocket 连接
+type ChatClient struct {
+	userID uint
+	Send   chan []byte
+}
+
+// ChatHub 聊天实时事件中心
+// Redis 可用时事件经 Redis 发布/订阅转发到所有实例，否则仅在本进程内分发
+type ChatHub struct {
+	mu        sync.RWMutex
+	clients   map[uint]map[*ChatClient]struct{}
+	redisOnce sync.Once
+}
+
+var (
+	chatHub     *ChatHub
+	chatHubOnce sync.Once
+)
+
+// GetChatHub 获取全局聊天事件中心
+func GetChatHub() *ChatHub {
+	chatHubOnce.Do(func() {
+		chatHub = &ChatHub{
+			clients: make(map[uint]map[*ChatClient]struct{}),
+		}
+	})
+	return chatHub
+}
+
+// Register 注册用户的 WebSocket 连接
+func (h *ChatHub) Register(userID uint) *ChatClient {
+	h.ensureRedisListener()
+
+	client := &ChatClient{
+		userID: userID,
+		Send:   make(chan []byte, chatClientBuffer),
+	}
+
+	h.mu.Lock()
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[*ChatClient]struct{})
+	}
+	h.clients[userID][client] = struct{}{}
+	h.mu.Unlock()
+
+	return client
+}
+
+// Unregister 注销连接
+func (h *ChatHub) Unregister(client *ChatClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if clients, ok := h.clients[client.userID]; ok {
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(h.clients, client.userID)
+		}
+	}
+}
+
+// Publish 向指定用户的所有连接推送事件
+func (h *ChatHub) Publish(event ChatEvent, recipients ...uint) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("序列化聊天事件失败: %v", err)
+		return
+	}
+
+	if isCacheReady() {
+		h.ensureRedisListener()
+		envelope, err := json.Marshal(chatEnvelope{Recipients: recipients, Event: payload})
+		if err == nil {
+			if err = rdb.Publish(ctx, chatHubChannel, envelope).Err(); err == nil {
+				return
+			}
+		}
+		log.Printf("聊天事件发布到Redis失败，改为本地分发: %v", err)
+	}
+	h.dispatch(payload, recipients)
+}
+
+// dispatch 将事件分发给本进程内的连接
+func (h *ChatHub) dispatch(payload []byte, recipients []uint) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	seen := make(map[uint]struct{}, len(recipients))
+	for _, userID := range recipients {
+		if _, ok := seen[userID]; ok {
+			continue
+		}
+		seen[userID] = struct{}{}
+		for client := range h.clients[userID] {
+			select {
+			case client.Send <- payload:
+			default:
+				// 客户端消费过慢，丢弃事件
+			}
+		}
+	}
+}
+
+// ensureRedisListener 在 Redis 可用时启动一次频道监听
+func (h *ChatHub) ensureRedisListener() {
+	if !isCacheReady() {
+		return
+	}
+	h.redisOnce.Do(func() {
+		pubsub := rdb.Subscribe(ctx, chatHubChannel)
+		// 等待订阅确认，避免首条事件在订阅建立前发布而丢失
+		if _, err := pubsub.Receive(ctx); err != nil {
+			log.Printf("订阅聊天频道失败: %v", err)
+		}
+		go func() {
+			for msg := range pubsub.Channel() {
+				var envelope chatEnvelope
+				if err := json.Unmarshal([]byte(msg.Payload), &envelope); err != nil {
+					log.Printf("解析聊天事件失败: %v", err)
+					continue
+				}
+				h.dispatch(envelope.Event, envelope.Recipients)
+			}
+		}()
+	})
+}
@@ -101,6 +101,20 @@ func (cs *ChatService) GetConversationsByUser(userID uint, page, limit int) ([]*
 
 // 发送消息
 func (cs *ChatService) SendMessage(req SendMessageRequest) (*models.ChatMessage, error) {
+	// 禁言期间不能发送私信
+	if err := NewModerationService(cs.db).EnsureCanPost(req.SenderID); err != nil {
+		return nil, err
+	}
+
+	// 敏感词检测：私信没有人工审核队列，review 类命中与 block 一样拒绝发送
+	screen, err := GetSensitiveFilter().Screen(req.Content)
+	if err != nil {
+		return nil, err
+	}
+	if screen.Review {
+		return nil, ErrSensitiveContent
+	}
+
 	// 检查互相关注关系
 	mutualFollow, err := cs.checkMutualFollow(req.SenderID, req.ReceiverID)
 	if err != nil {
@@ -149,6 +163,7 @@ func (cs *ChatService) SendMessage(req SendMessageRequest) (*models.ChatMessage,
 		for i, img := range req.Images {
 			images[i] = *img
 		}
+		cs.fillImageInfo(req.SenderID, images)
 		err = message.SetImages(images)
 		if err != nil {
 			tx.Rollback()
@@ -185,6 +200,14 @@ func (cs *ChatService) SendMessage(req SendMessageRequest) (*models.ChatMessage,
 	
 	// 加载关联数据
 	cs.db.Preload("Sender").Preload("Receiver").Preload("Emoji").First(message, message.ID)
+
+	// 实时推送给会话双方（发送者的其他设备也需要同步）
+	GetChatHub().Publish(ChatEvent{
+		Type:           ChatEventMessage,
+		ConversationID: conversation.ID,
+		UserID:         req.SenderID,
+		Data:           message,
+	}, req.SenderID, req.ReceiverID)
 	
 	// 创建私信通知
 	if cs.notificationService != nil && message.Content != nil {
@@ -281,7 +304,47 @@ func (cs *ChatService) MarkMessagesAsRead(conversationID uint, userID uint) erro
 		return err
 	}
 	
-	return tx.Commit().Error
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	// 推送已读回执
+	otherUserID := conversation.User1ID
+	if conversation.User1ID == userID {
+		otherUserID = conversation.User2ID
+	}
+	GetChatHub().Publish(ChatEvent{
+		Type:           ChatEventRead,
+		ConversationID: conversationID,
+		UserID:         userID,
+		Data:           map[string]interface{}{"read_at": now},
+	}, userID, otherUserID)
+
+	return nil
+}
+
+// GetConversationPeer 获取会话中对方用户ID，同时校验当前用户是否属于该会话
+func (cs *ChatService) GetConversationPeer(conversationID uint, userID uint) (uint, error) {
+	var conversation models.ChatConversation
+	err := cs.db.Where("id = ? AND (user1_id = ? OR user2_id = ?)", conversationID, userID, userID).
+		First(&conversation).Error
+	if err != nil {
+		return 0, fmt.Errorf("对话不存在或无权限访问")
+	}
+	if conversation.User1ID == userID {
+		return conversation.User2ID, nil
+	}
+	return conversation.User1ID, nil
+}
+
+// SendTypingEvent 推送正在输入状态给对方
+func (cs *ChatService) SendTypingEvent(conversationID, userID, peerID uint, typing bool) {
+	GetChatHub().Publish(ChatEvent{
+		Type:           ChatEventTyping,
+		ConversationID: conversationID,
+		UserID:         userID,
+		Data:           map[string]interface{}{"typing": typing},
+	}, peerID)
 }
 
 // 删除对话（软删除）
@@ -372,6 +435,36 @@ func (cs *ChatService) checkDailyLimit(senderID, receiverID uint) (bool, error)
 	return limit.CanSendMessage(3), nil  // 默认每日限制3条消息
 }
 
+// fillImageInfo 根据发送者的上传记录补全图片尺寸、大小与缩略图，不信任客户端提交的这些字段
+func (cs *ChatService) fillImageInfo(senderID uint, images []models.ImageInfo) {
+	urls := make([]string, 0, len(images))
+	for _, img := range images {
+		urls = append(urls, img.URL)
+	}
+
+	var uploads []models.Upload
+	if err := cs.db.Where("user_id = ? AND public_url IN ?", senderID, urls).Find(&uploads).Error; err != nil {
+		return
+	}
+	byURL := make(map[string]*models.Upload, len(uploads))
+	for i := range uploads {
+		byURL[uploads[i].PublicURL] = &uploads[i]
+	}
+
+	for i := range images {
+		upload, ok := byURL[images[i].URL]
+		if !ok {
+			continue
+		}
+		images[i].Width = upload.Width
+		images[i].Height = upload.Height
+		images[i].Size = int(upload.FileSize)
+		if thumb, ok := upload.GetVariants()[ImageVariantThumbnail]; ok {
+			images[i].Thumbnail = thumb.URL
+		}
+	}
+}
+
 // 辅助函数：更新每日限制计数
 func (cs *ChatService) updateDailyLimit(tx *gorm.DB, senderID, receiverID uint) error {
 	today := models.GetTodayDate()
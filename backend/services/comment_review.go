@@ -0,0 +1,263 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"godad-backend/config"
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sensitiveModerationReason 命中 review 类敏感词时的审核原因
+const sensitiveModerationReason = "内容包含待审核敏感词"
+
+// preModerationReason 按先审后发规则判断评论是否需要进入待审核队列，返回空字符串表示直接发布
+// 管理员与文章作者在自己文章下的评论不受限制
+func (s *CommentService) preModerationReason(userID uint, article *models.Article, content, contentHTML string) (string, error) {
+	cfg := config.GetConfig().Comment
+	if !cfg.ModerationEnabled || userID == article.AuthorID {
+		return "", nil
+	}
+
+	var user models.User
+	if err := s.db.Select("id", "role", "created_at").First(&user, userID).Error; err != nil {
+		return "", err
+	}
+	if user.Role >= 2 {
+		return "", nil
+	}
+
+	if cfg.NewAccountHours > 0 && time.Since(user.CreatedAt) < time.Duration(cfg.NewAccountHours)*time.Hour {
+		return fmt.Sprintf("注册未满%d小时的新账号", cfg.NewAccountHours), nil
+	}
+
+	if cfg.MinLevel > 0 {
+		points, err := NewPointsService(s.db).GetUserPoints(userID)
+		if err != nil {
+			return "", err
+		}
+		if points.CurrentLevel < cfg.MinLevel {
+			return fmt.Sprintf("用户等级低于Lv%d", cfg.MinLevel), nil
+		}
+	}
+
+	lower := strings.ToLower(content)
+	if cfg.HoldLinks && (strings.Contains(contentHTML, "<a ") || strings.Contains(lower, "http://") || strings.Contains(lower, "https://") || strings.Contains(lower, "www.")) {
+		return "内容包含链接", nil
+	}
+
+	for _, word := range cfg.FlaggedWords {
+		if strings.Contains(lower, word) {
+			return "内容包含敏感词", nil
+		}
+	}
+
+	return "", nil
+}
+
+// onCommentPublished 评论公开后的副作用：热度事件、作者/被回复者通知与@提及
+func (s *CommentService) onCommentPublished(comment *models.Comment, article *models.Article, mentions []uint) {
+	NewTrendingService(s.db).RecordEvent(TrendingArticle, comment.ArticleID, TrendingEventComment)
+
+	userID := comment.UserID
+	if comment.ParentID != nil && *comment.ParentID > 0 {
+		// 这是回复评论，需要通知原评论作者
+		var parentComment models.Comment
+		if err := s.db.Where("id = ?", *comment.ParentID).First(&parentComment).Error; err == nil {
+			// 通知原评论作者（如果不是自己）
+			if parentComment.UserID != userID {
+				if err := s.notificationService.CreateCommentReplyNotification(userID, parentComment.UserID, comment.ArticleID, *comment.ParentID, comment.Content); err != nil {
+					log.Printf("发送回复通知失败: %v", err)
+				}
+			}
+		}
+
+		// 如果原评论作者不是文章作者，也要通知文章作者
+		if article.AuthorID != userID && article.AuthorID != parentComment.UserID {
+			if err := s.notificationService.CreateCommentNotification(userID, article.AuthorID, comment.ArticleID, comment.Content); err != nil {
+				log.Printf("发送文章评论通知失败: %v", err)
+			}
+		}
+	} else if article.AuthorID != userID {
+		// 这是顶级评论，通知文章作者
+		if err := s.notificationService.CreateCommentNotification(userID, article.AuthorID, comment.ArticleID, comment.Content); err != nil {
+			log.Printf("发送评论通知失败: %v", err)
+		}
+	}
+
+	// 处理@提及（互相关注限制），去重并过滤自己
+	seen := make(map[uint]struct{})
+	for _, mid := range mentions {
+		if mid == 0 || mid == userID {
+			continue
+		}
+		if _, ok := seen[mid]; ok {
+			continue
+		}
+		seen[mid] = struct{}{}
+		if s.isMutualFollow(userID, mid) {
+			_ = s.notificationService.CreateMentionNotification(userID, mid, comment.ArticleID, comment.ID, comment.Content)
+		}
+	}
+}
+
+// GetPendingComments 待审核评论列表（管理员），按提交时间先后排列，articleID 为 0 时不限文章
+func (s *CommentService) GetPendingComments(articleID uint, page, size int) ([]*models.Comment, int64, error) {
+	query := s.db.Model(&models.Comment{}).Where("status = ?", models.CommentStatusPending)
+	if articleID > 0 {
+		query = query.Where("article_id = ?", articleID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var comments []*models.Comment
+	offset := (page - 1) * size
+	if err := query.Preload("User").Preload("Article").Order("created_at ASC, id ASC").Offset(offset).Limit(size).Find(&comments).Error; err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+// ApproveComments 批量通过待审核评论，计入文章评论数与父评论回复数并补发通知，返回实际通过的数量
+func (s *CommentService) ApproveComments(ids []uint, adminID uint) (int, error) {
+	var approved []models.Comment
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND status = ?", ids, models.CommentStatusPending).
+			Find(&approved).Error; err != nil {
+			return err
+		}
+		if len(approved) == 0 {
+			return nil
+		}
+
+		approvedIDs := make([]uint, len(approved))
+		perArticle := make(map[uint]int64)
+		perParent := make(map[uint]int64)
+		for i, c := range approved {
+			approvedIDs[i] = c.ID
+			perArticle[c.ArticleID]++
+			if c.ParentID != nil && *c.ParentID > 0 {
+				perParent[*c.ParentID]++
+			}
+		}
+
+		if err := tx.Model(&models.Comment{}).Where("id IN ?", approvedIDs).Updates(map[string]interface{}{
+			"status":            models.CommentStatusNormal,
+			"moderation_reason": "",
+			"reviewed_by":       adminID,
+			"reviewed_at":       now,
+			"pending_mentions":  nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		for articleID, n := range perArticle {
+			if err := tx.Model(&models.Article{}).Where("id = ?", articleID).
+				UpdateColumn("comment_count", gorm.Expr("comment_count + ?", n)).Error; err != nil {
+				return err
+			}
+		}
+		for parentID, n := range perParent {
+			if err := tx.Model(&models.Comment{}).Where("id = ?", parentID).
+				UpdateColumn("reply_count", gorm.Expr("reply_count + ?", n)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := NewSpamService(s.db).markReviewed(models.ReportTargetComment, commentIDs(approved), models.SpamReviewReleased, adminID); err != nil {
+		log.Printf("回写垃圾内容审核结果失败: %v", err)
+	}
+
+	articles := make(map[uint]*models.Article)
+	for i := range approved {
+		comment := &approved[i]
+		article, ok := articles[comment.ArticleID]
+		if !ok {
+			article = &models.Article{}
+			if err := s.db.Select("id", "author_id").First(article, comment.ArticleID).Error; err != nil {
+				log.Printf("审核通过评论 %d 时加载文章失败: %v", comment.ID, err)
+				continue
+			}
+			articles[comment.ArticleID] = article
+		}
+		s.onCommentPublished(comment, article, comment.PendingMentions)
+		s.clearCommentCache(comment.ArticleID, comment.ID)
+	}
+	return len(approved), nil
+}
+
+// RejectComments 批量驳回待审核评论，评论转为隐藏状态并通知作者，返回实际驳回的数量
+func (s *CommentService) RejectComments(ids []uint, reason string, adminID uint) (int, error) {
+	reason = strings.TrimSpace(reason)
+	var rejected []models.Comment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND status = ?", ids, models.CommentStatusPending).
+			Find(&rejected).Error; err != nil {
+			return err
+		}
+		if len(rejected) == 0 {
+			return nil
+		}
+
+		rejectedIDs := make([]uint, len(rejected))
+		for i, c := range rejected {
+			rejectedIDs[i] = c.ID
+		}
+		return tx.Model(&models.Comment{}).Where("id IN ?", rejectedIDs).Updates(map[string]interface{}{
+			"status":            models.CommentStatusHidden,
+			"moderation_reason": reason,
+			"reviewed_by":       adminID,
+			"reviewed_at":       time.Now(),
+			"pending_mentions":  nil,
+		}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := NewSpamService(s.db).markReviewed(models.ReportTargetComment, commentIDs(rejected), models.SpamReviewConfirmed, adminID); err != nil {
+		log.Printf("回写垃圾内容审核结果失败: %v", err)
+	}
+
+	for _, comment := range rejected {
+		msg := fmt.Sprintf("您的评论“%s”未通过审核", truncateString(comment.Content, 60))
+		if reason != "" {
+			msg += "，原因：" + reason
+		}
+		if err := s.notificationService.CreateNotification(&models.Notification{
+			ReceiverID: comment.UserID,
+			ActorID:    adminID,
+			Type:       models.NotificationTypeModeration,
+			Title:      "评论审核未通过",
+			ResourceID: comment.ID,
+			Message:    msg,
+			IsRead:     false,
+		}); err != nil {
+			log.Printf("发送评论驳回通知失败 user=%d: %v", comment.UserID, err)
+		}
+	}
+	return len(rejected), nil
+}
+
+// commentIDs 提取评论ID
+func commentIDs(comments []models.Comment) []uint {
+	ids := make([]uint, len(comments))
+	for i, c := range comments {
+		ids[i] = c.ID
+	}
+	return ids
+}
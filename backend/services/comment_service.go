@@ -44,6 +44,11 @@ func (s *CommentService) CreateComment(userID uint, req *models.CommentCreateReq
 		return nil, err
 	}
 
+	// 禁言期间不能发表评论
+	if err := NewModerationService(s.db).EnsureCanPost(userID); err != nil {
+		return nil, err
+	}
+
 	// 验证文章是否存在
 	var article models.Article
 	if err := s.db.Where("id = ? AND status = ?", req.ArticleID, 1).First(&article).Error; err != nil {
@@ -64,16 +69,58 @@ func (s *CommentService) CreateComment(userID uint, req *models.CommentCreateReq
 		}
 	}
 
+	// 敏感词检测：block 拒绝提交，mask 直接打码，review 进入待审核队列
+	screen, err := GetSensitiveFilter().Screen(&req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// 渲染并过滤评论内容，默认按 Markdown 处理
+	format := req.ContentFormat
+	if format == "" {
+		format = models.ContentFormatMarkdown
+	}
+	rendered, err := renderCommentContent(strings.TrimSpace(req.Content), format)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先审后发：命中规则的评论进入待审核队列，审核通过前不计数、不通知
+	reason, err := s.preModerationReason(userID, &article, req.Content, rendered.HTML)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" && screen.Review {
+		reason = sensitiveModerationReason
+	}
+
+	// 垃圾内容评分：低信任账号发布过快直接拒绝，高分评论进入待审核队列
+	spamService := NewSpamService(s.db)
+	spamCheck, err := spamService.Assess(userID, models.ReportTargetComment, req.Content, rendered.HTML)
+	if err != nil {
+		return nil, err
+	}
+	if spamReason := spamHoldReason(spamCheck); reason == "" && spamReason != "" {
+		reason = spamReason
+	}
+
 	// 创建评论
 	comment := &models.Comment{
 		ArticleID: req.ArticleID,
 		UserID:    userID,
 		ParentID:  req.ParentID,
 		Content:   strings.TrimSpace(req.Content),
-		Status:    1, // 默认启用
+		ContentFormat: format,
+		ContentHTML: rendered.HTML,
+		Status:    models.CommentStatusNormal,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+	if reason != "" {
+		comment.Status = models.CommentStatusPending
+		comment.ModerationReason = reason
+		comment.PendingMentions = req.Mentions
+	}
 
 	// 开启事务
 	tx := s.db.Begin()
@@ -89,51 +136,31 @@ func (s *CommentService) CreateComment(userID uint, req *models.CommentCreateReq
 		return nil, err
 	}
 
-	// 更新文章评论数
-	if err := tx.Model(&article).UpdateColumn("comment_count", gorm.Expr("comment_count + ?", 1)).Error; err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
-	// 如果是回复评论，更新父评论的回复数
-	if req.ParentID != nil && *req.ParentID > 0 {
-		if err := tx.Model(&models.Comment{}).Where("id = ?", *req.ParentID).UpdateColumn("reply_count", gorm.Expr("reply_count + ?", 1)).Error; err != nil {
+	if comment.Status == models.CommentStatusNormal {
+		// 更新文章评论数
+		if err := tx.Model(&article).UpdateColumn("comment_count", gorm.Expr("comment_count + ?", 1)).Error; err != nil {
 			tx.Rollback()
 			return nil, err
 		}
+
+		// 如果是回复评论，更新父评论的回复数
+		if req.ParentID != nil && *req.ParentID > 0 {
+			if err := tx.Model(&models.Comment{}).Where("id = ?", *req.ParentID).UpdateColumn("reply_count", gorm.Expr("reply_count + ?", 1)).Error; err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
 	}
 
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
+	spamService.Record(spamCheck, comment.ID)
 
-	// 发送通知
-	if req.ParentID != nil && *req.ParentID > 0 {
-		// 这是回复评论，需要通知原评论作者
-		var parentComment models.Comment
-		if err := s.db.Where("id = ?", *req.ParentID).First(&parentComment).Error; err == nil {
-			// 通知原评论作者（如果不是自己）
-			if parentComment.UserID != userID {
-				if err := s.notificationService.CreateCommentReplyNotification(userID, parentComment.UserID, req.ArticleID, *req.ParentID, req.Content); err != nil {
-					fmt.Printf("发送回复通知失败: %v\n", err)
-				}
-			}
-		}
-		
-		// 如果原评论作者不是文章作者，也要通知文章作者
-		if article.AuthorID != userID && article.AuthorID != parentComment.UserID {
-			if err := s.notificationService.CreateCommentNotification(userID, article.AuthorID, req.ArticleID, req.Content); err != nil {
-				fmt.Printf("发送文章评论通知失败: %v\n", err)
-			}
-		}
-	} else {
-		// 这是顶级评论，通知文章作者
-		if article.AuthorID != userID {
-			if err := s.notificationService.CreateCommentNotification(userID, article.AuthorID, req.ArticleID, req.Content); err != nil {
-				fmt.Printf("发送评论通知失败: %v\n", err)
-			}
-		}
+	// 发送通知（待审核评论在审核通过后补发）
+	if comment.Status == models.CommentStatusNormal {
+		s.onCommentPublished(comment, &article, req.Mentions)
 	}
 
     // 预加载关联数据
@@ -141,20 +168,6 @@ func (s *CommentService) CreateComment(userID uint, req *models.CommentCreateReq
         return nil, err
     }
 
-    // 处理@提及（互相关注限制）
-    if len(req.Mentions) > 0 {
-        // 去重并过滤
-        seen := make(map[uint]struct{})
-        for _, mid := range req.Mentions {
-            if mid == 0 || mid == userID { continue }
-            if _, ok := seen[mid]; ok { continue }
-            seen[mid] = struct{}{}
-            if s.isMutualFollow(userID, mid) {
-                _ = s.notificationService.CreateMentionNotification(userID, mid, req.ArticleID, comment.ID, req.Content)
-            }
-        }
-    }
-
     return comment, nil
 }
 
@@ -190,20 +203,93 @@ func (s *CommentService) UpdateComment(commentID, userID uint, req *models.Comme
 		return nil, errors.New("无权限修改此评论")
 	}
 
-	// 检查评论状态
-	if comment.Status != 1 {
+	// 检查评论状态（待审核的评论允许修改）
+	if comment.Status != models.CommentStatusNormal && comment.Status != models.CommentStatusPending {
 		return nil, errors.New("评论已被删除或禁用")
 	}
 
+	// 敏感词检测
+	screen, err := GetSensitiveFilter().Screen(&req.Content)
+	if err != nil {
+		return nil, err
+	}
+
 	// 更新评论内容
+	format := req.ContentFormat
+	if format == "" {
+		format = comment.ContentFormat
+	}
+	rendered, err := renderCommentContent(strings.TrimSpace(req.Content), format)
+	if err != nil {
+		return nil, err
+	}
 	updateData := map[string]interface{}{
-		"content":    strings.TrimSpace(req.Content),
-		"updated_at": time.Now(),
+		"content":        strings.TrimSpace(req.Content),
+		"content_format": format,
+		"content_html":   rendered.HTML,
+		"updated_at":     time.Now(),
+	}
+
+
+	// 修改后的内容重新经过先审后发规则，已公开的评论命中规则时撤回到待审核队列
+	var article models.Article
+	if err := s.db.Select("id", "author_id").First(&article, comment.ArticleID).Error; err != nil {
+		return nil, err
+	}
+	reason, err := s.preModerationReason(userID, &article, req.Content, rendered.HTML)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" && screen.Review {
+		reason = sensitiveModerationReason
+	}
+
+	// 修改后的内容重新进行垃圾内容评分
+	spamService := NewSpamService(s.db)
+	spamCheck, err := spamService.AssessEdit(userID, models.ReportTargetComment, comment.ID, req.Content, rendered.HTML)
+	if err != nil {
+		return nil, err
+	}
+	if spamReason := spamHoldReason(spamCheck); reason == "" && spamReason != "" {
+		reason = spamReason
+	}
+	if comment.Status == models.CommentStatusPending {
+		if reason == "" {
+			reason = comment.ModerationReason
+		}
+		updateData["moderation_reason"] = reason
+	}
+	withdrawn := comment.Status == models.CommentStatusNormal && reason != ""
+	if withdrawn {
+		updateData["status"] = models.CommentStatusPending
+		updateData["moderation_reason"] = reason
 	}
 
-	if err := s.db.Model(&comment).Updates(updateData).Error; err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&comment).Updates(updateData).Error; err != nil {
+			return err
+		}
+		if !withdrawn {
+			return nil
+		}
+		if err := tx.Model(&models.Article{}).Where("id = ? AND comment_count > 0", comment.ArticleID).
+			UpdateColumn("comment_count", gorm.Expr("comment_count - 1")).Error; err != nil {
+			return err
+		}
+		// 撤回的回复不再计入父评论的回复数
+		if comment.ParentID != nil && *comment.ParentID > 0 {
+			return tx.Model(&models.Comment{}).Where("id = ? AND reply_count > 0", *comment.ParentID).
+				UpdateColumn("reply_count", gorm.Expr("reply_count - 1")).Error
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
+	spamService.Record(spamCheck, comment.ID)
+	if withdrawn {
+		s.clearCommentCache(comment.ArticleID, comment.ID)
+	}
 
 	// 预加载关联数据
 	if err := s.db.Preload("User").Preload("Article").First(&comment, comment.ID).Error; err != nil {
@@ -248,7 +334,9 @@ func (s *CommentService) DeleteComment(commentID, userID uint) error {
 		tx.Rollback()
 		return err
 	}
-	totalDeleteCount += 1 // 加上主评论本身
+	if comment.Status == models.CommentStatusNormal {
+		totalDeleteCount += 1 // 加上主评论本身（待审核或已隐藏的评论未计入评论数）
+	}
 	
 	// 硬删除子评论（递归删除所有回复）
 	if err := s.deleteChildCommentsRecursively(tx, comment.ID); err != nil {
@@ -269,7 +357,7 @@ func (s *CommentService) DeleteComment(commentID, userID uint) error {
 	}
 
 	// 如果是回复评论，更新父评论的回复数
-	if comment.ParentID != nil && *comment.ParentID > 0 {
+	if comment.Status == models.CommentStatusNormal && comment.ParentID != nil && *comment.ParentID > 0 {
 		if err := tx.Model(&models.Comment{}).Where("id = ?", *comment.ParentID).UpdateColumn("reply_count", gorm.Expr("reply_count - ?", 1)).Error; err != nil {
 			tx.Rollback()
 			return err
@@ -606,16 +694,19 @@ func (s *CommentService) validateUpdateRequest(req *models.CommentUpdateRequest)
 	return nil
 }
 
-// countCommentsRecursively 递归统计子评论数量
+// countCommentsRecursively 递归统计已计入评论数（状态正常）的子评论数量
 func (s *CommentService) countCommentsRecursively(tx *gorm.DB, parentID uint) (int64, error) {
 	var childComments []models.Comment
 	if err := tx.Where("parent_id = ?", parentID).Find(&childComments).Error; err != nil {
 		return 0, err
 	}
 	
-	count := int64(len(childComments))
-	
+	var count int64
 	for _, child := range childComments {
+		if child.Status == models.CommentStatusNormal {
+			count++
+		}
+
 		// 递归统计子评论的子评论
 		childCount, err := s.countCommentsRecursively(tx, child.ID)
 		if err != nil {
@@ -0,0 +1,388 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"godad-backend/config"
+	"godad-backend/models"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// contentPolicy 内容白名单策略
+// 不在白名单内的标签会被去掉但保留其文本，dropContentTags 中的标签连同内容一起丢弃
+type contentPolicy struct {
+	elements map[string]bool
+}
+
+func newContentPolicy(tags ...string) *contentPolicy {
+	p := &contentPolicy{elements: make(map[string]bool, len(tags))}
+	for _, tag := range tags {
+		p.elements[tag] = true
+	}
+	return p
+}
+
+var (
+	// articleContentPolicy 文章与论坛帖子：允许标题、图片、表格等完整排版
+	articleContentPolicy = newContentPolicy(
+		"p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6",
+		"strong", "b", "em", "i", "u", "s", "del", "ins", "mark", "sub", "sup", "kbd",
+		"blockquote", "pre", "code", "ul", "ol", "li", "dl", "dt", "dd",
+		"a", "img", "figure", "figcaption", "span", "div",
+		"table", "thead", "tbody", "tfoot", "tr", "th", "td",
+	)
+	// commentContentPolicy 评论与论坛回复：只保留行内格式、链接、列表、引用和代码
+	commentContentPolicy = newContentPolicy(
+		"p", "br", "strong", "b", "em", "i", "u", "s", "del", "code", "pre",
+		"blockquote", "ul", "ol", "li", "a",
+	)
+)
+
+// dropContentTags 连同内容一起丢弃的标签
+var dropContentTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "frame": true, "frameset": true,
+	"object": true, "embed": true, "applet": true, "noscript": true, "template": true,
+	"textarea": true, "select": true, "button": true, "input": true, "form": true,
+	"svg": true, "math": true, "head": true, "title": true, "meta": true, "link": true, "base": true,
+}
+
+// voidTags 无结束标签的元素
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// RenderedContent 正文渲染结果
+type RenderedContent struct {
+	HTML   string                   // 过滤后的 HTML
+	Images []string                 // 正文引用的图片地址，按出现顺序去重
+	TOC    []*models.ContentHeading // 目录
+}
+
+// renderContent 将 Markdown 或 HTML 源文渲染为安全的 HTML
+// 按策略过滤标签和属性，只保留 http/https/相对地址（链接另允许 mailto），
+// 站外链接追加 rel="nofollow noopener noreferrer"，并为标题生成锚点
+func renderContent(source, format string, policy *contentPolicy) (*RenderedContent, error) {
+	if format == "" {
+		format = models.ContentFormatHTML
+	}
+	raw := source
+	switch format {
+	case models.ContentFormatHTML:
+	case models.ContentFormatMarkdown:
+		raw = renderMarkdown(source)
+	default:
+		return nil, errors.New("不支持的内容格式")
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(raw), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return nil, errors.New("内容解析失败")
+	}
+
+	s := &contentSanitizer{
+		policy:     policy,
+		siteHosts:  contentSiteHosts(),
+		imageSeen:  make(map[string]bool),
+		headingIDs: make(map[string]int),
+	}
+	for _, n := range nodes {
+		s.walk(n)
+	}
+
+	result := &RenderedContent{
+		HTML:   strings.TrimSpace(s.out.String()),
+		Images: s.images,
+		TOC:    s.toc,
+	}
+	if result.HTML == "" {
+		return nil, errors.New("内容过滤后为空")
+	}
+	return result, nil
+}
+
+// renderArticleContent 按文章策略渲染正文
+func renderArticleContent(source, format string) (*RenderedContent, error) {
+	return renderContent(source, format, articleContentPolicy)
+}
+
+// renderCommentContent 按评论策略渲染正文
+func renderCommentContent(source, format string) (*RenderedContent, error) {
+	return renderContent(source, format, commentContentPolicy)
+}
+
+// articleContentColumns 文章正文相关列，用于 map 形式的更新（map 更新不会经过 serializer，需自行编码）
+func articleContentColumns(content, format string, rendered *RenderedContent) map[string]interface{} {
+	images, _ := json.Marshal(rendered.Images)
+	toc, _ := json.Marshal(rendered.TOC)
+	return map[string]interface{}{
+		"content":        content,
+		"content_format": format,
+		"content_html":   rendered.HTML,
+		"content_images": string(images),
+		"toc":            string(toc),
+	}
+}
+
+// contentSiteHosts 站内域名，指向这些域名的链接不视为外链
+func contentSiteHosts() map[string]bool {
+	hosts := make(map[string]bool)
+	cfg := config.GetConfig()
+	if cfg == nil {
+		return hosts
+	}
+	for _, raw := range []string{cfg.Server.FrontendURL, cfg.Storage.Local.BaseURL, cfg.Storage.S3.PublicURL} {
+		if u, err := url.Parse(raw); err == nil && u.Hostname() != "" {
+			hosts[strings.ToLower(u.Hostname())] = true
+		}
+	}
+	if cfg.OSS.CustomDomain != "" {
+		hosts[strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(cfg.OSS.CustomDomain, "https://"), "http://"))] = true
+	}
+	return hosts
+}
+
+// contentSanitizer 遍历解析后的节点树并输出过滤后的 HTML
+type contentSanitizer struct {
+	policy     *contentPolicy
+	siteHosts  map[string]bool
+	out        strings.Builder
+	images     []string
+	imageSeen  map[string]bool
+	toc        []*models.ContentHeading
+	headingIDs map[string]int
+}
+
+func (s *contentSanitizer) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		s.out.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		// 注释、doctype 等直接丢弃
+		return
+	}
+
+	tag := strings.ToLower(n.Data)
+	if dropContentTags[tag] {
+		return
+	}
+	if !s.policy.elements[tag] {
+		s.walkChildren(n)
+		return
+	}
+
+	attrs, ok := s.attributes(tag, n)
+	if !ok {
+		// 必需属性不合法（如图片地址不安全），丢弃该元素但保留文本
+		s.walkChildren(n)
+		return
+	}
+
+	s.out.WriteByte('<')
+	s.out.WriteString(tag)
+	for _, attr := range attrs {
+		s.out.WriteByte(' ')
+		s.out.WriteString(attr.Key)
+		s.out.WriteString(`="`)
+		s.out.WriteString(html.EscapeString(attr.Val))
+		s.out.WriteByte('"')
+	}
+	s.out.WriteByte('>')
+	if voidTags[tag] {
+		return
+	}
+	s.walkChildren(n)
+	s.out.WriteString("</")
+	s.out.WriteString(tag)
+	s.out.WriteByte('>')
+}
+
+func (s *contentSanitizer) walkChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		s.walk(c)
+	}
+}
+
+// attributes 返回元素允许保留的属性，第二个返回值为 false 表示元素应被去掉
+func (s *contentSanitizer) attributes(tag string, n *html.Node) ([]html.Attribute, bool) {
+	var attrs []html.Attribute
+	get := func(key string) (string, bool) {
+		for _, a := range n.Attr {
+			if a.Namespace == "" && strings.ToLower(a.Key) == key {
+				return a.Val, true
+			}
+		}
+		return "", false
+	}
+	copyAttr := func(key string, valid func(string) bool) {
+		if v, ok := get(key); ok && valid(v) {
+			attrs = append(attrs, html.Attribute{Key: key, Val: v})
+		}
+	}
+	anyText := func(v string) bool { return len(v) <= 200 }
+
+	switch tag {
+	case "a":
+		href, ok := get("href")
+		if !ok {
+			return nil, true
+		}
+		link, external, ok := sanitizeContentURL(href, true, s.siteHosts)
+		if !ok {
+			return nil, false
+		}
+		attrs = append(attrs, html.Attribute{Key: "href", Val: link})
+		copyAttr("title", anyText)
+		if external {
+			attrs = append(attrs,
+				html.Attribute{Key: "rel", Val: "nofollow noopener noreferrer"},
+				html.Attribute{Key: "target", Val: "_blank"},
+			)
+		}
+	case "img":
+		src, ok := get("src")
+		if !ok {
+			return nil, false
+		}
+		link, _, ok := sanitizeContentURL(src, false, s.siteHosts)
+		if !ok {
+			return nil, false
+		}
+		attrs = append(attrs, html.Attribute{Key: "src", Val: link})
+		copyAttr("alt", anyText)
+		copyAttr("title", anyText)
+		copyAttr("width", isSmallNumber)
+		copyAttr("height", isSmallNumber)
+		if !s.imageSeen[link] {
+			s.imageSeen[link] = true
+			s.images = append(s.images, link)
+		}
+	case "code":
+		copyAttr("class", isLanguageClass)
+	case "ol":
+		copyAttr("start", isSmallNumber)
+	case "th", "td":
+		copyAttr("colspan", isSmallNumber)
+		copyAttr("rowspan", isSmallNumber)
+		copyAttr("align", func(v string) bool { return v == "left" || v == "center" || v == "right" })
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := strings.Join(strings.Fields(nodeText(n)), " ")
+		if text == "" {
+			break
+		}
+		id := s.headingID(text)
+		attrs = append(attrs, html.Attribute{Key: "id", Val: id})
+		s.toc = append(s.toc, &models.ContentHeading{Level: int(tag[1] - '0'), ID: id, Text: text})
+	}
+	return attrs, true
+}
+
+// headingID 根据标题文字生成锚点，重复时追加序号
+func (s *contentSanitizer) headingID(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			dash = false
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			if !dash && b.Len() > 0 {
+				b.WriteByte('-')
+				dash = true
+			}
+		}
+	}
+	slug := strings.TrimRight(b.String(), "-")
+	if slug == "" {
+		slug = "section"
+	}
+	// 加前缀，避免与页面元素 id 或全局变量冲突
+	id := "toc-" + slug
+	n := s.headingIDs[id]
+	s.headingIDs[id] = n + 1
+	if n > 0 {
+		id += "-" + strconv.Itoa(n)
+	}
+	return id
+}
+
+// sanitizeContentURL 校验链接地址，只允许 http/https、相对地址与锚点，allowMailto 时另允许 mailto
+// 返回规范化后的地址以及是否为站外链接
+func sanitizeContentURL(raw string, allowMailto bool, siteHosts map[string]bool) (string, bool, bool) {
+	// 浏览器会忽略地址中的空白与控制字符，先去掉再判断协议，防止 "java\tscript:" 之类的绕过
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, raw)
+	if cleaned == "" || len(cleaned) > 2048 {
+		return "", false, false
+	}
+	// 浏览器把 http(s) 及相对地址中的反斜杠当作斜杠，"\\evil.com" 实际是协议相对的站外地址
+	cleaned = strings.ReplaceAll(cleaned, "\\", "/")
+	u, err := url.Parse(cleaned)
+	if err != nil {
+		return "", false, false
+	}
+	switch u.Scheme {
+	case "":
+		if strings.HasPrefix(cleaned, "//") {
+			return cleaned, !siteHosts[strings.ToLower(u.Hostname())], true
+		}
+		return cleaned, false, true
+	case "http", "https":
+		if u.Host == "" {
+			return "", false, false
+		}
+		return cleaned, !siteHosts[strings.ToLower(u.Hostname())], true
+	case "mailto":
+		if allowMailto {
+			return cleaned, false, true
+		}
+	}
+	return "", false, false
+}
+
+// nodeText 拼接节点下的全部文本
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	if n.Type == html.ElementNode && dropContentTags[strings.ToLower(n.Data)] {
+		return ""
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(nodeText(c))
+	}
+	return b.String()
+}
+
+func isSmallNumber(v string) bool {
+	n, err := strconv.Atoi(v)
+	return err == nil && n >= 0 && n <= 10000
+}
+
+func isLanguageClass(v string) bool {
+	if !strings.HasPrefix(v, "language-") || len(v) > 40 {
+		return false
+	}
+	for _, r := range v[len("language-"):] {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '+' || r == '_' || r == '#') {
+			return false
+		}
+	}
+	return true
+}
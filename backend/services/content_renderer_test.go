@@ -0,0 +1,153 @@
+package services
+
+import (
+	"testing"
+
+	"godad-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRenderMarkdownContent 测试 Markdown 渲染与白名单过滤
+func TestRenderMarkdownContent(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:     "行内格式",
+			source:   "**粗体** *斜体* `code`",
+			expected: "<p><strong>粗体</strong> <em>斜体</em> <code>code</code></p>",
+		},
+		{
+			name:     "表格",
+			source:   "| a | b |\n|---|---|\n| 1 | 2 |",
+			expected: "<table>\n<thead>\n<tr><th>a</th><th>b</th></tr>\n</thead>\n<tbody>\n<tr><td>1</td><td>2</td></tr>\n</tbody>\n</table>",
+		},
+		{
+			name:     "代码块转义并保留语言",
+			source:   "```go\nfmt.Println(\"<x>\")\n```",
+			expected: "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;x&gt;&#34;)\n</code></pre>",
+		},
+		{
+			name:     "内嵌HTML按文本转义",
+			source:   "<script>alert(1)</script>",
+			expected: "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>",
+		},
+		{
+			name:     "javascript链接被移除",
+			source:   "[x](javascript:alert(1))",
+			expected: "<p>x</p>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rendered, err := renderArticleContent(tc.source, models.ContentFormatMarkdown)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, rendered.HTML)
+		})
+	}
+}
+
+// TestRenderContentLinksImagesAndTOC 测试外链标记、图片收集与标题锚点
+func TestRenderContentLinksImagesAndTOC(t *testing.T) {
+	source := "## 重复\n\n## 重复\n\n[外链](https://example.com) [站内](/a/1) ![图](https://img.example.com/a.png) ![图](https://img.example.com/a.png)"
+	rendered, err := renderArticleContent(source, models.ContentFormatMarkdown)
+	require.NoError(t, err)
+
+	assert.Contains(t, rendered.HTML, `<a href="https://example.com" rel="nofollow noopener noreferrer" target="_blank">外链</a>`)
+	assert.Contains(t, rendered.HTML, `<a href="/a/1">站内</a>`)
+	assert.Equal(t, []string{"https://img.example.com/a.png"}, rendered.Images)
+
+	require.Len(t, rendered.TOC, 2)
+	assert.Equal(t, "toc-重复", rendered.TOC[0].ID)
+	assert.Equal(t, "toc-重复-1", rendered.TOC[1].ID)
+	assert.Equal(t, 2, rendered.TOC[1].Level)
+}
+
+// TestSanitizeHTMLContent 测试 HTML 源文的标签与属性过滤
+func TestSanitizeHTMLContent(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:     "去掉事件属性与脚本",
+			source:   `<p onclick="x()">hi<script>evil()</script></p>`,
+			expected: "<p>hi</p>",
+		},
+		{
+			name:     "协议中夹杂空白字符",
+			source:   `<a href="java&#9;script:alert(1)">x</a>`,
+			expected: "x",
+		},
+		{
+			name:     "反斜杠地址视为站外协议相对地址",
+			source:   `<a href="\\evil.com">x</a>`,
+			expected: `<a href="//evil.com" rel="nofollow noopener noreferrer" target="_blank">x</a>`,
+		},
+		{
+			name:     "丢弃iframe并保留未知标签的文本",
+			source:   `<h2>T</h2><iframe src="x"></iframe><custom>keep</custom>`,
+			expected: `<h2 id="toc-t">T</h2>keep`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rendered, err := renderArticleContent(tc.source, models.ContentFormatHTML)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, rendered.HTML)
+		})
+	}
+
+	// 只含被过滤内容时返回错误
+	_, err := renderArticleContent(`<img src="data:image/png;base64,xx">`, models.ContentFormatHTML)
+	assert.Error(t, err)
+
+	// 评论策略不允许标题与图片
+	rendered, err := renderCommentContent(`<h1>t</h1><img src="/a.png">text`, models.ContentFormatHTML)
+	require.NoError(t, err)
+	assert.Equal(t, "ttext", rendered.HTML)
+}
+
+// TestSanitizeContentURL 测试链接地址的协议校验与站外判断
+func TestSanitizeContentURL(t *testing.T) {
+	siteHosts := map[string]bool{"godad.example.com": true}
+	testCases := []struct {
+		name        string
+		raw         string
+		allowMailto bool
+		expected    string
+		external    bool
+		ok          bool
+	}{
+		{name: "站外https", raw: "https://example.com/a", expected: "https://example.com/a", external: true, ok: true},
+		{name: "站内域名", raw: "https://GoDad.example.com/a", expected: "https://GoDad.example.com/a", ok: true},
+		{name: "相对地址", raw: "/articles/1", expected: "/articles/1", ok: true},
+		{name: "锚点", raw: "#toc-1", expected: "#toc-1", ok: true},
+		{name: "协议相对地址", raw: "//example.com/a", expected: "//example.com/a", external: true, ok: true},
+		{name: "反斜杠协议相对地址", raw: `\\example.com`, expected: "//example.com", external: true, ok: true},
+		{name: "斜杠加反斜杠", raw: `/\example.com`, expected: "//example.com", external: true, ok: true},
+		{name: "javascript", raw: "javascript:alert(1)", ok: false},
+		{name: "夹杂换行的javascript", raw: "java\nscript:alert(1)", ok: false},
+		{name: "data", raw: "data:text/html,<script>", ok: false},
+		{name: "缺少主机的http", raw: "http:///a", ok: false},
+		{name: "未允许mailto", raw: "mailto:a@example.com", ok: false},
+		{name: "允许mailto", raw: "mailto:a@example.com", allowMailto: true, expected: "mailto:a@example.com", ok: true},
+		{name: "空地址", raw: " ", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cleaned, external, ok := sanitizeContentURL(tc.raw, tc.allowMailto, siteHosts)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, cleaned)
+			assert.Equal(t, tc.external, external)
+		})
+	}
+}
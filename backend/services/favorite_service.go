@@ -67,6 +67,8 @@ func (s *FavoriteService) ToggleFavorite(userID, articleID uint) (*models.Favori
 	// 清理相关缓存
 	s.clearArticleCache()
 
+	NewTrendingService(s.db).RecordUserEvent(TrendingArticle, articleID, TrendingEventFavorite, userID)
+
 	// 创建收藏通知
 	var article models.Article
 	if err := s.db.First(&article, articleID).Error; err == nil {
@@ -222,5 +224,5 @@ func (s *FavoriteService) clearArticleCache() {
 	// 清理文章列表缓存
 	s.cacheService.DeletePattern("articles:*")
 	// 清理搜索缓存
-	s.cacheService.DeletePattern("search:*")
+	InvalidateSearchCache()
 }
\ No newline at end of file
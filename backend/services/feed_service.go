@@ -0,0 +1,381 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// 信息流内容类型
+const (
+	FeedItemArticle   = "article"
+	FeedItemForumPost = "forum_post"
+)
+
+// 信息流推荐原因
+const (
+	FeedReasonFollowing = "following" // 关注的作者
+	FeedReasonTag       = "tag"       // 关注的标签
+	FeedReasonHot       = "hot"       // 热门推荐
+)
+
+const (
+	// feedCacheTTL 用户信息流候选集的缓存时长；关注关系或关注作者发布内容时会主动失效
+	feedCacheTTL = 5 * time.Minute
+	// feedWindow 关注内容的时间范围
+	feedWindow = 30 * 24 * time.Hour
+	// feedHotWindow 热门推荐的时间范围
+	feedHotWindow = 7 * 24 * time.Hour
+	// feedSourceLimit 每个来源最多取的候选数
+	feedSourceLimit = 200
+	// feedHotLimit 每类热门内容最多取的候选数
+	feedHotLimit = 30
+)
+
+var errInvalidFeedCursor = errors.New("无效的分页游标")
+
+// feedEntry 信息流候选项，缓存在 Redis 中，翻页时再按 ID 加载内容
+type feedEntry struct {
+	Type   string `json:"t"`
+	ID     uint   `json:"i"`
+	Reason string `json:"r"`
+	Time   int64  `json:"s"` // 排序时间（毫秒）
+}
+
+// before 判断 e 是否排在 other 之前：时间倒序，同一时间按类型、ID 倒序，保证顺序稳定
+func (e feedEntry) before(other feedEntry) bool {
+	if e.Time != other.Time {
+		return e.Time > other.Time
+	}
+	if e.Type != other.Type {
+		return e.Type > other.Type
+	}
+	return e.ID > other.ID
+}
+
+// FeedItem 信息流条目
+type FeedItem struct {
+	Type      string                    `json:"type"`
+	Reason    string                    `json:"reason"`
+	Article   *models.ArticleResponse   `json:"article,omitempty"`
+	ForumPost *models.ForumPostResponse `json:"forum_post,omitempty"`
+}
+
+// FeedPage 信息流分页结果
+type FeedPage struct {
+	Items      []FeedItem `json:"items"`
+	NextCursor string     `json:"next_cursor"`
+	HasMore    bool       `json:"has_more"`
+}
+
+// FeedService 个性化首页信息流：关注的作者、关注的标签与热门内容合并，按时间倒序
+type FeedService struct {
+	db           *gorm.DB
+	cacheService *CacheService
+	tagService   *TagService
+}
+
+// NewFeedService 创建信息流服务
+func NewFeedService(db *gorm.DB) *FeedService {
+	return &FeedService{
+		db:           db,
+		cacheService: NewCacheService(),
+		tagService:   NewTagService(db),
+	}
+}
+
+func feedCacheKey(userID uint) string {
+	return fmt.Sprintf("feed:user:%d", userID)
+}
+
+// GetFeed 获取用户信息流，cursor 为上一页返回的 next_cursor，首页传空
+// 游标记录上一页最后一条的位置，候选集刷新后也只返回排在其后的内容，不会重复
+func (s *FeedService) GetFeed(userID uint, cursor string, size int) (*FeedPage, error) {
+	var after *feedEntry
+	if cursor != "" {
+		decoded, err := decodeFeedCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = decoded
+	}
+
+	entries, err := s.loadEntries(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(entries), func(i int) bool { return after.before(entries[i]) })
+	}
+	end := min(start+size, len(entries))
+	pageEntries := entries[start:end]
+
+	items, err := s.hydrate(pageEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &FeedPage{Items: items, HasMore: end < len(entries)}
+	if len(pageEntries) > 0 && page.HasMore {
+		page.NextCursor = encodeFeedCursor(pageEntries[len(pageEntries)-1])
+	}
+	return page, nil
+}
+
+// InvalidateFeed 清除用户信息流缓存（关注、取关、关注标签后调用）
+func (s *FeedService) InvalidateFeed(userID uint) {
+	if err := s.cacheService.Delete(feedCacheKey(userID)); err != nil && !errors.Is(err, ErrCacheDisabled) {
+		log.Printf("清除信息流缓存失败 (user: %d): %v", userID, err)
+	}
+}
+
+// InvalidateFollowerFeeds 作者发布新内容后清除其粉丝的信息流缓存
+func (s *FeedService) InvalidateFollowerFeeds(authorID uint) {
+	if !isCacheReady() {
+		return
+	}
+	var followerIDs []uint
+	if err := s.db.Model(&models.Follow{}).Where("followee_id = ?", authorID).Pluck("follower_id", &followerIDs).Error; err != nil {
+		log.Printf("查询粉丝失败 (user: %d): %v", authorID, err)
+		return
+	}
+	for i := 0; i < len(followerIDs); i += 500 {
+		batch := followerIDs[i:min(i+500, len(followerIDs))]
+		keys := make([]string, 0, len(batch))
+		for _, id := range batch {
+			keys = append(keys, feedCacheKey(id))
+		}
+		if err := rdb.Del(ctx, keys...).Err(); err != nil {
+			log.Printf("清除粉丝信息流缓存失败 (user: %d): %v", authorID, err)
+			return
+		}
+	}
+}
+
+// loadEntries 读取缓存的候选集，未命中时重新构建
+func (s *FeedService) loadEntries(userID uint) ([]feedEntry, error) {
+	var entries []feedEntry
+	if err := s.cacheService.Get(feedCacheKey(userID), &entries); err == nil {
+		return entries, nil
+	}
+
+	entries, err := s.buildEntries(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cacheService.SetWithExpire(feedCacheKey(userID), entries, feedCacheTTL); err != nil && !errors.Is(err, ErrCacheDisabled) {
+		log.Printf("缓存信息流失败 (user: %d): %v", userID, err)
+	}
+	return entries, nil
+}
+
+// buildEntries 汇总各来源的候选内容，同一内容只保留优先级最高的推荐原因
+func (s *FeedService) buildEntries(userID uint) ([]feedEntry, error) {
+	now := time.Now()
+	since := now.Add(-feedWindow)
+	hotSince := now.Add(-feedHotWindow)
+
+	var followeeIDs []uint
+	if err := s.db.Model(&models.Follow{}).Where("follower_id = ?", userID).Pluck("followee_id", &followeeIDs).Error; err != nil {
+		return nil, fmt.Errorf("查询关注列表失败: %v", err)
+	}
+	tagIDs, err := s.tagService.GetFollowedTagIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	blockedIDs, err := NewUserBlockService(s.db).GetBlockedUserIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[uint]bool, len(blockedIDs))
+	for _, id := range blockedIDs {
+		blocked[id] = true
+	}
+
+	seen := make(map[string]bool)
+	var entries []feedEntry
+	add := func(itemType, reason string, rows []feedRow) {
+		for _, row := range rows {
+			key := itemType + ":" + strconv.FormatUint(uint64(row.ID), 10)
+			if seen[key] || row.AuthorID == userID || blocked[row.AuthorID] {
+				continue
+			}
+			seen[key] = true
+			entries = append(entries, feedEntry{Type: itemType, ID: row.ID, Reason: reason, Time: row.SortTime.UnixMilli()})
+		}
+	}
+
+	// 关注的作者
+	if len(followeeIDs) > 0 {
+		rows, err := s.queryArticles(since, func(q *gorm.DB) *gorm.DB {
+			return q.Where("author_id IN ?", followeeIDs)
+		}, "sort_time DESC", feedSourceLimit)
+		if err != nil {
+			return nil, err
+		}
+		add(FeedItemArticle, FeedReasonFollowing, rows)
+
+		rows, err = s.queryForumPosts(since, func(q *gorm.DB) *gorm.DB {
+			return q.Where("author_id IN ?", followeeIDs)
+		}, "created_at DESC", feedSourceLimit)
+		if err != nil {
+			return nil, err
+		}
+		add(FeedItemForumPost, FeedReasonFollowing, rows)
+	}
+
+	// 关注的标签
+	if len(tagIDs) > 0 {
+		rows, err := s.queryArticles(since, func(q *gorm.DB) *gorm.DB {
+			return q.Where("id IN (?)", s.db.Model(&models.ArticleTag{}).Select("article_id").Where("tag_id IN ?", tagIDs))
+		}, "sort_time DESC", feedSourceLimit)
+		if err != nil {
+			return nil, err
+		}
+		add(FeedItemArticle, FeedReasonTag, rows)
+	}
+
+	// 热门推荐，保证新用户（没有关注）也有内容
+	rows, err := s.queryArticles(hotSince, nil, "(view_count * 0.6 + like_count * 0.3 + comment_count * 0.1) DESC", feedHotLimit)
+	if err != nil {
+		return nil, err
+	}
+	add(FeedItemArticle, FeedReasonHot, rows)
+
+	rows, err = s.queryForumPosts(hotSince, nil, "is_hot DESC, (view_count * 0.6 + reply_count * 0.3 + like_count * 0.1) DESC", feedHotLimit)
+	if err != nil {
+		return nil, err
+	}
+	add(FeedItemForumPost, FeedReasonHot, rows)
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].before(entries[j]) })
+	return entries, nil
+}
+
+// feedRow 候选内容的排序信息
+type feedRow struct {
+	ID       uint
+	AuthorID uint
+	SortTime time.Time
+}
+
+func (s *FeedService) queryArticles(since time.Time, scope func(*gorm.DB) *gorm.DB, order string, limit int) ([]feedRow, error) {
+	query := s.db.Model(&models.Article{}).
+		Select("id, author_id, COALESCE(published_at, created_at) AS sort_time").
+		Where("status = ? AND COALESCE(published_at, created_at) >= ?", models.ArticleStatusPublished, since)
+	if scope != nil {
+		query = scope(query)
+	}
+	var rows []feedRow
+	if err := query.Order(order).Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询信息流文章失败: %v", err)
+	}
+	return rows, nil
+}
+
+func (s *FeedService) queryForumPosts(since time.Time, scope func(*gorm.DB) *gorm.DB, order string, limit int) ([]feedRow, error) {
+	query := s.db.Model(&models.ForumPost{}).
+		Select("id, author_id, created_at AS sort_time").
+		Where("status = ? AND created_at >= ?", 1, since)
+	if scope != nil {
+		query = scope(query)
+	}
+	var rows []feedRow
+	if err := query.Order(order).Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询信息流帖子失败: %v", err)
+	}
+	return rows, nil
+}
+
+// hydrate 按候选项加载内容，已删除或下架的内容直接跳过
+func (s *FeedService) hydrate(entries []feedEntry) ([]FeedItem, error) {
+	var articleIDs, postIDs []uint
+	for _, e := range entries {
+		if e.Type == FeedItemArticle {
+			articleIDs = append(articleIDs, e.ID)
+		} else {
+			postIDs = append(postIDs, e.ID)
+		}
+	}
+
+	articles := make(map[uint]*models.Article, len(articleIDs))
+	if len(articleIDs) > 0 {
+		var list []*models.Article
+		if err := s.db.Preload("Author").Preload("Category").Preload("Tags").
+			Where("id IN ? AND status = ?", articleIDs, models.ArticleStatusPublished).
+			Find(&list).Error; err != nil {
+			return nil, fmt.Errorf("加载信息流文章失败: %v", err)
+		}
+		for _, a := range list {
+			articles[a.ID] = a
+		}
+	}
+
+	posts := make(map[uint]*models.ForumPost, len(postIDs))
+	if len(postIDs) > 0 {
+		var list []*models.ForumPost
+		if err := s.db.Preload("Author").Where("id IN ? AND status = ?", postIDs, 1).Find(&list).Error; err != nil {
+			return nil, fmt.Errorf("加载信息流帖子失败: %v", err)
+		}
+		for _, p := range list {
+			posts[p.ID] = p
+		}
+	}
+
+	items := make([]FeedItem, 0, len(entries))
+	for _, e := range entries {
+		item := FeedItem{Type: e.Type, Reason: e.Reason}
+		if e.Type == FeedItemArticle {
+			article, ok := articles[e.ID]
+			if !ok {
+				continue
+			}
+			item.Article = article.ToResponse(false)
+		} else {
+			post, ok := posts[e.ID]
+			if !ok {
+				continue
+			}
+			item.ForumPost = post.ToResponse(false)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// encodeFeedCursor 游标格式：排序时间:类型:ID，URL 安全的 base64 编码
+func encodeFeedCursor(e feedEntry) string {
+	raw := fmt.Sprintf("%d:%s:%d", e.Time, e.Type, e.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeFeedCursor(cursor string) (*feedEntry, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errInvalidFeedCursor
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 || (parts[1] != FeedItemArticle && parts[1] != FeedItemForumPost) {
+		return nil, errInvalidFeedCursor
+	}
+	ts, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, errInvalidFeedCursor
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 32)
+	if err != nil {
+		return nil, errInvalidFeedCursor
+	}
+	return &feedEntry{Time: ts, Type: parts[1], ID: uint(id)}, nil
+}
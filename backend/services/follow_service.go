@@ -50,6 +50,7 @@ func (s *FollowService) FollowUser(followerID, followeeID uint) error {
 	if err := s.db.Create(&follow).Error; err != nil {
 		return err
 	}
+	NewFeedService(s.db).InvalidateFeed(followerID)
 
 	// 发送关注通知
 	if err := s.notificationService.CreateFollowNotification(followerID, followeeID); err != nil {
@@ -70,7 +71,8 @@ func (s *FollowService) UnfollowUser(followerID, followeeID uint) error {
 	if result.RowsAffected == 0 {
 		return errors.New("not following this user")
 	}
-	
+	NewFeedService(s.db).InvalidateFeed(followerID)
+
 	return nil
 }
 
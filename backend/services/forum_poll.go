@@ -0,0 +1,203 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"godad-backend/config"
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// preparePoll 校验并规范化发帖时附带的投票，返回待创建的投票（不含帖子ID）及敏感词检测结果
+func (s *ForumService) preparePoll(req *models.ForumPollCreateRequest) (*models.ForumPoll, *SensitiveScreenResult, error) {
+	cfg := config.GetConfig().Poll
+
+	question := strings.TrimSpace(req.Question)
+	options := make([]string, 0, len(req.Options))
+	seen := make(map[string]struct{}, len(req.Options))
+	for _, opt := range req.Options {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			return nil, nil, errors.New("投票选项不能为空")
+		}
+		if _, ok := seen[opt]; ok {
+			return nil, nil, errors.New("投票选项不能重复")
+		}
+		seen[opt] = struct{}{}
+		options = append(options, opt)
+	}
+	if len(options) < 2 {
+		return nil, nil, errors.New("投票至少需要两个选项")
+	}
+
+	// 投票问题与选项同样经过敏感词检测
+	fields := []*string{&question}
+	for i := range options {
+		fields = append(fields, &options[i])
+	}
+	screen, err := GetSensitiveFilter().Screen(fields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if req.ClosesAt != nil {
+		now := time.Now()
+		if !req.ClosesAt.After(now) {
+			return nil, nil, errors.New("投票截止时间必须晚于当前时间")
+		}
+		if cfg.MaxDays > 0 && req.ClosesAt.After(now.AddDate(0, 0, cfg.MaxDays)) {
+			return nil, nil, fmt.Errorf("投票截止时间不能超过%d天", cfg.MaxDays)
+		}
+	}
+
+	maxChoices := 1
+	if req.MultipleChoice {
+		maxChoices = len(options)
+		if req.MaxChoices > 0 && req.MaxChoices < maxChoices {
+			maxChoices = req.MaxChoices
+		}
+	}
+	hideResults := cfg.HideResults
+	if req.HideResults != nil {
+		hideResults = *req.HideResults
+	}
+
+	poll := &models.ForumPoll{
+		Question:       question,
+		MultipleChoice: req.MultipleChoice,
+		MaxChoices:     maxChoices,
+		HideResults:    hideResults,
+		ClosesAt:       req.ClosesAt,
+		Options:        make([]models.ForumPollOption, len(options)),
+	}
+	for i, opt := range options {
+		poll.Options[i] = models.ForumPollOption{Content: opt, SortOrder: i}
+	}
+	return poll, screen, nil
+}
+
+// VotePoll 参与帖子附带的投票，每人一次，返回投票后的结果
+func (s *ForumService) VotePoll(postID, userID uint, optionIDs []uint) (*models.ForumPollResponse, error) {
+	if err := NewModerationService(s.db).EnsureCanPost(userID); err != nil {
+		return nil, err
+	}
+
+	var post models.ForumPost
+	if err := s.db.Select("id").Where("id = ? AND status = ?", postID, models.ForumPostStatusPublished).First(&post).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("帖子不存在")
+		}
+		return nil, err
+	}
+
+	var poll models.ForumPoll
+	if err := s.db.Preload("Options").Where("post_id = ?", postID).First(&poll).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("该帖子没有投票")
+		}
+		return nil, err
+	}
+	if poll.IsClosed(time.Now()) {
+		return nil, errors.New("投票已截止")
+	}
+
+	valid := make(map[uint]bool, len(poll.Options))
+	for _, opt := range poll.Options {
+		valid[opt.ID] = true
+	}
+	chosen := make([]uint, 0, len(optionIDs))
+	picked := make(map[uint]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if !valid[id] {
+			return nil, errors.New("投票选项不存在")
+		}
+		if !picked[id] {
+			picked[id] = true
+			chosen = append(chosen, id)
+		}
+	}
+	if len(chosen) > poll.MaxChoices {
+		if poll.MultipleChoice {
+			return nil, errors.New("超过该投票允许的最多选项数")
+		}
+		return nil, errors.New("单选投票只能选择一项")
+	}
+
+	// (poll_id, user_id) 唯一索引保证并发重复提交时只有一张选票成功
+	ballot := &models.ForumPollBallot{PollID: poll.ID, UserID: userID, OptionIDs: chosen}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(ballot).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ForumPollOption{}).Where("id IN ?", chosen).
+			UpdateColumn("vote_count", gorm.Expr("vote_count + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ForumPoll{}).Where("id = ?", poll.ID).
+			UpdateColumn("voter_count", gorm.Expr("voter_count + 1")).Error
+	})
+	if err != nil {
+		if voted, _ := s.findBallot(poll.ID, userID); voted != nil {
+			return nil, errors.New("您已参与过该投票")
+		}
+		return nil, fmt.Errorf("投票失败: %w", err)
+	}
+
+	polls, err := s.LoadPolls([]uint{postID}, userID)
+	if err != nil {
+		return nil, err
+	}
+	return polls[postID], nil
+}
+
+// LoadPolls 批量加载帖子附带的投票，结果按 viewerID 的投票情况决定是否可见；viewerID 为 0 表示未登录
+func (s *ForumService) LoadPolls(postIDs []uint, viewerID uint) (map[uint]*models.ForumPollResponse, error) {
+	result := make(map[uint]*models.ForumPollResponse)
+	if len(postIDs) == 0 {
+		return result, nil
+	}
+
+	var polls []models.ForumPoll
+	if err := s.db.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC, id ASC")
+	}).Where("post_id IN ?", postIDs).Find(&polls).Error; err != nil {
+		return nil, err
+	}
+	if len(polls) == 0 {
+		return result, nil
+	}
+
+	ballots := make(map[uint]*models.ForumPollBallot)
+	if viewerID > 0 {
+		pollIDs := make([]uint, len(polls))
+		for i, p := range polls {
+			pollIDs[i] = p.ID
+		}
+		var rows []models.ForumPollBallot
+		if err := s.db.Where("poll_id IN ? AND user_id = ?", pollIDs, viewerID).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for i := range rows {
+			ballots[rows[i].PollID] = &rows[i]
+		}
+	}
+
+	now := time.Now()
+	for i := range polls {
+		result[polls[i].PostID] = polls[i].ToResponse(ballots[polls[i].ID], now)
+	}
+	return result, nil
+}
+
+// findBallot 查询用户在某个投票中的选票
+func (s *ForumService) findBallot(pollID, userID uint) (*models.ForumPollBallot, error) {
+	var ballot models.ForumPollBallot
+	if err := s.db.Where("poll_id = ? AND user_id = ?", pollID, userID).First(&ballot).Error; err != nil {
+		return nil, err
+	}
+	return &ballot, nil
+}
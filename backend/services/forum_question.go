@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrBountyInsufficientPoints 提问者积分不足以托管悬赏
+var ErrBountyInsufficientPoints = errors.New("积分不足，无法设置该悬赏")
+
+// 悬赏相关的积分流水
+const (
+	bountyActionEscrow = "question_bounty_escrow" // 发布问题时托管
+	bountyActionAward  = "question_bounty_award"  // 回答被采纳或自动发放
+	bountyActionRefund = "question_bounty_refund" // 退还提问者
+	bountySourceType   = "forum_post"
+)
+
+// bountySettleBatch 每次结算到期悬赏的最大帖子数
+const bountySettleBatch = 200
+
+// escrowBounty 在发帖事务中锁定提问者积分并扣除悬赏，积分不足时返回 ErrBountyInsufficientPoints
+func (s *ForumService) escrowBounty(tx *gorm.DB, post *models.ForumPost) error {
+	var points models.UserPoints
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", post.AuthorID).First(&points).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if points.TotalPoints < post.BountyPoints {
+		return ErrBountyInsufficientPoints
+	}
+	desc := fmt.Sprintf("悬赏问题《%s》", truncateString(post.Title, 50))
+	return NewPointsService(tx).DeductPoints(post.AuthorID, bountyActionEscrow, bountySourceType, post.ID, desc, post.BountyPoints)
+}
+
+// AcceptAnswer 提问者采纳一条回复为答案；悬赏仍在托管中时转给回答者
+func (s *ForumService) AcceptAnswer(postID, replyID, userID uint) (*models.ForumPost, error) {
+	var post models.ForumPost
+	var reply models.ForumReply
+	awarded := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", postID, models.ForumPostStatusPublished).
+			First(&post).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("帖子不存在")
+			}
+			return err
+		}
+		if post.PostType != models.ForumPostTypeQuestion {
+			return errors.New("只有问答帖可以采纳答案")
+		}
+		if post.AuthorID != userID {
+			return errors.New("只有提问者可以采纳答案")
+		}
+		if post.AcceptedReplyID != nil {
+			return errors.New("该问题已采纳答案")
+		}
+
+		if err := tx.Where("id = ? AND post_id = ? AND status = ?", replyID, postID, 1).First(&reply).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("回复不存在")
+			}
+			return err
+		}
+		if reply.AuthorID == userID {
+			return errors.New("不能采纳自己的回复")
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"accepted_reply_id": reply.ID,
+			"solved_at":         now,
+		}
+		if post.BountyStatus == models.BountyStatusOpen {
+			if err := s.payBounty(tx, &post, &reply, "回答被采纳"); err != nil {
+				return err
+			}
+			updates["bounty_status"] = models.BountyStatusAwarded
+			updates["bounty_reply_id"] = reply.ID
+			awarded = true
+		}
+		if err := tx.Model(&post).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Model(&reply).Update("is_accepted", true).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("您在《%s》中的回答被提问者采纳", post.Title)
+	if awarded {
+		message += fmt.Sprintf("，获得悬赏 %d 积分", post.BountyPoints)
+	}
+	s.sendQuestionNotification(reply.AuthorID, userID, post.ID, "回答被采纳", message)
+	NewCacheService().DeletePattern("forum:posts:*")
+
+	if err := s.db.Preload("Author").First(&post, post.ID).Error; err != nil {
+		return nil, fmt.Errorf("加载帖子数据失败: %w", err)
+	}
+	return &post, nil
+}
+
+// SettleExpiredBounties 结算到期未采纳的悬赏：发放给点赞最多的他人回答，没有获赞回答时退还提问者
+// 已删除或未发布的问题同样需要结算（退还），因此不使用默认的软删除过滤
+func (s *ForumService) SettleExpiredBounties() (string, error) {
+	var posts []models.ForumPost
+	if err := s.db.Unscoped().Where("bounty_status = ? AND bounty_expires_at <= ?", models.BountyStatusOpen, time.Now()).
+		Order("bounty_expires_at ASC").Limit(bountySettleBatch).Find(&posts).Error; err != nil {
+		return "", err
+	}
+
+	awarded, refunded := 0, 0
+	for i := range posts {
+		status, err := s.settleExpiredBounty(posts[i].ID)
+		if err != nil {
+			log.Printf("结算悬赏失败 post=%d: %v", posts[i].ID, err)
+			continue
+		}
+		switch status {
+		case models.BountyStatusAutoAwarded:
+			awarded++
+		case models.BountyStatusRefunded:
+			refunded++
+		}
+	}
+	if awarded+refunded > 0 {
+		NewCacheService().DeletePattern("forum:posts:*")
+	}
+	return fmt.Sprintf("自动发放 %d 个悬赏，退还 %d 个悬赏", awarded, refunded), nil
+}
+
+// settleExpiredBounty 结算单个到期悬赏，返回结算后的悬赏状态；状态已变化（如刚被采纳）时不做处理
+// 问题已删除或不再公开时直接退还提问者
+func (s *ForumService) settleExpiredBounty(postID uint) (string, error) {
+	var post models.ForumPost
+	var reply models.ForumReply
+	status := ""
+	offline := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&post, postID).Error; err != nil {
+			return err
+		}
+		if post.BountyStatus != models.BountyStatusOpen {
+			return nil
+		}
+		if post.DeletedAt.Valid || post.Status != models.ForumPostStatusPublished {
+			status, offline = models.BountyStatusRefunded, true
+			return s.refundBountyTx(tx, &post, "问题已下线，退还悬赏")
+		}
+
+		err := tx.Where("post_id = ? AND author_id <> ? AND status = ? AND like_count > 0", post.ID, post.AuthorID, 1).
+			Order("like_count DESC, created_at ASC").First(&reply).Error
+		switch {
+		case err == nil:
+			if err := s.payBounty(tx, &post, &reply, "悬赏到期自动发放"); err != nil {
+				return err
+			}
+			status = models.BountyStatusAutoAwarded
+			return tx.Model(&post).Updates(map[string]interface{}{
+				"bounty_status":   status,
+				"bounty_reply_id": reply.ID,
+			}).Error
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			status = models.BountyStatusRefunded
+			return s.refundBountyTx(tx, &post, "悬赏到期无人获赞，退还")
+		default:
+			return err
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// 给提问者的结算通知由系统发出（ActorID 为 0），否则会被“不给自己发通知”的规则拦下
+	switch status {
+	case models.BountyStatusAutoAwarded:
+		s.sendQuestionNotification(reply.AuthorID, post.AuthorID, post.ID, "获得悬赏",
+			fmt.Sprintf("您在《%s》中的回答获赞最多，悬赏到期后自动获得 %d 积分", post.Title, post.BountyPoints))
+		s.sendQuestionNotification(post.AuthorID, 0, post.ID, "悬赏已结算",
+			fmt.Sprintf("您的问题《%s》悬赏到期未采纳答案，%d 积分已发放给获赞最多的回答", post.Title, post.BountyPoints))
+	case models.BountyStatusRefunded:
+		reason := "悬赏到期且没有获赞回答"
+		if offline {
+			reason = "已下线"
+		}
+		s.sendQuestionNotification(post.AuthorID, 0, post.ID, "悬赏已退还",
+			fmt.Sprintf("您的问题《%s》%s，%d 积分已退还", post.Title, reason, post.BountyPoints))
+	}
+	return status, nil
+}
+
+// refundBounty 问题未能公开（如未通过审核）时退还仍在托管中的悬赏
+func (s *ForumService) refundBounty(postID uint, reason string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var post models.ForumPost
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&post, postID).Error; err != nil {
+			return err
+		}
+		if post.BountyStatus != models.BountyStatusOpen {
+			return nil
+		}
+		return s.refundBountyTx(tx, &post, reason)
+	})
+}
+
+// refundBountyTx 在已锁定帖子的事务中退还悬赏
+func (s *ForumService) refundBountyTx(tx *gorm.DB, post *models.ForumPost, reason string) error {
+	desc := fmt.Sprintf("%s《%s》", reason, truncateString(post.Title, 50))
+	if err := NewPointsService(tx).GrantPoints(post.AuthorID, bountyActionRefund, bountySourceType, post.ID, desc, post.BountyPoints); err != nil {
+		return err
+	}
+	return tx.Unscoped().Model(post).Update("bounty_status", models.BountyStatusRefunded).Error
+}
+
+// payBounty 把托管的悬赏转给回答者
+func (s *ForumService) payBounty(tx *gorm.DB, post *models.ForumPost, reply *models.ForumReply, reason string) error {
+	desc := fmt.Sprintf("%s《%s》", reason, truncateString(post.Title, 50))
+	return NewPointsService(tx).GrantPoints(reply.AuthorID, bountyActionAward, bountySourceType, post.ID, desc, post.BountyPoints)
+}
+
+// sendQuestionNotification 问答相关通知，资源指向帖子
+func (s *ForumService) sendQuestionNotification(receiverID, actorID, postID uint, title, message string) {
+	if err := s.notificationService.CreateNotification(&models.Notification{
+		ReceiverID: receiverID,
+		ActorID:    actorID,
+		Type:       models.NotificationTypeComment,
+		Title:      title,
+		ResourceID: postID,
+		Message:    message,
+		IsRead:     false,
+	}); err != nil {
+		log.Printf("发送问答通知失败 user=%d post=%d: %v", receiverID, postID, err)
+	}
+}
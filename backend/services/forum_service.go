@@ -10,6 +10,7 @@ import (
 	"godad-backend/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ForumService 论坛服务
@@ -94,6 +95,11 @@ func (s *ForumService) CreatePost(req *models.ForumPostCreateRequest, userID uin
 		return nil, err
 	}
 
+	// 禁言期间不能发布内容
+	if err := NewModerationService(s.db).EnsureCanPost(userID); err != nil {
+		return nil, err
+	}
+
 	// 验证用户是否存在
 	var user models.User
 	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
@@ -103,19 +109,91 @@ func (s *ForumService) CreatePost(req *models.ForumPostCreateRequest, userID uin
 		return nil, fmt.Errorf("验证用户失败: %w", err)
 	}
 
+	// 敏感词检测：block 拒绝提交，mask 直接打码，review 发布后进入举报审核队列
+	screen, err := GetSensitiveFilter().Screen(&req.Title, &req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// 渲染并过滤帖子内容，默认按 Markdown 处理
+	format := req.ContentFormat
+	if format == "" {
+		format = models.ContentFormatMarkdown
+	}
+	rendered, err := renderArticleContent(strings.TrimSpace(req.Content), format)
+	if err != nil {
+		return nil, err
+	}
+
+	// 附带的投票
+	var poll *models.ForumPoll
+	if req.Poll != nil {
+		var pollScreen *SensitiveScreenResult
+		if poll, pollScreen, err = s.preparePoll(req.Poll); err != nil {
+			return nil, err
+		}
+		// 投票中的 review 类命中随帖子一起提交审核
+		screen.Merge(pollScreen)
+	}
+
+	// 垃圾内容评分：低信任账号发布过快直接拒绝，高分帖子进入待审核
+	spamService := NewSpamService(s.db)
+	spamCheck, err := spamService.Assess(userID, models.ReportTargetForumPost, req.Title+"\n"+req.Content, rendered.HTML)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建帖子
 	post := &models.ForumPost{
 		Title:       strings.TrimSpace(req.Title),
 		Content:     strings.TrimSpace(req.Content),
+		ContentFormat: format,
+		ContentHTML: rendered.HTML,
 		Topic:       req.Topic,
 		AuthorID:    userID,
-		Status:      1, // 直接发布
+		Status:      models.ForumPostStatusPublished,
+		PostType:    models.ForumPostTypeDiscussion,
+		BountyStatus: models.BountyStatusNone,
 		LastReplyAt: nil,
 	}
+	if spamHoldReason(spamCheck) != "" {
+		post.Status = models.ForumPostStatusHeld
+	}
+	if req.PostType == models.ForumPostTypeQuestion {
+		post.PostType = models.ForumPostTypeQuestion
+		if req.BountyPoints > 0 {
+			days := req.BountyDays
+			if days <= 0 {
+				days = models.QuestionBountyDefaultDays
+			}
+			expiresAt := time.Now().AddDate(0, 0, days)
+			post.BountyPoints = req.BountyPoints
+			post.BountyStatus = models.BountyStatusOpen
+			post.BountyExpiresAt = &expiresAt
+		}
+	}
 
-	// 保存到数据库
-	if err := s.db.Create(post).Error; err != nil {
-		return nil, fmt.Errorf("创建帖子失败: %w", err)
+	// 保存到数据库，投票与悬赏积分托管在同一事务中完成
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return fmt.Errorf("创建帖子失败: %w", err)
+		}
+		if poll != nil {
+			poll.PostID = post.ID
+			if poll.Question == "" {
+				poll.Question = post.Title
+			}
+			if err := tx.Create(poll).Error; err != nil {
+				return fmt.Errorf("创建投票失败: %w", err)
+			}
+		}
+		if post.BountyStatus == models.BountyStatusOpen {
+			return s.escrowBounty(tx, post)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// 预加载关联数据
@@ -123,6 +201,13 @@ func (s *ForumService) CreatePost(req *models.ForumPostCreateRequest, userID uin
 		return nil, fmt.Errorf("加载帖子数据失败: %w", err)
 	}
 
+	spamService.Record(spamCheck, post.ID)
+	s.indexPost(post)
+	fileSensitiveReport(s.db, models.ReportTargetForumPost, post.ID, screen)
+	if post.Status == models.ForumPostStatusPublished {
+		go NewFeedService(s.db).InvalidateFollowerFeeds(userID)
+	}
+
 	return post, nil
 }
 
@@ -165,6 +250,19 @@ func (s *ForumService) GetPostList(req *models.ForumPostListRequest) ([]models.F
 		query = query.Where("is_locked = ?", *req.IsLocked)
 	}
 
+	// 帖子类型筛选
+	if req.PostType != "" {
+		query = query.Where("post_type = ?", req.PostType)
+	}
+
+	// 问答筛选：无人回答 / 已解决
+	switch req.Filter {
+	case "unanswered":
+		query = query.Where("post_type = ? AND accepted_reply_id IS NULL AND reply_count = 0", models.ForumPostTypeQuestion)
+	case "solved":
+		query = query.Where("post_type = ? AND accepted_reply_id IS NOT NULL", models.ForumPostTypeQuestion)
+	}
+
 	// 获取总数
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("统计帖子数量失败: %w", err)
@@ -298,18 +396,42 @@ func (s *ForumService) UpdatePost(id uint, req *models.ForumPostUpdateRequest, u
         return nil, errors.New("无权限修改此帖子")
     }
 
+	// 敏感词检测
+	screen, err := GetSensitiveFilter().Screen(&req.Title, &req.Content)
+	if err != nil {
+		return nil, err
+	}
+
 	// 更新字段
 	updates := make(map[string]interface{})
 	if req.Title != "" {
 		updates["title"] = strings.TrimSpace(req.Title)
 	}
-	if req.Content != "" {
-		updates["content"] = strings.TrimSpace(req.Content)
+	if req.Content != "" || req.ContentFormat != "" {
+		// 只修改格式时按新格式重新渲染原文
+		content, format := strings.TrimSpace(req.Content), req.ContentFormat
+		if content == "" {
+			content = post.Content
+		}
+		if format == "" {
+			format = post.ContentFormat
+		}
+		rendered, err := renderArticleContent(content, format)
+		if err != nil {
+			return nil, err
+		}
+		updates["content"] = content
+		updates["content_format"] = format
+		updates["content_html"] = rendered.HTML
 	}
 	if req.Topic != "" {
 		updates["topic"] = req.Topic
 	}
-	if req.Status != nil {
+	if req.Status != nil && *req.Status != post.Status {
+		// 悬赏托管期间不能下线问题，否则回答者无法获得悬赏；删除请走删除接口以退还悬赏
+		if post.BountyStatus == models.BountyStatusOpen {
+			return nil, errors.New("悬赏中的问题不能修改状态")
+		}
 		updates["status"] = *req.Status
 	}
 
@@ -318,16 +440,47 @@ func (s *ForumService) UpdatePost(id uint, req *models.ForumPostUpdateRequest, u
 		return &post, nil
 	}
 
+	_, titleChanged := updates["title"]
+	_, contentChanged := updates["content"]
+	// 悬赏中的问题已有回答时不能修改标题和正文：回答针对的是原问题，
+	// 且重新评分转入待审核后到期会按下线退还悬赏，等同于收到回答后撤回悬赏
+	if (titleChanged || contentChanged) && post.BountyStatus == models.BountyStatusOpen && post.ReplyCount > 0 {
+		return nil, errors.New("悬赏中的问题已有回答，无法修改标题或正文")
+	}
+
+	// 修改标题或正文后重新评分，高分帖子转入待审核
+	spamService := NewSpamService(s.db)
+	var spamCheck *models.SpamCheck
+	if titleChanged || contentChanged {
+		title, content, html := post.Title, post.Content, post.ContentHTML
+		if v, ok := updates["title"].(string); ok {
+			title = v
+		}
+		if v, ok := updates["content"].(string); ok {
+			content, html = v, updates["content_html"].(string)
+		}
+		if spamCheck, err = spamService.AssessEdit(userID, models.ReportTargetForumPost, post.ID, title+"\n"+content, html); err != nil {
+			return nil, err
+		}
+		if spamHoldReason(spamCheck) != "" {
+			updates["status"] = models.ForumPostStatusHeld
+		}
+	}
+
 	// 执行更新
 	if err := s.db.Model(&post).Updates(updates).Error; err != nil {
 		return nil, fmt.Errorf("更新帖子失败: %w", err)
 	}
+	spamService.Record(spamCheck, post.ID)
 
 	// 重新加载帖子数据
 	if err := s.db.Preload("Author").First(&post, id).Error; err != nil {
 		return nil, fmt.Errorf("加载更新后的帖子数据失败: %w", err)
 	}
 
+	s.indexPost(&post)
+	fileSensitiveReport(s.db, models.ReportTargetForumPost, post.ID, screen)
+
 	return &post, nil
 }
 
@@ -338,7 +491,9 @@ func (s *ForumService) AdminSetPostTop(id uint, top bool) error {
 
 // AdminSetPostHot 设置帖子热门状态
 func (s *ForumService) AdminSetPostHot(id uint, hot bool) error {
-    return s.db.Model(&models.ForumPost{}).Where("id = ?", id).Update("is_hot", hot).Error
+    // 手动设置后不再由热度排行自动维护
+    return s.db.Model(&models.ForumPost{}).Where("id = ?", id).
+        Updates(map[string]interface{}{"is_hot": hot, "hot_manual": true}).Error
 }
 
 // AdminSetPostLock 设置帖子锁定状态
@@ -349,48 +504,88 @@ func (s *ForumService) AdminSetPostLock(id uint, locked bool) error {
 // AdminDeletePost 管理员删除帖子（软删除）
 func (s *ForumService) AdminDeletePost(id uint) error {
     var post models.ForumPost
-    if err := s.db.Where("id = ?", id).First(&post).Error; err != nil {
-        if errors.Is(err, gorm.ErrRecordNotFound) {
-            return errors.New("帖子不存在")
+    // 删除与退还悬赏在同一事务中完成
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&post).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                return errors.New("帖子不存在")
+            }
+            return fmt.Errorf("查询帖子失败: %w", err)
         }
-        return fmt.Errorf("查询帖子失败: %w", err)
-    }
-    if err := s.db.Delete(&post).Error; err != nil {
-        return fmt.Errorf("删除帖子失败: %w", err)
+        if err := tx.Delete(&post).Error; err != nil {
+            return fmt.Errorf("删除帖子失败: %w", err)
+        }
+        if post.BountyStatus == models.BountyStatusOpen {
+            return s.refundBountyTx(tx, &post, "问题被删除，退还悬赏")
+        }
+        return nil
+    })
+    if err != nil {
+        return err
     }
+    removeSearchDocument(SearchTypeForumPost, post.ID)
     return nil
 }
 
 // DeletePost 删除帖子
 func (s *ForumService) DeletePost(id uint, userID uint) error {
-	// 查找帖子
 	var post models.ForumPost
-	if err := s.db.Where("id = ? AND status = ?", id, 1).First(&post).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("帖子不存在")
+	// 锁定帖子后检查并删除，退还悬赏与删除在同一事务中完成
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 查找帖子
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND status = ?", id, 1).First(&post).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("帖子不存在")
+			}
+			return fmt.Errorf("查询帖子失败: %w", err)
 		}
-		return fmt.Errorf("查询帖子失败: %w", err)
-	}
 
-	// 检查权限：只有作者本人可以删除
-	if post.AuthorID != userID {
-		return errors.New("无权限删除此帖子")
-	}
+		// 检查权限：只有作者本人可以删除
+		if post.AuthorID != userID {
+			return errors.New("无权限删除此帖子")
+		}
+
+		// 悬赏中的问题已有回答时不能删除，避免收到回答后撤回悬赏
+		if post.BountyStatus == models.BountyStatusOpen && post.ReplyCount > 0 {
+			return errors.New("悬赏中的问题已有回答，无法删除")
+		}
 
-	// 软删除帖子
-	if err := s.db.Delete(&post).Error; err != nil {
-		return fmt.Errorf("删除帖子失败: %w", err)
+		// 软删除帖子
+		if err := tx.Delete(&post).Error; err != nil {
+			return fmt.Errorf("删除帖子失败: %w", err)
+		}
+		if post.BountyStatus == models.BountyStatusOpen {
+			return s.refundBountyTx(tx, &post, "问题被删除，退还悬赏")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	removeSearchDocument(SearchTypeForumPost, post.ID)
 	return nil
 }
 
+// indexPost 同步帖子到搜索索引（仅已发布帖子可被检索）
+func (s *ForumService) indexPost(post *models.ForumPost) {
+	indexSearchDocument(&SearchDocument{
+		Type:      SearchTypeForumPost,
+		ID:        post.ID,
+		Title:     post.Title,
+		Content:   post.Content,
+		Visible:   post.Status == 1,
+		CreatedAt: post.CreatedAt,
+	})
+}
+
 // IncrementPostView 增加帖子浏览量
 func (s *ForumService) IncrementPostView(id uint) error {
 	// 增加浏览量
 	if err := s.db.Model(&models.ForumPost{}).Where("id = ?", id).UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error; err != nil {
 		return fmt.Errorf("增加浏览量失败: %w", err)
 	}
+	NewTrendingService(s.db).RecordEvent(TrendingForumPost, id, TrendingEventView)
 
 	return nil
 }
@@ -402,6 +597,11 @@ func (s *ForumService) CreateReply(req *models.ForumReplyCreateRequest, userID u
 		return nil, err
 	}
 
+	// 禁言期间不能发布内容
+	if err := NewModerationService(s.db).EnsureCanPost(userID); err != nil {
+		return nil, err
+	}
+
 	// 验证帖子是否存在
 	var post models.ForumPost
 	if err := s.db.Where("id = ? AND status = ?", req.PostID, 1).First(&post).Error; err != nil {
@@ -427,12 +627,30 @@ func (s *ForumService) CreateReply(req *models.ForumReplyCreateRequest, userID u
 		}
 	}
 
+	// 敏感词检测
+	screen, err := GetSensitiveFilter().Screen(&req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// 渲染并过滤回复内容，默认按 Markdown 处理
+	format := req.ContentFormat
+	if format == "" {
+		format = models.ContentFormatMarkdown
+	}
+	rendered, err := renderCommentContent(strings.TrimSpace(req.Content), format)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建回复
 	reply := &models.ForumReply{
 		PostID:   req.PostID,
 		AuthorID: userID,
 		ParentID: req.ParentID,
 		Content:  strings.TrimSpace(req.Content),
+		ContentFormat: format,
+		ContentHTML: rendered.HTML,
 		Status:   1, // 直接发布
 	}
 
@@ -470,6 +688,9 @@ func (s *ForumService) CreateReply(req *models.ForumReplyCreateRequest, userID u
 		return nil, fmt.Errorf("加载回复数据失败: %w", err)
 	}
 
+	NewTrendingService(s.db).RecordEvent(TrendingForumPost, post.ID, TrendingEventComment)
+	fileSensitiveReport(s.db, models.ReportTargetForumReply, reply.ID, screen)
+
 	// 发送通知给帖子作者（如果不是自己回复自己的帖子）
 	if post.AuthorID != userID {
 		go s.sendReplyNotification(&post, reply, userID)
@@ -542,6 +763,11 @@ func (s *ForumService) DeleteReply(id uint, userID uint) error {
 		return errors.New("无权限删除此回复")
 	}
 
+	// 已被采纳的答案不能删除
+	if reply.IsAccepted {
+		return errors.New("已被采纳的回复无法删除")
+	}
+
 	// 开始事务
 	tx := s.db.Begin()
 	defer func() {
@@ -587,6 +813,12 @@ func (s *ForumService) validateCreatePostRequest(req *models.ForumPostCreateRequ
 	if !models.IsValidTopic(req.Topic) {
 		return errors.New("无效的话题分类")
 	}
+	if req.BountyPoints < 0 {
+		return errors.New("悬赏积分不能为负数")
+	}
+	if req.BountyPoints > 0 && req.PostType != models.ForumPostTypeQuestion {
+		return errors.New("只有问答帖可以设置悬赏")
+	}
 	return nil
 }
 
@@ -618,6 +850,7 @@ func (s *ForumService) buildOrderString(sort string) string {
 		"view_count desc":    "view_count DESC",
 		"last_reply_at desc": "last_reply_at DESC",
 		"like_count desc":    "like_count DESC",
+		"bounty_points desc": "bounty_points DESC",
 	}
 
 	if orderStr, exists := validSorts[strings.ToLower(sort)]; exists {
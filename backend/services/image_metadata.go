@@ -0,0 +1,250 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+)
+
+// exifOrientationTag EXIF 方向标签
+const exifOrientationTag = 0x0112
+
+// jpegOrientation 读取 JPEG 中 EXIF 的方向信息，不存在或无法解析时返回 1（正常方向）
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		if marker == 0xD8 || (marker >= 0xD0 && marker <= 0xD7) || marker == 0x01 || marker == 0xFF {
+			pos++
+			continue
+		}
+		// SOS 之后是图像数据，不再有 EXIF
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+	return 1
+}
+
+// tiffOrientation 从 EXIF 的 TIFF 结构中读取 IFD0 的方向标签
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation 按 EXIF 方向旋转/翻转图片，使其以正常方向显示
+// 去除 EXIF 后方向信息会丢失，必须先把方向"烧"进像素
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // 水平翻转
+				sx, sy = w-1-x, y
+			case 3: // 旋转 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				sx, sy = x, h-1-y
+			case 5: // 沿主对角线翻转
+				sx, sy = y, x
+			case 6: // 顺时针旋转 90°
+				sx, sy = y, h-1-x
+			case 7: // 沿副对角线翻转
+				sx, sy = w-1-y, h-1-x
+			case 8: // 逆时针旋转 90°
+				sx, sy = w-1-y, x
+			}
+			s := src.PixOffset(src.Rect.Min.X+sx, src.Rect.Min.Y+sy)
+			d := dst.PixOffset(x, y)
+			copy(dst.Pix[d:d+4], src.Pix[s:s+4])
+		}
+	}
+	return dst
+}
+
+var errInvalidWebP = errors.New("无效的 WebP 文件")
+
+// stripWebPMetadata 移除 WebP 中的 EXIF 与 XMP 数据块，并返回画布尺寸
+// 标准库无法解码/编码 WebP，因此直接在 RIFF 容器层面处理，图像数据保持不变
+func stripWebPMetadata(data []byte) ([]byte, int, int, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, 0, 0, errInvalidWebP
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+	width, height := 0, 0
+
+	pos := 12
+	for pos+8 <= len(data) {
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size
+		if end > len(data) {
+			return nil, 0, 0, errInvalidWebP
+		}
+		padded := end + size%2
+		if padded > len(data) {
+			padded = len(data)
+		}
+		payload := data[pos+8 : end]
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+			pos = padded
+			continue
+		case "VP8X":
+			if len(payload) >= 10 {
+				width = (int(payload[4]) | int(payload[5])<<8 | int(payload[6])<<16) + 1
+				height = (int(payload[7]) | int(payload[8])<<8 | int(payload[9])<<16) + 1
+			}
+			chunk := append([]byte(nil), data[pos:padded]...)
+			if len(payload) > 0 {
+				chunk[8] &^= 0x08 | 0x04 // 清除 EXIF、XMP 标志位
+			}
+			out = append(out, chunk...)
+			pos = padded
+			continue
+		case "VP8 ":
+			if width == 0 && len(payload) >= 10 && payload[3] == 0x9D && payload[4] == 0x01 && payload[5] == 0x2A {
+				width = int(binary.LittleEndian.Uint16(payload[6:]) & 0x3FFF)
+				height = int(binary.LittleEndian.Uint16(payload[8:]) & 0x3FFF)
+			}
+		case "VP8L":
+			if width == 0 && len(payload) >= 5 && payload[0] == 0x2F {
+				bits := binary.LittleEndian.Uint32(payload[1:])
+				width = int(bits&0x3FFF) + 1
+				height = int(bits>>14&0x3FFF) + 1
+			}
+		}
+		out = append(out, data[pos:padded]...)
+		pos = padded
+	}
+
+	if width == 0 || height == 0 {
+		return nil, 0, 0, errInvalidWebP
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, width, height, nil
+}
+
+var errInvalidGIF = errors.New("无效的 GIF 文件")
+
+// gifFrameStats 只扫描 GIF 的块结构（不解码 LZW 数据），返回图像帧数与各帧像素总数，
+// 用于在 gif.DecodeAll 为每一帧分配内存之前限制帧数和总像素
+func gifFrameStats(data []byte) (int, int64, error) {
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return 0, 0, errInvalidGIF
+	}
+	pos := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		pos += 3 << ((flags & 0x07) + 1)
+	}
+
+	// skipSubBlocks 跳过以 0 长度结尾的数据子块序列
+	skipSubBlocks := func() bool {
+		for pos < len(data) {
+			size := int(data[pos])
+			pos += 1 + size
+			if size == 0 {
+				return pos <= len(data)
+			}
+		}
+		return false
+	}
+
+	frames, pixels := 0, int64(0)
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x21: // 扩展块：标签 + 数据子块
+			pos += 2
+			if !skipSubBlocks() {
+				return 0, 0, errInvalidGIF
+			}
+		case 0x2C: // 图像描述符
+			if pos+10 > len(data) {
+				return 0, 0, errInvalidGIF
+			}
+			width := int64(binary.LittleEndian.Uint16(data[pos+5:]))
+			height := int64(binary.LittleEndian.Uint16(data[pos+7:]))
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << ((flags & 0x07) + 1)
+			}
+			pos++ // LZW 最小码长
+			if !skipSubBlocks() {
+				return 0, 0, errInvalidGIF
+			}
+			frames++
+			pixels += width * height
+		case 0x3B: // 结束标记
+			return frames, pixels, nil
+		default:
+			return 0, 0, errInvalidGIF
+		}
+	}
+	// 缺少结束标记时 gif.DecodeAll 仍会解码已读到的帧，按已扫描的结果计算
+	return frames, pixels, nil
+}
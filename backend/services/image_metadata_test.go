@@ -0,0 +1,51 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeTestGIF 生成指定帧数的 GIF，第 i 帧尺寸为 sizes[i]
+func encodeTestGIF(t *testing.T, sizes ...image.Point) []byte {
+	anim := &gif.GIF{Config: image.Config{Width: 64, Height: 64, ColorModel: color.Palette(palette.Plan9)}}
+	for _, size := range sizes {
+		anim.Image = append(anim.Image, image.NewPaletted(image.Rect(0, 0, size.X, size.Y), palette.Plan9))
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, anim))
+	return buf.Bytes()
+}
+
+// TestGIFFrameStats 测试 GIF 块结构扫描得到的帧数与像素总数
+func TestGIFFrameStats(t *testing.T) {
+	data := encodeTestGIF(t, image.Pt(64, 64), image.Pt(32, 16), image.Pt(8, 8))
+	frames, pixels, err := gifFrameStats(data)
+	require.NoError(t, err)
+	assert.Equal(t, 3, frames)
+	assert.Equal(t, int64(64*64+32*16+8*8), pixels)
+
+	_, _, err = gifFrameStats(data[:len(data)-2])
+	assert.ErrorIs(t, err, errInvalidGIF)
+
+	_, _, err = gifFrameStats([]byte("GIF89a"))
+	assert.ErrorIs(t, err, errInvalidGIF)
+}
+
+// TestProcessGIFRejectsTooManyFrames 测试帧数超过上限的 GIF 在解码前被拒绝
+func TestProcessGIFRejectsTooManyFrames(t *testing.T) {
+	sizes := make([]image.Point, maxGIFFrames+1)
+	for i := range sizes {
+		sizes[i] = image.Pt(1, 1)
+	}
+	p := &ImageProcessor{}
+	_, err := p.Process(encodeTestGIF(t, sizes...))
+	assert.ErrorContains(t, err, "帧数过多")
+}
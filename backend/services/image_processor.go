@@ -0,0 +1,286 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"godad-backend/config"
+)
+
+const (
+	// maxImagePixels 允许解码的最大像素数，防止超大尺寸图片耗尽内存
+	maxImagePixels = 50_000_000
+	// maxGIFFrames GIF 允许的最大帧数
+	maxGIFFrames = 1000
+	// maxGIFTotalPixels GIF 所有帧的像素总数上限，逐帧解码时每个像素占 1 字节
+	maxGIFTotalPixels = 100_000_000
+	// ImageVariantThumbnail 缩略图尺寸名称，聊天图片等场景使用
+	ImageVariantThumbnail = "thumbnail"
+)
+
+// ImageVariantSpec 衍生尺寸定义
+type ImageVariantSpec struct {
+	Name   string
+	Width  int  // 0 表示不限
+	Height int  // 0 表示不限
+	Crop   bool // 居中裁剪为 Width:Height 比例后缩放，需同时指定宽高
+}
+
+// ProcessedImage 处理后的图片
+type ProcessedImage struct {
+	Data     []byte
+	MimeType string
+	Ext      string
+	Width    int
+	Height   int
+}
+
+// ImageProcessResult 图片处理结果
+type ImageProcessResult struct {
+	Original *ProcessedImage
+	// Variants 按 ImageVariantSpec 顺序排列；值为 nil 表示无需生成，直接使用原图
+	Variants []*ProcessedImage
+	// Unavailable 与 Variants 对应，为 true 表示该尺寸无法生成（如需要缩放的 WebP），不应对外提供
+	Unavailable []bool
+}
+
+// ImageProcessor 上传图片处理：按 EXIF 方向校正、去除元数据、限制尺寸、重新编码并生成衍生尺寸
+//
+// JPEG/PNG 会被解码后重新编码（不透明图片输出 JPEG，含透明通道输出 PNG），编码结果不含任何元数据；
+// GIF 逐帧重新编码以保留动画并去掉注释与扩展数据块，尺寸不做缩放，衍生尺寸由第一帧生成；
+// WebP 无法用标准库解码，仅移除 EXIF/XMP 数据块，原图已满足的衍生尺寸直接使用原图，其余尺寸不提供。
+type ImageProcessor struct {
+	variants     []ImageVariantSpec
+	quality      int
+	maxDimension int
+}
+
+// NewImageProcessor 根据配置创建图片处理器
+func NewImageProcessor(cfg config.ImageConfig) (*ImageProcessor, error) {
+	variants, err := ParseImageVariants(cfg.Variants)
+	if err != nil {
+		return nil, err
+	}
+	quality := cfg.JPEGQuality
+	if quality <= 0 || quality > 100 {
+		quality = 85
+	}
+	return &ImageProcessor{
+		variants:     variants,
+		quality:      quality,
+		maxDimension: cfg.MaxDimension,
+	}, nil
+}
+
+// ParseImageVariants 解析衍生尺寸配置，格式 名称:宽x高[:crop]，逗号分隔
+func ParseImageVariants(spec string) ([]ImageVariantSpec, error) {
+	var variants []ImageVariantSpec
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, fmt.Errorf("无效的图片尺寸配置: %s", item)
+		}
+		size := strings.SplitN(strings.ToLower(parts[1]), "x", 2)
+		if len(size) != 2 {
+			return nil, fmt.Errorf("无效的图片尺寸配置: %s", item)
+		}
+		width, errW := strconv.Atoi(size[0])
+		height, errH := strconv.Atoi(size[1])
+		if errW != nil || errH != nil || width < 0 || height < 0 || (width == 0 && height == 0) {
+			return nil, fmt.Errorf("无效的图片尺寸配置: %s", item)
+		}
+
+		variant := ImageVariantSpec{Name: parts[0], Width: width, Height: height}
+		if len(parts) == 3 {
+			if parts[2] != "crop" || width == 0 || height == 0 {
+				return nil, fmt.Errorf("无效的图片尺寸配置: %s", item)
+			}
+			variant.Crop = true
+		}
+		if seen[variant.Name] {
+			return nil, fmt.Errorf("图片尺寸名称重复: %s", variant.Name)
+		}
+		seen[variant.Name] = true
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+// Variants 已配置的衍生尺寸
+func (p *ImageProcessor) Variants() []ImageVariantSpec {
+	return p.variants
+}
+
+// Process 处理上传的图片内容
+func (p *ImageProcessor) Process(data []byte) (*ImageProcessResult, error) {
+	mimeType := http.DetectContentType(data)
+	if mimeType == "image/webp" {
+		return p.processWebP(data)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("无法识别的图片格式: %v", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxImagePixels {
+		return nil, errors.New("图片尺寸过大")
+	}
+
+	if mimeType == "image/gif" {
+		return p.processGIF(data, cfg)
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %v", err)
+	}
+	rgba := toRGBA(img)
+	if format == "jpeg" {
+		rgba = applyOrientation(rgba, jpegOrientation(data))
+	}
+
+	w, h := fitSize(rgba.Rect.Dx(), rgba.Rect.Dy(), p.maxDimension, p.maxDimension)
+	rgba = resizeImage(rgba, w, h)
+
+	original, err := p.encode(rgba)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := p.buildVariants(rgba)
+	if err != nil {
+		return nil, err
+	}
+	return &ImageProcessResult{Original: original, Variants: variants}, nil
+}
+
+// processGIF 逐帧重新编码 GIF（保留动画，去掉注释、XMP 等扩展数据块），衍生尺寸由第一帧生成
+// 解码前先扫描帧数与像素总数，避免体积很小但帧数极多的文件在解码时耗尽内存
+func (p *ImageProcessor) processGIF(data []byte, cfg image.Config) (*ImageProcessResult, error) {
+	frames, pixels, err := gifFrameStats(data)
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %v", err)
+	}
+	if frames > maxGIFFrames {
+		return nil, fmt.Errorf("GIF 帧数过多，最多允许 %d 帧", maxGIFFrames)
+	}
+	if pixels > maxGIFTotalPixels {
+		return nil, errors.New("GIF 动画过大")
+	}
+
+	anim, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %v", err)
+	}
+	if len(anim.Image) == 0 {
+		return nil, errors.New("解码图片失败: GIF 不含图像帧")
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, fmt.Errorf("编码图片失败: %v", err)
+	}
+
+	variants, err := p.buildVariants(toRGBA(anim.Image[0]))
+	if err != nil {
+		return nil, err
+	}
+	return &ImageProcessResult{
+		Original: &ProcessedImage{
+			Data:     buf.Bytes(),
+			MimeType: "image/gif",
+			Ext:      ".gif",
+			Width:    cfg.Width,
+			Height:   cfg.Height,
+		},
+		Variants: variants,
+	}, nil
+}
+
+// processWebP 移除 WebP 元数据；无法缩放，只有原图本身已满足的衍生尺寸才提供（直接使用原图）
+func (p *ImageProcessor) processWebP(data []byte) (*ImageProcessResult, error) {
+	stripped, width, height, err := stripWebPMetadata(data)
+	if err != nil {
+		return nil, err
+	}
+	if width*height > maxImagePixels {
+		return nil, errors.New("图片尺寸过大")
+	}
+	unavailable := make([]bool, len(p.variants))
+	for i, spec := range p.variants {
+		if spec.Crop {
+			unavailable[i] = width*spec.Height != height*spec.Width || width > spec.Width
+			continue
+		}
+		w, h := fitSize(width, height, spec.Width, spec.Height)
+		unavailable[i] = w != width || h != height
+	}
+	return &ImageProcessResult{
+		Original: &ProcessedImage{
+			Data:     stripped,
+			MimeType: "image/webp",
+			Ext:      ".webp",
+			Width:    width,
+			Height:   height,
+		},
+		Variants:    make([]*ProcessedImage, len(p.variants)),
+		Unavailable: unavailable,
+	}, nil
+}
+
+// buildVariants 生成各衍生尺寸；与原图尺寸一致的不重复生成
+func (p *ImageProcessor) buildVariants(base *image.RGBA) ([]*ProcessedImage, error) {
+	variants := make([]*ProcessedImage, len(p.variants))
+	bw, bh := base.Rect.Dx(), base.Rect.Dy()
+
+	for i, spec := range p.variants {
+		src := base
+		if spec.Crop {
+			src = cropCenter(base, spec.Width, spec.Height)
+		}
+		w, h := fitSize(src.Rect.Dx(), src.Rect.Dy(), spec.Width, spec.Height)
+		if w == bw && h == bh {
+			continue
+		}
+
+		encoded, err := p.encode(resizeImage(src, w, h))
+		if err != nil {
+			return nil, err
+		}
+		variants[i] = encoded
+	}
+	return variants, nil
+}
+
+// encode 不透明图片编码为 JPEG，含透明像素的编码为 PNG
+func (p *ImageProcessor) encode(img *image.RGBA) (*ProcessedImage, error) {
+	var buf bytes.Buffer
+	result := &ProcessedImage{Width: img.Rect.Dx(), Height: img.Rect.Dy()}
+
+	if img.Opaque() {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: p.quality}); err != nil {
+			return nil, fmt.Errorf("编码图片失败: %v", err)
+		}
+		result.MimeType, result.Ext = "image/jpeg", ".jpg"
+	} else {
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("编码图片失败: %v", err)
+		}
+		result.MimeType, result.Ext = "image/png", ".png"
+	}
+
+	result.Data = buf.Bytes()
+	return result, nil
+}
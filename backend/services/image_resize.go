@@ -0,0 +1,132 @@
+package services
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// resizeWeight 缩小时目标像素对源像素的覆盖权重
+type resizeWeight struct {
+	index  int
+	weight float32
+}
+
+// toRGBA 转换为预乘 alpha 的 RGBA，便于逐像素处理
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// resizeImage 使用区域平均（box filter）将图片缩小到指定尺寸，只用于缩小
+func resizeImage(src image.Image, width, height int) *image.RGBA {
+	img := toRGBA(src)
+	sw, sh := img.Rect.Dx(), img.Rect.Dy()
+	if width <= 0 || height <= 0 || (width == sw && height == sh) {
+		return img
+	}
+
+	xWeights := resizeWeights(sw, width)
+	yWeights := resizeWeights(sh, height)
+
+	// 水平方向
+	tmp := make([]float32, width*sh*4)
+	for y := 0; y < sh; y++ {
+		row := img.Pix[y*img.Stride:]
+		for x, weights := range xWeights {
+			var r, g, b, a float32
+			for _, w := range weights {
+				p := row[w.index*4:]
+				r += float32(p[0]) * w.weight
+				g += float32(p[1]) * w.weight
+				b += float32(p[2]) * w.weight
+				a += float32(p[3]) * w.weight
+			}
+			o := (y*width + x) * 4
+			tmp[o], tmp[o+1], tmp[o+2], tmp[o+3] = r, g, b, a
+		}
+	}
+
+	// 垂直方向
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y, weights := range yWeights {
+		for x := 0; x < width; x++ {
+			var r, g, b, a float32
+			for _, w := range weights {
+				o := (w.index*width + x) * 4
+				r += tmp[o] * w.weight
+				g += tmp[o+1] * w.weight
+				b += tmp[o+2] * w.weight
+				a += tmp[o+3] * w.weight
+			}
+			p := dst.Pix[y*dst.Stride+x*4:]
+			p[0], p[1], p[2], p[3] = clampUint8(r), clampUint8(g), clampUint8(b), clampUint8(a)
+		}
+	}
+	return dst
+}
+
+// resizeWeights 计算每个目标像素覆盖的源像素区间及权重
+func resizeWeights(srcLen, dstLen int) [][]resizeWeight {
+	scale := float64(srcLen) / float64(dstLen)
+	weights := make([][]resizeWeight, dstLen)
+	for i := range weights {
+		start := float64(i) * scale
+		end := start + scale
+		for j := int(start); j < srcLen && float64(j) < end; j++ {
+			cover := math.Min(end, float64(j+1)) - math.Max(start, float64(j))
+			if cover <= 0 {
+				continue
+			}
+			weights[i] = append(weights[i], resizeWeight{index: j, weight: float32(cover / scale)})
+		}
+	}
+	return weights
+}
+
+func clampUint8(v float32) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
+
+// cropCenter 居中裁剪为指定宽高比，返回裁剪后的子图
+func cropCenter(img *image.RGBA, ratioW, ratioH int) *image.RGBA {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	cw, ch := w, w*ratioH/ratioW
+	if ch > h {
+		cw, ch = h*ratioW/ratioH, h
+	}
+	if cw < 1 {
+		cw = 1
+	}
+	if ch < 1 {
+		ch = 1
+	}
+	x0, y0 := (w-cw)/2, (h-ch)/2
+	return img.SubImage(image.Rect(x0, y0, x0+cw, y0+ch)).(*image.RGBA)
+}
+
+// fitSize 等比缩放到不超过 maxW x maxH（0 表示不限），不放大
+func fitSize(w, h, maxW, maxH int) (int, int) {
+	scale := 1.0
+	if maxW > 0 && w > maxW {
+		scale = math.Min(scale, float64(maxW)/float64(w))
+	}
+	if maxH > 0 && h > maxH {
+		scale = math.Min(scale, float64(maxH)/float64(h))
+	}
+	if scale >= 1 {
+		return w, h
+	}
+	return max(1, int(math.Round(float64(w)*scale))), max(1, int(math.Round(float64(h)*scale)))
+}
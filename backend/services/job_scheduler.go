@@ -0,0 +1,491 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"godad-backend/config"
+	"godad-backend/models"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// jobLockKeyPrefix 任务分布式锁键前缀，同一任务同一时刻只允许一个实例执行
+	jobLockKeyPrefix = "scheduler:lock:"
+	// jobSlotKeyPrefix 定时触发的时间槽键前缀（任务名+计划分钟），同一时间槽只允许一个实例触发
+	jobSlotKeyPrefix = "scheduler:slot:"
+	// jobSlotTTL 时间槽键的过期时间，执行完不释放，需长于各实例间的时钟偏差与任务执行时间
+	jobSlotTTL = time.Hour
+	// defaultJobTimeout 未指定时任务的最长执行时间
+	defaultJobTimeout = 30 * time.Minute
+	// jobMessageMaxLength 执行结果信息的最大长度
+	jobMessageMaxLength = 2000
+)
+
+var (
+	ErrJobNotFound = errors.New("任务不存在")
+	ErrJobRunning  = errors.New("任务正在运行中")
+)
+
+// jobUnlockScript 仅在锁仍由自己持有时释放，避免误删其他实例在锁过期后获取的锁
+var jobUnlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// ScheduledJob 定时任务定义
+type ScheduledJob struct {
+	Name        string
+	Description string
+	Spec        string        // cron 表达式，见 ParseCronSchedule
+	Timeout     time.Duration // 最长执行时间，同时作为分布式锁的过期时间
+	Run         func() (string, error)
+}
+
+// JobInfo 任务状态
+type JobInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Spec        string         `json:"spec"`
+	NextRunAt   *time.Time     `json:"next_run_at"`
+	Running     bool           `json:"running"`
+	LastRun     *models.JobRun `json:"last_run"`
+}
+
+type scheduledEntry struct {
+	job      ScheduledJob
+	schedule *CronSchedule
+	next     time.Time
+	running  bool // 本进程内是否正在执行，受 JobScheduler.mu 保护
+}
+
+// JobScheduler 进程内定时任务调度器
+// 按 cron 表达式触发任务；Redis 可用时通过分布式锁保证多实例部署下同一任务只在一个实例上执行，
+// Redis 不可用时退化为仅本进程内互斥。每次执行都会写入 job_runs 记录。
+type JobScheduler struct {
+	db       *gorm.DB
+	instance string
+
+	mu      sync.Mutex
+	entries map[string]*scheduledEntry
+	order   []string
+
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	startMux sync.Mutex
+	started  bool
+	jobsWG   sync.WaitGroup
+}
+
+var (
+	jobScheduler     *JobScheduler
+	jobSchedulerOnce sync.Once
+)
+
+// GetJobScheduler 获取全局任务调度器（已注册内置维护任务）
+func GetJobScheduler() *JobScheduler {
+	jobSchedulerOnce.Do(func() {
+		jobScheduler = NewJobScheduler(config.GetDB())
+		registerMaintenanceJobs(jobScheduler)
+		registerPublishingJobs(jobScheduler)
+	})
+	return jobScheduler
+}
+
+// NewJobScheduler 创建任务调度器
+func NewJobScheduler(db *gorm.DB) *JobScheduler {
+	hostname, _ := os.Hostname()
+	return &JobScheduler{
+		db:       db,
+		instance: fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+		entries:  make(map[string]*scheduledEntry),
+	}
+}
+
+// Register 注册任务，名称重复或表达式无效时返回错误
+func (s *JobScheduler) Register(job ScheduledJob) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("任务名称和执行函数不能为空")
+	}
+	schedule, err := ParseCronSchedule(job.Spec)
+	if err != nil {
+		return err
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = defaultJobTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.entries[job.Name]; exists {
+		return fmt.Errorf("任务 %s 已注册", job.Name)
+	}
+	s.entries[job.Name] = &scheduledEntry{
+		job:      job,
+		schedule: schedule,
+		next:     schedule.Next(time.Now()),
+	}
+	s.order = append(s.order, job.Name)
+	return nil
+}
+
+// Start 启动调度协程
+func (s *JobScheduler) Start() {
+	s.startMux.Lock()
+	defer s.startMux.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+	s.stopCh = make(chan struct{})
+	s.doneCh = make(chan struct{})
+
+	s.markStaleRuns()
+	go s.loop()
+}
+
+// Stop 停止调度，并等待正在执行的任务结束
+func (s *JobScheduler) Stop() {
+	s.startMux.Lock()
+	defer s.startMux.Unlock()
+
+	if !s.started {
+		return
+	}
+	close(s.stopCh)
+	<-s.doneCh
+	s.jobsWG.Wait()
+	s.started = false
+}
+
+func (s *JobScheduler) loop() {
+	defer close(s.doneCh)
+
+	for {
+		timer := time.NewTimer(time.Until(s.nextWakeup()))
+		select {
+		case now := <-timer.C:
+			s.runDue(now)
+		case <-s.stopCh:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// nextWakeup 最近一次需要触发的时间，没有任务时每分钟检查一次
+func (s *JobScheduler) nextWakeup() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wakeup := time.Now().Add(time.Minute)
+	for _, entry := range s.entries {
+		if !entry.next.IsZero() && entry.next.Before(wakeup) {
+			wakeup = entry.next
+		}
+	}
+	return wakeup
+}
+
+// runDue 触发所有已到期的任务
+func (s *JobScheduler) runDue(now time.Time) {
+	s.mu.Lock()
+	var due []string
+	slots := make(map[string]time.Time)
+	for _, name := range s.order {
+		entry := s.entries[name]
+		if entry.next.IsZero() || entry.next.After(now) {
+			continue
+		}
+		due = append(due, name)
+		slots[name] = entry.next
+		entry.next = entry.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	for _, name := range due {
+		if _, err := s.trigger(name, models.JobTriggerSchedule, nil, slots[name]); err != nil && !errors.Is(err, ErrJobRunning) {
+			log.Printf("触发定时任务 %s 失败: %v", name, err)
+		}
+	}
+}
+
+// RunNow 手动触发任务，立即返回本次执行记录，任务在后台执行
+func (s *JobScheduler) RunNow(name string, operatorID uint) (*models.JobRun, error) {
+	return s.trigger(name, models.JobTriggerManual, &operatorID, time.Time{})
+}
+
+// trigger 获取本地与分布式锁后写入执行记录并在后台执行任务
+// slot 为定时触发的计划时间，同一时间槽在所有实例中只执行一次；手动触发时为零值
+func (s *JobScheduler) trigger(name, trigger string, operatorID *uint, slot time.Time) (*models.JobRun, error) {
+	s.mu.Lock()
+	entry, ok := s.entries[name]
+	if !ok {
+		s.mu.Unlock()
+		return nil, ErrJobNotFound
+	}
+	if entry.running {
+		s.mu.Unlock()
+		return nil, ErrJobRunning
+	}
+	entry.running = true
+	job := entry.job
+	s.mu.Unlock()
+
+	release := func() {
+		s.mu.Lock()
+		entry.running = false
+		s.mu.Unlock()
+	}
+
+	if !slot.IsZero() {
+		if err := s.claimSlot(job, slot); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	token, err := s.acquireLock(job)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	run := &models.JobRun{
+		JobName:     job.Name,
+		Trigger:     trigger,
+		TriggeredBy: operatorID,
+		Instance:    s.instance,
+		Status:      models.JobRunStatusRunning,
+		StartedAt:   time.Now(),
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		s.releaseLock(job, token)
+		release()
+		return nil, fmt.Errorf("记录任务执行失败: %v", err)
+	}
+
+	s.jobsWG.Add(1)
+	go func() {
+		defer s.jobsWG.Done()
+		defer release()
+		defer s.releaseLock(job, token)
+		s.execute(job, run)
+	}()
+
+	snapshot := *run
+	return &snapshot, nil
+}
+
+// execute 执行任务并回写执行结果，任务 panic 时记为失败
+func (s *JobScheduler) execute(job ScheduledJob, run *models.JobRun) {
+	var message string
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+				log.Printf("定时任务 %s panic: %v\n%s", job.Name, r, debug.Stack())
+			}
+		}()
+		message, err = job.Run()
+	}()
+
+	finishedAt := time.Now()
+	status := models.JobRunStatusSuccess
+	if err != nil {
+		status = models.JobRunStatusFailed
+		message = err.Error()
+		log.Printf("定时任务 %s 执行失败: %v", job.Name, err)
+	} else {
+		log.Printf("定时任务 %s 执行完成: %s", job.Name, message)
+	}
+
+	updates := map[string]interface{}{
+		"status":      status,
+		"message":     truncateString(message, jobMessageMaxLength),
+		"finished_at": finishedAt,
+		"duration_ms": finishedAt.Sub(run.StartedAt).Milliseconds(),
+	}
+	if err := s.db.Model(&models.JobRun{}).Where("id = ?", run.ID).Updates(updates).Error; err != nil {
+		log.Printf("更新任务 %s 执行记录失败: %v", job.Name, err)
+	}
+}
+
+// acquireLock 获取任务的分布式锁，已被其他实例持有时返回 ErrJobRunning
+func (s *JobScheduler) acquireLock(job ScheduledJob) (string, error) {
+	if !isCacheReady() {
+		return "", nil
+	}
+	token := uuid.New().String()
+	ok, err := rdb.SetNX(ctx, jobLockKeyPrefix+job.Name, token, job.Timeout).Result()
+	if err != nil {
+		return "", fmt.Errorf("获取任务锁失败: %v", err)
+	}
+	if !ok {
+		return "", ErrJobRunning
+	}
+	return token, nil
+}
+
+// claimSlot 占用定时触发的时间槽，已被其他实例占用时返回 ErrJobRunning
+// 时间槽键不随任务结束释放，只在过期后删除，避免执行较快的实例释放后时钟稍慢的实例再次执行
+func (s *JobScheduler) claimSlot(job ScheduledJob, slot time.Time) error {
+	if !isCacheReady() {
+		return nil
+	}
+	key := fmt.Sprintf("%s%s:%s", jobSlotKeyPrefix, job.Name, slot.UTC().Format("200601021504"))
+	ok, err := rdb.SetNX(ctx, key, s.instance, jobSlotTTL).Result()
+	if err != nil {
+		return fmt.Errorf("获取任务时间槽失败: %v", err)
+	}
+	if !ok {
+		return ErrJobRunning
+	}
+	return nil
+}
+
+func (s *JobScheduler) releaseLock(job ScheduledJob, token string) {
+	if token == "" || !isCacheReady() {
+		return
+	}
+	if err := jobUnlockScript.Run(ctx, rdb, []string{jobLockKeyPrefix + job.Name}, token).Err(); err != nil {
+		log.Printf("释放任务锁 %s 失败: %v", job.Name, err)
+	}
+}
+
+// isLocked 任务是否正由任一实例执行
+func (s *JobScheduler) isLocked(name string) bool {
+	if !isCacheReady() {
+		return false
+	}
+	n, err := rdb.Exists(ctx, jobLockKeyPrefix+name).Result()
+	return err == nil && n > 0
+}
+
+// markStaleRuns 将超过最长执行时间仍处于运行中的记录标记为失败（实例异常退出遗留）
+func (s *JobScheduler) markStaleRuns() {
+	s.mu.Lock()
+	timeouts := make(map[string]time.Duration, len(s.entries))
+	for name, entry := range s.entries {
+		timeouts[name] = entry.job.Timeout
+	}
+	s.mu.Unlock()
+
+	for name, timeout := range timeouts {
+		err := s.db.Model(&models.JobRun{}).
+			Where("job_name = ? AND status = ? AND started_at < ?", name, models.JobRunStatusRunning, time.Now().Add(-timeout)).
+			Updates(map[string]interface{}{
+				"status":  models.JobRunStatusFailed,
+				"message": "执行超时或实例已退出",
+			}).Error
+		if err != nil {
+			log.Printf("清理任务 %s 遗留执行记录失败: %v", name, err)
+		}
+	}
+}
+
+// ListJobs 列出全部任务及最近一次执行结果
+func (s *JobScheduler) ListJobs() ([]JobInfo, error) {
+	s.mu.Lock()
+	infos := make([]JobInfo, 0, len(s.order))
+	for _, name := range s.order {
+		entry := s.entries[name]
+		info := JobInfo{
+			Name:        entry.job.Name,
+			Description: entry.job.Description,
+			Spec:        entry.schedule.String(),
+			Running:     entry.running,
+		}
+		if !entry.next.IsZero() {
+			next := entry.next
+			info.NextRunAt = &next
+		}
+		infos = append(infos, info)
+	}
+	s.mu.Unlock()
+
+	if len(infos) == 0 {
+		return infos, nil
+	}
+
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	var runs []models.JobRun
+	err := s.db.Where("id IN (?)",
+		s.db.Model(&models.JobRun{}).Select("MAX(id)").Where("job_name IN ?", names).Group("job_name"),
+	).Find(&runs).Error
+	if err != nil {
+		return nil, fmt.Errorf("获取任务执行记录失败: %v", err)
+	}
+	lastRuns := make(map[string]models.JobRun, len(runs))
+	for _, run := range runs {
+		lastRuns[run.JobName] = run
+	}
+
+	for i := range infos {
+		if run, ok := lastRuns[infos[i].Name]; ok {
+			infos[i].LastRun = &run
+		}
+		if !infos[i].Running {
+			infos[i].Running = s.isLocked(infos[i].Name)
+		}
+	}
+	return infos, nil
+}
+
+// GetJob 获取单个任务状态
+func (s *JobScheduler) GetJob(name string) (*JobInfo, error) {
+	infos, err := s.ListJobs()
+	if err != nil {
+		return nil, err
+	}
+	for i := range infos {
+		if infos[i].Name == name {
+			return &infos[i], nil
+		}
+	}
+	return nil, ErrJobNotFound
+}
+
+// ListRuns 分页获取任务执行历史（按开始时间倒序）
+func (s *JobScheduler) ListRuns(name string, page, size int) ([]models.JobRun, int64, error) {
+	s.mu.Lock()
+	_, ok := s.entries[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, 0, ErrJobNotFound
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	query := s.db.Model(&models.JobRun{}).Where("job_name = ?", name)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计任务执行记录失败: %v", err)
+	}
+
+	var runs []models.JobRun
+	if err := query.Order("started_at DESC, id DESC").Offset((page - 1) * size).Limit(size).Find(&runs).Error; err != nil {
+		return nil, 0, fmt.Errorf("获取任务执行记录失败: %v", err)
+	}
+	return runs, total, nil
+}
@@ -80,6 +80,9 @@ func (s *LikeService) ToggleLike(userID uint, targetType string, targetID uint)
 		return nil, fmt.Errorf("更新点赞计数失败: %v", err)
 	}
 
+	// 计入热度（评论点赞不参与排行）
+	NewTrendingService(s.db).RecordUserEvent(targetType, targetID, TrendingEventLike, userID)
+
 	// 创建点赞动态和通知（仅对文章点赞）
 	if targetType == "article" {
 		var article models.Article
@@ -212,7 +215,7 @@ func (s *LikeService) updateLikeCount(targetType string, targetID uint, delta in
 			// 清除所有文章列表缓存（使用通配符）
 			s.cacheService.DeletePattern("articles:list:*")
 			// 同时清除搜索结果缓存，因为搜索结果也包含点赞数
-			s.cacheService.DeletePattern("search:*")
+			InvalidateSearchCache()
 		}
 	case "comment":
 		err = s.db.Model(&models.Comment{}).Where("id = ?", targetID).UpdateColumn("like_count", gorm.Expr("like_count + ?", delta)).Error
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"godad-backend/config"
+)
+
+// jobRunRetention 任务执行记录保留时长
+const jobRunRetention = 30 * 24 * time.Hour
+
+// spamCheckRetention 未被拦截的垃圾内容评分记录保留时长
+const spamCheckRetention = 30 * 24 * time.Hour
+
+// registerMaintenanceJobs 注册内置维护任务
+// 孤儿评论修复排在评论计数修复之前，保证计数基于修复后的层级关系
+func registerMaintenanceJobs(s *JobScheduler) {
+	db := config.GetDB()
+	maintenance := NewMaintenanceService(db)
+	notificationService := NewNotificationService(db)
+	trendingService := NewTrendingService(db)
+	relatedService := NewRelatedArticleService(db)
+	recommendationService := NewRecommendationService(db)
+	spamService := NewSpamService(db)
+	forumService := NewForumService()
+
+	jobs := []ScheduledJob{
+		{
+			Name:        "notification_cleanup",
+			Description: "清理 30 天前的已读通知",
+			Spec:        "30 3 * * *",
+			Timeout:     10 * time.Minute,
+			Run: func() (string, error) {
+				count, err := notificationService.CleanupOldNotifications()
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("清理了 %d 条已读通知", count), nil
+			},
+		},
+		{
+			Name:        "orphan_comment_repair",
+			Description: "将父评论已删除的回复提升为主评论",
+			Spec:        "0 4 * * *",
+			Timeout:     10 * time.Minute,
+			Run:         maintenance.RepairOrphanComments,
+		},
+		{
+			Name:        "comment_count_repair",
+			Description: "按实际评论重新计算文章评论数与评论回复数",
+			Spec:        "10 4 * * *",
+			Timeout:     20 * time.Minute,
+			Run:         maintenance.RepairCommentCounts,
+		},
+		{
+			Name:        "like_count_repair",
+			Description: "按点赞记录重新计算文章、评论与帖子的点赞数",
+			Spec:        "20 4 * * *",
+			Timeout:     20 * time.Minute,
+			Run:         maintenance.RepairLikeCounts,
+		},
+		{
+			Name:        "tag_count_repair",
+			Description: "按已发布文章与关注记录重新计算标签计数",
+			Spec:        "30 4 * * *",
+			Timeout:     10 * time.Minute,
+			Run:         maintenance.RepairTagCounts,
+		},
+		{
+			Name:        "trending_refresh",
+			Description: "维护热度排行并按阈值更新帖子热门标记",
+			Spec:        "*/10 * * * *",
+			Timeout:     5 * time.Minute,
+			Run:         trendingService.Refresh,
+		},
+		{
+			Name:        "related_article_rebuild",
+			Description: "全量重新计算文章词向量与相似文章",
+			Spec:        "40 4 * * *",
+			Timeout:     30 * time.Minute,
+			Run:         relatedService.RebuildAll,
+		},
+		{
+			Name:        "recommendation_neighbor_rebuild",
+			Description: "根据点赞与收藏重新计算协同过滤相似文章",
+			Spec:        "50 4 * * *",
+			Timeout:     30 * time.Minute,
+			Run:         recommendationService.RebuildNeighbors,
+		},
+		{
+			Name:        "content_render_backfill",
+			Description: "为历史文章、帖子、回复和评论补全过滤后的 HTML",
+			Spec:        "45 * * * *",
+			Timeout:     30 * time.Minute,
+			Run:         maintenance.RenderMissingContent,
+		},
+		{
+			Name:        "question_bounty_settle",
+			Description: "结算到期未采纳的问答悬赏：发放给最高赞回答或退还提问者",
+			Spec:        "5 * * * *",
+			Timeout:     10 * time.Minute,
+			Run:         forumService.SettleExpiredBounties,
+		},
+		{
+			Name:        "job_run_cleanup",
+			Description: "清理 30 天前的任务执行记录",
+			Spec:        "0 5 * * 0",
+			Timeout:     10 * time.Minute,
+			Run: func() (string, error) {
+				return maintenance.CleanupJobRuns(jobRunRetention)
+			},
+		},
+		{
+			Name:        "spam_check_cleanup",
+			Description: "清理 30 天前未被拦截的垃圾内容评分记录",
+			Spec:        "15 5 * * *",
+			Timeout:     10 * time.Minute,
+			Run: func() (string, error) {
+				count, err := spamService.CleanupChecks(spamCheckRetention)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("清理了 %d 条评分记录", count), nil
+			},
+		},
+	}
+
+	for _, job := range jobs {
+		if err := s.Register(job); err != nil {
+			log.Printf("注册定时任务 %s 失败: %v", job.Name, err)
+		}
+	}
+}
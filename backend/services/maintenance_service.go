@@ -0,0 +1,267 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"time"
+
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// MaintenanceService 数据维护任务（计数修复、孤儿数据清理等），由调度器定期执行
+type MaintenanceService struct {
+	db           *gorm.DB
+	cacheService *CacheService
+}
+
+// NewMaintenanceService 创建数据维护服务
+func NewMaintenanceService(db *gorm.DB) *MaintenanceService {
+	return &MaintenanceService{
+		db:           db,
+		cacheService: NewCacheService(),
+	}
+}
+
+// RepairOrphanComments 将父评论已不存在或已删除的回复提升为主评论
+// 被隐藏、待审核的父评论仍可能恢复，不在处理范围内
+func (s *MaintenanceService) RepairOrphanComments() (string, error) {
+	result := s.db.Exec(`
+		UPDATE comments c
+		LEFT JOIN comments p ON p.id = c.parent_id
+		SET c.parent_id = NULL
+		WHERE c.deleted_at IS NULL
+		AND c.parent_id IS NOT NULL AND c.parent_id > 0
+		AND (p.id IS NULL OR p.deleted_at IS NOT NULL OR p.status = ?)
+	`, models.CommentStatusDeleted)
+	if result.Error != nil {
+		return "", fmt.Errorf("修复孤儿评论失败: %v", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		s.cacheService.DeletePattern("comments:article:*")
+	}
+	return fmt.Sprintf("提升了 %d 条孤儿回复为主评论", result.RowsAffected), nil
+}
+
+// RepairCommentCounts 按正常状态的评论重新计算文章评论数与评论回复数
+func (s *MaintenanceService) RepairCommentCounts() (string, error) {
+	articles := s.db.Exec(`
+		UPDATE articles a
+		LEFT JOIN (
+			SELECT article_id, COUNT(*) AS cnt
+			FROM comments
+			WHERE status = ? AND deleted_at IS NULL
+			GROUP BY article_id
+		) c ON c.article_id = a.id
+		SET a.comment_count = COALESCE(c.cnt, 0)
+		WHERE a.comment_count <> COALESCE(c.cnt, 0)
+	`, models.CommentStatusNormal)
+	if articles.Error != nil {
+		return "", fmt.Errorf("修复文章评论数失败: %v", articles.Error)
+	}
+
+	// reply_count 为历史库中的列，模型未声明，不存在时跳过
+	var replies int64
+	if s.db.Migrator().HasColumn(&models.Comment{}, "reply_count") {
+		result := s.db.Exec(`
+			UPDATE comments p
+			LEFT JOIN (
+				SELECT parent_id, COUNT(*) AS cnt
+				FROM comments
+				WHERE parent_id IS NOT NULL AND parent_id > 0
+				AND status = ? AND deleted_at IS NULL
+				GROUP BY parent_id
+			) r ON r.parent_id = p.id
+			SET p.reply_count = COALESCE(r.cnt, 0)
+			WHERE p.deleted_at IS NULL AND p.reply_count <> COALESCE(r.cnt, 0)
+		`, models.CommentStatusNormal)
+		if result.Error != nil {
+			return "", fmt.Errorf("修复评论回复数失败: %v", result.Error)
+		}
+		replies = result.RowsAffected
+	}
+
+	if articles.RowsAffected > 0 {
+		s.invalidateArticleCaches()
+	}
+	if replies > 0 {
+		s.cacheService.DeletePattern("comments:article:*")
+	}
+	return fmt.Sprintf("修复了 %d 篇文章的评论数、%d 条评论的回复数", articles.RowsAffected, replies), nil
+}
+
+// RepairLikeCounts 按点赞记录重新计算文章、评论与论坛帖子的点赞数
+func (s *MaintenanceService) RepairLikeCounts() (string, error) {
+	targets := []struct {
+		targetType string
+		table      string
+	}{
+		{"article", "articles"},
+		{"comment", "comments"},
+		{"forum_post", "forum_posts"},
+	}
+
+	fixed := make(map[string]int64, len(targets))
+	for _, target := range targets {
+		result := s.db.Exec(fmt.Sprintf(`
+			UPDATE %s t
+			LEFT JOIN (
+				SELECT target_id, COUNT(*) AS cnt
+				FROM likes
+				WHERE target_type = ?
+				GROUP BY target_id
+			) l ON l.target_id = t.id
+			SET t.like_count = COALESCE(l.cnt, 0)
+			WHERE t.like_count <> COALESCE(l.cnt, 0)
+		`, target.table), target.targetType)
+		if result.Error != nil {
+			return "", fmt.Errorf("修复 %s 点赞数失败: %v", target.table, result.Error)
+		}
+		fixed[target.targetType] = result.RowsAffected
+	}
+
+	if fixed["article"] > 0 {
+		s.invalidateArticleCaches()
+	}
+	if fixed["comment"] > 0 {
+		s.cacheService.DeletePattern("comments:article:*")
+	}
+	if fixed["forum_post"] > 0 {
+		s.cacheService.DeletePattern("forum_post:*")
+		s.cacheService.DeletePattern("forum:posts:*")
+	}
+	return fmt.Sprintf("修复了 %d 篇文章、%d 条评论、%d 个帖子的点赞数",
+		fixed["article"], fixed["comment"], fixed["forum_post"]), nil
+}
+
+// RepairTagCounts 按已发布文章与关注记录重新计算标签的文章数与关注数
+// 文章被审核下架等不经过 ArticleService 的状态变化会让文章数偏离
+func (s *MaintenanceService) RepairTagCounts() (string, error) {
+	articles := s.db.Exec(`
+		UPDATE tags t
+		LEFT JOIN (
+			SELECT at.tag_id, COUNT(*) AS cnt
+			FROM article_tags at
+			JOIN articles a ON a.id = at.article_id
+			WHERE a.status = ? AND a.deleted_at IS NULL
+			GROUP BY at.tag_id
+		) c ON c.tag_id = t.id
+		SET t.article_count = COALESCE(c.cnt, 0)
+		WHERE t.article_count <> COALESCE(c.cnt, 0)
+	`, models.ArticleStatusPublished)
+	if articles.Error != nil {
+		return "", fmt.Errorf("修复标签文章数失败: %v", articles.Error)
+	}
+
+	followers := s.db.Exec(`
+		UPDATE tags t
+		LEFT JOIN (
+			SELECT tag_id, COUNT(*) AS cnt FROM tag_follows GROUP BY tag_id
+		) f ON f.tag_id = t.id
+		SET t.follower_count = COALESCE(f.cnt, 0)
+		WHERE t.follower_count <> COALESCE(f.cnt, 0)
+	`)
+	if followers.Error != nil {
+		return "", fmt.Errorf("修复标签关注数失败: %v", followers.Error)
+	}
+	return fmt.Sprintf("修复了 %d 个标签的文章数、%d 个标签的关注数", articles.RowsAffected, followers.RowsAffected), nil
+}
+
+// contentRenderBatchSize 补全渲染结果时每批处理的记录数
+const contentRenderBatchSize = 200
+
+// contentRenderTargets 需要补全渲染结果的表及对应的过滤策略
+var contentRenderTargets = []struct {
+	table  string
+	policy *contentPolicy
+}{
+	{"articles", articleContentPolicy},
+	{"forum_posts", articleContentPolicy},
+	{"forum_replies", commentContentPolicy},
+	{"comments", commentContentPolicy},
+}
+
+// RenderMissingContent 为内容管道上线前保存的文章、帖子、回复和评论补全过滤后的 HTML
+// 只处理 content_html 为空的记录，更新时再次判断，不会覆盖期间被用户编辑过的内容
+func (s *MaintenanceService) RenderMissingContent() (string, error) {
+	counts := make(map[string]int64, len(contentRenderTargets))
+	for _, target := range contentRenderTargets {
+		var lastID uint
+		for {
+			var rows []struct {
+				ID            uint
+				Content       string
+				ContentFormat string
+			}
+			if err := s.db.Table(target.table).Select("id, content, content_format").
+				Where("id > ? AND content_html IS NULL AND deleted_at IS NULL", lastID).
+				Order("id").Limit(contentRenderBatchSize).
+				Scan(&rows).Error; err != nil {
+				return "", fmt.Errorf("查询待渲染内容失败 (%s): %v", target.table, err)
+			}
+			if len(rows) == 0 {
+				break
+			}
+
+			for _, row := range rows {
+				lastID = row.ID
+				rendered, err := renderContent(row.Content, row.ContentFormat, target.policy)
+				if err != nil {
+					// 无法渲染的历史内容按纯文本保存，避免每次任务都重复处理
+					rendered = &RenderedContent{HTML: "<p>" + html.EscapeString(row.Content) + "</p>"}
+				}
+				columns := map[string]interface{}{"content_html": rendered.HTML}
+				if target.table == "articles" {
+					images, _ := json.Marshal(rendered.Images)
+					toc, _ := json.Marshal(rendered.TOC)
+					columns["content_images"] = string(images)
+					columns["toc"] = string(toc)
+				}
+				result := s.db.Table(target.table).
+					Where("id = ? AND content_html IS NULL", row.ID).
+					UpdateColumns(columns)
+				if result.Error != nil {
+					return "", fmt.Errorf("保存渲染结果失败 (%s %d): %v", target.table, row.ID, result.Error)
+				}
+				counts[target.table] += result.RowsAffected
+			}
+		}
+	}
+
+	if counts["articles"] > 0 {
+		s.invalidateArticleCaches()
+	}
+	return fmt.Sprintf("补全了 %d 篇文章、%d 个帖子、%d 条回复、%d 条评论的渲染结果",
+		counts["articles"], counts["forum_posts"], counts["forum_replies"], counts["comments"]), nil
+}
+
+// CleanupJobRuns 清理超过保留期的任务执行记录
+func (s *MaintenanceService) CleanupJobRuns(retention time.Duration) (string, error) {
+	result := s.db.Where("started_at < ? AND status <> ?", time.Now().Add(-retention), models.JobRunStatusRunning).
+		Delete(&models.JobRun{})
+	if result.Error != nil {
+		return "", fmt.Errorf("清理任务执行记录失败: %v", result.Error)
+	}
+	return fmt.Sprintf("清理了 %d 条任务执行记录", result.RowsAffected), nil
+}
+
+// articleDetailCachePattern 只匹配 article:<id> 详情缓存，不能误删 article:views:* 中尚未回写的浏览量
+// 以及 article:related:* 相似文章缓存
+const articleDetailCachePattern = "article:[0-9]*"
+
+// invalidateArticleCaches 计数变化后清除文章详情、列表与搜索缓存
+func (s *MaintenanceService) invalidateArticleCaches() {
+	for _, pattern := range []string{articleDetailCachePattern, "articles:*"} {
+		if err := s.cacheService.DeletePattern(pattern); err != nil && !errors.Is(err, ErrCacheDisabled) {
+			log.Printf("清除缓存 %s 失败: %v", pattern, err)
+		}
+	}
+	if err := InvalidateSearchCache(); err != nil && !errors.Is(err, ErrCacheDisabled) {
+		log.Printf("清除搜索缓存失败: %v", err)
+	}
+}
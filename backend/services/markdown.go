@@ -0,0 +1,680 @@
+package services
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// markdownMaxDepth 引用与列表允许的最大嵌套层数，超过后按普通段落处理
+const markdownMaxDepth = 16
+
+// markdownLinkLabelLimit 链接文字、地址与标题的最大查找长度，避免异常输入导致大量回溯
+const markdownLinkLabelLimit = 1000
+
+// renderMarkdown 将 Markdown 转换为 HTML
+// 支持标题、段落、引用、有序/无序列表、代码块、分割线、GFM 表格以及强调、删除线、
+// 行内代码、链接、图片和自动链接；段落内的换行保留为 <br>。
+// 内嵌的 HTML 按普通文本转义，输出结果仍需经过 renderContent 的白名单过滤
+func renderMarkdown(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	source = strings.ReplaceAll(source, "\t", "    ")
+
+	var b strings.Builder
+	renderMarkdownBlocks(&b, strings.Split(source, "\n"), 0, false)
+	return b.String()
+}
+
+// renderMarkdownBlocks 渲染块级元素，tight 为 true 时段落不包裹 <p>（紧凑列表项）
+func renderMarkdownBlocks(b *strings.Builder, lines []string, depth int, tight bool) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			i++
+			continue
+		}
+		t := trimIndent(line, 3)
+
+		switch {
+		case isFenceStart(t):
+			i = renderFencedCode(b, lines, i)
+		case markdownHeadingLevel(t) > 0:
+			level := markdownHeadingLevel(t)
+			text := strings.TrimSpace(t[level:])
+			text = strings.TrimSpace(strings.TrimRight(text, "#"))
+			tag := "h" + strconv.Itoa(level)
+			b.WriteString("<" + tag + ">" + renderInline(text) + "</" + tag + ">\n")
+			i++
+		case isThematicBreak(t):
+			b.WriteString("<hr>\n")
+			i++
+		case strings.HasPrefix(t, ">") && depth < markdownMaxDepth:
+			var quoted []string
+			for ; i < len(lines); i++ {
+				q := trimIndent(lines[i], 3)
+				if !strings.HasPrefix(q, ">") {
+					break
+				}
+				q = strings.TrimPrefix(q, ">")
+				q = strings.TrimPrefix(q, " ")
+				quoted = append(quoted, q)
+			}
+			b.WriteString("<blockquote>\n")
+			renderMarkdownBlocks(b, quoted, depth+1, false)
+			b.WriteString("</blockquote>\n")
+		case depth < markdownMaxDepth && isListItem(t):
+			i = renderList(b, lines, i, depth)
+		case i+1 < len(lines) && strings.Contains(t, "|") && isTableSeparator(lines[i+1]):
+			i = renderTable(b, lines, i)
+		default:
+			i = renderParagraph(b, lines, i, tight)
+		}
+	}
+}
+
+// renderFencedCode 渲染 ``` 或 ~~~ 包围的代码块，返回下一行的下标
+func renderFencedCode(b *strings.Builder, lines []string, start int) int {
+	t := trimIndent(lines[start], 3)
+	fence := t[0]
+	n := 0
+	for n < len(t) && t[n] == fence {
+		n++
+	}
+	lang := ""
+	if fields := strings.Fields(t[n:]); len(fields) > 0 {
+		lang = fields[0]
+	}
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		c := strings.TrimSpace(lines[i])
+		if len(c) >= n && strings.Trim(c, string(fence)) == "" {
+			i++
+			break
+		}
+		code = append(code, lines[i])
+	}
+
+	b.WriteString("<pre><code")
+	if lang != "" {
+		b.WriteString(` class="language-` + html.EscapeString(lang) + `"`)
+	}
+	b.WriteString(">")
+	if len(code) > 0 {
+		b.WriteString(html.EscapeString(strings.Join(code, "\n")) + "\n")
+	}
+	b.WriteString("</code></pre>\n")
+	return i
+}
+
+// renderParagraph 渲染段落（含 Setext 风格标题），返回下一行的下标
+func renderParagraph(b *strings.Builder, lines []string, start int, tight bool) int {
+	var para []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+		t := trimIndent(line, 3)
+		if len(para) > 0 {
+			if level := setextLevel(t); level > 0 {
+				tag := "h" + strconv.Itoa(level)
+				b.WriteString("<" + tag + ">" + renderInline(strings.TrimSpace(strings.Join(para, " "))) + "</" + tag + ">\n")
+				return i + 1
+			}
+			if isFenceStart(t) || markdownHeadingLevel(t) > 0 || isThematicBreak(t) ||
+				strings.HasPrefix(t, ">") || isListItem(t) {
+				break
+			}
+		}
+		para = append(para, line)
+	}
+
+	rendered := make([]string, len(para))
+	for k, line := range para {
+		line = strings.TrimLeft(line, " ")
+		line = strings.TrimSuffix(strings.TrimRight(line, " "), "\\")
+		rendered[k] = renderInline(line)
+	}
+	content := strings.Join(rendered, "<br>\n")
+	if tight {
+		b.WriteString(content + "\n")
+	} else {
+		b.WriteString("<p>" + content + "</p>\n")
+	}
+	return i
+}
+
+// listMarker 解析列表项标记，返回是否有序、起始序号与正文开始的位置
+func listMarker(t string) (ordered bool, number int, offset int, ok bool) {
+	if len(t) >= 2 && (t[0] == '-' || t[0] == '*' || t[0] == '+') && t[1] == ' ' {
+		return false, 0, 2, true
+	}
+	if len(t) == 1 && (t[0] == '-' || t[0] == '*' || t[0] == '+') {
+		return false, 0, 1, true
+	}
+	n := 0
+	for n < len(t) && n < 9 && t[n] >= '0' && t[n] <= '9' {
+		n++
+	}
+	if n == 0 || n >= len(t) || (t[n] != '.' && t[n] != ')') {
+		return false, 0, 0, false
+	}
+	if n+1 < len(t) && t[n+1] != ' ' {
+		return false, 0, 0, false
+	}
+	number, _ = strconv.Atoi(t[:n])
+	return true, number, min(n+2, len(t)), true
+}
+
+func isListItem(t string) bool {
+	_, _, _, ok := listMarker(t)
+	return ok && !isThematicBreak(t)
+}
+
+// renderList 渲染连续的同类列表项，返回下一行的下标
+func renderList(b *strings.Builder, lines []string, start, depth int) int {
+	indent := len(lines[start]) - len(strings.TrimLeft(lines[start], " "))
+	ordered, number, _, _ := listMarker(trimIndent(lines[start], 3))
+
+	var items [][]string
+	loose := false
+	i := start
+	for i < len(lines) {
+		line := lines[i]
+		lineIndent := len(line) - len(strings.TrimLeft(line, " "))
+		t := strings.TrimLeft(line, " ")
+		o, _, offset, ok := listMarker(t)
+		if !ok || o != ordered || lineIndent > indent+1 || lineIndent+1 < indent || isThematicBreak(t) {
+			break
+		}
+		contentIndent := lineIndent + offset
+		item := []string{t[offset:]}
+		i++
+
+		// 收集属于该列表项的后续行：缩进到正文位置的行、懒惰续行，以及其间的空行
+		for i < len(lines) {
+			next := lines[i]
+			if strings.TrimSpace(next) == "" {
+				j := i
+				for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+					j++
+				}
+				if j < len(lines) && len(lines[j])-len(strings.TrimLeft(lines[j], " ")) >= contentIndent {
+					for ; i < j; i++ {
+						item = append(item, "")
+					}
+					loose = true
+					continue
+				}
+				if j < len(lines) {
+					if o2, _, _, ok2 := listMarker(strings.TrimLeft(lines[j], " ")); ok2 && o2 == ordered {
+						loose = true
+					}
+				}
+				break
+			}
+			nextIndent := len(next) - len(strings.TrimLeft(next, " "))
+			nt := strings.TrimLeft(next, " ")
+			if nextIndent >= contentIndent {
+				item = append(item, next[contentIndent:])
+				i++
+				continue
+			}
+			if nextIndent >= 2 && nextIndent > indent {
+				item = append(item, nt)
+				i++
+				continue
+			}
+			if isListItem(nt) || isFenceStart(nt) || markdownHeadingLevel(nt) > 0 ||
+				isThematicBreak(nt) || strings.HasPrefix(nt, ">") {
+				break
+			}
+			item = append(item, nt)
+			i++
+		}
+		items = append(items, item)
+
+		// 跳过列表项之间的空行
+		j := i
+		for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+			j++
+		}
+		if j == i || j >= len(lines) {
+			continue
+		}
+		if o2, _, _, ok2 := listMarker(strings.TrimLeft(lines[j], " ")); !ok2 || o2 != ordered {
+			break
+		}
+		i = j
+	}
+
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	b.WriteString("<" + tag)
+	if ordered && number != 1 {
+		b.WriteString(` start="` + strconv.Itoa(number) + `"`)
+	}
+	b.WriteString(">\n")
+	for _, item := range items {
+		b.WriteString("<li>")
+		renderMarkdownBlocks(b, item, depth+1, !loose)
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// renderTable 渲染 GFM 表格，返回下一行的下标
+func renderTable(b *strings.Builder, lines []string, start int) int {
+	header := splitTableRow(lines[start])
+	aligns := splitTableRow(lines[start+1])
+	for k, a := range aligns {
+		a = strings.TrimSpace(a)
+		switch {
+		case strings.HasPrefix(a, ":") && strings.HasSuffix(a, ":"):
+			aligns[k] = "center"
+		case strings.HasSuffix(a, ":"):
+			aligns[k] = "right"
+		case strings.HasPrefix(a, ":"):
+			aligns[k] = "left"
+		default:
+			aligns[k] = ""
+		}
+	}
+
+	writeRow := func(cells []string, cellTag string) {
+		b.WriteString("<tr>")
+		for k := range header {
+			cell := ""
+			if k < len(cells) {
+				cell = cells[k]
+			}
+			b.WriteString("<" + cellTag)
+			if k < len(aligns) && aligns[k] != "" {
+				b.WriteString(` align="` + aligns[k] + `"`)
+			}
+			b.WriteString(">" + renderInline(strings.TrimSpace(cell)) + "</" + cellTag + ">")
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	writeRow(header, "th")
+	b.WriteString("</thead>\n<tbody>\n")
+	i := start + 2
+	for ; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" || !strings.Contains(lines[i], "|") {
+			break
+		}
+		writeRow(splitTableRow(lines[i]), "td")
+	}
+	b.WriteString("</tbody>\n</table>\n")
+	return i
+}
+
+// splitTableRow 按未转义的 | 拆分表格行
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for k := 0; k < len(line); k++ {
+		if line[k] == '\\' && k+1 < len(line) && line[k+1] == '|' {
+			cell.WriteByte('|')
+			k++
+			continue
+		}
+		if line[k] == '|' {
+			cells = append(cells, cell.String())
+			cell.Reset()
+			continue
+		}
+		cell.WriteByte(line[k])
+	}
+	return append(cells, cell.String())
+}
+
+func isTableSeparator(line string) bool {
+	cells := splitTableRow(line)
+	if len(cells) == 0 {
+		return false
+	}
+	for _, c := range cells {
+		c = strings.TrimSpace(c)
+		c = strings.TrimPrefix(c, ":")
+		c = strings.TrimSuffix(c, ":")
+		if c == "" || strings.Trim(c, "-") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func isFenceStart(t string) bool {
+	return strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~")
+}
+
+// markdownHeadingLevel 返回 ATX 标题的级别，不是标题时返回 0
+func markdownHeadingLevel(t string) int {
+	n := 0
+	for n < len(t) && t[n] == '#' {
+		n++
+	}
+	if n == 0 || n > 6 || (n < len(t) && t[n] != ' ') {
+		return 0
+	}
+	return n
+}
+
+// setextLevel 判断段落下方的 === / --- 标题下划线
+func setextLevel(t string) int {
+	t = strings.TrimSpace(t)
+	switch {
+	case t == "":
+		return 0
+	case strings.Trim(t, "=") == "":
+		return 1
+	case strings.Trim(t, "-") == "":
+		return 2
+	}
+	return 0
+}
+
+func isThematicBreak(t string) bool {
+	t = strings.ReplaceAll(strings.TrimSpace(t), " ", "")
+	if len(t) < 3 {
+		return false
+	}
+	return strings.Trim(t, "-") == "" || strings.Trim(t, "*") == "" || strings.Trim(t, "_") == ""
+}
+
+// trimIndent 去掉最多 n 个前导空格
+func trimIndent(line string, n int) string {
+	k := 0
+	for k < len(line) && k < n && line[k] == ' ' {
+		k++
+	}
+	return line[k:]
+}
+
+// renderInline 渲染行内元素
+func renderInline(text string) string {
+	r := &inlineRenderer{text: text, noClose: make(map[string]bool)}
+	return r.render()
+}
+
+// inlineRenderer 行内渲染状态，noClose 记录已确认找不到结束标记的分隔符，避免重复扫描
+type inlineRenderer struct {
+	text    string
+	noClose map[string]bool
+	out     strings.Builder
+}
+
+func (r *inlineRenderer) render() string {
+	s := r.text
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			r.out.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+		case c == '`':
+			i = r.codeSpan(i)
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if label, dest, title, end, ok := parseMarkdownLink(s, i+1); ok {
+				r.out.WriteString(`<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(label) + `"`)
+				if title != "" {
+					r.out.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				r.out.WriteString(">")
+				i = end
+			} else {
+				r.out.WriteByte('!')
+				i++
+			}
+		case c == '[':
+			if label, dest, title, end, ok := parseMarkdownLink(s, i); ok {
+				r.out.WriteString(`<a href="` + html.EscapeString(dest) + `"`)
+				if title != "" {
+					r.out.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				r.out.WriteString(">" + renderInline(label) + "</a>")
+				i = end
+			} else {
+				r.out.WriteString("[")
+				i++
+			}
+		case c == '<':
+			i = r.autolink(i)
+		case c == '*' || c == '_' || c == '~':
+			i = r.emphasis(i)
+		case (c == 'h' || c == 'H') && (i == 0 || !isWordByte(s[i-1])) && hasURLPrefix(s[i:]):
+			i = r.bareURL(i)
+		default:
+			// 按字节转义，多字节字符原样输出
+			switch c {
+			case '&':
+				r.out.WriteString("&amp;")
+			case '>':
+				r.out.WriteString("&gt;")
+			case '"':
+				r.out.WriteString("&#34;")
+			case '\'':
+				r.out.WriteString("&#39;")
+			default:
+				r.out.WriteByte(c)
+			}
+			i++
+		}
+	}
+	return r.out.String()
+}
+
+// codeSpan 渲染行内代码，找不到等长的结束反引号时按普通文本输出
+func (r *inlineRenderer) codeSpan(i int) int {
+	s := r.text
+	n := 0
+	for i+n < len(s) && s[i+n] == '`' {
+		n++
+	}
+	ticks := s[i : i+n]
+	if !r.noClose[ticks] {
+		for j := i + n; j < len(s); {
+			k := strings.Index(s[j:], ticks)
+			if k < 0 {
+				break
+			}
+			j += k
+			m := 0
+			for j+m < len(s) && s[j+m] == '`' {
+				m++
+			}
+			if m == n {
+				code := s[i+n : j]
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				r.out.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				return j + n
+			}
+			j += m
+		}
+		r.noClose[ticks] = true
+	}
+	r.out.WriteString(ticks)
+	return i + n
+}
+
+// emphasis 渲染 **粗体**、*斜体*、~~删除线~~
+func (r *inlineRenderer) emphasis(i int) int {
+	s := r.text
+	c := s[i]
+	delim := string(c)
+	if i+1 < len(s) && s[i+1] == c {
+		delim += string(c)
+	}
+	if c == '~' && len(delim) == 1 {
+		r.out.WriteByte(c)
+		return i + 1
+	}
+	// 下划线只在词边界生效，避免 snake_case 被误判
+	if c == '_' && i > 0 && isWordByte(s[i-1]) {
+		r.out.WriteString(delim)
+		return i + len(delim)
+	}
+
+	start := i + len(delim)
+	if start < len(s) && s[start] != ' ' && !r.noClose[delim] {
+		for j := start + 1; j <= len(s)-len(delim); j++ {
+			if s[j:j+len(delim)] != delim || s[j-1] == ' ' || s[j-1] == '\\' {
+				continue
+			}
+			// 单个分隔符不能是双分隔符的一部分
+			if len(delim) == 1 && j+1 < len(s) && s[j+1] == c {
+				j++
+				continue
+			}
+			if c == '_' && j+len(delim) < len(s) && isWordByte(s[j+len(delim)]) {
+				continue
+			}
+			tag := "em"
+			switch {
+			case c == '~':
+				tag = "del"
+			case len(delim) == 2:
+				tag = "strong"
+			}
+			r.out.WriteString("<" + tag + ">" + renderInline(s[start:j]) + "</" + tag + ">")
+			return j + len(delim)
+		}
+		r.noClose[delim] = true
+	}
+	r.out.WriteString(delim)
+	return start
+}
+
+// autolink 渲染 <https://...> 形式的自动链接，其余情况转义 <
+func (r *inlineRenderer) autolink(i int) int {
+	s := r.text
+	if end := strings.IndexByte(s[i:min(len(s), i+markdownLinkLabelLimit)], '>'); end > 0 {
+		inner := s[i+1 : i+end]
+		if !strings.ContainsAny(inner, " <") && (hasURLPrefix(inner) || strings.HasPrefix(strings.ToLower(inner), "mailto:")) {
+			r.out.WriteString(`<a href="` + html.EscapeString(inner) + `">` + html.EscapeString(inner) + "</a>")
+			return i + end + 1
+		}
+	}
+	r.out.WriteString("&lt;")
+	return i + 1
+}
+
+// bareURL 渲染正文中直接出现的 http/https 地址，遇到空白或非 ASCII 字符结束
+func (r *inlineRenderer) bareURL(i int) int {
+	s := r.text
+	j := i
+	for j < len(s) && s[j] > ' ' && s[j] < 0x80 && s[j] != '<' && s[j] != '"' {
+		j++
+	}
+	for j > i && strings.IndexByte(".,;:!?)'", s[j-1]) >= 0 {
+		j--
+	}
+	link := s[i:j]
+	r.out.WriteString(`<a href="` + html.EscapeString(link) + `">` + html.EscapeString(link) + "</a>")
+	return j
+}
+
+// parseMarkdownLink 解析从 s[i]=='[' 开始的 [文字](地址 "标题")，返回文字、地址、标题与结束位置
+func parseMarkdownLink(s string, i int) (label, dest, title string, end int, ok bool) {
+	depth := 0
+	j := i
+	limit := min(len(s), i+markdownLinkLabelLimit)
+	for ; j < limit; j++ {
+		switch s[j] {
+		case '\\':
+			j++
+			continue
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if depth != 0 || j >= limit || j+1 >= len(s) || s[j+1] != '(' {
+		return "", "", "", 0, false
+	}
+	label = s[i+1 : j]
+
+	k := j + 2
+	for k < len(s) && s[k] == ' ' {
+		k++
+	}
+	if k < len(s) && s[k] == '<' {
+		close := strings.IndexByte(s[k:min(len(s), k+markdownLinkLabelLimit)], '>')
+		if close < 0 {
+			return "", "", "", 0, false
+		}
+		dest = s[k+1 : k+close]
+		k += close + 1
+	} else {
+		parens := 0
+		d := k
+		for ; d < len(s) && d < k+markdownLinkLabelLimit; d++ {
+			if s[d] == ' ' {
+				break
+			}
+			if s[d] == '(' {
+				parens++
+			} else if s[d] == ')' {
+				if parens == 0 {
+					break
+				}
+				parens--
+			}
+		}
+		dest = s[k:d]
+		k = d
+	}
+	for k < len(s) && s[k] == ' ' {
+		k++
+	}
+	if k < len(s) && (s[k] == '"' || s[k] == '\'') {
+		quote := s[k]
+		close := strings.IndexByte(s[k+1:min(len(s), k+1+markdownLinkLabelLimit)], quote)
+		if close < 0 {
+			return "", "", "", 0, false
+		}
+		title = s[k+1 : k+1+close]
+		k += close + 2
+		for k < len(s) && s[k] == ' ' {
+			k++
+		}
+	}
+	if k >= len(s) || s[k] != ')' {
+		return "", "", "", 0, false
+	}
+	return label, dest, title, k + 1, true
+}
+
+func hasURLPrefix(s string) bool {
+	lower := strings.ToLower(s[:min(len(s), 8)])
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
@@ -0,0 +1,528 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxModerationHours 禁言/封禁最长时长（一年）
+const maxModerationHours = 24 * 365
+
+// ModerationRequest 举报处理动作参数
+type ModerationRequest struct {
+	Action        models.ModerationAction
+	DurationHours int // 禁言必填；封禁为0表示永久
+	Note          string
+}
+
+// moderationSnapshot 执行处理动作前的对象状态，申诉通过时据此恢复
+type moderationSnapshot struct {
+	Status      *int8      `json:"status,omitempty"`
+	IsLocked    *bool      `json:"is_locked,omitempty"`
+	MutedUntil  *time.Time `json:"muted_until,omitempty"`
+	BannedUntil *time.Time `json:"banned_until,omitempty"`
+	ParentID    uint       `json:"parent_id,omitempty"` // 回复所属帖子 / 评论所属文章
+}
+
+// moderationTarget 被处理对象的基本信息
+type moderationTarget struct {
+	Title    string
+	AuthorID uint
+	ParentID uint
+}
+
+// ModerationService 内容治理服务：执行举报处理动作并记录审计日志
+type ModerationService struct {
+	db           *gorm.DB
+	cacheService *CacheService
+}
+
+// NewModerationService 创建内容治理服务实例
+func NewModerationService(db *gorm.DB) *ModerationService {
+	return &ModerationService{
+		db:           db,
+		cacheService: NewCacheService(),
+	}
+}
+
+// ValidateRequest 校验处理动作是否适用于对象类型
+func (s *ModerationService) ValidateRequest(targetType string, req *ModerationRequest) error {
+	if !req.Action.IsAllowedFor(targetType) {
+		return errors.New("无效处理动作")
+	}
+	if req.DurationHours < 0 || req.DurationHours > maxModerationHours {
+		return errors.New("处理时长无效")
+	}
+	if req.Action == models.ModerationActionMute && req.DurationHours == 0 {
+		return errors.New("禁言需要指定时长")
+	}
+	return nil
+}
+
+// Apply 在事务内执行处理动作并写入审计记录
+func (s *ModerationService) Apply(tx *gorm.DB, report *models.Report, req *ModerationRequest, operatorID uint) (*models.ModerationLog, error) {
+	target, err := s.loadTarget(tx, report.TargetType, report.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.ModerationLog{
+		ReportID:      &report.ID,
+		Action:        req.Action,
+		TargetType:    report.TargetType,
+		TargetID:      report.TargetID,
+		TargetUserID:  target.AuthorID,
+		OperatorID:    operatorID,
+		Note:          truncateString(strings.TrimSpace(req.Note), 255),
+		DurationHours: req.DurationHours,
+	}
+
+	snapshot := moderationSnapshot{ParentID: target.ParentID}
+	now := time.Now()
+
+	switch req.Action {
+	case models.ModerationActionWarning:
+		// 仅通知作者，不改动内容
+
+	case models.ModerationActionUnpublish:
+		var article models.Article
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id, status").First(&article, report.TargetID).Error; err != nil {
+			return nil, s.targetError(err)
+		}
+		snapshot.Status = &article.Status
+		if err := tx.Model(&models.Article{}).Where("id = ?", article.ID).Update("status", 2).Error; err != nil {
+			return nil, fmt.Errorf("下架文章失败: %v", err)
+		}
+
+	case models.ModerationActionLock:
+		var post models.ForumPost
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id, is_locked").First(&post, report.TargetID).Error; err != nil {
+			return nil, s.targetError(err)
+		}
+		snapshot.IsLocked = &post.IsLocked
+		if err := tx.Model(&models.ForumPost{}).Where("id = ?", post.ID).Update("is_locked", true).Error; err != nil {
+			return nil, fmt.Errorf("锁定帖子失败: %v", err)
+		}
+
+	case models.ModerationActionDelete:
+		if err := s.deleteTarget(tx, report.TargetType, report.TargetID, target); err != nil {
+			return nil, err
+		}
+
+	case models.ModerationActionHide:
+		var comment models.Comment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id, status, article_id").First(&comment, report.TargetID).Error; err != nil {
+			return nil, s.targetError(err)
+		}
+		snapshot.Status = &comment.Status
+		if comment.Status != models.CommentStatusHidden {
+			if err := tx.Model(&models.Comment{}).Where("id = ?", comment.ID).Update("status", models.CommentStatusHidden).Error; err != nil {
+				return nil, fmt.Errorf("隐藏评论失败: %v", err)
+			}
+			if comment.Status == models.CommentStatusNormal {
+				if err := tx.Model(&models.Article{}).Where("id = ? AND comment_count > 0", comment.ArticleID).
+					UpdateColumn("comment_count", gorm.Expr("comment_count - 1")).Error; err != nil {
+					return nil, fmt.Errorf("更新文章评论数失败: %v", err)
+				}
+			}
+		}
+
+	case models.ModerationActionMute, models.ModerationActionBan:
+		user, err := s.lockTargetUser(tx, target.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		var expiresAt *time.Time
+		if req.DurationHours > 0 {
+			t := now.Add(time.Duration(req.DurationHours) * time.Hour)
+			expiresAt = &t
+		}
+		entry.ExpiresAt = expiresAt
+
+		if req.Action == models.ModerationActionMute {
+			snapshot.MutedUntil = user.MutedUntil
+			err = tx.Model(&models.User{}).Where("id = ?", user.ID).Update("muted_until", expiresAt).Error
+		} else {
+			snapshot.Status = &user.Status
+			snapshot.BannedUntil = user.BannedUntil
+			err = tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+				"status":       0,
+				"banned_until": expiresAt,
+			}).Error
+		}
+		if err != nil {
+			return nil, fmt.Errorf("处理用户失败: %v", err)
+		}
+
+	default:
+		return nil, errors.New("无效处理动作")
+	}
+
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	entry.Snapshot = string(raw)
+
+	if err := tx.Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("记录处理日志失败: %v", err)
+	}
+	return entry, nil
+}
+
+// AfterApply 事务提交后的收尾：清理缓存、同步搜索索引、吊销会话并通知内容作者
+func (s *ModerationService) AfterApply(entry *models.ModerationLog, report *models.Report) {
+	s.refreshTarget(entry)
+
+	if entry.Action == models.ModerationActionBan {
+		if err := NewSessionService(s.db).RevokeAllUserSessions(entry.TargetUserID, models.SessionRevokeBanned, ""); err != nil {
+			log.Printf("吊销被封禁用户会话失败 user=%d: %v", entry.TargetUserID, err)
+		}
+	}
+
+	s.notifyAuthor(entry, report)
+}
+
+// refreshTarget 处理动作生效后清理对象相关缓存并同步搜索索引
+func (s *ModerationService) refreshTarget(entry *models.ModerationLog) {
+	switch entry.TargetType {
+	case models.ReportTargetArticle:
+		s.cacheService.Delete(fmt.Sprintf("article:%d", entry.TargetID))
+		s.cacheService.DeletePattern("articles:*")
+		var article models.Article
+		if err := s.db.First(&article, entry.TargetID).Error; err == nil {
+			NewArticleService().indexArticle(&article)
+		} else {
+			removeSearchDocument(SearchTypeArticle, entry.TargetID)
+		}
+	case models.ReportTargetForumPost:
+		var post models.ForumPost
+		if err := s.db.First(&post, entry.TargetID).Error; err == nil {
+			NewForumService().indexPost(&post)
+		} else {
+			removeSearchDocument(SearchTypeForumPost, entry.TargetID)
+		}
+	case models.ReportTargetComment:
+		var comment models.Comment
+		if err := s.db.Unscoped().Select("id, article_id").First(&comment, entry.TargetID).Error; err == nil {
+			s.cacheService.Delete(fmt.Sprintf("comment:%d", comment.ID))
+			s.cacheService.DeletePattern(fmt.Sprintf("comments:article:%d*", comment.ArticleID))
+		}
+	}
+
+	if entry.Action == models.ModerationActionMute || entry.Action == models.ModerationActionBan {
+		s.cacheService.Delete(fmt.Sprintf("user:%d", entry.TargetUserID))
+	}
+}
+
+// notifyAuthor 向内容作者发送违规处理通知
+func (s *ModerationService) notifyAuthor(entry *models.ModerationLog, report *models.Report) {
+	if entry.TargetUserID == 0 {
+		return
+	}
+
+	target, err := s.loadTarget(s.db, entry.TargetType, entry.TargetID)
+	title := fmt.Sprintf("#%d", entry.TargetID)
+	if err == nil && target.Title != "" {
+		title = target.Title
+	}
+
+	var extra []string
+	if report.Reason != "" {
+		extra = append(extra, "原因："+report.Reason)
+	}
+	act := "已执行：" + entry.Action.Label()
+	if entry.ExpiresAt != nil {
+		act += "，至 " + entry.ExpiresAt.Format("2006-01-02 15:04")
+	} else if entry.Action == models.ModerationActionBan {
+		act += "（永久）"
+	}
+	extra = append(extra, act)
+
+	msg := fmt.Sprintf("您的%s《%s》被举报并经审核确认存在违规（%s）", ReportTargetLabel(entry.TargetType), title, strings.Join(extra, "，"))
+	if note := strings.TrimSpace(entry.Note); note != "" {
+		msg += "。处理备注：" + truncateString(note, 120)
+	}
+	// 引导申诉说明（前端会在消息正文内提供“申诉”按钮）
+	msg += "。如对此结果有疑问，可在本通知中点击“申诉”发起复核"
+
+	err = NewNotificationService(s.db).CreateNotification(&models.Notification{
+		ReceiverID: entry.TargetUserID,
+		ActorID:    entry.OperatorID,
+		Type:       models.NotificationTypeModeration,
+		Title:      "内容违规处理通知",
+		ResourceID: entry.TargetID,
+		Message:    msg,
+		IsRead:     false,
+	})
+	if err != nil {
+		log.Printf("发送违规处理通知失败 user=%d: %v", entry.TargetUserID, err)
+	}
+}
+
+// Revert 在事务内撤销举报对应的处理动作（申诉通过），恢复执行前状态并写入撤销记录
+// 举报没有可撤销的处理记录时返回 nil
+func (s *ModerationService) Revert(tx *gorm.DB, reportID, appealID, operatorID uint, note string) (*models.ModerationLog, error) {
+	var original models.ModerationLog
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("report_id = ? AND revert_of_id IS NULL AND reverted_at IS NULL", reportID).
+		Order("id DESC").First(&original).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snapshot moderationSnapshot
+	if original.Snapshot != "" {
+		if err := json.Unmarshal([]byte(original.Snapshot), &snapshot); err != nil {
+			return nil, fmt.Errorf("解析处理记录失败: %v", err)
+		}
+	}
+
+	if err := s.restoreTarget(tx, &original, &snapshot); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := tx.Model(&original).Update("reverted_at", now).Error; err != nil {
+		return nil, err
+	}
+
+	entry := &models.ModerationLog{
+		ReportID:     original.ReportID,
+		AppealID:     &appealID,
+		Action:       models.ModerationActionRevert,
+		TargetType:   original.TargetType,
+		TargetID:     original.TargetID,
+		TargetUserID: original.TargetUserID,
+		OperatorID:   operatorID,
+		Note:         truncateString(strings.TrimSpace(note), 255),
+		RevertOfID:   &original.ID,
+	}
+	if err := tx.Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("记录撤销日志失败: %v", err)
+	}
+	return &original, nil
+}
+
+// AfterRevert 撤销提交后的收尾：清理缓存并同步搜索索引
+func (s *ModerationService) AfterRevert(original *models.ModerationLog) {
+	s.refreshTarget(original)
+}
+
+// restoreTarget 按快照恢复对象状态
+func (s *ModerationService) restoreTarget(tx *gorm.DB, original *models.ModerationLog, snapshot *moderationSnapshot) error {
+	switch original.Action {
+	case models.ModerationActionWarning:
+		// 警告无需恢复内容
+		return nil
+
+	case models.ModerationActionUnpublish:
+		if snapshot.Status == nil {
+			return nil
+		}
+		// 仅在文章仍处于下架状态时恢复，避免覆盖作者之后的修改
+		return tx.Model(&models.Article{}).Where("id = ? AND status = ?", original.TargetID, 2).
+			Update("status", *snapshot.Status).Error
+
+	case models.ModerationActionLock:
+		if snapshot.IsLocked == nil {
+			return nil
+		}
+		return tx.Model(&models.ForumPost{}).Where("id = ?", original.TargetID).
+			Update("is_locked", *snapshot.IsLocked).Error
+
+	case models.ModerationActionDelete:
+		return s.restoreDeleted(tx, original, snapshot)
+
+	case models.ModerationActionHide:
+		if snapshot.Status == nil {
+			return nil
+		}
+		result := tx.Model(&models.Comment{}).Where("id = ? AND status = ?", original.TargetID, models.CommentStatusHidden).
+			Update("status", *snapshot.Status)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 && *snapshot.Status == models.CommentStatusNormal {
+			return tx.Model(&models.Article{}).Where("id = ?", snapshot.ParentID).
+				UpdateColumn("comment_count", gorm.Expr("comment_count + 1")).Error
+		}
+		return nil
+
+	case models.ModerationActionMute:
+		return tx.Model(&models.User{}).Where("id = ?", original.TargetUserID).
+			Update("muted_until", snapshot.MutedUntil).Error
+
+	case models.ModerationActionBan:
+		status := int8(1)
+		if snapshot.Status != nil {
+			status = *snapshot.Status
+		}
+		return tx.Model(&models.User{}).Where("id = ?", original.TargetUserID).Updates(map[string]interface{}{
+			"status":       status,
+			"banned_until": snapshot.BannedUntil,
+		}).Error
+
+	default:
+		return fmt.Errorf("处理动作 %s 不支持撤销", original.Action)
+	}
+}
+
+// restoreDeleted 恢复被软删除的文章/帖子/回复
+func (s *ModerationService) restoreDeleted(tx *gorm.DB, original *models.ModerationLog, snapshot *moderationSnapshot) error {
+	var model interface{}
+	switch original.TargetType {
+	case models.ReportTargetArticle:
+		model = &models.Article{}
+	case models.ReportTargetForumPost:
+		model = &models.ForumPost{}
+	case models.ReportTargetForumReply:
+		model = &models.ForumReply{}
+	default:
+		return errors.New("不支持的举报类型")
+	}
+
+	result := tx.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", original.TargetID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return fmt.Errorf("恢复内容失败: %v", result.Error)
+	}
+	if result.RowsAffected > 0 && original.TargetType == models.ReportTargetForumReply {
+		return tx.Model(&models.ForumPost{}).Where("id = ?", snapshot.ParentID).
+			UpdateColumn("reply_count", gorm.Expr("reply_count + 1")).Error
+	}
+	return nil
+}
+
+// EnsureCanPost 检查用户是否处于禁言期
+func (s *ModerationService) EnsureCanPost(userID uint) error {
+	var user models.User
+	if err := s.db.Select("id, muted_until").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return err
+	}
+	if user.MutedUntil != nil && user.MutedUntil.After(time.Now()) {
+		return fmt.Errorf("您已被禁言至 %s，暂时无法发布内容", user.MutedUntil.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// TargetInfo 获取被举报对象的标题（回复/评论为内容摘要）与作者，包含已删除内容
+func (s *ModerationService) TargetInfo(targetType string, targetID uint) (string, uint, error) {
+	target, err := s.loadTarget(s.db, targetType, targetID)
+	if err != nil {
+		return "", 0, err
+	}
+	return target.Title, target.AuthorID, nil
+}
+
+// loadTarget 读取被处理对象的标题与作者（包含已软删除的内容）
+func (s *ModerationService) loadTarget(tx *gorm.DB, targetType string, targetID uint) (*moderationTarget, error) {
+	db := tx.Unscoped()
+	switch targetType {
+	case models.ReportTargetArticle:
+		var article models.Article
+		if err := db.Select("id, title, author_id").First(&article, targetID).Error; err != nil {
+			return nil, s.targetError(err)
+		}
+		return &moderationTarget{Title: article.Title, AuthorID: article.AuthorID}, nil
+	case models.ReportTargetForumPost:
+		var post models.ForumPost
+		if err := db.Select("id, title, author_id").First(&post, targetID).Error; err != nil {
+			return nil, s.targetError(err)
+		}
+		return &moderationTarget{Title: post.Title, AuthorID: post.AuthorID}, nil
+	case models.ReportTargetForumReply:
+		var reply models.ForumReply
+		if err := db.Select("id, content, author_id, post_id").First(&reply, targetID).Error; err != nil {
+			return nil, s.targetError(err)
+		}
+		return &moderationTarget{Title: truncateString(reply.Content, 60), AuthorID: reply.AuthorID, ParentID: reply.PostID}, nil
+	case models.ReportTargetComment:
+		var comment models.Comment
+		if err := db.Select("id, content, user_id, article_id").First(&comment, targetID).Error; err != nil {
+			return nil, s.targetError(err)
+		}
+		return &moderationTarget{Title: truncateString(comment.Content, 60), AuthorID: comment.UserID, ParentID: comment.ArticleID}, nil
+	default:
+		return nil, errors.New("不支持的举报类型")
+	}
+}
+
+// deleteTarget 软删除文章/帖子/回复
+func (s *ModerationService) deleteTarget(tx *gorm.DB, targetType string, targetID uint, target *moderationTarget) error {
+	var result *gorm.DB
+	switch targetType {
+	case models.ReportTargetArticle:
+		result = tx.Delete(&models.Article{}, targetID)
+	case models.ReportTargetForumPost:
+		result = tx.Delete(&models.ForumPost{}, targetID)
+	case models.ReportTargetForumReply:
+		result = tx.Delete(&models.ForumReply{}, targetID)
+		if result.Error == nil && result.RowsAffected > 0 {
+			if err := tx.Model(&models.ForumPost{}).Where("id = ? AND reply_count > 0", target.ParentID).
+				UpdateColumn("reply_count", gorm.Expr("reply_count - 1")).Error; err != nil {
+				return fmt.Errorf("更新帖子统计失败: %v", err)
+			}
+		}
+	default:
+		return errors.New("无效处理动作")
+	}
+	if result.Error != nil {
+		return fmt.Errorf("删除内容失败: %v", result.Error)
+	}
+	return nil
+}
+
+// lockTargetUser 锁定并读取被处理用户，管理员不能被禁言或封禁
+func (s *ModerationService) lockTargetUser(tx *gorm.DB, userID uint) (*models.User, error) {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, role, status, muted_until, banned_until").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, err
+	}
+	if user.Role >= 2 {
+		return nil, errors.New("不能禁言或封禁管理员")
+	}
+	return &user, nil
+}
+
+func (s *ModerationService) targetError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("被举报内容不存在")
+	}
+	return err
+}
+
+// ReportTargetLabel 举报对象类型的中文名称
+func ReportTargetLabel(targetType string) string {
+	switch targetType {
+	case models.ReportTargetArticle:
+		return "文章"
+	case models.ReportTargetForumPost:
+		return "社区帖子"
+	case models.ReportTargetForumReply:
+		return "社区回复"
+	case models.ReportTargetComment:
+		return "评论"
+	default:
+		return "内容"
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+
+	"godad-backend/models"
+)
+
+// notificationHubChannel Redis 发布/订阅频道，用于多实例之间扇出通知事件
+const notificationHubChannel = "notifications:events"
+
+// notificationSubscriberBuffer 每个订阅者的事件缓冲大小，写满后丢弃事件（客户端可通过 Last-Event-ID 补拉）
+const notificationSubscriberBuffer = 32
+
+// NotificationEvent 推送给 SSE 客户端的通知事件
+type NotificationEvent struct {
+	ReceiverID uint            `json:"receiver_id"` // 接收者ID，0 表示全员广播
+	Event      string          `json:"event"`       // SSE 事件名
+	ID         uint            `json:"id"`          // SSE 事件ID（通知ID），0 表示不设置
+	Data       json.RawMessage `json:"data"`        // 事件负载
+
+	// Stats 发布时计算好的接收者未读统计，订阅者直接转发，无需各自回查；广播事件为空
+	Stats *models.NotificationStats `json:"stats,omitempty"`
+}
+
+// NotificationSubscription 单个 SSE 连接的订阅
+type NotificationSubscription struct {
+	userID uint
+	C      chan NotificationEvent
+}
+
+// NotificationHub 进程内通知事件中心
+// Redis 可用时事件经 Redis 发布/订阅转发到所有实例，否则仅在本进程内分发
+type NotificationHub struct {
+	mu          sync.RWMutex
+	subscribers map[uint]map[*NotificationSubscription]struct{}
+	redisOnce   sync.Once
+}
+
+var (
+	notificationHub     *NotificationHub
+	notificationHubOnce sync.Once
+)
+
+// GetNotificationHub 获取全局通知事件中心
+func GetNotificationHub() *NotificationHub {
+	notificationHubOnce.Do(func() {
+		notificationHub = &NotificationHub{
+			subscribers: make(map[uint]map[*NotificationSubscription]struct{}),
+		}
+	})
+	return notificationHub
+}
+
+// Subscribe 订阅指定用户的通知事件
+func (h *NotificationHub) Subscribe(userID uint) *NotificationSubscription {
+	h.ensureRedisListener()
+
+	sub := &NotificationSubscription{
+		userID: userID,
+		C:      make(chan NotificationEvent, notificationSubscriberBuffer),
+	}
+
+	h.mu.Lock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*NotificationSubscription]struct{})
+	}
+	h.subscribers[userID][sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+// Unsubscribe 取消订阅
+func (h *NotificationHub) Unsubscribe(sub *NotificationSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if subs, ok := h.subscribers[sub.userID]; ok {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.subscribers, sub.userID)
+		}
+	}
+}
+
+// Publish 发布通知事件
+func (h *NotificationHub) Publish(event NotificationEvent) {
+	if isCacheReady() {
+		h.ensureRedisListener()
+		payload, err := json.Marshal(event)
+		if err == nil {
+			if err = rdb.Publish(ctx, notificationHubChannel, payload).Err(); err == nil {
+				return
+			}
+		}
+		log.Printf("通知事件发布到Redis失败，改为本地分发: %v", err)
+	}
+	h.dispatch(event)
+}
+
+// dispatch 将事件分发给本进程内的订阅者
+func (h *NotificationHub) dispatch(event NotificationEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	deliver := func(subs map[*NotificationSubscription]struct{}) {
+		for sub := range subs {
+			select {
+			case sub.C <- event:
+			default:
+				// 客户端消费过慢，丢弃事件
+			}
+		}
+	}
+
+	if event.ReceiverID == 0 {
+		for _, subs := range h.subscribers {
+			deliver(subs)
+		}
+		return
+	}
+	deliver(h.subscribers[event.ReceiverID])
+}
+
+// ensureRedisListener 在 Redis 可用时启动一次频道监听
+func (h *NotificationHub) ensureRedisListener() {
+	if !isCacheReady() {
+		return
+	}
+	h.redisOnce.Do(func() {
+		pubsub := rdb.Subscribe(ctx, notificationHubChannel)
+		// 等待订阅确认，避免首条事件在订阅建立前发布而丢失
+		if _, err := pubsub.Receive(ctx); err != nil {
+			log.Printf("订阅通知频道失败: %v", err)
+		}
+		go func() {
+			for msg := range pubsub.Channel() {
+				var event NotificationEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					log.Printf("解析通知事件失败: %v", err)
+					continue
+				}
+				h.dispatch(event)
+			}
+		}()
+	})
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+    "encoding/json"
     "fmt"
     "godad-backend/models"
     "log"
@@ -33,15 +34,121 @@ func (s *NotificationService) CreateNotification(notification *models.Notificati
 	).First(&existingNotification).Error
 
 	if err == nil {
-		// 如果存在相同通知，更新时间即可
-		return s.db.Model(&existingNotification).Update("created_at", gorm.Expr("NOW()")).Error
+		// 如果存在相同通知，以新ID重新发出，使实时推送与断线补发都能感知
+		oldID := existingNotification.ID
+		if err := s.reissueNotification(&existingNotification); err != nil {
+			return err
+		}
+		s.publishNotification(existingNotification.ID, existingNotification.ReceiverID, oldID)
+		return nil
 	}
 
 	if err != gorm.ErrRecordNotFound {
 		return err
 	}
 
-	return s.db.Create(notification).Error
+	if err := s.db.Create(notification).Error; err != nil {
+		return err
+	}
+	s.publishNotification(notification.ID, notification.ReceiverID, 0)
+	return nil
+}
+
+// reissueNotification 用新ID重新发出已有通知（合并重复通知时使用），已读状态保持不变
+// SSE 事件ID与断线补发都以通知ID递增为准，原地更新的通知会被客户端当作已收到而丢弃；
+// 旧记录软删除并通过 replaced_by 指向新记录，客户端仍持有旧ID时标记已读与删除会作用到新记录
+func (s *NotificationService) reissueNotification(notification *models.Notification) error {
+	oldID := notification.ID
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		notification.ID = 0
+		notification.CreatedAt = time.Time{}
+		notification.UpdatedAt = time.Time{}
+		if err := tx.Create(notification).Error; err != nil {
+			return err
+		}
+		// 之前已被合并到旧记录的通知一并指向新记录，保证只需一跳即可找到当前通知
+		if err := tx.Unscoped().Model(&models.Notification{}).
+			Where("id = ? OR replaced_by = ?", oldID, oldID).
+			Update("replaced_by", notification.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Notification{}, oldID).Error
+	})
+}
+
+// withReissuedIDs 补上已被重新发出的通知对应的新ID
+func (s *NotificationService) withReissuedIDs(userID uint, notificationIDs []uint) ([]uint, error) {
+	var replacedBy []uint
+	if err := s.db.Unscoped().Model(&models.Notification{}).
+		Where("receiver_id = ? AND id IN ? AND replaced_by <> 0", userID, notificationIDs).
+		Pluck("replaced_by", &replacedBy).Error; err != nil {
+		return nil, err
+	}
+	return append(notificationIDs, replacedBy...), nil
+}
+
+// publishNotification 将通知完整内容推送到实时通知中心，replacesID 为被重新发出的旧通知ID（没有时为 0）
+func (s *NotificationService) publishNotification(notificationID, receiverID, replacesID uint) {
+	detail, err := s.getNotificationDetail(notificationID)
+	if err != nil {
+		log.Printf("加载通知详情失败 (id: %d): %v", notificationID, err)
+		return
+	}
+	detail.ReplacesID = replacesID
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return
+	}
+	stats, err := s.notificationStatsFor([]uint{receiverID})
+	if err != nil {
+		log.Printf("统计未读通知失败 (user: %d): %v", receiverID, err)
+	}
+	GetNotificationHub().Publish(NotificationEvent{
+		ReceiverID: receiverID,
+		Event:      "notification",
+		ID:         notificationID,
+		Data:       data,
+		Stats:      stats[receiverID],
+	})
+}
+
+// notificationStatsFor 一次查询统计多个用户的未读数与总数，用于随推送事件下发
+func (s *NotificationService) notificationStatsFor(receiverIDs []uint) (map[uint]*models.NotificationStats, error) {
+	var rows []struct {
+		ReceiverID  uint
+		UnreadCount int64
+		TotalCount  int64
+	}
+	if err := s.db.Model(&models.Notification{}).
+		Select("receiver_id, SUM(CASE WHEN is_read = false THEN 1 ELSE 0 END) AS unread_count, COUNT(*) AS total_count").
+		Where("receiver_id IN ? AND deleted_at IS NULL", receiverIDs).
+		Group("receiver_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	stats := make(map[uint]*models.NotificationStats, len(rows))
+	for _, row := range rows {
+		stats[row.ReceiverID] = &models.NotificationStats{UnreadCount: row.UnreadCount, TotalCount: row.TotalCount}
+	}
+	return stats, nil
+}
+
+// publishBroadcast 推送系统广播事件（所有在线用户）
+func (s *NotificationService) publishBroadcast(adminID uint, title, message string, broadcastID uint) {
+	data, err := json.Marshal(map[string]interface{}{
+		"type":         models.NotificationTypeSystem,
+		"actor_id":     adminID,
+		"title":        title,
+		"message":      message,
+		"broadcast_id": broadcastID,
+		"created_at":   time.Now(),
+	})
+	if err != nil {
+		return
+	}
+	GetNotificationHub().Publish(NotificationEvent{
+		Event: "broadcast",
+		Data:  data,
+	})
 }
 
 // CreateLikeNotification 创建点赞通知
@@ -199,8 +306,12 @@ func (s *NotificationService) CreateMessageNotification(actorID, receiverID uint
 		// 存在未读通知，更新内容、发送者和时间
 		existingNotification.ActorID = actorID
 		existingNotification.Message = fmt.Sprintf("%s 给你发送了一条私信：%s", displayName, messageContent)
-		existingNotification.UpdatedAt = time.Now()
-		return s.db.Save(&existingNotification).Error
+		oldID := existingNotification.ID
+		if err := s.reissueNotification(&existingNotification); err != nil {
+			return err
+		}
+		s.publishNotification(existingNotification.ID, receiverID, oldID)
+		return nil
 	} else if err == gorm.ErrRecordNotFound {
 		// 不存在未读通知，创建新通知
 		notification := &models.Notification{
@@ -232,7 +343,22 @@ func (s *NotificationService) GetNotifications(userID uint, page, limit int) ([]
 	}
 
 	// 查询通知详情
-    query := `
+    query := notificationDetailsQuery + `
+        WHERE n.receiver_id = ? AND n.deleted_at IS NULL
+        ORDER BY n.created_at DESC
+        LIMIT ? OFFSET ?
+    `
+
+	err = s.db.Raw(query, userID, limit, offset).Scan(&notifications).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
+}
+
+// notificationDetailsQuery 通知详情查询（带发起者与文章信息），调用方追加 WHERE/ORDER 子句
+const notificationDetailsQuery = `
         SELECT 
             n.id, n.receiver_id, n.actor_id, n.type, n.title, n.resource_id, n.comment_id, n.message, 
             n.is_read, n.created_at, n.updated_at,
@@ -242,18 +368,35 @@ func (s *NotificationService) GetNotifications(userID uint, page, limit int) ([]
             COALESCE(a.title, '') as article_title, COALESCE(a.cover_image, '') as article_cover
         FROM notifications n
         LEFT JOIN users u ON n.actor_id = u.id
-        LEFT JOIN articles a ON n.resource_id = a.id AND n.type IN ('like', 'comment', 'bookmark', 'mention')
-        WHERE n.receiver_id = ? AND n.deleted_at IS NULL
-        ORDER BY n.created_at DESC
-        LIMIT ? OFFSET ?
-    `
+        LEFT JOIN articles a ON n.resource_id = a.id AND n.type IN ('like', 'comment', 'bookmark', 'mention', 'article')`
 
-	err = s.db.Raw(query, userID, limit, offset).Scan(&notifications).Error
-	if err != nil {
-		return nil, 0, err
+// getNotificationDetail 获取单条通知详情
+func (s *NotificationService) getNotificationDetail(notificationID uint) (*models.NotificationWithDetails, error) {
+	var notifications []models.NotificationWithDetails
+	query := notificationDetailsQuery + `
+        WHERE n.id = ? AND n.deleted_at IS NULL
+    `
+	if err := s.db.Raw(query, notificationID).Scan(&notifications).Error; err != nil {
+		return nil, err
+	}
+	if len(notifications) == 0 {
+		return nil, gorm.ErrRecordNotFound
 	}
+	return &notifications[0], nil
+}
 
-	return notifications, total, nil
+// GetNotificationsSince 获取ID大于 afterID 的通知（按ID升序），用于 SSE 断线重连补发
+func (s *NotificationService) GetNotificationsSince(userID, afterID uint, limit int) ([]models.NotificationWithDetails, error) {
+	var notifications []models.NotificationWithDetails
+	query := notificationDetailsQuery + `
+        WHERE n.receiver_id = ? AND n.id > ? AND n.deleted_at IS NULL
+        ORDER BY n.id ASC
+        LIMIT ?
+    `
+	if err := s.db.Raw(query, userID, afterID, limit).Scan(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
 }
 
 // GetNotificationStats 获取通知统计
@@ -317,6 +460,8 @@ func (s *NotificationService) GetNotificationStatsByType(userID uint) (*models.N
             stats.System += r.Count
         case string(models.NotificationTypeMention):
             stats.Mention = r.Count
+        case string(models.NotificationTypeArticle):
+            stats.Article = r.Count
         default:
             // 未知类型暂不计入具体分类，仅计入总数
         }
@@ -347,6 +492,109 @@ func (s *NotificationService) CreateMentionNotification(actorID, receiverID, art
     return s.CreateNotification(n)
 }
 
+// NotifyFollowersNewArticle 通知作者的粉丝有新文章发布
+func (s *NotificationService) NotifyFollowersNewArticle(authorID, articleID uint) error {
+	var followerIDs []uint
+	if err := s.db.Model(&models.Follow{}).
+		Where("followee_id = ? AND follower_id <> ?", authorID, authorID).
+		Pluck("follower_id", &followerIDs).Error; err != nil {
+		return err
+	}
+	return s.notifyNewArticle(followerIDs, authorID, articleID, func(article *models.Article) string {
+		return fmt.Sprintf("发布了新文章《%s》", article.Title)
+	})
+}
+
+// NotifySeriesFollowersNewArticle 通知系列的关注者有新文章加入
+// authorFollowersNotified 表示本次发布已通知过作者的粉丝，此时同时关注了作者的用户不再重复通知；
+// 已发布文章后来加入系列时为 false，系列关注者都会收到通知
+func (s *NotificationService) NotifySeriesFollowersNewArticle(seriesID, articleID uint, authorFollowersNotified bool) error {
+	var series models.Series
+	if err := s.db.Select("id, title").First(&series, seriesID).Error; err != nil {
+		return err
+	}
+	var article models.Article
+	if err := s.db.Select("id, author_id").First(&article, articleID).Error; err != nil {
+		return err
+	}
+
+	var receiverIDs []uint
+	query := s.db.Model(&models.SeriesFollow{}).Where("series_id = ? AND user_id <> ?", seriesID, article.AuthorID)
+	if authorFollowersNotified {
+		query = query.Where("user_id NOT IN (?)", s.db.Model(&models.Follow{}).Select("follower_id").Where("followee_id = ?", article.AuthorID))
+	}
+	if err := query.Pluck("user_id", &receiverIDs).Error; err != nil {
+		return err
+	}
+	return s.notifyNewArticle(receiverIDs, article.AuthorID, articleID, func(article *models.Article) string {
+		return fmt.Sprintf("更新了系列《%s》：《%s》", series.Title, article.Title)
+	})
+}
+
+// notifyNewArticle 向一批用户发送新文章通知
+// 接收者可能很多，分批写入；推送内容在内存中组装，避免逐条回查详情
+func (s *NotificationService) notifyNewArticle(receiverIDs []uint, authorID, articleID uint, message func(article *models.Article) string) error {
+	if len(receiverIDs) == 0 {
+		return nil
+	}
+	var article models.Article
+	if err := s.db.Select("id, title, cover_image").First(&article, articleID).Error; err != nil {
+		return err
+	}
+	var author models.User
+	if err := s.db.Select("id, username, nickname, avatar").First(&author, authorID).Error; err != nil {
+		return err
+	}
+
+	text := message(&article)
+	now := time.Now()
+	batchSize := 500
+	for i := 0; i < len(receiverIDs); i += batchSize {
+		end := min(i+batchSize, len(receiverIDs))
+		batch := make([]models.Notification, 0, end-i)
+		for _, uid := range receiverIDs[i:end] {
+			batch = append(batch, models.Notification{
+				ReceiverID: uid,
+				ActorID:    authorID,
+				Type:       models.NotificationTypeArticle,
+				ResourceID: articleID,
+				Message:    text,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			})
+		}
+		if err := s.db.Create(&batch).Error; err != nil {
+			return err
+		}
+
+		stats, err := s.notificationStatsFor(receiverIDs[i:end])
+		if err != nil {
+			log.Printf("统计未读通知失败: %v", err)
+		}
+		for _, n := range batch {
+			data, err := json.Marshal(models.NotificationWithDetails{
+				Notification:  n,
+				ActorUsername: author.Username,
+				ActorNickname: author.Nickname,
+				ActorAvatar:   author.Avatar,
+				ArticleTitle:  article.Title,
+				ArticleCover:  article.CoverImage,
+			})
+			if err != nil {
+				continue
+			}
+			GetNotificationHub().Publish(NotificationEvent{
+				ReceiverID: n.ReceiverID,
+				Event:      "notification",
+				ID:         n.ID,
+				Data:       data,
+				Stats:      stats[n.ReceiverID],
+			})
+		}
+	}
+	return nil
+}
+
 // BroadcastSystemNotification 管理员广播系统通知到所有用户
 func (s *NotificationService) BroadcastSystemNotification(adminID uint, message string) error {
     if message == "" {
@@ -399,6 +647,7 @@ func (s *NotificationService) BroadcastSystemNotification(adminID uint, message
         }
     }
 
+    s.publishBroadcast(adminID, "", message, broadcastID)
     return nil
 }
 
@@ -438,6 +687,7 @@ func (s *NotificationService) BroadcastSystemNotificationWithTitle(adminID uint,
         if len(batch) == 0 { continue }
         if err := s.db.Create(&batch).Error; err != nil { return err }
     }
+    s.publishBroadcast(adminID, title, content, broadcastID)
     return nil
 }
 
@@ -528,8 +778,12 @@ func (s *NotificationService) MarkAsRead(userID uint, notificationIDs []uint) er
 		return nil
 	}
 
+	ids, err := s.withReissuedIDs(userID, notificationIDs)
+	if err != nil {
+		return err
+	}
 	return s.db.Model(&models.Notification{}).
-		Where("receiver_id = ? AND id IN ?", userID, notificationIDs).
+		Where("receiver_id = ? AND id IN ?", userID, ids).
 		Update("is_read", true).Error
 }
 
@@ -542,7 +796,11 @@ func (s *NotificationService) MarkAllAsRead(userID uint) error {
 
 // DeleteNotification 删除通知
 func (s *NotificationService) DeleteNotification(userID uint, notificationID uint) error {
-	return s.db.Where("receiver_id = ? AND id = ?", userID, notificationID).
+	ids, err := s.withReissuedIDs(userID, []uint{notificationID})
+	if err != nil {
+		return err
+	}
+	return s.db.Where("receiver_id = ? AND id IN ?", userID, ids).
 		Delete(&models.Notification{}).Error
 }
 
@@ -552,16 +810,16 @@ func (s *NotificationService) DeleteAllNotifications(userID uint) error {
 		Delete(&models.Notification{}).Error
 }
 
-// CleanupOldNotifications 清理旧通知（30天前的已读通知）
-func (s *NotificationService) CleanupOldNotifications() error {
+// CleanupOldNotifications 清理旧通知（30天前的已读通知，以及已被重新发出的旧记录）
+func (s *NotificationService) CleanupOldNotifications() (int64, error) {
 	result := s.db.Unscoped().
-		Where("is_read = true AND created_at < DATE_SUB(NOW(), INTERVAL 30 DAY)").
+		Where("(is_read = true OR replaced_by <> 0) AND created_at < DATE_SUB(NOW(), INTERVAL 30 DAY)").
 		Delete(&models.Notification{})
 	
 	if result.Error != nil {
-		return result.Error
+		return 0, result.Error
 	}
 
 	log.Printf("Cleaned up %d old notifications", result.RowsAffected)
-	return nil
+	return result.RowsAffected, nil
 }
@@ -72,39 +72,45 @@ func (ps *PointsService) AwardPoints(userID uint, action string, sourceType stri
 }
 
 // DeductPoints 扣除积分
+// 在事务中调用时（ps 由 tx 构造）以保存点方式嵌套，随外层事务一起提交或回滚
 func (ps *PointsService) DeductPoints(userID uint, action string, sourceType string, sourceID uint, description string, points int64) error {
-	// 开始事务
-	tx := ps.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	return ps.db.Transaction(func(tx *gorm.DB) error {
+		// 创建积分交易记录（负值）
+		transaction := &models.PointsTransaction{
+			UserID:      userID,
+			Action:      action,
+			Points:      -points, // 负值表示扣除
+			Description: description,
+			SourceType:  sourceType,
+			SourceID:    sourceID,
+			CreatedAt:   time.Now(),
+		}
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
 		}
-	}()
-
-	// 创建积分交易记录（负值）
-	transaction := &models.PointsTransaction{
-		UserID:      userID,
-		Action:      action,
-		Points:      -points, // 负值表示扣除
-		Description: description,
-		SourceType:  sourceType,
-		SourceID:    sourceID,
-		CreatedAt:   time.Now(),
-	}
-
-	if err := tx.Create(transaction).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
 
-	// 更新用户积分（扣除）
-	err := ps.updateUserPoints(tx, userID, -points)
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
+		// 更新用户积分（扣除）
+		return ps.updateUserPoints(tx, userID, -points)
+	})
+}
 
-	return tx.Commit().Error
+// GrantPoints 按指定数额发放积分（不经积分规则），用于悬赏发放、退还等
+func (ps *PointsService) GrantPoints(userID uint, action string, sourceType string, sourceID uint, description string, points int64) error {
+	return ps.db.Transaction(func(tx *gorm.DB) error {
+		transaction := &models.PointsTransaction{
+			UserID:      userID,
+			Action:      action,
+			Points:      points,
+			Description: description,
+			SourceType:  sourceType,
+			SourceID:    sourceID,
+			CreatedAt:   time.Now(),
+		}
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
+		return ps.updateUserPoints(tx, userID, points)
+	})
 }
 
 // GetUserPoints 获取用户积分信息
@@ -0,0 +1,413 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 推荐理由
+const (
+	RecommendReasonSimilarUsers = "similar_users" // 喜欢过相似文章的用户也喜欢
+	RecommendReasonTrending     = "trending"      // 冷启动或候选不足时的热门内容
+)
+
+const (
+	// recommendWindow 参与协同过滤的互动时间范围
+	recommendWindow = 180 * 24 * time.Hour
+	// recommendMaxUserItems 每个用户参与计算的最近互动文章数，避免少数重度用户主导相似度
+	recommendMaxUserItems = 200
+	// recommendNeighbors 每篇文章保存的相似文章数
+	recommendNeighbors = 20
+	// recommendShrinkage 相似度收缩系数：共同用户越少，相似度打折越多
+	recommendShrinkage = 3.0
+	// recommendHistoryItems 生成推荐时参考的用户最近互动文章数
+	recommendHistoryItems = 50
+	// recommendCandidates 缓存的候选推荐数
+	recommendCandidates = 100
+	recommendCacheTTL   = 10 * time.Minute
+)
+
+// 互动权重：收藏比点赞更能代表兴趣
+const (
+	recommendLikeWeight     = 1.0
+	recommendFavoriteWeight = 2.0
+)
+
+// RecommendationItem 推荐文章
+type RecommendationItem struct {
+	Article *models.ArticleResponse `json:"article"`
+	Score   float64                 `json:"score"`
+	Reason  string                  `json:"reason"`
+	BasedOn uint                    `json:"based_on,omitempty"` // 贡献最大的已互动文章
+}
+
+// recommendEntry 缓存的候选推荐
+type recommendEntry struct {
+	ArticleID uint    `json:"a"`
+	Score     float64 `json:"s"`
+	Reason    string  `json:"r"`
+	BasedOn   uint    `json:"b,omitempty"`
+}
+
+// interaction 用户对文章的互动
+type interaction struct {
+	UserID    uint
+	ArticleID uint
+	Weight    float64
+	LastAt    time.Time
+}
+
+// RecommendationService 基于点赞与收藏的物品协同过滤推荐
+type RecommendationService struct {
+	db           *gorm.DB
+	cacheService *CacheService
+}
+
+// NewRecommendationService 创建推荐服务
+func NewRecommendationService(db *gorm.DB) *RecommendationService {
+	return &RecommendationService{
+		db:           db,
+		cacheService: NewCacheService(),
+	}
+}
+
+func recommendCacheKey(userID uint) string {
+	return fmt.Sprintf("recommendations:user:%d", userID)
+}
+
+// interactions 点赞与收藏合并后的用户-文章互动，不含作者对自己文章的互动；userID 为 0 时查询所有用户
+func (s *RecommendationService) interactions(since time.Time, userID uint) ([]interaction, error) {
+	userFilter := ""
+	likeArgs := []interface{}{recommendLikeWeight, "article", since}
+	favoriteArgs := []interface{}{recommendFavoriteWeight, since}
+	if userID > 0 {
+		userFilter = " AND user_id = ?"
+		likeArgs = append(likeArgs, userID)
+		favoriteArgs = append(favoriteArgs, userID)
+	}
+
+	args := append(append(likeArgs, favoriteArgs...), models.ArticleStatusPublished)
+	var rows []interaction
+	err := s.db.Raw(`SELECT i.user_id, i.article_id, SUM(i.weight) AS weight, MAX(i.created_at) AS last_at
+		FROM (
+			SELECT user_id, target_id AS article_id, ? AS weight, created_at FROM likes WHERE target_type = ? AND created_at >= ?`+userFilter+`
+			UNION ALL
+			SELECT user_id, article_id, ? AS weight, created_at FROM favorites WHERE deleted_at IS NULL AND created_at >= ?`+userFilter+`
+		) i
+		JOIN articles a ON a.id = i.article_id AND a.status = ? AND a.deleted_at IS NULL AND a.author_id <> i.user_id
+		GROUP BY i.user_id, i.article_id
+		ORDER BY last_at DESC`, args...).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询用户互动失败: %v", err)
+	}
+	return rows, nil
+}
+
+// RebuildNeighbors 全量计算文章之间的协同过滤相似度（带收缩的余弦相似度）
+func (s *RecommendationService) RebuildNeighbors() (string, error) {
+	started := time.Now()
+
+	rows, err := s.interactions(started.Add(-recommendWindow), 0)
+	if err != nil {
+		return "", err
+	}
+
+	byUser := make(map[uint][]interaction)
+	for _, row := range rows {
+		byUser[row.UserID] = append(byUser[row.UserID], row)
+	}
+
+	type pairStat struct {
+		dot float64
+		co  int
+	}
+	norms := make(map[uint]float64)
+	pairs := make(map[[2]uint]*pairStat)
+	for _, items := range byUser {
+		// 互动按时间倒序返回，只取每个用户最近的部分
+		if len(items) > recommendMaxUserItems {
+			items = items[:recommendMaxUserItems]
+		}
+		for i, a := range items {
+			norms[a.ArticleID] += a.Weight * a.Weight
+			for _, b := range items[i+1:] {
+				key := [2]uint{min(a.ArticleID, b.ArticleID), max(a.ArticleID, b.ArticleID)}
+				stat := pairs[key]
+				if stat == nil {
+					stat = &pairStat{}
+					pairs[key] = stat
+				}
+				stat.dot += a.Weight * b.Weight
+				stat.co++
+			}
+		}
+	}
+
+	candidates := make(map[uint][]models.ArticleNeighbor)
+	now := time.Now()
+	for key, stat := range pairs {
+		score := stat.dot / math.Sqrt(norms[key[0]]*norms[key[1]]) * float64(stat.co) / (float64(stat.co) + recommendShrinkage)
+		score = math.Round(score*10000) / 10000
+		if score <= 0 {
+			continue
+		}
+		candidates[key[0]] = append(candidates[key[0]], models.ArticleNeighbor{ArticleID: key[0], NeighborID: key[1], Score: score, CoUsers: stat.co, UpdatedAt: now})
+		candidates[key[1]] = append(candidates[key[1]], models.ArticleNeighbor{ArticleID: key[1], NeighborID: key[0], Score: score, CoUsers: stat.co, UpdatedAt: now})
+	}
+
+	neighbors := make([]models.ArticleNeighbor, 0, len(candidates)*recommendNeighbors)
+	for _, list := range candidates {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			return list[i].NeighborID > list[j].NeighborID
+		})
+		if len(list) > recommendNeighbors {
+			list = list[:recommendNeighbors]
+		}
+		neighbors = append(neighbors, list...)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.ArticleNeighbor{}).Error; err != nil {
+			return fmt.Errorf("清理相似文章失败: %v", err)
+		}
+		if len(neighbors) > 0 {
+			if err := tx.CreateInBatches(neighbors, 500).Error; err != nil {
+				return fmt.Errorf("保存相似文章失败: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	s.cacheService.DeletePattern("recommendations:user:*")
+	return fmt.Sprintf("基于 %d 个用户的 %d 条互动计算了 %d 篇文章的 %d 条相似关系，耗时 %s",
+		len(byUser), len(rows), len(candidates), len(neighbors), time.Since(started).Round(time.Millisecond)), nil
+}
+
+// GetRecommendations 获取个性化推荐
+// 根据用户最近点赞、收藏的文章汇总其相似文章；没有互动记录或候选不足时用热门文章补足。
+// 已互动、标记不感兴趣、自己发布以及屏蔽用户发布的文章都不会出现
+func (s *RecommendationService) GetRecommendations(userID uint, limit int) ([]RecommendationItem, error) {
+	var entries []recommendEntry
+	if err := s.cacheService.Get(recommendCacheKey(userID), &entries); err != nil {
+		if entries, err = s.buildEntries(userID); err != nil {
+			return nil, err
+		}
+		if err := s.cacheService.SetWithExpire(recommendCacheKey(userID), entries, recommendCacheTTL); err != nil && !errors.Is(err, ErrCacheDisabled) {
+			log.Printf("缓存推荐结果失败 (user: %d): %v", userID, err)
+		}
+	}
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	items := []RecommendationItem{}
+	if len(entries) == 0 {
+		return items, nil
+	}
+	ids := make([]uint, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ArticleID)
+	}
+	var articles []*models.Article
+	if err := s.db.Preload("Author").Preload("Category").Preload("Tags").
+		Where("id IN ? AND status = ?", ids, models.ArticleStatusPublished).
+		Find(&articles).Error; err != nil {
+		return nil, fmt.Errorf("查询推荐文章失败: %v", err)
+	}
+	byID := make(map[uint]*models.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+	for _, e := range entries {
+		if article, ok := byID[e.ArticleID]; ok {
+			items = append(items, RecommendationItem{Article: article.ToResponse(false), Score: e.Score, Reason: e.Reason, BasedOn: e.BasedOn})
+		}
+	}
+	return items, nil
+}
+
+// buildEntries 计算用户的候选推荐
+func (s *RecommendationService) buildEntries(userID uint) ([]recommendEntry, error) {
+	history, err := s.interactions(time.Now().Add(-recommendWindow), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded, err := s.excludedArticleIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range history {
+		excluded[h.ArticleID] = true
+	}
+	blockedIDs, err := NewUserBlockService(s.db).GetBlockedUserIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []recommendEntry{}
+	if len(history) > recommendHistoryItems {
+		history = history[:recommendHistoryItems]
+	}
+	if len(history) > 0 {
+		weights := make(map[uint]float64, len(history))
+		historyIDs := make([]uint, 0, len(history))
+		for _, h := range history {
+			weights[h.ArticleID] = h.Weight
+			historyIDs = append(historyIDs, h.ArticleID)
+		}
+
+		var neighbors []models.ArticleNeighbor
+		if err := s.db.Where("article_id IN ?", historyIDs).Find(&neighbors).Error; err != nil {
+			return nil, fmt.Errorf("查询相似文章失败: %v", err)
+		}
+
+		scores := make(map[uint]float64)
+		basedOn := make(map[uint]uint)
+		best := make(map[uint]float64)
+		for _, n := range neighbors {
+			if excluded[n.NeighborID] {
+				continue
+			}
+			contribution := n.Score * weights[n.ArticleID]
+			scores[n.NeighborID] += contribution
+			if contribution > best[n.NeighborID] {
+				best[n.NeighborID] = contribution
+				basedOn[n.NeighborID] = n.ArticleID
+			}
+		}
+
+		for id, score := range scores {
+			entries = append(entries, recommendEntry{ArticleID: id, Score: math.Round(score*10000) / 10000, Reason: RecommendReasonSimilarUsers, BasedOn: basedOn[id]})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].Score != entries[j].Score {
+				return entries[i].Score > entries[j].Score
+			}
+			return entries[i].ArticleID > entries[j].ArticleID
+		})
+		if len(entries) > recommendCandidates {
+			entries = entries[:recommendCandidates]
+		}
+		if entries, err = s.filterVisible(entries, userID, blockedIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// 冷启动或候选不足时用热门文章补足
+	if len(entries) < recommendCandidates {
+		trending, err := NewTrendingService(s.db).GetTrendingArticles(recommendCandidates)
+		if err != nil {
+			log.Printf("获取热门文章失败: %v", err)
+			return entries, nil
+		}
+		for _, e := range entries {
+			excluded[e.ArticleID] = true
+		}
+		var fill []recommendEntry
+		for _, item := range trending {
+			if excluded[item.Article.ID] {
+				continue
+			}
+			excluded[item.Article.ID] = true
+			fill = append(fill, recommendEntry{ArticleID: item.Article.ID, Score: item.Score, Reason: RecommendReasonTrending})
+		}
+		if fill, err = s.filterVisible(fill, userID, blockedIDs); err != nil {
+			return nil, err
+		}
+		entries = append(entries, fill...)
+		if len(entries) > recommendCandidates {
+			entries = entries[:recommendCandidates]
+		}
+	}
+	return entries, nil
+}
+
+// excludedArticleIDs 用户已点赞、收藏或标记不感兴趣的文章
+func (s *RecommendationService) excludedArticleIDs(userID uint) (map[uint]bool, error) {
+	var ids []uint
+	if err := s.db.Raw(`SELECT target_id FROM likes WHERE user_id = ? AND target_type = ?
+		UNION SELECT article_id FROM favorites WHERE user_id = ? AND deleted_at IS NULL
+		UNION SELECT article_id FROM article_dismissals WHERE user_id = ?`,
+		userID, "article", userID, userID).Scan(&ids).Error; err != nil {
+		return nil, fmt.Errorf("查询用户互动失败: %v", err)
+	}
+	excluded := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		excluded[id] = true
+	}
+	return excluded, nil
+}
+
+// filterVisible 保留已发布、非本人且作者未被屏蔽的文章，顺序不变
+func (s *RecommendationService) filterVisible(entries []recommendEntry, userID uint, blockedIDs []uint) ([]recommendEntry, error) {
+	if len(entries) == 0 {
+		return entries, nil
+	}
+	ids := make([]uint, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ArticleID)
+	}
+
+	query := s.db.Model(&models.Article{}).
+		Where("id IN ? AND status = ? AND author_id <> ?", ids, models.ArticleStatusPublished, userID)
+	if len(blockedIDs) > 0 {
+		query = query.Where("author_id NOT IN ?", blockedIDs)
+	}
+	var visibleIDs []uint
+	if err := query.Pluck("id", &visibleIDs).Error; err != nil {
+		return nil, fmt.Errorf("查询推荐文章失败: %v", err)
+	}
+	visible := make(map[uint]bool, len(visibleIDs))
+	for _, id := range visibleIDs {
+		visible[id] = true
+	}
+
+	result := entries[:0]
+	for _, e := range entries {
+		if visible[e.ArticleID] {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+// DismissArticle 将文章标记为不感兴趣，之后不再推荐
+func (s *RecommendationService) DismissArticle(userID, articleID uint) error {
+	var count int64
+	if err := s.db.Model(&models.Article{}).Where("id = ?", articleID).Count(&count).Error; err != nil {
+		return fmt.Errorf("查询文章失败: %v", err)
+	}
+	if count == 0 {
+		return errors.New("文章不存在")
+	}
+
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.ArticleDismissal{UserID: userID, ArticleID: articleID}).Error; err != nil {
+		return fmt.Errorf("保存失败: %v", err)
+	}
+	s.InvalidateRecommendations(userID)
+	return nil
+}
+
+// InvalidateRecommendations 清除用户的推荐缓存
+func (s *RecommendationService) InvalidateRecommendations(userID uint) {
+	if err := s.cacheService.Delete(recommendCacheKey(userID)); err != nil && !errors.Is(err, ErrCacheDisabled) {
+		log.Printf("清除推荐缓存失败 (user: %d): %v", userID, err)
+	}
+}
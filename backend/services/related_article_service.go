@@ -0,0 +1,753 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// relatedMaxArticles 参与相似度计算的最近发布文章数上限
+	relatedMaxArticles = 5000
+	// relatedRefreshMaxArticles 单篇刷新时参与比较的最近文章数，更早的文章由全量计算覆盖
+	relatedRefreshMaxArticles = 1000
+	// relatedRefreshDelay 单篇刷新的合并等待时间，等待期内的连续保存只计算一次
+	relatedRefreshDelay = 30 * time.Second
+	// relatedPerArticle 每篇文章保存的相似文章数
+	relatedPerArticle = 10
+	// relatedMinScore 综合得分低于该值的文章不视为相似
+	relatedMinScore = 0.05
+	// relatedVectorTerms 每篇文章词向量保留的词项数
+	relatedVectorTerms = 200
+	// relatedMaxContentRunes 参与分词的正文长度上限
+	relatedMaxContentRunes = 20000
+	// relatedTitleBoost 标题词项的权重倍数
+	relatedTitleBoost = 3
+	// relatedMaxUserFavorites 收藏数超过该值的用户不参与共同收藏计算，避免“全都收藏”的账号稀释信号
+	relatedMaxUserFavorites = 500
+	relatedCacheTTL         = 30 * time.Minute
+)
+
+// 综合得分中各信号的权重：内容相似度为主，共同收藏次之，同分类只做加成
+const (
+	relatedContentWeight    = 0.7
+	relatedCofavoriteWeight = 0.2
+	relatedCategoryWeight   = 0.1
+)
+
+// relatedStopWords 常见英文虚词
+var relatedStopWords = map[string]struct{}{
+	"the": {}, "and": {}, "for": {}, "are": {}, "but": {}, "not": {}, "you": {}, "with": {},
+	"this": {}, "that": {}, "from": {}, "have": {}, "was": {}, "were": {}, "will": {}, "can": {},
+	"of": {}, "to": {}, "in": {}, "is": {}, "it": {}, "on": {}, "as": {}, "at": {}, "be": {},
+	"by": {}, "or": {}, "an": {}, "if": {}, "we": {}, "do": {}, "so": {}, "nbsp": {},
+}
+
+// RelatedArticleService 基于内容相似度、分类与共同收藏的相似文章推荐
+// 词向量与相似关系由定时任务离线全量计算，文章发布或更新后单独刷新该文章
+type RelatedArticleService struct {
+	db             *gorm.DB
+	cacheService   *CacheService
+	articleService *ArticleService
+}
+
+// NewRelatedArticleService 创建相似文章服务
+func NewRelatedArticleService(db *gorm.DB) *RelatedArticleService {
+	cacheService := NewCacheService()
+	return &RelatedArticleService{
+		db:             db,
+		cacheService:   cacheService,
+		articleService: NewArticleServiceWithDI(db, cacheService),
+	}
+}
+
+var (
+	relatedRefreshMu     sync.Mutex
+	relatedRefreshTimers = make(map[uint]*time.Timer)
+)
+
+// relatedSource 参与计算的文章
+type relatedSource struct {
+	ID         uint
+	Title      string
+	Content    string
+	CategoryID uint
+}
+
+// relatedVector 文章的归一化词向量
+type relatedVector struct {
+	ArticleID  uint
+	CategoryID uint
+	Terms      map[string]float64
+}
+
+func relatedCacheKey(articleID uint) string {
+	return fmt.Sprintf("article:related:%d", articleID)
+}
+
+// GetRelatedArticles 获取相似文章；预计算结果不足时用同分类的热门文章补足
+func (s *RelatedArticleService) GetRelatedArticles(articleID uint, limit int) ([]*models.Article, error) {
+	var article models.Article
+	if err := s.db.Select("id, category_id").
+		Where("id = ? AND status = ?", articleID, models.ArticleStatusPublished).
+		First(&article).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("文章不存在")
+		}
+		return nil, fmt.Errorf("查询文章失败: %v", err)
+	}
+
+	var ids []uint
+	if err := s.cacheService.Get(relatedCacheKey(articleID), &ids); err != nil {
+		if ids, err = s.relatedIDs(&article); err != nil {
+			return nil, err
+		}
+		if err := s.cacheService.SetWithExpire(relatedCacheKey(articleID), ids, relatedCacheTTL); err != nil && !errors.Is(err, ErrCacheDisabled) {
+			log.Printf("缓存相似文章失败 (article: %d): %v", articleID, err)
+		}
+	}
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	if len(ids) == 0 {
+		return []*models.Article{}, nil
+	}
+
+	var articles []*models.Article
+	if err := s.db.Preload("Author").Preload("Category").Preload("Tags").
+		Where("id IN ? AND status = ?", ids, models.ArticleStatusPublished).
+		Find(&articles).Error; err != nil {
+		return nil, fmt.Errorf("查询相似文章失败: %v", err)
+	}
+	byID := make(map[uint]*models.Article, len(articles))
+	for _, a := range articles {
+		byID[a.ID] = a
+	}
+	result := make([]*models.Article, 0, len(ids))
+	for _, id := range ids {
+		if a, ok := byID[id]; ok {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+// relatedIDs 按得分读取预计算的相似文章，不足 relatedPerArticle 篇时用同分类热门文章补足
+func (s *RelatedArticleService) relatedIDs(article *models.Article) ([]uint, error) {
+	ids := []uint{}
+	if err := s.db.Model(&models.ArticleRelation{}).
+		Joins("JOIN articles ON articles.id = article_relations.related_id").
+		Where("article_relations.article_id = ? AND articles.status = ? AND articles.deleted_at IS NULL", article.ID, models.ArticleStatusPublished).
+		Order("article_relations.score DESC").
+		Limit(relatedPerArticle).
+		Pluck("article_relations.related_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("查询相似文章失败: %v", err)
+	}
+	if len(ids) >= relatedPerArticle {
+		return ids, nil
+	}
+
+	exclude := append([]uint{article.ID}, ids...)
+	var fill []uint
+	if err := s.db.Model(&models.Article{}).
+		Where("category_id = ? AND status = ? AND id NOT IN ?", article.CategoryID, models.ArticleStatusPublished, exclude).
+		Order("like_count + favorite_count DESC, id DESC").
+		Limit(relatedPerArticle-len(ids)).
+		Pluck("id", &fill).Error; err != nil {
+		return nil, fmt.Errorf("查询相似文章失败: %v", err)
+	}
+	return append(ids, fill...), nil
+}
+
+// RebuildAll 全量重新计算词频统计、词向量与相似关系
+// 先扫描一遍统计文档频率，再扫描一遍生成词向量，避免同时在内存中保存所有文章的完整词频
+func (s *RelatedArticleService) RebuildAll() (string, error) {
+	started := time.Now()
+
+	df := make(map[string]int)
+	docs := 0
+	err := s.scanSources(func(src *relatedSource) {
+		for term := range tokenizeArticle(src.Title, s.articleService.cleanHTMLContent(src.Content)) {
+			df[term]++
+		}
+		docs++
+	})
+	if err != nil {
+		return "", err
+	}
+
+	vectors := make([]*relatedVector, 0, docs)
+	err = s.scanSources(func(src *relatedSource) {
+		counts := tokenizeArticle(src.Title, s.articleService.cleanHTMLContent(src.Content))
+		vectors = append(vectors, &relatedVector{
+			ArticleID:  src.ID,
+			CategoryID: src.CategoryID,
+			Terms:      buildTermVector(counts, func(term string) int { return df[term] }, docs),
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	relations := s.computeRelations(vectors, s.loadCofavorites(nil), nil)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 只出现在一篇文章中的词项对相似度没有贡献，不保存；查询不到的词项按文档频率 1 处理
+		if err := tx.Where("1 = 1").Delete(&models.ArticleTermStat{}).Error; err != nil {
+			return fmt.Errorf("清理词频统计失败: %v", err)
+		}
+		stats := make([]models.ArticleTermStat, 0, 1000)
+		for term, count := range df {
+			if count < 2 {
+				continue
+			}
+			stats = append(stats, models.ArticleTermStat{Term: term, DocCount: count})
+			if len(stats) == cap(stats) {
+				if err := tx.Create(&stats).Error; err != nil {
+					return fmt.Errorf("保存词频统计失败: %v", err)
+				}
+				stats = stats[:0]
+			}
+		}
+		if len(stats) > 0 {
+			if err := tx.Create(&stats).Error; err != nil {
+				return fmt.Errorf("保存词频统计失败: %v", err)
+			}
+		}
+
+		if err := tx.Where("1 = 1").Delete(&models.ArticleVector{}).Error; err != nil {
+			return fmt.Errorf("清理词向量失败: %v", err)
+		}
+		rows := make([]models.ArticleVector, 0, len(vectors))
+		for _, v := range vectors {
+			rows = append(rows, models.ArticleVector{ArticleID: v.ArticleID, Terms: encodeTermVector(v.Terms)})
+		}
+		if len(rows) > 0 {
+			if err := tx.CreateInBatches(rows, 200).Error; err != nil {
+				return fmt.Errorf("保存词向量失败: %v", err)
+			}
+		}
+
+		if err := tx.Where("1 = 1").Delete(&models.ArticleRelation{}).Error; err != nil {
+			return fmt.Errorf("清理相似文章失败: %v", err)
+		}
+		if len(relations) > 0 {
+			if err := tx.CreateInBatches(relations, 500).Error; err != nil {
+				return fmt.Errorf("保存相似文章失败: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	s.cacheService.DeletePattern("article:related:*")
+	return fmt.Sprintf("计算了 %d 篇文章的相似关系，共 %d 条，耗时 %s", len(vectors), len(relations), time.Since(started).Round(time.Millisecond)), nil
+}
+
+// ScheduleRefresh 延迟刷新文章的相似文章，等待期内重复调用会推迟并合并为一次计算
+func (s *RelatedArticleService) ScheduleRefresh(articleID uint) {
+	relatedRefreshMu.Lock()
+	defer relatedRefreshMu.Unlock()
+
+	if timer, ok := relatedRefreshTimers[articleID]; ok && timer.Stop() {
+		timer.Reset(relatedRefreshDelay)
+		return
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(relatedRefreshDelay, func() {
+		relatedRefreshMu.Lock()
+		if relatedRefreshTimers[articleID] == timer {
+			delete(relatedRefreshTimers, articleID)
+		}
+		relatedRefreshMu.Unlock()
+
+		if err := s.RefreshArticle(articleID); err != nil {
+			log.Printf("刷新相似文章失败 (article: %d): %v", articleID, err)
+		}
+	})
+	relatedRefreshTimers[articleID] = timer
+}
+
+// RefreshArticle 文章发布或更新后重新计算该文章的词向量与相似文章，并更新其相似文章中指向它的记录
+// 文档频率沿用最近一次全量计算的结果，下次全量计算时再统一校正；
+// 只与最近 relatedRefreshMaxArticles 篇文章比较，更早的文章等待全量计算
+func (s *RelatedArticleService) RefreshArticle(articleID uint) error {
+	var src relatedSource
+	err := s.db.Model(&models.Article{}).Select("id, title, content, category_id").
+		Where("id = ? AND status = ?", articleID, models.ArticleStatusPublished).
+		First(&src).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// 未发布或已删除的文章不再参与推荐
+		return s.RemoveArticle(articleID)
+	}
+	if err != nil {
+		return fmt.Errorf("查询文章失败: %v", err)
+	}
+
+	counts := tokenizeArticle(src.Title, s.articleService.cleanHTMLContent(src.Content))
+	df, docs, err := s.loadTermStats(counts)
+	if err != nil {
+		return err
+	}
+	target := &relatedVector{
+		ArticleID:  src.ID,
+		CategoryID: src.CategoryID,
+		Terms:      buildTermVector(counts, func(term string) int { return df[term] }, docs),
+	}
+
+	others, err := s.loadVectors(articleID, relatedRefreshMaxArticles)
+	if err != nil {
+		return err
+	}
+	own := s.computeRelations(append(others, target), s.loadCofavorites(&articleID), func(v *relatedVector) bool {
+		return v.ArticleID == articleID
+	})
+
+	// 得分是对称的，本文的相似文章同样可能把本文排进自己的前列
+	neighbors := make(map[uint]models.ArticleRelation, len(own))
+	for _, r := range own {
+		r.ArticleID, r.RelatedID = r.RelatedID, r.ArticleID
+		neighbors[r.ArticleID] = r
+	}
+
+	affected := []uint{articleID}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "article_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"terms", "updated_at"}),
+		}).Create(&models.ArticleVector{ArticleID: articleID, Terms: encodeTermVector(target.Terms)}).Error; err != nil {
+			return fmt.Errorf("保存词向量失败: %v", err)
+		}
+
+		if err := tx.Where("article_id = ?", articleID).Delete(&models.ArticleRelation{}).Error; err != nil {
+			return fmt.Errorf("清理相似文章失败: %v", err)
+		}
+		if len(own) > 0 {
+			if err := tx.Create(&own).Error; err != nil {
+				return fmt.Errorf("保存相似文章失败: %v", err)
+			}
+		}
+
+		// 其他文章指向本文的记录：不再相似的删除，仍然相似的更新得分并重新截取前列
+		var stale []uint
+		if err := tx.Model(&models.ArticleRelation{}).Where("related_id = ?", articleID).Pluck("article_id", &stale).Error; err != nil {
+			return fmt.Errorf("查询相似文章失败: %v", err)
+		}
+		for _, id := range stale {
+			if _, ok := neighbors[id]; !ok {
+				affected = append(affected, id)
+			}
+		}
+		if err := tx.Where("related_id = ?", articleID).Delete(&models.ArticleRelation{}).Error; err != nil {
+			return fmt.Errorf("清理相似文章失败: %v", err)
+		}
+		for id, r := range neighbors {
+			if err := tx.Create(&r).Error; err != nil {
+				return fmt.Errorf("保存相似文章失败: %v", err)
+			}
+			if err := trimArticleRelations(tx, id); err != nil {
+				return err
+			}
+			affected = append(affected, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range affected {
+		s.cacheService.Delete(relatedCacheKey(id))
+	}
+	return nil
+}
+
+// RemoveArticle 删除文章的词向量与相关的相似记录
+func (s *RelatedArticleService) RemoveArticle(articleID uint) error {
+	var referrers []uint
+	if err := s.db.Model(&models.ArticleRelation{}).Where("related_id = ?", articleID).Pluck("article_id", &referrers).Error; err != nil {
+		return fmt.Errorf("查询相似文章失败: %v", err)
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("article_id = ?", articleID).Delete(&models.ArticleVector{}).Error; err != nil {
+			return fmt.Errorf("删除词向量失败: %v", err)
+		}
+		if err := tx.Where("article_id = ? OR related_id = ?", articleID, articleID).Delete(&models.ArticleRelation{}).Error; err != nil {
+			return fmt.Errorf("删除相似文章失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cacheService.Delete(relatedCacheKey(articleID))
+	for _, id := range referrers {
+		s.cacheService.Delete(relatedCacheKey(id))
+	}
+	return nil
+}
+
+// trimArticleRelations 只保留文章得分最高的 relatedPerArticle 条相似记录
+func trimArticleRelations(tx *gorm.DB, articleID uint) error {
+	var keep []uint
+	if err := tx.Model(&models.ArticleRelation{}).Where("article_id = ?", articleID).
+		Order("score DESC").Limit(relatedPerArticle).Pluck("id", &keep).Error; err != nil {
+		return fmt.Errorf("查询相似文章失败: %v", err)
+	}
+	if len(keep) == 0 {
+		return nil
+	}
+	if err := tx.Where("article_id = ? AND id NOT IN ?", articleID, keep).Delete(&models.ArticleRelation{}).Error; err != nil {
+		return fmt.Errorf("清理相似文章失败: %v", err)
+	}
+	return nil
+}
+
+// scanSources 分批遍历参与计算的已发布文章（按发布时间取最近 relatedMaxArticles 篇）
+func (s *RelatedArticleService) scanSources(fn func(src *relatedSource)) error {
+	var ids []uint
+	if err := s.db.Model(&models.Article{}).
+		Where("status = ?", models.ArticleStatusPublished).
+		Order("COALESCE(published_at, created_at) DESC").
+		Limit(relatedMaxArticles).
+		Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("查询文章失败: %v", err)
+	}
+
+	const batchSize = 200
+	for start := 0; start < len(ids); start += batchSize {
+		end := min(start+batchSize, len(ids))
+		var batch []relatedSource
+		if err := s.db.Model(&models.Article{}).Select("id, title, content, category_id").
+			Where("id IN ?", ids[start:end]).Order("id").Find(&batch).Error; err != nil {
+			return fmt.Errorf("查询文章失败: %v", err)
+		}
+		for i := range batch {
+			fn(&batch[i])
+		}
+	}
+	return nil
+}
+
+// loadVectors 读取最近 limit 篇其他已发布文章的词向量
+func (s *RelatedArticleService) loadVectors(excludeID uint, limit int) ([]*relatedVector, error) {
+	var rows []struct {
+		ArticleID  uint
+		CategoryID uint
+		Terms      string
+	}
+	if err := s.db.Table("article_vectors").
+		Select("article_vectors.article_id, articles.category_id, article_vectors.terms").
+		Joins("JOIN articles ON articles.id = article_vectors.article_id").
+		Where("article_vectors.article_id <> ? AND articles.status = ? AND articles.deleted_at IS NULL", excludeID, models.ArticleStatusPublished).
+		Order("COALESCE(articles.published_at, articles.created_at) DESC").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询词向量失败: %v", err)
+	}
+
+	vectors := make([]*relatedVector, 0, len(rows))
+	for _, row := range rows {
+		terms := make(map[string]float64)
+		if err := json.Unmarshal([]byte(row.Terms), &terms); err != nil {
+			continue
+		}
+		vectors = append(vectors, &relatedVector{ArticleID: row.ArticleID, CategoryID: row.CategoryID, Terms: terms})
+	}
+	return vectors, nil
+}
+
+// loadTermStats 读取指定词项的文档频率与文档总数
+func (s *RelatedArticleService) loadTermStats(counts map[string]int) (map[string]int, int, error) {
+	terms := make([]string, 0, len(counts))
+	for term := range counts {
+		terms = append(terms, term)
+	}
+
+	df := make(map[string]int, len(terms))
+	for start := 0; start < len(terms); start += 1000 {
+		end := min(start+1000, len(terms))
+		var stats []models.ArticleTermStat
+		if err := s.db.Where("term IN ?", terms[start:end]).Find(&stats).Error; err != nil {
+			return nil, 0, fmt.Errorf("查询词频统计失败: %v", err)
+		}
+		for _, stat := range stats {
+			df[stat.Term] = stat.DocCount
+		}
+	}
+
+	var docs int64
+	if err := s.db.Model(&models.ArticleVector{}).Count(&docs).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询词向量失败: %v", err)
+	}
+	return df, int(docs) + 1, nil
+}
+
+// cofavoriteStats 共同收藏统计
+type cofavoriteStats struct {
+	totals map[uint]int
+	pairs  map[uint]map[uint]int
+}
+
+// score 两篇文章收藏用户集合的余弦相似度
+func (c *cofavoriteStats) score(a, b uint) float64 {
+	co := c.pairs[a][b]
+	if co == 0 {
+		return 0
+	}
+	return float64(co) / math.Sqrt(float64(c.totals[a])*float64(c.totals[b]))
+}
+
+// loadCofavorites 统计共同收藏；指定 articleID 时只统计与该文章相关的组合
+func (s *RelatedArticleService) loadCofavorites(articleID *uint) *cofavoriteStats {
+	stats := &cofavoriteStats{totals: make(map[uint]int), pairs: make(map[uint]map[uint]int)}
+
+	query := s.db.Model(&models.Favorite{}).Select("user_id, article_id").
+		Where("user_id IN (?)", s.db.Model(&models.Favorite{}).Select("user_id").
+			Group("user_id").Having("COUNT(*) <= ?", relatedMaxUserFavorites))
+	if articleID != nil {
+		query = query.Where("user_id IN (?)", s.db.Model(&models.Favorite{}).Select("user_id").Where("article_id = ?", *articleID))
+	}
+	var rows []struct {
+		UserID    uint
+		ArticleID uint
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		log.Printf("统计共同收藏失败: %v", err)
+		return stats
+	}
+
+	byUser := make(map[uint][]uint)
+	for _, row := range rows {
+		byUser[row.UserID] = append(byUser[row.UserID], row.ArticleID)
+	}
+	for _, articles := range byUser {
+		for _, a := range articles {
+			stats.totals[a]++
+			for _, b := range articles {
+				if a == b || (articleID != nil && a != *articleID && b != *articleID) {
+					continue
+				}
+				if stats.pairs[a] == nil {
+					stats.pairs[a] = make(map[uint]int)
+				}
+				stats.pairs[a][b]++
+			}
+		}
+	}
+	// 单篇刷新时只加载了收藏过该文章的用户，其他文章的收藏总数需要单独查询
+	if articleID != nil && len(stats.pairs[*articleID]) > 0 {
+		ids := make([]uint, 0, len(stats.pairs[*articleID]))
+		for id := range stats.pairs[*articleID] {
+			ids = append(ids, id)
+		}
+		var totals []struct {
+			ArticleID uint
+			Total     int
+		}
+		if err := s.db.Model(&models.Favorite{}).Select("article_id, COUNT(*) AS total").
+			Where("article_id IN ?", ids).Group("article_id").Scan(&totals).Error; err == nil {
+			for _, t := range totals {
+				stats.totals[t.ArticleID] = t.Total
+			}
+		}
+	}
+	return stats
+}
+
+// computeRelations 计算文章的相似文章，include 为 nil 时计算全部文章，否则只计算满足条件的文章
+// 内容相似度通过倒排索引只在有共同词项的文章之间计算；仅同分类而内容与收藏都不相关的文章不作为候选
+func (s *RelatedArticleService) computeRelations(vectors []*relatedVector, cofav *cofavoriteStats, include func(v *relatedVector) bool) []models.ArticleRelation {
+	type posting struct {
+		index  int
+		weight float64
+	}
+	index := make(map[string][]posting)
+	positions := make(map[uint]int, len(vectors))
+	for i, v := range vectors {
+		positions[v.ArticleID] = i
+		for term, weight := range v.Terms {
+			index[term] = append(index[term], posting{index: i, weight: weight})
+		}
+	}
+
+	now := time.Now()
+	relations := []models.ArticleRelation{}
+	for i, v := range vectors {
+		if include != nil && !include(v) {
+			continue
+		}
+		content := make(map[int]float64)
+		for term, weight := range v.Terms {
+			for _, p := range index[term] {
+				if p.index != i {
+					content[p.index] += weight * p.weight
+				}
+			}
+		}
+		for related := range cofav.pairs[v.ArticleID] {
+			if j, ok := positions[related]; ok {
+				if _, exists := content[j]; !exists {
+					content[j] = 0
+				}
+			}
+		}
+
+		candidates := make([]models.ArticleRelation, 0, len(content))
+		for j, contentScore := range content {
+			other := vectors[j]
+			r := models.ArticleRelation{
+				ArticleID:       v.ArticleID,
+				RelatedID:       other.ArticleID,
+				ContentScore:    roundScore(contentScore),
+				CofavoriteScore: roundScore(cofav.score(v.ArticleID, other.ArticleID)),
+				UpdatedAt:       now,
+			}
+			if v.CategoryID == other.CategoryID {
+				r.CategoryScore = 1
+			}
+			r.Score = roundScore(relatedContentWeight*r.ContentScore + relatedCofavoriteWeight*r.CofavoriteScore + relatedCategoryWeight*r.CategoryScore)
+			if r.Score >= relatedMinScore {
+				candidates = append(candidates, r)
+			}
+		}
+		sort.Slice(candidates, func(a, b int) bool {
+			if candidates[a].Score != candidates[b].Score {
+				return candidates[a].Score > candidates[b].Score
+			}
+			return candidates[a].RelatedID > candidates[b].RelatedID
+		})
+		if len(candidates) > relatedPerArticle {
+			candidates = candidates[:relatedPerArticle]
+		}
+		relations = append(relations, candidates...)
+	}
+	return relations
+}
+
+// tokenizeArticle 统计标题与正文的词频：中文按相邻两字切分（bigram），英文与数字按单词切分
+func tokenizeArticle(title, content string) map[string]int {
+	counts := make(map[string]int)
+	for term, n := range tokenizeText(title) {
+		counts[term] += n * relatedTitleBoost
+	}
+	if runes := []rune(content); len(runes) > relatedMaxContentRunes {
+		content = string(runes[:relatedMaxContentRunes])
+	}
+	for term, n := range tokenizeText(content) {
+		counts[term] += n
+	}
+	return counts
+}
+
+func tokenizeText(text string) map[string]int {
+	counts := make(map[string]int)
+	var han []rune
+	var word strings.Builder
+
+	flushHan := func() {
+		for i := 0; i+1 < len(han); i++ {
+			counts[string(han[i:i+2])]++
+		}
+		han = han[:0]
+	}
+	flushWord := func() {
+		w := word.String()
+		word.Reset()
+		n := len([]rune(w))
+		if n < 2 || n > 32 || isDigits(w) {
+			return
+		}
+		if _, stop := relatedStopWords[w]; !stop {
+			counts[w]++
+		}
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word.WriteRune(r)
+		default:
+			flushHan()
+			flushWord()
+		}
+	}
+	flushHan()
+	flushWord()
+	return counts
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildTermVector 计算 TF-IDF 权重（对数词频 × 平滑 IDF），保留权重最高的 relatedVectorTerms 个词项并做 L2 归一化
+func buildTermVector(counts map[string]int, df func(term string) int, docs int) map[string]float64 {
+	type weighted struct {
+		term   string
+		weight float64
+	}
+	items := make([]weighted, 0, len(counts))
+	for term, n := range counts {
+		freq := max(df(term), 1)
+		idf := math.Log(float64(docs+1)/float64(freq+1)) + 1
+		items = append(items, weighted{term: term, weight: (1 + math.Log(float64(n))) * idf})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].weight != items[j].weight {
+			return items[i].weight > items[j].weight
+		}
+		return items[i].term < items[j].term
+	})
+	if len(items) > relatedVectorTerms {
+		items = items[:relatedVectorTerms]
+	}
+
+	var norm float64
+	for _, item := range items {
+		norm += item.weight * item.weight
+	}
+	norm = math.Sqrt(norm)
+
+	vector := make(map[string]float64, len(items))
+	for _, item := range items {
+		vector[item.term] = item.weight / norm
+	}
+	return vector
+}
+
+func encodeTermVector(terms map[string]float64) string {
+	data, err := json.Marshal(terms)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*10000) / 10000
+}
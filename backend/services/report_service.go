@@ -8,6 +8,7 @@ import (
     "godad-backend/config"
     "godad-backend/models"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 type ReportService struct {
@@ -28,7 +29,7 @@ type CreateReportRequest struct {
 
 func (s *ReportService) CreateReport(req *CreateReportRequest, reporterID uint) (*models.Report, error) {
     rt := strings.ToLower(req.TargetType)
-    if rt != "article" && rt != "forum_post" { return nil, fmt.Errorf("不支持的举报类型") }
+    if !models.IsValidReportTarget(rt) { return nil, fmt.Errorf("不支持的举报类型") }
 
     r := &models.Report{
         TargetType: rt,
@@ -66,41 +67,42 @@ func (s *ReportService) ListReports(p *ReportListParams) ([]models.Report, int64
     return items, total, nil
 }
 
-func (s *ReportService) UpdateStatus(id uint, status string, handledBy uint, note string, action string) (*models.Report, error) {
+// UpdateStatus 处理举报
+// 举报成立(reviewed)时在同一事务内执行处理动作并写入审计记录，提交后通知内容作者
+func (s *ReportService) UpdateStatus(id uint, status string, handledBy uint, note string, moderation *ModerationRequest) (*models.Report, error) {
     if status != "reviewed" && status != "rejected" { return nil, fmt.Errorf("无效状态") }
-    // 先读取当前状态，防止重复处理
-    var current models.Report
-    if err := s.db.Where("id = ?", id).First(&current).Error; err != nil {
-        if errors.Is(err, gorm.ErrRecordNotFound) { return nil, fmt.Errorf("举报不存在") }
-        return nil, err
-    }
-    if current.Status != "pending" {
-        return nil, fmt.Errorf("举报已处理")
-    }
-    updates := map[string]interface{}{
-        "status":       status,
-        "handled_by":   handledBy,
-        "handled_note": strings.TrimSpace(note),
-    }
-    if action != "" { updates["handled_action"] = strings.TrimSpace(action) }
-    tx := s.db.Model(&models.Report{}).Where("id = ? AND status = ?", id, "pending").Updates(updates)
-    if tx.Error != nil {
-        // 兼容旧库：若 handled_action 不存在导致失败，去掉该字段重试
-        if action != "" {
-            delete(updates, "handled_action")
-            if err2 := s.db.Model(&models.Report{}).Where("id = ? AND status = ?", id, "pending").Updates(updates).Error; err2 != nil {
-                return nil, tx.Error
-            }
-        } else {
-            return nil, tx.Error
-        }
-    }
-    if tx.RowsAffected == 0 {
-        return nil, fmt.Errorf("举报已处理")
-    }
+    if status == "reviewed" && moderation == nil { return nil, fmt.Errorf("无效处理动作") }
+
+    ms := NewModerationService(s.db)
     var r models.Report
-    if err := s.db.Where("id = ?", id).First(&r).Error; err != nil {
-        return nil, err
+    var entry *models.ModerationLog
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        // 锁定举报记录，防止重复处理
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&r).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) { return fmt.Errorf("举报不存在") }
+            return err
+        }
+        if r.Status != "pending" { return fmt.Errorf("举报已处理") }
+
+        updates := map[string]interface{}{
+            "status":       status,
+            "handled_by":   handledBy,
+            "handled_note": strings.TrimSpace(note),
+        }
+        if status == "reviewed" {
+            if err := ms.ValidateRequest(r.TargetType, moderation); err != nil { return err }
+            if moderation.Note == "" { moderation.Note = note }
+            var err error
+            if entry, err = ms.Apply(tx, &r, moderation, handledBy); err != nil { return err }
+            updates["handled_action"] = string(moderation.Action)
+        }
+        return tx.Model(&models.Report{}).Where("id = ?", r.ID).Updates(updates).Error
+    })
+    if err != nil { return nil, err }
+    if err := s.db.Where("id = ?", id).First(&r).Error; err != nil { return nil, err }
+
+    if entry != nil {
+        ms.AfterApply(entry, &r)
     }
     return &r, nil
 }
@@ -111,6 +111,8 @@ func (s *ResourceService) CreateResource(req *CreateResourceRequest) (*models.Re
 		s.db.Preload("Uploader").First(resource, resource.ID)
 	}
 
+	s.indexResource(resource)
+
 	return resource, nil
 }
 
@@ -243,6 +245,8 @@ func (s *ResourceService) UpdateResource(id uint, req *UpdateResourceRequest) (*
 		s.db.Preload("Uploader").First(&resource, resource.ID)
 	}
 
+	s.indexResource(&resource)
+
 	return &resource, nil
 }
 
@@ -260,6 +264,9 @@ func (s *ResourceService) UpdateResourceStatus(id uint, status int) error {
 		return fmt.Errorf("更新资源状态失败: %v", err)
 	}
 
+	resource.Status = status
+	s.indexResource(&resource)
+
 	return nil
 }
 
@@ -277,9 +284,23 @@ func (s *ResourceService) DeleteResource(id uint) error {
 		return fmt.Errorf("删除资源失败: %v", err)
 	}
 
+	removeSearchDocument(SearchTypeResource, resource.ID)
+
 	return nil
 }
 
+// indexResource 同步资源到搜索索引（仅已发布资源可被检索）
+func (s *ResourceService) indexResource(resource *models.Resource) {
+	indexSearchDocument(&SearchDocument{
+		Type:      SearchTypeResource,
+		ID:        resource.ID,
+		Title:     resource.Title,
+		Content:   resource.Description,
+		Visible:   resource.Status == models.ResourceStatusApproved,
+		CreatedAt: resource.CreatedAt,
+	})
+}
+
 // IncrementDownloadCount 增加下载次数
 func (s *ResourceService) IncrementDownloadCount(id uint) error {
 	err := s.db.Model(&models.Resource{}).Where("id = ?", id).
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"html"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"godad-backend/config"
+)
+
+// 可检索的文档类型
+const (
+	SearchTypeArticle   = "article"
+	SearchTypeForumPost = "forum_post"
+	SearchTypeResource  = "resource"
+)
+
+// SearchTypes 全部可检索的文档类型
+var SearchTypes = []string{SearchTypeArticle, SearchTypeForumPost, SearchTypeResource}
+
+// 排序方式
+const (
+	SearchSortRelevance = "relevance" // 相关度（含时间衰减）
+	SearchSortLatest    = "latest"    // 最新发布
+)
+
+const (
+	// snippetLength 摘要片段长度（字符数）
+	snippetLength = 120
+	// snippetLeading 命中词之前保留的上下文长度（字符数）
+	snippetLeading = 30
+)
+
+// SearchDocument 需要写入索引的文档
+type SearchDocument struct {
+	Type       string
+	ID         uint
+	Title      string
+	Content    string
+	CategoryID uint
+	Visible    bool // 是否允许被检索（已发布且未删除）
+	CreatedAt  time.Time
+}
+
+// SearchQuery 索引查询条件
+type SearchQuery struct {
+	Keyword    string
+	Types      []string // 为空时检索全部类型
+	CategoryID uint     // 仅文章有分类，指定后只检索文章
+	Sort       string
+	Offset     int
+	Limit      int
+}
+
+// SearchHit 检索命中项
+type SearchHit struct {
+	Type           string    `json:"type"`
+	ID             uint      `json:"id"`
+	Title          string    `json:"title"`
+	TitleHighlight string    `json:"title_highlight"` // 高亮后的标题（已转义，命中词用 <em> 包裹）
+	Snippet        string    `json:"snippet"`         // 高亮后的正文片段（已转义，命中词用 <em> 包裹）
+	Score          float64   `json:"score"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// SearchIndex 全文检索索引
+// 默认实现基于 MySQL FULLTEXT（ngram 分词），也可替换为外部搜索引擎
+type SearchIndex interface {
+	// Search 按关键词检索，返回当前页命中项与命中总数
+	Search(query *SearchQuery) ([]SearchHit, int64, error)
+	// Index 新增或更新文档；文档不可见时应从索引中移除
+	Index(doc *SearchDocument) error
+	// Remove 从索引中移除文档
+	Remove(docType string, id uint) error
+}
+
+var (
+	searchIndex   SearchIndex
+	searchIndexMu sync.RWMutex
+)
+
+// GetSearchIndex 获取全局搜索索引，未设置时使用 MySQL 全文索引
+func GetSearchIndex() SearchIndex {
+	searchIndexMu.RLock()
+	idx := searchIndex
+	searchIndexMu.RUnlock()
+	if idx != nil {
+		return idx
+	}
+
+	searchIndexMu.Lock()
+	defer searchIndexMu.Unlock()
+	if searchIndex == nil {
+		searchIndex = NewMySQLSearchIndex(config.GetDB())
+	}
+	return searchIndex
+}
+
+// SetSearchIndex 替换全局搜索索引实现
+func SetSearchIndex(idx SearchIndex) {
+	searchIndexMu.Lock()
+	searchIndex = idx
+	searchIndexMu.Unlock()
+}
+
+// indexSearchDocument 维护索引，失败只记录日志，不影响业务写入
+func indexSearchDocument(doc *SearchDocument) {
+	if err := GetSearchIndex().Index(doc); err != nil {
+		log.Printf("更新搜索索引失败 type=%s id=%d: %v", doc.Type, doc.ID, err)
+	}
+}
+
+// removeSearchDocument 从索引移除文档，失败只记录日志
+func removeSearchDocument(docType string, id uint) {
+	if err := GetSearchIndex().Remove(docType, id); err != nil {
+		log.Printf("移除搜索索引失败 type=%s id=%d: %v", docType, id, err)
+	}
+}
+
+var (
+	searchImageReg    = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	searchLinkReg     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	searchHTMLTagReg  = regexp.MustCompile(`<[^>]*>`)
+	searchMarkdownReg = regexp.MustCompile("[#*>`~|]+")
+	searchSpaceReg    = regexp.MustCompile(`\s+`)
+)
+
+// searchPlainText 去除 HTML 标签与常见 Markdown 标记，得到用于生成摘要的纯文本
+func searchPlainText(content string) string {
+	content = searchImageReg.ReplaceAllString(content, "")
+	content = searchLinkReg.ReplaceAllString(content, "$1")
+	content = searchHTMLTagReg.ReplaceAllString(content, " ")
+	content = html.UnescapeString(content)
+	content = searchMarkdownReg.ReplaceAllString(content, " ")
+	content = searchSpaceReg.ReplaceAllString(content, " ")
+	return strings.TrimSpace(content)
+}
+
+// searchTerms 将关键词按空白拆分为检索词
+func searchTerms(keyword string) []string {
+	return strings.Fields(keyword)
+}
+
+// searchHighlighter 构造匹配任一检索词的正则（忽略大小写），长词优先
+func searchHighlighter(terms []string) *regexp.Regexp {
+	if len(terms) == 0 {
+		return nil
+	}
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+	// 长词优先，避免短词先行匹配截断长词
+	for i := 1; i < len(quoted); i++ {
+		for j := i; j > 0 && utf8.RuneCountInString(quoted[j]) > utf8.RuneCountInString(quoted[j-1]); j-- {
+			quoted[j], quoted[j-1] = quoted[j-1], quoted[j]
+		}
+	}
+	return regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+}
+
+// highlightText 转义文本并用 <em> 包裹命中词
+func highlightText(text string, matcher *regexp.Regexp) string {
+	if matcher == nil {
+		return html.EscapeString(text)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range matcher.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:loc[0]]))
+		b.WriteString("<em>")
+		b.WriteString(html.EscapeString(text[loc[0]:loc[1]]))
+		b.WriteString("</em>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// buildSnippet 截取命中词附近的正文片段并高亮；未命中时取正文开头
+func buildSnippet(content string, matcher *regexp.Regexp) string {
+	text := searchPlainText(content)
+	runes := []rune(text)
+
+	start := 0
+	if matcher != nil {
+		if loc := matcher.FindStringIndex(text); loc != nil {
+			start = utf8.RuneCountInString(text[:loc[0]]) - snippetLeading
+			if start < 0 {
+				start = 0
+			}
+		}
+	}
+	end := start + snippetLength
+	if end > len(runes) {
+		end = len(runes)
+		// 靠近结尾时向前补足长度
+		if start = end - snippetLength; start < 0 {
+			start = 0
+		}
+	}
+
+	snippet := highlightText(string(runes[start:end]), matcher)
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
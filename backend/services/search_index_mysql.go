@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// ngramTokenSize 与 MySQL ngram_token_size 保持一致（默认 2），更短的关键词无法命中全文索引
+	ngramTokenSize = 2
+	// searchTitleWeight 标题命中的相关度权重
+	searchTitleWeight = 2.0
+	// searchRecencyBoost 新内容的最大加成比例，随时间衰减
+	searchRecencyBoost = 0.5
+	// searchRecencyDays 时间衰减周期（天），发布 N 天后加成减半
+	searchRecencyDays = 30
+)
+
+// mysqlSearchSource 一种可检索文档对应的数据表
+// 每张表需要两个 FULLTEXT 索引：(title) 与 (title, body)，见 models.createFulltextIndexes
+type mysqlSearchSource struct {
+	table   string
+	bodyCol string
+	timeCol string
+	where   string
+}
+
+var mysqlSearchSources = map[string]mysqlSearchSource{
+	SearchTypeArticle: {
+		table:   "articles",
+		bodyCol: "content",
+		timeCol: "COALESCE(published_at, created_at)",
+		where:   "status = 1 AND deleted_at IS NULL",
+	},
+	SearchTypeForumPost: {
+		table:   "forum_posts",
+		bodyCol: "content",
+		timeCol: "created_at",
+		where:   "status = 1 AND deleted_at IS NULL",
+	},
+	SearchTypeResource: {
+		table:   "resources",
+		bodyCol: "description",
+		timeCol: "created_at",
+		where:   "status = 1",
+	},
+}
+
+// MySQLSearchIndex 基于 MySQL FULLTEXT（ngram 分词）的搜索索引
+// 全文索引直接建在业务表上，由 InnoDB 随写入维护，Index/Remove 只需让搜索结果缓存失效
+type MySQLSearchIndex struct {
+	db           *gorm.DB
+	cacheService *CacheService
+}
+
+// NewMySQLSearchIndex 创建 MySQL 全文搜索索引
+func NewMySQLSearchIndex(db *gorm.DB) *MySQLSearchIndex {
+	return &MySQLSearchIndex{
+		db:           db,
+		cacheService: NewCacheService(),
+	}
+}
+
+// Index 文档写入后使搜索缓存失效
+func (i *MySQLSearchIndex) Index(doc *SearchDocument) error {
+	return i.invalidate()
+}
+
+// Remove 文档删除后使搜索缓存失效
+func (i *MySQLSearchIndex) Remove(docType string, id uint) error {
+	return i.invalidate()
+}
+
+func (i *MySQLSearchIndex) invalidate() error {
+	if err := InvalidateSearchCache(); err != nil && !errors.Is(err, ErrCacheDisabled) {
+		return err
+	}
+	return nil
+}
+
+// searchRow 检索第一阶段的结果行（不含正文，避免大字段参与排序）
+type searchRow struct {
+	DocType string
+	ID      uint
+	DocTime time.Time
+	Score   float64
+}
+
+// searchDocRow 第二阶段加载的文档内容
+type searchDocRow struct {
+	ID    uint
+	Title string
+	Body  string
+}
+
+// Search 在各业务表上执行全文检索，按相关度与时间衰减综合排序
+func (i *MySQLSearchIndex) Search(query *SearchQuery) ([]SearchHit, int64, error) {
+	keyword := strings.TrimSpace(query.Keyword)
+	if keyword == "" {
+		return []SearchHit{}, 0, nil
+	}
+
+	types := query.Types
+	if query.CategoryID > 0 {
+		types = []string{SearchTypeArticle}
+	} else if len(types) == 0 {
+		types = SearchTypes
+	}
+
+	var parts []string
+	var args []interface{}
+	for _, docType := range types {
+		source, ok := mysqlSearchSources[docType]
+		if !ok {
+			continue
+		}
+		part, partArgs := i.buildSourceQuery(docType, source, keyword, query.CategoryID)
+		parts = append(parts, part)
+		args = append(args, partArgs...)
+	}
+	if len(parts) == 0 {
+		return []SearchHit{}, 0, nil
+	}
+	union := strings.Join(parts, " UNION ALL ")
+
+	var total int64
+	if err := i.db.Raw("SELECT COUNT(*) FROM ("+union+") AS docs", args...).Scan(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计搜索结果失败: %v", err)
+	}
+	if total == 0 {
+		return []SearchHit{}, 0, nil
+	}
+
+	orderBy := "score DESC, doc_time DESC"
+	if query.Sort == SearchSortLatest {
+		orderBy = "doc_time DESC, score DESC"
+	}
+	// 时间衰减：score = relevance * (1 + boost / (1 + 天数 / 周期))
+	pageSQL := fmt.Sprintf(`SELECT doc_type, id, doc_time,
+		relevance * (1 + %g / (1 + TIMESTAMPDIFF(DAY, doc_time, NOW()) / %d)) AS score
+		FROM (%s) AS docs ORDER BY %s LIMIT ? OFFSET ?`, searchRecencyBoost, searchRecencyDays, union, orderBy)
+
+	var rows []searchRow
+	pageArgs := append(append([]interface{}{}, args...), query.Limit, query.Offset)
+	if err := i.db.Raw(pageSQL, pageArgs...).Scan(&rows).Error; err != nil {
+		return nil, 0, fmt.Errorf("搜索失败: %v", err)
+	}
+
+	docs, err := i.loadDocuments(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matcher := searchHighlighter(searchTerms(keyword))
+	hits := make([]SearchHit, 0, len(rows))
+	for _, row := range rows {
+		doc, ok := docs[row.DocType][row.ID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, SearchHit{
+			Type:           row.DocType,
+			ID:             row.ID,
+			Title:          doc.Title,
+			TitleHighlight: highlightText(doc.Title, matcher),
+			Snippet:        buildSnippet(doc.Body, matcher),
+			Score:          row.Score,
+			CreatedAt:      row.DocTime,
+		})
+	}
+	return hits, total, nil
+}
+
+// buildSourceQuery 构造单张表的检索子查询
+// 关键词短于 ngram 分词长度时无法使用全文索引，退化为 LIKE 匹配
+func (i *MySQLSearchIndex) buildSourceQuery(docType string, source mysqlSearchSource, keyword string, categoryID uint) (string, []interface{}) {
+	var relevance, match string
+	var args []interface{}
+
+	if utf8.RuneCountInString(keyword) < ngramTokenSize {
+		like := "%" + escapeLike(keyword) + "%"
+		relevance = fmt.Sprintf("(CASE WHEN title LIKE ? THEN %g ELSE 1 END)", searchTitleWeight+1)
+		match = fmt.Sprintf("(title LIKE ? OR %s LIKE ?)", source.bodyCol)
+		args = append(args, like, like, like)
+	} else {
+		relevance = fmt.Sprintf("(MATCH(title) AGAINST(? IN NATURAL LANGUAGE MODE) * %g + MATCH(title, %s) AGAINST(? IN NATURAL LANGUAGE MODE))",
+			searchTitleWeight, source.bodyCol)
+		match = fmt.Sprintf("MATCH(title, %s) AGAINST(? IN NATURAL LANGUAGE MODE)", source.bodyCol)
+		args = append(args, keyword, keyword, keyword)
+	}
+
+	sql := fmt.Sprintf("SELECT '%s' AS doc_type, id, %s AS doc_time, %s AS relevance FROM %s WHERE %s AND %s",
+		docType, source.timeCol, relevance, source.table, source.where, match)
+	if categoryID > 0 && docType == SearchTypeArticle {
+		sql += " AND category_id = ?"
+		args = append(args, categoryID)
+	}
+	return sql, args
+}
+
+// loadDocuments 按类型批量加载当前页文档的标题和正文
+func (i *MySQLSearchIndex) loadDocuments(rows []searchRow) (map[string]map[uint]searchDocRow, error) {
+	idsByType := make(map[string][]uint)
+	for _, row := range rows {
+		idsByType[row.DocType] = append(idsByType[row.DocType], row.ID)
+	}
+
+	docs := make(map[string]map[uint]searchDocRow, len(idsByType))
+	for docType, ids := range idsByType {
+		source := mysqlSearchSources[docType]
+		var list []searchDocRow
+		err := i.db.Table(source.table).
+			Select(fmt.Sprintf("id, title, %s AS body", source.bodyCol)).
+			Where("id IN ?", ids).
+			Scan(&list).Error
+		if err != nil {
+			return nil, fmt.Errorf("加载搜索结果失败: %v", err)
+		}
+		docs[docType] = make(map[uint]searchDocRow, len(list))
+		for _, doc := range list {
+			docs[docType][doc.ID] = doc
+		}
+	}
+	return docs, nil
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
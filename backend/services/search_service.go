@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -11,6 +12,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrInvalidSearchType 指定的文档类型全部无效
+var ErrInvalidSearchType = errors.New("不支持的搜索类型")
+
+// searchCacheGenerationKey 搜索结果缓存的代数，内容变更时递增；旧代缓存不再命中，随过期时间自然淘汰
+const searchCacheGenerationKey = "search:generation"
+
 type SearchService struct {
 	db           *gorm.DB
 	cacheService *CacheService
@@ -25,14 +32,16 @@ func NewSearchService() *SearchService {
 
 type SearchRequest struct {
 	Keyword    string `json:"keyword" form:"keyword"`
+	Type       string `json:"type" form:"type"` // 文档类型，逗号分隔：article,forum_post,resource；为空时检索全部
 	CategoryID uint   `json:"category_id" form:"category_id"`
-	Sort       string `json:"sort" form:"sort"`
+	Sort       string `json:"sort" form:"sort"` // relevance（默认）/ latest
 	Page       int    `json:"page" form:"page"`
 	Size       int    `json:"size" form:"size"`
 }
 
 type SearchResult struct {
-	Articles    []*models.Article `json:"articles"`
+	Hits        []SearchHit       `json:"hits"`     // 全部类型的命中项，含高亮标题与正文片段
+	Articles    []*models.Article `json:"articles"` // 命中的文章详情（按相关度排序）
 	Total       int64             `json:"total"`
 	Keyword     string            `json:"keyword"`
 	Suggestions []string          `json:"suggestions"`
@@ -48,10 +57,14 @@ func (s *SearchService) Search(req *SearchRequest) (*SearchResult, error) {
 	if req.Size > 50 {
 		req.Size = 50
 	}
+	if req.Sort != SearchSortLatest {
+		req.Sort = SearchSortRelevance
+	}
 
 	keyword := strings.TrimSpace(req.Keyword)
 	if keyword == "" {
 		return &SearchResult{
+			Hits:     []SearchHit{},
 			Articles: []*models.Article{},
 			Total:    0,
 			Keyword:  keyword,
@@ -63,106 +76,106 @@ func (s *SearchService) Search(req *SearchRequest) (*SearchResult, error) {
 		s.cacheService.RecordSearchKeyword(keyword)
 	}()
 
-	// 生成缓存key
-	cacheKey := fmt.Sprintf("search:%s:%d:%s:%d", keyword, req.CategoryID, req.Sort, req.Page)
-	
-	// 尝试从缓存获取搜索结果
-	if cachedArticles, err := s.cacheService.GetSearchResults(cacheKey); err == nil {
-		// 获取总数（可能需要实时查询以保证准确性）
-		var total int64
-		countQuery := s.buildSearchQuery(keyword, req.CategoryID)
-		countQuery.Count(&total)
-		
-		// 转换为指针切片
-		articlesPtr := make([]*models.Article, len(cachedArticles))
-		for i := range cachedArticles {
-			articlesPtr[i] = &cachedArticles[i]
-		}
-
-		return &SearchResult{
-			Articles: articlesPtr,
-			Total:    total,
-			Keyword:  keyword,
-		}, nil
+	types, err := s.parseTypes(req.Type)
+	if err != nil {
+		return nil, err
 	}
 
-	var articles []*models.Article
-	var total int64
+	// 生成缓存key，带上当前缓存代数
+	cacheKey := fmt.Sprintf("search:result:%d:%s:%s:%d:%s:%d:%d", searchCacheGeneration(), keyword, strings.Join(types, ","), req.CategoryID, req.Sort, req.Page, req.Size)
 
-	// 构建搜索查询
-	query := s.buildSearchQuery(keyword, req.CategoryID)
-	
-	// 获取总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, fmt.Errorf("获取搜索结果总数失败: %v", err)
+	// 尝试从缓存获取搜索结果（内容变更时由搜索索引递增缓存代数使其失效）
+	var cached SearchResult
+	if err := s.cacheService.Get(cacheKey, &cached); err == nil {
+		return &cached, nil
 	}
 
-	// 应用排序
-	orderBy := s.buildOrderBy(req.Sort)
-	query = query.Order(orderBy)
-
-	// 分页查询
-	offset := (req.Page - 1) * req.Size
-	if err := query.Offset(offset).Limit(req.Size).Find(&articles).Error; err != nil {
-		return nil, fmt.Errorf("搜索失败: %v", err)
+	hits, total, err := GetSearchIndex().Search(&SearchQuery{
+		Keyword:    keyword,
+		Types:      types,
+		CategoryID: req.CategoryID,
+		Sort:       req.Sort,
+		Offset:     (req.Page - 1) * req.Size,
+		Limit:      req.Size,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// 缓存搜索结果
-	if len(articles) > 0 {
-		// 转换为值切片进行缓存
-		articleValues := make([]models.Article, len(articles))
-		for i, article := range articles {
-			articleValues[i] = *article
-		}
-		s.cacheService.SetSearchResults(cacheKey, articleValues, 10*time.Minute)
+	articles, err := s.loadHitArticles(hits)
+	if err != nil {
+		return nil, err
 	}
 
-	// 获取搜索建议
-	suggestions := s.getSearchSuggestions(keyword)
-
-	return &SearchResult{
+	result := &SearchResult{
+		Hits:        hits,
 		Articles:    articles,
 		Total:       total,
 		Keyword:     keyword,
-		Suggestions: suggestions,
-	}, nil
+		Suggestions: s.getSearchSuggestions(keyword),
+	}
+
+	// 缓存搜索结果
+	if len(hits) > 0 {
+		s.cacheService.SetWithExpire(cacheKey, result, 10*time.Minute)
+	}
+
+	return result, nil
 }
 
-func (s *SearchService) buildSearchQuery(keyword string, categoryID uint) *gorm.DB {
-	query := s.db.Model(&models.Article{}).
-		Preload("Author").
-		Preload("Category").
-		Where("status = ?", 1)
+// parseTypes 解析并校验文档类型，结果有序以便生成稳定的缓存key；未指定时返回空表示全部类型，
+// 指定的类型全部无效时返回 ErrInvalidSearchType
+func (s *SearchService) parseTypes(raw string) ([]string, error) {
+	requested := make(map[string]bool)
+	for _, t := range strings.Split(raw, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			requested[t] = true
+		}
+	}
+	if len(requested) == 0 {
+		return nil, nil
+	}
 
-	// 关键词搜索
-	if keyword != "" {
-		// 支持标题、内容、标签搜索
-		searchCondition := "title LIKE ? OR content LIKE ? OR tags LIKE ?"
-		searchValue := "%" + keyword + "%"
-		query = query.Where(searchCondition, searchValue, searchValue, searchValue)
+	var types []string
+	for _, t := range SearchTypes {
+		if requested[t] {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		return nil, ErrInvalidSearchType
 	}
+	return types, nil
+}
 
-	// 分类筛选
-	if categoryID > 0 {
-		query = query.Where("category_id = ?", categoryID)
+// loadHitArticles 加载命中文章的完整信息，保持命中顺序
+func (s *SearchService) loadHitArticles(hits []SearchHit) ([]*models.Article, error) {
+	var ids []uint
+	for _, hit := range hits {
+		if hit.Type == SearchTypeArticle {
+			ids = append(ids, hit.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return []*models.Article{}, nil
 	}
 
-	return query
-}
+	var list []*models.Article
+	if err := s.db.Preload("Author").Preload("Category").Where("id IN ?", ids).Find(&list).Error; err != nil {
+		return nil, fmt.Errorf("加载文章失败: %v", err)
+	}
 
-func (s *SearchService) buildOrderBy(sort string) string {
-	switch sort {
-	case "view_count":
-		return "view_count DESC, created_at DESC"
-	case "like_count":
-		return "like_count DESC, created_at DESC"
-	case "comment_count":
-		return "comment_count DESC, created_at DESC"
-	case "updated_at":
-		return "updated_at DESC"
-	default:
-		return "created_at DESC"
+	byID := make(map[uint]*models.Article, len(list))
+	for _, article := range list {
+		byID[article.ID] = article
 	}
+	articles := make([]*models.Article, 0, len(list))
+	for _, id := range ids {
+		if article, ok := byID[id]; ok {
+			articles = append(articles, article)
+		}
+	}
+	return articles, nil
 }
 
 func (s *SearchService) getSearchSuggestions(keyword string) []string {
@@ -176,7 +189,7 @@ func (s *SearchService) getSearchSuggestions(keyword string) []string {
 		// 查找相似的文章标题
 		var titles []string
 		s.db.Model(&models.Article{}).
-			Where("status = ? AND title LIKE ?", 1, "%"+keyword+"%").
+			Where("status = ? AND title LIKE ?", 1, "%"+escapeLike(keyword)+"%").
 			Limit(5).
 			Pluck("title", &titles)
 		
@@ -226,9 +239,9 @@ func (s *SearchService) GetSearchSuggestions(keyword string, limit int) ([]strin
 	
 	// 从文章标题中搜索相关建议
 	err := s.db.Model(&models.Article{}).
-		Where("status = ? AND title LIKE ?", 1, "%"+keyword+"%").
+		Where("status = ? AND title LIKE ?", 1, "%"+escapeLike(keyword)+"%").
 		Limit(limit).
-		Pluck("title", &suggestions)
+		Pluck("title", &suggestions).Error
 	
 	if err != nil {
 		return []string{}, fmt.Errorf("获取搜索建议失败: %v", err)
@@ -260,5 +273,25 @@ func (s *SearchService) processSearchSuggestions(suggestions []string, keyword s
 }
 
 func (s *SearchService) ClearSearchCache() error {
-	return s.cacheService.DeletePattern("search:*")
+	return InvalidateSearchCache()
+}
+
+// searchCacheGeneration 当前搜索缓存代数，Redis 不可用时返回 0
+func searchCacheGeneration() int64 {
+	if !isCacheReady() {
+		return 0
+	}
+	generation, err := rdb.Get(ctx, searchCacheGenerationKey).Int64()
+	if err != nil {
+		return 0
+	}
+	return generation
+}
+
+// InvalidateSearchCache 递增搜索缓存代数使全部搜索结果缓存失效，避免按模式扫描删除
+func InvalidateSearchCache() error {
+	if !isCacheReady() {
+		return ErrCacheDisabled
+	}
+	return rdb.Incr(ctx, searchCacheGenerationKey).Err()
 }
\ No newline at end of file
@@ -0,0 +1,434 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"godad-backend/config"
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrSensitiveContent 内容命中 block 类敏感词
+var ErrSensitiveContent = errors.New("内容包含违禁词，请修改后重新提交")
+
+const (
+	// sensitiveVersionKey 词库版本号，管理员修改词库时递增，各实例据此热加载
+	sensitiveVersionKey = "sensitive:words:version"
+	// sensitiveVersionCheckInterval 检查词库版本的最小间隔
+	sensitiveVersionCheckInterval = 30 * time.Second
+	// sensitiveMaxGap 敏感词相邻字符之间允许夹杂的干扰字符数（空格、标点、符号）
+	sensitiveMaxGap = 3
+)
+
+// SensitiveHit 一次敏感词命中
+type SensitiveHit struct {
+	Word     string `json:"word"`
+	Category string `json:"category"`
+	Action   string `json:"action"`
+	Matched  string `json:"matched"` // 原文中实际命中的片段
+}
+
+// SensitiveScreenResult 内容检测结果，mask 类命中已在原文中替换为 *
+type SensitiveScreenResult struct {
+	Hits   []SensitiveHit `json:"hits"`
+	Review bool           `json:"review"` // 存在 review 类命中，需要人工审核
+}
+
+// Words 命中的敏感词（去重），用于审核说明
+func (r *SensitiveScreenResult) Words() []string {
+	seen := make(map[string]struct{}, len(r.Hits))
+	var words []string
+	for _, hit := range r.Hits {
+		if _, ok := seen[hit.Word]; ok {
+			continue
+		}
+		seen[hit.Word] = struct{}{}
+		words = append(words, hit.Word)
+	}
+	return words
+}
+
+// Merge 合并另一次检测的结果（如帖子附带的投票），用于合并提交审核
+func (r *SensitiveScreenResult) Merge(other *SensitiveScreenResult) {
+	if other == nil {
+		return
+	}
+	r.Hits = append(r.Hits, other.Hits...)
+	r.Review = r.Review || other.Review
+}
+
+// sensitiveEntry 自动机中的一个模式，其他写法与原词共用同一条记录
+type sensitiveEntry struct {
+	word         string
+	category     string
+	action       string
+	length       int  // 归一化后的字符数
+	wordBoundary bool // 纯英文字母/数字模式，命中两侧不能紧邻英文字母或数字
+}
+
+type sensitiveNode struct {
+	next    map[rune]int32
+	fail    int32
+	outputs []int32 // 以该节点结尾的模式（含 fail 链上的模式）
+}
+
+// sensitiveMatcher Aho-Corasick 自动机，构建后只读，可并发使用
+type sensitiveMatcher struct {
+	nodes   []sensitiveNode
+	entries []sensitiveEntry
+}
+
+func newSensitiveMatcher() *sensitiveMatcher {
+	return &sensitiveMatcher{nodes: []sensitiveNode{{next: map[rune]int32{}}}}
+}
+
+// add 插入一个模式，pattern 为空（全是干扰字符）时忽略
+func (m *sensitiveMatcher) add(pattern string, entry sensitiveEntry) {
+	runes := normalizeSensitivePattern(pattern)
+	if len(runes) == 0 {
+		return
+	}
+	cur := int32(0)
+	for _, r := range runes {
+		nxt, ok := m.nodes[cur].next[r]
+		if !ok {
+			nxt = int32(len(m.nodes))
+			m.nodes = append(m.nodes, sensitiveNode{next: map[rune]int32{}})
+			m.nodes[cur].next[r] = nxt
+		}
+		cur = nxt
+	}
+	entry.length = len(runes)
+	entry.wordBoundary = true
+	for _, r := range runes {
+		if !isSensitiveWordRune(r) {
+			entry.wordBoundary = false
+			break
+		}
+	}
+	m.entries = append(m.entries, entry)
+	m.nodes[cur].outputs = append(m.nodes[cur].outputs, int32(len(m.entries)-1))
+}
+
+// build 按 BFS 计算 fail 指针并合并输出
+func (m *sensitiveMatcher) build() {
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		m.nodes[child].fail = 0
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[cur].next {
+			f := m.nodes[cur].fail
+			for f != 0 {
+				if _, ok := m.nodes[f].next[r]; ok {
+					break
+				}
+				f = m.nodes[f].fail
+			}
+			if target, ok := m.nodes[f].next[r]; ok && target != child {
+				m.nodes[child].fail = target
+			}
+			m.nodes[child].outputs = append(m.nodes[child].outputs, m.nodes[m.nodes[child].fail].outputs...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+// sensitiveSpan 命中在原文中的字符区间 [start, end)
+type sensitiveSpan struct {
+	entry      int32
+	start, end int
+}
+
+// match 在文本中查找全部命中；干扰字符被跳过，但相邻有效字符间隔超过 sensitiveMaxGap 时重新开始匹配。
+// 纯英文模式要求整词命中，避免 "sb" 命中 "this book" 这类跨单词的片段
+func (m *sensitiveMatcher) match(text []rune) []sensitiveSpan {
+	if len(m.entries) == 0 {
+		return nil
+	}
+
+	// positions[i] 为第 i 个有效字符在原文中的下标
+	positions := make([]int, 0, len(text))
+	var spans []sensitiveSpan
+	cur := int32(0)
+	gap := 0
+	for i, r := range text {
+		nr, ok := normalizeSensitiveRune(r)
+		if !ok {
+			gap++
+			continue
+		}
+		if gap > sensitiveMaxGap {
+			cur = 0
+		}
+		gap = 0
+		positions = append(positions, i)
+
+		for cur != 0 {
+			if _, ok := m.nodes[cur].next[nr]; ok {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		if nxt, ok := m.nodes[cur].next[nr]; ok {
+			cur = nxt
+		}
+		for _, idx := range m.nodes[cur].outputs {
+			n := m.entries[idx].length
+			if n > len(positions) {
+				continue
+			}
+			start := positions[len(positions)-n]
+			if m.entries[idx].wordBoundary && !atSensitiveWordBoundary(text, start, i+1) {
+				continue
+			}
+			spans = append(spans, sensitiveSpan{entry: idx, start: start, end: i + 1})
+		}
+	}
+	return spans
+}
+
+// atSensitiveWordBoundary 原文区间 [start, end) 两侧是否都不紧邻英文字母或数字
+func atSensitiveWordBoundary(text []rune, start, end int) bool {
+	if start > 0 {
+		if r, ok := normalizeSensitiveRune(text[start-1]); ok && isSensitiveWordRune(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		if r, ok := normalizeSensitiveRune(text[end]); ok && isSensitiveWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSensitiveWordRune 归一化后的字符是否为英文字母或数字
+func isSensitiveWordRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
+
+// normalizeSensitivePattern 归一化词库中的模式，去掉干扰字符
+func normalizeSensitivePattern(pattern string) []rune {
+	var out []rune
+	for _, r := range pattern {
+		if nr, ok := normalizeSensitiveRune(r); ok {
+			out = append(out, nr)
+		}
+	}
+	return out
+}
+
+// normalizeSensitiveRune 全角转半角、大写转小写、带声调的字母转普通字母；
+// 空白、标点与符号视为干扰字符，返回 false
+func normalizeSensitiveRune(r rune) (rune, bool) {
+	switch {
+	case r == 0x3000:
+		return 0, false
+	case r >= 0xFF01 && r <= 0xFF5E:
+		r -= 0xFEE0
+	}
+	if base, ok := toneMarkBase[r]; ok {
+		r = base
+	}
+	if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsControl(r) || unicode.Is(unicode.Mn, r) {
+		return 0, false
+	}
+	return unicode.ToLower(r), true
+}
+
+// isSensitiveVariantSeparator 其他写法之间的分隔符
+func isSensitiveVariantSeparator(r rune) bool {
+	return r == ',' || r == '，'
+}
+
+// toneMarkBase 带声调的字母（ā、ǚ 等）对应的不带声调字母
+var toneMarkBase = func() map[rune]rune {
+	groups := map[rune]string{
+		'a': "āáǎàĀÁǍÀ",
+		'e': "ēéěèĒÉĚÈ",
+		'i': "īíǐìĪÍǏÌ",
+		'o': "ōóǒòŌÓǑÒ",
+		'u': "ūúǔùŪÚǓÙ",
+		'v': "üǖǘǚǜÜǕǗǙǛ",
+	}
+	m := make(map[rune]rune)
+	for base, marked := range groups {
+		for _, r := range marked {
+			m[r] = base
+		}
+	}
+	return m
+}()
+
+// SensitiveFilter 敏感词过滤器，词库存于数据库，按 Redis 中的版本号热加载
+type SensitiveFilter struct {
+	db        *gorm.DB
+	matcher   atomic.Pointer[sensitiveMatcher]
+	version   atomic.Int64
+	checkedAt atomic.Int64 // 上次检查版本的时间（UnixNano）
+	loadMu    sync.Mutex
+}
+
+var (
+	sensitiveFilter     *SensitiveFilter
+	sensitiveFilterOnce sync.Once
+)
+
+// GetSensitiveFilter 获取全局敏感词过滤器
+func GetSensitiveFilter() *SensitiveFilter {
+	sensitiveFilterOnce.Do(func() {
+		sensitiveFilter = &SensitiveFilter{db: config.GetDB()}
+	})
+	return sensitiveFilter
+}
+
+// Screen 检测并处理若干文本字段：命中 block 返回 ErrSensitiveContent；
+// mask 类命中直接在字段中替换为 *；review 类命中在结果中标记
+func (f *SensitiveFilter) Screen(fields ...*string) (*SensitiveScreenResult, error) {
+	result := &SensitiveScreenResult{}
+	m := f.current()
+	if m == nil {
+		return result, nil
+	}
+
+	blocked := false
+	for _, field := range fields {
+		if field == nil || *field == "" {
+			continue
+		}
+		source := []rune(*field)
+		spans := m.match(source)
+		if len(spans) == 0 {
+			continue
+		}
+		text := append([]rune(nil), source...)
+		masked := false
+		for _, span := range spans {
+			entry := m.entries[span.entry]
+			result.Hits = append(result.Hits, SensitiveHit{
+				Word:     entry.word,
+				Category: entry.category,
+				Action:   entry.action,
+				Matched:  string(source[span.start:span.end]),
+			})
+			switch entry.action {
+			case models.SensitiveActionBlock:
+				blocked = true
+			case models.SensitiveActionReview:
+				result.Review = true
+			case models.SensitiveActionMask:
+				for i := span.start; i < span.end; i++ {
+					if _, ok := normalizeSensitiveRune(source[i]); ok {
+						text[i] = '*'
+					}
+				}
+				masked = true
+			}
+		}
+		if masked {
+			*field = string(text)
+		}
+	}
+
+	if blocked {
+		return result, ErrSensitiveContent
+	}
+	return result, nil
+}
+
+// Check 检测一段文本并返回打码后的预览，供管理员测试词库
+func (f *SensitiveFilter) Check(text string) (*SensitiveScreenResult, string) {
+	result, _ := f.Screen(&text)
+	return result, text
+}
+
+// current 返回当前自动机，必要时按版本号热加载
+func (f *SensitiveFilter) current() *sensitiveMatcher {
+	m := f.matcher.Load()
+	now := time.Now().UnixNano()
+	if now-f.checkedAt.Load() < int64(sensitiveVersionCheckInterval) {
+		return m
+	}
+	f.checkedAt.Store(now)
+
+	version := f.remoteVersion()
+	if m != nil && version == f.version.Load() {
+		return m
+	}
+	if err := f.reload(version); err != nil {
+		log.Printf("加载敏感词库失败: %v", err)
+	}
+	return f.matcher.Load()
+}
+
+// Reload 立即从数据库重建词库，并递增版本号通知其他实例
+func (f *SensitiveFilter) Reload() error {
+	version := f.version.Load() + 1
+	if client := GetRedisClient(); client != nil {
+		if v, err := client.Incr(context.Background(), sensitiveVersionKey).Result(); err == nil {
+			version = v
+		} else {
+			log.Printf("更新敏感词库版本失败: %v", err)
+		}
+	}
+	f.checkedAt.Store(time.Now().UnixNano())
+	return f.reload(version)
+}
+
+// remoteVersion 读取 Redis 中的词库版本，Redis 不可用时沿用本地版本
+func (f *SensitiveFilter) remoteVersion() int64 {
+	client := GetRedisClient()
+	if client == nil {
+		return f.version.Load()
+	}
+	raw, err := client.Get(context.Background(), sensitiveVersionKey).Result()
+	if err != nil {
+		return f.version.Load()
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return f.version.Load()
+	}
+	return v
+}
+
+func (f *SensitiveFilter) reload(version int64) error {
+	f.loadMu.Lock()
+	defer f.loadMu.Unlock()
+
+	var words []models.SensitiveWord
+	err := f.db.Joins("Category").
+		Where("sensitive_words.is_active = ? AND Category.is_active = ?", true, true).
+		Find(&words).Error
+	if err != nil {
+		return err
+	}
+
+	m := newSensitiveMatcher()
+	for _, w := range words {
+		entry := sensitiveEntry{word: w.Word, category: w.Category.Name, action: w.Category.Action}
+		m.add(w.Word, entry)
+		for _, variant := range strings.FieldsFunc(w.Variants, isSensitiveVariantSeparator) {
+			m.add(variant, entry)
+		}
+	}
+	m.build()
+
+	f.matcher.Store(m)
+	f.version.Store(version)
+	log.Printf("敏感词库已加载：%d 个词，版本 %d", len(words), version)
+	return nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"godad-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSensitiveMatcher 按 词 -> 动作 构建自动机
+func newTestSensitiveMatcher(words map[string]string) *sensitiveMatcher {
+	m := newSensitiveMatcher()
+	for word, action := range words {
+		m.add(word, sensitiveEntry{word: word, category: "test", action: action})
+	}
+	m.build()
+	return m
+}
+
+// matchedWords 返回命中的词及其在原文中的片段
+func matchedWords(m *sensitiveMatcher, text string) []string {
+	source := []rune(text)
+	var out []string
+	for _, span := range m.match(source) {
+		out = append(out, m.entries[span.entry].word+"="+string(source[span.start:span.end]))
+	}
+	return out
+}
+
+// TestSensitiveMatcherMatch 测试自动机的归一化、干扰字符跳过与整词匹配
+func TestSensitiveMatcherMatch(t *testing.T) {
+	m := newTestSensitiveMatcher(map[string]string{
+		"傻逼":   models.SensitiveActionMask,
+		"sb":   models.SensitiveActionMask,
+		"赌博":   models.SensitiveActionBlock,
+		"网络赌博": models.SensitiveActionBlock,
+	})
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "直接命中", text: "你是傻逼吗", expected: []string{"傻逼=傻逼"}},
+		{name: "夹杂空格与标点", text: "傻 。逼", expected: []string{"傻逼=傻 。逼"}},
+		{name: "间隔超过上限", text: "傻    逼", expected: nil},
+		{name: "全角与大写", text: "ＳＢ！", expected: []string{"sb=ＳＢ"}},
+		{name: "英文整词", text: "you sb!", expected: []string{"sb=sb"}},
+		{name: "英文跨单词不命中", text: "this book", expected: nil},
+		{name: "英文嵌在单词中不命中", text: "usb", expected: nil},
+		{name: "英文紧邻汉字", text: "你sb吧", expected: []string{"sb=sb"}},
+		{name: "重叠模式经fail链输出", text: "禁止网络赌博", expected: []string{"赌博=赌博", "网络赌博=网络赌博"}},
+		{name: "失配后沿fail链继续", text: "网络网络赌博", expected: []string{"赌博=赌博", "网络赌博=网络赌博"}},
+		{name: "无命中", text: "正常内容", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tc.expected, matchedWords(m, tc.text))
+		})
+	}
+}
+
+// TestSensitiveMatcherIgnoresEmptyPattern 测试全是干扰字符的模式被忽略
+func TestSensitiveMatcherIgnoresEmptyPattern(t *testing.T) {
+	m := newTestSensitiveMatcher(map[string]string{" ！？": models.SensitiveActionBlock})
+	assert.Empty(t, m.entries)
+	assert.Empty(t, m.match([]rune("任意内容 ！？")))
+}
+
+// TestNormalizeSensitiveRune 测试字符归一化
+func TestNormalizeSensitiveRune(t *testing.T) {
+	testCases := []struct {
+		input    rune
+		expected rune
+		ok       bool
+	}{
+		{input: 'Ａ', expected: 'a', ok: true},
+		{input: '１', expected: '1', ok: true},
+		{input: 'ǎ', expected: 'a', ok: true},
+		{input: 'Ü', expected: 'v', ok: true},
+		{input: '汉', expected: '汉', ok: true},
+		{input: '　', ok: false},
+		{input: '，', ok: false},
+		{input: '*', ok: false},
+	}
+
+	for _, tc := range testCases {
+		r, ok := normalizeSensitiveRune(tc.input)
+		assert.Equal(t, tc.ok, ok, string(tc.input))
+		if tc.ok {
+			assert.Equal(t, tc.expected, r, string(tc.input))
+		}
+	}
+}
+
+// TestSensitiveFilterScreen 测试 mask/review/block 三类动作的处理
+func TestSensitiveFilterScreen(t *testing.T) {
+	f := &SensitiveFilter{}
+	f.matcher.Store(newTestSensitiveMatcher(map[string]string{
+		"傻逼": models.SensitiveActionMask,
+		"代购": models.SensitiveActionReview,
+		"赌博": models.SensitiveActionBlock,
+	}))
+	f.checkedAt.Store(time.Now().UnixNano())
+
+	title, content := "傻 逼标题", "正常内容"
+	result, err := f.Screen(&title, &content)
+	require.NoError(t, err)
+	assert.Equal(t, "* *标题", title)
+	assert.Equal(t, "正常内容", content)
+	assert.False(t, result.Review)
+	assert.Equal(t, []string{"傻逼"}, result.Words())
+
+	content = "海外代购"
+	result, err = f.Screen(&content)
+	require.NoError(t, err)
+	assert.True(t, result.Review)
+	assert.Equal(t, "海外代购", content)
+
+	content = "线上赌博"
+	_, err = f.Screen(&content)
+	assert.ErrorIs(t, err, ErrSensitiveContent)
+}
+
+// TestSensitiveScreenResultMerge 测试合并检测结果时保留命中与审核标记
+func TestSensitiveScreenResultMerge(t *testing.T) {
+	result := &SensitiveScreenResult{Hits: []SensitiveHit{{Word: "傻逼", Action: models.SensitiveActionMask}}}
+	result.Merge(nil)
+	assert.False(t, result.Review)
+
+	result.Merge(&SensitiveScreenResult{Hits: []SensitiveHit{{Word: "代购", Action: models.SensitiveActionReview}}, Review: true})
+	assert.True(t, result.Review)
+	assert.Equal(t, []string{"傻逼", "代购"}, result.Words())
+}
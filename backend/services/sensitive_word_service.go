@@ -0,0 +1,361 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// sensitiveReportReason 命中 review 类敏感词时自动生成的举报理由
+const sensitiveReportReason = "敏感词审核"
+
+// SensitiveWordService 敏感词库管理服务
+type SensitiveWordService struct {
+	db *gorm.DB
+}
+
+// NewSensitiveWordService 创建敏感词库管理服务
+func NewSensitiveWordService(db *gorm.DB) *SensitiveWordService {
+	return &SensitiveWordService{db: db}
+}
+
+// ListCategories 分类列表（含词数）
+func (s *SensitiveWordService) ListCategories() ([]models.SensitiveWordCategory, error) {
+	var categories []models.SensitiveWordCategory
+	if err := s.db.Order("id ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		CategoryID uint
+		Cnt        int64
+	}
+	if err := s.db.Model(&models.SensitiveWord{}).Select("category_id, COUNT(*) AS cnt").Group("category_id").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	byCategory := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		byCategory[c.CategoryID] = c.Cnt
+	}
+	for i := range categories {
+		categories[i].WordCount = byCategory[categories[i].ID]
+	}
+	return categories, nil
+}
+
+// CreateCategory 创建分类
+func (s *SensitiveWordService) CreateCategory(req *models.SensitiveCategoryRequest) (*models.SensitiveWordCategory, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := s.ensureCategoryName(name, 0); err != nil {
+		return nil, err
+	}
+	if !models.IsValidSensitiveAction(req.Action) {
+		return nil, errors.New("不支持的处理方式")
+	}
+
+	category := &models.SensitiveWordCategory{
+		Name:        name,
+		Action:      req.Action,
+		Description: strings.TrimSpace(req.Description),
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+	if err := s.db.Create(category).Error; err != nil {
+		return nil, err
+	}
+	s.reload()
+	return category, nil
+}
+
+// UpdateCategory 更新分类，处理方式或启用状态变化后立即生效
+func (s *SensitiveWordService) UpdateCategory(id uint, req *models.SensitiveCategoryRequest) (*models.SensitiveWordCategory, error) {
+	var category models.SensitiveWordCategory
+	if err := s.db.First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("敏感词分类不存在")
+		}
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if err := s.ensureCategoryName(name, id); err != nil {
+		return nil, err
+	}
+	if !models.IsValidSensitiveAction(req.Action) {
+		return nil, errors.New("不支持的处理方式")
+	}
+
+	updates := map[string]interface{}{
+		"name":        name,
+		"action":      req.Action,
+		"description": strings.TrimSpace(req.Description),
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if err := s.db.Model(&category).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	s.reload()
+	return &category, nil
+}
+
+// DeleteCategory 删除分类，分类下仍有敏感词时拒绝删除
+func (s *SensitiveWordService) DeleteCategory(id uint) error {
+	var count int64
+	if err := s.db.Model(&models.SensitiveWord{}).Where("category_id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("分类下仍有敏感词")
+	}
+	result := s.db.Delete(&models.SensitiveWordCategory{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("敏感词分类不存在")
+	}
+	s.reload()
+	return nil
+}
+
+// ListWords 敏感词列表，可按分类与关键字筛选
+func (s *SensitiveWordService) ListWords(categoryID uint, keyword string, page, size int) ([]models.SensitiveWord, int64, error) {
+	query := s.db.Model(&models.SensitiveWord{})
+	if categoryID > 0 {
+		query = query.Where("category_id = ?", categoryID)
+	}
+	if keyword = strings.TrimSpace(keyword); keyword != "" {
+		like := "%" + keyword + "%"
+		query = query.Where("word LIKE ? OR variants LIKE ?", like, like)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var words []models.SensitiveWord
+	if err := query.Preload("Category").Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&words).Error; err != nil {
+		return nil, 0, err
+	}
+	return words, total, nil
+}
+
+// CreateWord 添加敏感词
+func (s *SensitiveWordService) CreateWord(req *models.SensitiveWordRequest, adminID uint) (*models.SensitiveWord, error) {
+	if err := s.ensureCategory(req.CategoryID); err != nil {
+		return nil, err
+	}
+	word := &models.SensitiveWord{
+		Word:       strings.TrimSpace(req.Word),
+		Variants:   strings.TrimSpace(req.Variants),
+		CategoryID: req.CategoryID,
+		IsActive:   req.IsActive == nil || *req.IsActive,
+		CreatedBy:  adminID,
+	}
+	if len(normalizeSensitivePattern(word.Word)) == 0 {
+		return nil, errors.New("敏感词不能只包含空白或符号")
+	}
+	if err := s.ensureWordUnique(word.Word, 0); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(word).Error; err != nil {
+		return nil, err
+	}
+	s.reload()
+	return word, nil
+}
+
+// UpdateWord 更新敏感词
+func (s *SensitiveWordService) UpdateWord(id uint, req *models.SensitiveWordRequest) (*models.SensitiveWord, error) {
+	var word models.SensitiveWord
+	if err := s.db.First(&word, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("敏感词不存在")
+		}
+		return nil, err
+	}
+	if err := s.ensureCategory(req.CategoryID); err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(req.Word)
+	if len(normalizeSensitivePattern(text)) == 0 {
+		return nil, errors.New("敏感词不能只包含空白或符号")
+	}
+	if err := s.ensureWordUnique(text, id); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"word":        text,
+		"variants":    strings.TrimSpace(req.Variants),
+		"category_id": req.CategoryID,
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if err := s.db.Model(&word).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	s.reload()
+	return &word, nil
+}
+
+// DeleteWord 删除敏感词
+func (s *SensitiveWordService) DeleteWord(id uint) error {
+	result := s.db.Delete(&models.SensitiveWord{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("敏感词不存在")
+	}
+	s.reload()
+	return nil
+}
+
+// ImportWords 批量导入敏感词，已存在或为空的词跳过，返回新增与跳过的数量
+func (s *SensitiveWordService) ImportWords(req *models.SensitiveWordImportRequest, adminID uint) (int, int, error) {
+	if err := s.ensureCategory(req.CategoryID); err != nil {
+		return 0, 0, err
+	}
+
+	seen := make(map[string]struct{}, len(req.Words))
+	words := make([]models.SensitiveWord, 0, len(req.Words))
+	for _, line := range req.Words {
+		text, variants, _ := strings.Cut(line, "|")
+		text, variants = strings.TrimSpace(text), strings.TrimSpace(variants)
+		if len(normalizeSensitivePattern(text)) == 0 || len([]rune(text)) > 100 {
+			continue
+		}
+		if _, ok := seen[text]; ok {
+			continue
+		}
+		seen[text] = struct{}{}
+		words = append(words, models.SensitiveWord{
+			Word:       text,
+			Variants:   truncateString(variants, 255),
+			CategoryID: req.CategoryID,
+			IsActive:   true,
+			CreatedBy:  adminID,
+		})
+	}
+
+	added := 0
+	if len(words) > 0 {
+		texts := make([]string, len(words))
+		for i, w := range words {
+			texts[i] = w.Word
+		}
+		var existing []string
+		if err := s.db.Model(&models.SensitiveWord{}).Where("word IN ?", texts).Pluck("word", &existing).Error; err != nil {
+			return 0, 0, err
+		}
+		exists := make(map[string]struct{}, len(existing))
+		for _, w := range existing {
+			exists[w] = struct{}{}
+		}
+		fresh := words[:0]
+		for _, w := range words {
+			if _, ok := exists[w.Word]; !ok {
+				fresh = append(fresh, w)
+			}
+		}
+		if len(fresh) > 0 {
+			if err := s.db.CreateInBatches(fresh, 500).Error; err != nil {
+				return 0, 0, err
+			}
+		}
+		added = len(fresh)
+	}
+
+	if added > 0 {
+		s.reload()
+	}
+	return added, len(req.Words) - added, nil
+}
+
+// ensureCategory 校验分类存在
+func (s *SensitiveWordService) ensureCategory(id uint) error {
+	var count int64
+	if err := s.db.Model(&models.SensitiveWordCategory{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("敏感词分类不存在")
+	}
+	return nil
+}
+
+func (s *SensitiveWordService) ensureCategoryName(name string, excludeID uint) error {
+	if name == "" {
+		return errors.New("分类名称不能为空")
+	}
+	var count int64
+	if err := s.db.Model(&models.SensitiveWordCategory{}).Where("name = ? AND id <> ?", name, excludeID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("分类名称已存在")
+	}
+	return nil
+}
+
+func (s *SensitiveWordService) ensureWordUnique(word string, excludeID uint) error {
+	var count int64
+	if err := s.db.Model(&models.SensitiveWord{}).Where("word = ? AND id <> ?", word, excludeID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("敏感词已存在")
+	}
+	return nil
+}
+
+// reload 词库变化后重建本实例的自动机，并通知其他实例
+func (s *SensitiveWordService) reload() {
+	if err := GetSensitiveFilter().Reload(); err != nil {
+		log.Printf("重建敏感词库失败: %v", err)
+	}
+}
+
+// fileSensitiveReport 内容命中 review 类敏感词后以系统身份提交举报，进入举报处理队列
+func fileSensitiveReport(db *gorm.DB, targetType string, targetID uint, result *SensitiveScreenResult) {
+	if result == nil || !result.Review {
+		return
+	}
+
+	var words []string
+	seen := make(map[string]struct{})
+	for _, hit := range result.Hits {
+		if _, ok := seen[hit.Word]; ok || hit.Action != models.SensitiveActionReview {
+			continue
+		}
+		seen[hit.Word] = struct{}{}
+		words = append(words, hit.Word)
+	}
+	report := &models.Report{
+		TargetType:  targetType,
+		TargetID:    targetID,
+		ReporterID:  0,
+		Reason:      sensitiveReportReason,
+		Description: truncateString(fmt.Sprintf("命中敏感词：%s", strings.Join(words, "、")), 500),
+		Status:      "pending",
+	}
+
+	// 同一内容已有未处理的敏感词审核时不重复提交
+	var pending int64
+	db.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND reason = ? AND status = ?", targetType, targetID, sensitiveReportReason, "pending").
+		Count(&pending)
+	if pending > 0 {
+		return
+	}
+	if err := db.Create(report).Error; err != nil {
+		log.Printf("提交敏感词审核失败 %s#%d: %v", targetType, targetID, err)
+	}
+}
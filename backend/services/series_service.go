@@ -0,0 +1,524 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SeriesService 文章系列服务
+// 作者可以把自己的文章编排成连载，管理员可以创建收录任意文章的编辑专题
+type SeriesService struct {
+	db *gorm.DB
+}
+
+// NewSeriesService 创建系列服务
+func NewSeriesService(db *gorm.DB) *SeriesService {
+	return &SeriesService{db: db}
+}
+
+// CreateSeries 创建系列
+func (s *SeriesService) CreateSeries(userID uint, req *models.SeriesCreateRequest) (*models.Series, error) {
+	kind := models.SeriesKindAuthor
+	if req.Editorial {
+		isAdmin, err := s.isAdmin(userID)
+		if err != nil {
+			return nil, err
+		}
+		if !isAdmin {
+			return nil, errors.New("只有管理员可以创建专题")
+		}
+		kind = models.SeriesKindEditorial
+	}
+
+	series := &models.Series{
+		Title:       strings.TrimSpace(req.Title),
+		Description: strings.TrimSpace(req.Description),
+		CoverImage:  req.CoverImage,
+		Kind:        kind,
+		OwnerID:     userID,
+	}
+	if series.Title == "" {
+		return nil, errors.New("系列标题不能为空")
+	}
+	if err := s.db.Create(series).Error; err != nil {
+		return nil, fmt.Errorf("创建系列失败: %v", err)
+	}
+	return s.GetSeries(series.ID)
+}
+
+// UpdateSeries 更新系列信息（创建者或管理员）
+func (s *SeriesService) UpdateSeries(seriesID, userID uint, req *models.SeriesUpdateRequest) (*models.Series, error) {
+	if _, err := s.getManageableSeries(seriesID, userID); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			return nil, errors.New("系列标题不能为空")
+		}
+		updates["title"] = title
+	}
+	if req.Description != nil {
+		updates["description"] = strings.TrimSpace(*req.Description)
+	}
+	if req.CoverImage != nil {
+		updates["cover_image"] = *req.CoverImage
+	}
+	if len(updates) > 0 {
+		if err := s.db.Model(&models.Series{}).Where("id = ?", seriesID).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("更新系列失败: %v", err)
+		}
+	}
+	return s.GetSeries(seriesID)
+}
+
+// DeleteSeries 删除系列（创建者或管理员），文章本身不受影响
+func (s *SeriesService) DeleteSeries(seriesID, userID uint) error {
+	series, err := s.getManageableSeries(seriesID, userID)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("series_id = ?", seriesID).Delete(&models.SeriesArticle{}).Error; err != nil {
+			return fmt.Errorf("删除系列文章失败: %v", err)
+		}
+		if err := tx.Where("series_id = ?", seriesID).Delete(&models.SeriesFollow{}).Error; err != nil {
+			return fmt.Errorf("删除系列关注失败: %v", err)
+		}
+		if err := tx.Delete(series).Error; err != nil {
+			return fmt.Errorf("删除系列失败: %v", err)
+		}
+		return nil
+	})
+}
+
+// GetSeries 获取系列
+func (s *SeriesService) GetSeries(seriesID uint) (*models.Series, error) {
+	var series models.Series
+	if err := s.db.Preload("Owner").First(&series, seriesID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("系列不存在")
+		}
+		return nil, fmt.Errorf("查询系列失败: %v", err)
+	}
+	return &series, nil
+}
+
+// ListSeries 系列列表，可按创建者与类型筛选，按更新时间倒序
+func (s *SeriesService) ListSeries(ownerID uint, kind string, page, size int) ([]models.Series, int64, error) {
+	query := s.db.Model(&models.Series{})
+	if ownerID > 0 {
+		query = query.Where("owner_id = ?", ownerID)
+	}
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询系列失败: %v", err)
+	}
+	var series []models.Series
+	if err := query.Preload("Owner").
+		Order("updated_at DESC, id DESC").
+		Offset((page - 1) * size).Limit(size).
+		Find(&series).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询系列失败: %v", err)
+	}
+	return series, total, nil
+}
+
+// GetSeriesArticles 按顺序获取系列中的文章
+// 创建者与管理员可以看到未发布的文章，其他人只能看到已发布的文章
+func (s *SeriesService) GetSeriesArticles(series *models.Series, viewerID uint) ([]models.SeriesArticle, error) {
+	includeAll := false
+	if viewerID > 0 {
+		canManage, err := s.canManage(series, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		includeAll = canManage
+	}
+
+	query := s.db.Preload("Article").Preload("Article.Author").Preload("Article.Category").Preload("Article.Tags").
+		Joins("JOIN articles ON articles.id = series_articles.article_id AND articles.deleted_at IS NULL").
+		Where("series_articles.series_id = ?", series.ID)
+	if !includeAll {
+		query = query.Where("articles.status = ?", models.ArticleStatusPublished)
+	}
+
+	var items []models.SeriesArticle
+	if err := query.Order("series_articles.position").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("查询系列文章失败: %v", err)
+	}
+	return items, nil
+}
+
+// AddArticle 向系列添加文章，Position 为 0 时追加到末尾
+// 作者连载只能收录创建者自己的文章；已发布的文章加入后会通知系列关注者
+func (s *SeriesService) AddArticle(seriesID, userID uint, req *models.SeriesAddArticleRequest) error {
+	series, err := s.getManageableSeries(seriesID, userID)
+	if err != nil {
+		return err
+	}
+
+	var article models.Article
+	if err := s.db.Select("id, author_id, status").First(&article, req.ArticleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("文章不存在")
+		}
+		return fmt.Errorf("查询文章失败: %v", err)
+	}
+	if series.Kind == models.SeriesKindAuthor && article.AuthorID != series.OwnerID {
+		return errors.New("只能添加自己的文章")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定系列行，串行化同一系列的顺序调整
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Series{}, seriesID).Error; err != nil {
+			return fmt.Errorf("查询系列失败: %v", err)
+		}
+
+		var exists int64
+		if err := tx.Model(&models.SeriesArticle{}).Where("series_id = ? AND article_id = ?", seriesID, article.ID).Count(&exists).Error; err != nil {
+			return fmt.Errorf("查询系列文章失败: %v", err)
+		}
+		if exists > 0 {
+			return errors.New("文章已在该系列中")
+		}
+
+		var count int64
+		if err := tx.Model(&models.SeriesArticle{}).Where("series_id = ?", seriesID).Count(&count).Error; err != nil {
+			return fmt.Errorf("查询系列文章失败: %v", err)
+		}
+		position := int(count) + 1
+		if req.Position > 0 && req.Position < position {
+			position = req.Position
+			if err := tx.Model(&models.SeriesArticle{}).
+				Where("series_id = ? AND position >= ?", seriesID, position).
+				UpdateColumn("position", gorm.Expr("position + 1")).Error; err != nil {
+				return fmt.Errorf("调整系列顺序失败: %v", err)
+			}
+		}
+
+		if err := tx.Create(&models.SeriesArticle{SeriesID: seriesID, ArticleID: article.ID, Position: position}).Error; err != nil {
+			return fmt.Errorf("添加系列文章失败: %v", err)
+		}
+		return refreshSeriesArticleCounts(tx, []uint{seriesID})
+	})
+	if err != nil {
+		return err
+	}
+
+	if article.Status == models.ArticleStatusPublished {
+		go s.notifyNewPart(seriesID, article.ID, false)
+	}
+	return nil
+}
+
+// RemoveArticle 从系列移除文章，后面的文章依次前移
+func (s *SeriesService) RemoveArticle(seriesID, articleID, userID uint) error {
+	if _, err := s.getManageableSeries(seriesID, userID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Series{}, seriesID).Error; err != nil {
+			return fmt.Errorf("查询系列失败: %v", err)
+		}
+
+		var item models.SeriesArticle
+		if err := tx.Where("series_id = ? AND article_id = ?", seriesID, articleID).First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("文章不在该系列中")
+			}
+			return fmt.Errorf("查询系列文章失败: %v", err)
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return fmt.Errorf("移除系列文章失败: %v", err)
+		}
+		if err := tx.Model(&models.SeriesArticle{}).
+			Where("series_id = ? AND position > ?", seriesID, item.Position).
+			UpdateColumn("position", gorm.Expr("position - 1")).Error; err != nil {
+			return fmt.Errorf("调整系列顺序失败: %v", err)
+		}
+		return refreshSeriesArticleCounts(tx, []uint{seriesID})
+	})
+}
+
+// ReorderArticles 按给定顺序重新排列系列中的全部文章
+func (s *SeriesService) ReorderArticles(seriesID, userID uint, articleIDs []uint) error {
+	if _, err := s.getManageableSeries(seriesID, userID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Series{}, seriesID).Error; err != nil {
+			return fmt.Errorf("查询系列失败: %v", err)
+		}
+
+		var current []uint
+		if err := tx.Model(&models.SeriesArticle{}).Where("series_id = ?", seriesID).Pluck("article_id", &current).Error; err != nil {
+			return fmt.Errorf("查询系列文章失败: %v", err)
+		}
+		members := make(map[uint]bool, len(current))
+		for _, id := range current {
+			members[id] = true
+		}
+		seen := make(map[uint]bool, len(articleIDs))
+		for _, id := range articleIDs {
+			if !members[id] || seen[id] {
+				return errors.New("文章列表与系列不一致")
+			}
+			seen[id] = true
+		}
+		if len(seen) != len(members) {
+			return errors.New("文章列表与系列不一致")
+		}
+
+		for i, id := range articleIDs {
+			if err := tx.Model(&models.SeriesArticle{}).
+				Where("series_id = ? AND article_id = ?", seriesID, id).
+				UpdateColumn("position", i+1).Error; err != nil {
+				return fmt.Errorf("调整系列顺序失败: %v", err)
+			}
+		}
+		return nil
+	})
+}
+
+// FollowSeries 关注系列
+func (s *SeriesService) FollowSeries(userID, seriesID uint) error {
+	if _, err := s.GetSeries(seriesID); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.SeriesFollow{UserID: userID, SeriesID: seriesID})
+		if result.Error != nil {
+			return fmt.Errorf("关注系列失败: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("已关注该系列")
+		}
+		return tx.Model(&models.Series{}).Where("id = ?", seriesID).
+			UpdateColumn("follower_count", gorm.Expr("follower_count + 1")).Error
+	})
+}
+
+// UnfollowSeries 取消关注系列
+func (s *SeriesService) UnfollowSeries(userID, seriesID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND series_id = ?", userID, seriesID).Delete(&models.SeriesFollow{})
+		if result.Error != nil {
+			return fmt.Errorf("取消关注失败: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("未关注该系列")
+		}
+		return tx.Model(&models.Series{}).Where("id = ? AND follower_count > 0", seriesID).
+			UpdateColumn("follower_count", gorm.Expr("follower_count - 1")).Error
+	})
+}
+
+// IsFollowingSeries 是否已关注系列
+func (s *SeriesService) IsFollowingSeries(userID, seriesID uint) bool {
+	if userID == 0 {
+		return false
+	}
+	var count int64
+	s.db.Model(&models.SeriesFollow{}).Where("user_id = ? AND series_id = ?", userID, seriesID).Count(&count)
+	return count > 0
+}
+
+// GetFollowedSeries 获取用户关注的系列
+func (s *SeriesService) GetFollowedSeries(userID uint, page, size int) ([]models.Series, int64, error) {
+	query := s.db.Model(&models.Series{}).
+		Joins("JOIN series_follows ON series_follows.series_id = series.id").
+		Where("series_follows.user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询关注的系列失败: %v", err)
+	}
+	var series []models.Series
+	if err := query.Preload("Owner").
+		Order("series_follows.created_at DESC").
+		Offset((page - 1) * size).Limit(size).
+		Find(&series).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询关注的系列失败: %v", err)
+	}
+	return series, total, nil
+}
+
+// GetArticleNavigation 获取文章所属的系列及前后导航，只计入已发布的文章
+func (s *SeriesService) GetArticleNavigation(articleID uint) ([]*models.ArticleSeriesNav, error) {
+	var memberships []models.SeriesArticle
+	if err := s.db.Joins("JOIN series ON series.id = series_articles.series_id AND series.deleted_at IS NULL").
+		Where("series_articles.article_id = ?", articleID).
+		Find(&memberships).Error; err != nil {
+		return nil, fmt.Errorf("查询文章系列失败: %v", err)
+	}
+
+	if len(memberships) == 0 {
+		return []*models.ArticleSeriesNav{}, nil
+	}
+	seriesIDs := make([]uint, len(memberships))
+	for i, m := range memberships {
+		seriesIDs[i] = m.SeriesID
+	}
+
+	// 一次查出所有系列及其已发布文章，按系列分组
+	var seriesList []models.Series
+	if err := s.db.Select("id, title, kind").Where("id IN ?", seriesIDs).Find(&seriesList).Error; err != nil {
+		return nil, fmt.Errorf("查询系列失败: %v", err)
+	}
+	seriesByID := make(map[uint]*models.Series, len(seriesList))
+	for i := range seriesList {
+		seriesByID[seriesList[i].ID] = &seriesList[i]
+	}
+
+	var rows []struct {
+		SeriesID uint
+		models.SeriesNavArticle
+	}
+	if err := s.db.Model(&models.SeriesArticle{}).
+		Select("series_articles.series_id, articles.id, articles.title, articles.slug").
+		Joins("JOIN articles ON articles.id = series_articles.article_id").
+		Where("series_articles.series_id IN ? AND articles.status = ? AND articles.deleted_at IS NULL", seriesIDs, models.ArticleStatusPublished).
+		Order("series_articles.series_id, series_articles.position").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询系列文章失败: %v", err)
+	}
+	partsBySeries := make(map[uint][]models.SeriesNavArticle, len(seriesIDs))
+	for _, row := range rows {
+		partsBySeries[row.SeriesID] = append(partsBySeries[row.SeriesID], row.SeriesNavArticle)
+	}
+
+	navs := make([]*models.ArticleSeriesNav, 0, len(memberships))
+	for _, m := range memberships {
+		series, ok := seriesByID[m.SeriesID]
+		if !ok {
+			continue
+		}
+		parts := partsBySeries[m.SeriesID]
+
+		nav := &models.ArticleSeriesNav{SeriesID: series.ID, Title: series.Title, Kind: series.Kind, Total: len(parts)}
+		for i := range parts {
+			if parts[i].ID != articleID {
+				continue
+			}
+			nav.Position = i + 1
+			if i > 0 {
+				nav.Prev = &parts[i-1]
+			}
+			if i+1 < len(parts) {
+				nav.Next = &parts[i+1]
+			}
+		}
+		navs = append(navs, nav)
+	}
+	return navs, nil
+}
+
+// OnArticlePublished 文章发布后刷新所属系列的文章数，并通知尚未通知过的系列关注者
+func (s *SeriesService) OnArticlePublished(articleID uint) {
+	var seriesIDs []uint
+	if err := s.db.Model(&models.SeriesArticle{}).Where("article_id = ?", articleID).Pluck("series_id", &seriesIDs).Error; err != nil {
+		log.Printf("查询文章系列失败 (article: %d): %v", articleID, err)
+		return
+	}
+	if len(seriesIDs) == 0 {
+		return
+	}
+	if err := refreshSeriesArticleCounts(s.db, seriesIDs); err != nil {
+		log.Printf("刷新系列文章数失败 (article: %d): %v", articleID, err)
+	}
+	for _, seriesID := range seriesIDs {
+		s.notifyNewPart(seriesID, articleID, true)
+	}
+}
+
+// RefreshArticleSeriesCounts 文章状态变化或删除后刷新所属系列的已发布文章数
+func (s *SeriesService) RefreshArticleSeriesCounts(articleID uint) error {
+	var seriesIDs []uint
+	if err := s.db.Model(&models.SeriesArticle{}).Where("article_id = ?", articleID).Pluck("series_id", &seriesIDs).Error; err != nil {
+		return fmt.Errorf("查询文章系列失败: %v", err)
+	}
+	return refreshSeriesArticleCounts(s.db, seriesIDs)
+}
+
+// notifyNewPart 通知系列关注者有新文章；通过条件更新 notified_at 保证同一篇文章只通知一次
+// onPublish 表示随文章首次发布触发，作者的粉丝已在同一次发布中收到通知
+func (s *SeriesService) notifyNewPart(seriesID, articleID uint, onPublish bool) {
+	now := time.Now()
+	result := s.db.Model(&models.SeriesArticle{}).
+		Where("series_id = ? AND article_id = ? AND notified_at IS NULL", seriesID, articleID).
+		UpdateColumn("notified_at", &now)
+	if result.Error != nil {
+		log.Printf("更新系列通知状态失败 (series: %d, article: %d): %v", seriesID, articleID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+	if err := NewNotificationService(s.db).NotifySeriesFollowersNewArticle(seriesID, articleID, onPublish); err != nil {
+		log.Printf("通知系列关注者失败 (series: %d, article: %d): %v", seriesID, articleID, err)
+	}
+}
+
+// refreshSeriesArticleCounts 按已发布文章重新计算系列的文章数
+func refreshSeriesArticleCounts(db *gorm.DB, seriesIDs []uint) error {
+	if len(seriesIDs) == 0 {
+		return nil
+	}
+	published := db.Model(&models.SeriesArticle{}).Select("COUNT(*)").
+		Joins("JOIN articles ON articles.id = series_articles.article_id").
+		Where("series_articles.series_id = series.id AND articles.status = ? AND articles.deleted_at IS NULL", models.ArticleStatusPublished)
+	if err := db.Model(&models.Series{}).Where("id IN ?", seriesIDs).
+		UpdateColumn("article_count", gorm.Expr("(?)", published)).Error; err != nil {
+		return fmt.Errorf("刷新系列文章数失败: %v", err)
+	}
+	return nil
+}
+
+// getManageableSeries 获取系列并校验当前用户可管理（创建者或管理员）
+func (s *SeriesService) getManageableSeries(seriesID, userID uint) (*models.Series, error) {
+	series, err := s.GetSeries(seriesID)
+	if err != nil {
+		return nil, err
+	}
+	canManage, err := s.canManage(series, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		return nil, errors.New("无权限管理此系列")
+	}
+	return series, nil
+}
+
+func (s *SeriesService) canManage(series *models.Series, userID uint) (bool, error) {
+	if series.OwnerID == userID {
+		return true, nil
+	}
+	return s.isAdmin(userID)
+}
+
+func (s *SeriesService) isAdmin(userID uint) (bool, error) {
+	var user models.User
+	if err := s.db.Select("id, role").First(&user, userID).Error; err != nil {
+		return false, fmt.Errorf("查询用户失败: %v", err)
+	}
+	return user.Role >= 2, nil
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"godad-backend/config"
+	"godad-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// sessionCacheKeyPrefix 会话状态缓存键前缀，值为 active / revoked
+	sessionCacheKeyPrefix = "auth:session:"
+	sessionStateActive    = "active"
+	sessionStateRevoked   = "revoked"
+
+	// revokedJTIKeyPrefix 已吊销访问令牌ID（jti）列表，键在原令牌过期后自动删除
+	revokedJTIKeyPrefix = "auth:jti:revoked:"
+
+	// refreshReuseGrace 刷新令牌轮换后的宽限期
+	// 宽限期内重复提交上一个刷新令牌视为并发刷新，仅拒绝不吊销会话
+	refreshReuseGrace = 30 * time.Second
+)
+
+var (
+	// ErrSessionRevoked 会话已吊销或已过期
+	ErrSessionRevoked = errors.New("会话已失效，请重新登录")
+	// ErrRefreshTokenReused 检测到刷新令牌被重复使用（可能已泄露），会话已被吊销
+	ErrRefreshTokenReused = errors.New("刷新令牌已被使用，会话已吊销，请重新登录")
+	// ErrRefreshConcurrent 刷新令牌刚刚被轮换（并发刷新）
+	ErrRefreshConcurrent = errors.New("令牌已刷新，请使用最新令牌")
+)
+
+// SessionService 登录会话服务
+type SessionService struct {
+	db *gorm.DB
+}
+
+// NewSessionService 创建会话服务实例
+func NewSessionService(db *gorm.DB) *SessionService {
+	return &SessionService{db: db}
+}
+
+// sessionTTL 会话有效期（与刷新令牌一致）
+func sessionTTL() time.Duration {
+	return time.Duration(config.GetConfig().JWT.RefreshExpireHours) * time.Hour
+}
+
+// CreateSession 登录时创建新会话
+func (s *SessionService) CreateSession(userID uint, userAgent, ip string) (*models.UserSession, error) {
+	now := time.Now()
+	session := &models.UserSession{
+		UserID:     userID,
+		SessionID:  uuid.New().String(),
+		AccessJTI:  uuid.New().String(),
+		RefreshJTI: uuid.New().String(),
+		UserAgent:  truncateString(userAgent, 255),
+		IP:         ip,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(sessionTTL()),
+	}
+
+	if err := s.db.Create(session).Error; err != nil {
+		return nil, fmt.Errorf("创建会话失败: %v", err)
+	}
+
+	s.cacheState(session.SessionID, sessionStateActive, time.Until(session.ExpiresAt))
+	return session, nil
+}
+
+// RotateSession 使用刷新令牌轮换会话令牌
+// 提交的刷新令牌ID必须与会话当前记录一致；否则视为刷新令牌被重放，吊销整个会话
+func (s *SessionService) RotateSession(sessionID, refreshJTI, userAgent, ip string) (*models.UserSession, error) {
+	var session models.UserSession
+	var reused bool
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSessionRevoked
+			}
+			return err
+		}
+
+		if !session.IsActive() {
+			return ErrSessionRevoked
+		}
+
+		now := time.Now()
+		if session.RefreshJTI != refreshJTI {
+			if session.PrevRefreshJTI == refreshJTI && session.RotatedAt != nil && now.Sub(*session.RotatedAt) < refreshReuseGrace {
+				return ErrRefreshConcurrent
+			}
+			reused = true
+			return tx.Model(&session).Updates(map[string]interface{}{
+				"revoked_at":    now,
+				"revoke_reason": models.SessionRevokeTokenReuse,
+			}).Error
+		}
+
+		session.PrevAccessJTI = session.AccessJTI
+		updates := map[string]interface{}{
+			"access_jti":       uuid.New().String(),
+			"refresh_jti":      uuid.New().String(),
+			"prev_refresh_jti": session.RefreshJTI,
+			"rotated_at":       now,
+			"last_used_at":     now,
+			"expires_at":       now.Add(sessionTTL()),
+			"user_agent":       truncateString(userAgent, 255),
+			"ip":               ip,
+		}
+		if err := tx.Model(&session).Updates(updates).Error; err != nil {
+			return err
+		}
+		prevAccessJTI := session.PrevAccessJTI
+		if err := tx.First(&session, session.ID).Error; err != nil {
+			return err
+		}
+		session.PrevAccessJTI = prevAccessJTI
+		return nil
+	})
+
+	if reused {
+		s.cacheState(sessionID, sessionStateRevoked, sessionTTL())
+		return nil, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 轮换前签发的访问令牌立即失效
+	s.revokeAccessJTI(session.PrevAccessJTI)
+	s.cacheState(session.SessionID, sessionStateActive, time.Until(session.ExpiresAt))
+	return &session, nil
+}
+
+// IsAccessTokenActive 检查访问令牌是否有效：会话有效，且令牌ID未因轮换被吊销
+// Redis 可用时查询吊销列表；不可用时回源数据库，要求令牌ID与会话当前记录一致
+func (s *SessionService) IsAccessTokenActive(sessionID, jti string) (bool, error) {
+	if isCacheReady() && jti != "" {
+		revoked, err := rdb.Exists(ctx, revokedJTIKeyPrefix+jti).Result()
+		if err == nil {
+			if revoked > 0 {
+				return false, nil
+			}
+			return s.IsSessionActive(sessionID)
+		}
+		// Redis 异常时回源数据库
+	}
+
+	if sessionID == "" {
+		return false, nil
+	}
+	var session models.UserSession
+	if err := s.db.Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return session.IsActive() && (jti == "" || jti == session.AccessJTI), nil
+}
+
+// revokeAccessJTI 将访问令牌ID加入吊销列表，保留到该令牌自然过期
+func (s *SessionService) revokeAccessJTI(jti string) {
+	ttl := time.Duration(config.GetConfig().JWT.ExpireHours) * time.Hour
+	if jti == "" || !isCacheReady() || ttl <= 0 {
+		return
+	}
+	rdb.Set(ctx, revokedJTIKeyPrefix+jti, 1, ttl)
+}
+
+// IsSessionActive 检查会话是否有效
+// 优先读取 Redis 中缓存的会话状态，未命中或 Redis 不可用时回源数据库
+func (s *SessionService) IsSessionActive(sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+
+	if isCacheReady() {
+		if state, err := rdb.Get(ctx, sessionCacheKeyPrefix+sessionID).Result(); err == nil {
+			return state == sessionStateActive, nil
+		}
+		// 未命中或 Redis 异常时回源数据库
+	}
+
+	var session models.UserSession
+	if err := s.db.Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.cacheState(sessionID, sessionStateRevoked, sessionTTL())
+			return false, nil
+		}
+		return false, err
+	}
+
+	if session.IsActive() {
+		s.cacheState(sessionID, sessionStateActive, time.Until(session.ExpiresAt))
+		return true, nil
+	}
+	s.cacheState(sessionID, sessionStateRevoked, sessionTTL())
+	return false, nil
+}
+
+// ListActiveSessions 获取用户的有效会话列表
+func (s *SessionService) ListActiveSessions(userID uint) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("获取会话列表失败: %v", err)
+	}
+	return sessions, nil
+}
+
+// GetSessionBySID 根据会话标识获取会话
+func (s *SessionService) GetSessionBySID(sessionID string) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := s.db.Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("会话不存在")
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RevokeSession 吊销指定会话（按会话标识）
+func (s *SessionService) RevokeSession(sessionID, reason string) error {
+	if sessionID == "" {
+		return nil
+	}
+	err := s.db.Model(&models.UserSession{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Updates(map[string]interface{}{
+			"revoked_at":    time.Now(),
+			"revoke_reason": reason,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("吊销会话失败: %v", err)
+	}
+	s.cacheState(sessionID, sessionStateRevoked, sessionTTL())
+	return nil
+}
+
+// RevokeUserSession 用户吊销自己的某个会话（按会话记录ID）
+func (s *SessionService) RevokeUserSession(userID, id uint) error {
+	var session models.UserSession
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("会话不存在")
+		}
+		return err
+	}
+	return s.RevokeSession(session.SessionID, models.SessionRevokeUser)
+}
+
+// RevokeAllUserSessions 吊销用户的所有会话，exceptSessionID 不为空时保留该会话
+func (s *SessionService) RevokeAllUserSessions(userID uint, reason, exceptSessionID string) error {
+	query := s.db.Model(&models.UserSession{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptSessionID != "" {
+		query = query.Where("session_id <> ?", exceptSessionID)
+	}
+
+	var sessionIDs []string
+	if err := query.Pluck("session_id", &sessionIDs).Error; err != nil {
+		return fmt.Errorf("查询用户会话失败: %v", err)
+	}
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+
+	err := s.db.Model(&models.UserSession{}).
+		Where("session_id IN ?", sessionIDs).
+		Updates(map[string]interface{}{
+			"revoked_at":    time.Now(),
+			"revoke_reason": reason,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("吊销用户会话失败: %v", err)
+	}
+
+	for _, sid := range sessionIDs {
+		s.cacheState(sid, sessionStateRevoked, sessionTTL())
+	}
+	return nil
+}
+
+// cacheState 缓存会话状态（缓存不可用时忽略）
+func (s *SessionService) cacheState(sessionID, state string, ttl time.Duration) {
+	if !isCacheReady() || ttl <= 0 {
+		return
+	}
+	rdb.Set(ctx, sessionCacheKeyPrefix+sessionID, state, ttl)
+}
+
+// truncateString 按字节截断字符串（保证不截断多字节字符）
+func truncateString(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	runes := []rune(value)
+	for len(string(runes)) > max {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
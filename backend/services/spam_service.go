@@ -0,0 +1,418 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math/bits"
+	"strings"
+	"time"
+
+	"godad-backend/config"
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSpamThrottled 低信任账号发布过于频繁
+var ErrSpamThrottled = errors.New("发布过于频繁，请稍后再试")
+
+const (
+	// spamShingleSize simhash 分片长度（字符数），中文短文本用二元组对局部改写更稳定
+	spamShingleSize = 2
+	// spamMinFingerprintLength 参与近似重复检测的最短内容（归一化后的字符数），过短的内容如“谢谢分享”不做去重
+	spamMinFingerprintLength = 20
+	// spamMaxHammingDistance 视为近似重复的最大汉明距离
+	spamMaxHammingDistance = 3
+	// spamDuplicateCandidateLimit 近似重复候选的最大查询数量
+	spamDuplicateCandidateLimit = 500
+)
+
+// SpamService 垃圾内容评分服务：综合近似重复、链接密度、发布频率与账号年龄
+type SpamService struct {
+	db *gorm.DB
+}
+
+// NewSpamService 创建垃圾内容评分服务
+func NewSpamService(db *gorm.DB) *SpamService {
+	return &SpamService{db: db}
+}
+
+// SpamCheckDetail 评分记录详情，附带窗口内的近似重复内容
+type SpamCheckDetail struct {
+	*models.SpamCheck
+	Duplicates []models.SpamCheck `json:"duplicates"`
+}
+
+// Assess 为即将发布的内容评分，返回尚未保存的记录；未启用或管理员发布时返回 nil。
+// 低信任账号在频率窗口内发布过多时返回 ErrSpamThrottled
+func (s *SpamService) Assess(userID uint, targetType, content, contentHTML string) (*models.SpamCheck, error) {
+	return s.assess(userID, targetType, 0, content, contentHTML)
+}
+
+// AssessEdit 为编辑后的内容重新评分，同一内容此前的评分记录不计入近似重复
+func (s *SpamService) AssessEdit(userID uint, targetType string, targetID uint, content, contentHTML string) (*models.SpamCheck, error) {
+	return s.assess(userID, targetType, targetID, content, contentHTML)
+}
+
+// assess 评分实现，targetID 为被编辑内容的ID，新发布时为 0
+func (s *SpamService) assess(userID uint, targetType string, targetID uint, content, contentHTML string) (*models.SpamCheck, error) {
+	cfg := config.GetConfig().Spam
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	var user models.User
+	if err := s.db.Select("id", "role", "created_at").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if user.Role >= 2 {
+		return nil, nil
+	}
+	now := time.Now()
+	accountAge := now.Sub(user.CreatedAt)
+
+	// 发布频率：以评分记录统计用户近期发布的帖子与评论
+	velocityWindow := time.Duration(cfg.VelocityWindowMins) * time.Minute
+	var recent int64
+	if err := s.db.Model(&models.SpamCheck{}).
+		Where("user_id = ? AND created_at > ?", userID, now.Add(-velocityWindow)).
+		Count(&recent).Error; err != nil {
+		return nil, err
+	}
+	lowTrust := accountAge < time.Duration(cfg.LowTrustAccountDays)*24*time.Hour
+	if lowTrust && cfg.LowTrustMaxPerWindow > 0 && recent >= int64(cfg.LowTrustMaxPerWindow) {
+		return nil, ErrSpamThrottled
+	}
+
+	text := normalizeSensitivePattern(content)
+	check := &models.SpamCheck{
+		TargetType: targetType,
+		UserID:     userID,
+		Excerpt:    truncateString(strings.Join(strings.Fields(content), " "), 240),
+		CreatedAt:  now,
+	}
+	if len(text) >= spamMinFingerprintLength {
+		setSpamFingerprint(check, simhash(text))
+	}
+
+	// 近似重复
+	if check.Simhash != 0 {
+		since := now.Add(-time.Duration(cfg.DuplicateWindowHours) * time.Hour)
+		found, err := s.findDuplicates(check, since, now, 0)
+		if err != nil {
+			return nil, err
+		}
+		duplicates := found[:0]
+		for _, d := range found {
+			if targetID == 0 || d.TargetType != targetType || d.TargetID != targetID {
+				duplicates = append(duplicates, d)
+			}
+		}
+		if len(duplicates) > 0 {
+			others := make(map[uint]struct{})
+			self := 0
+			for _, d := range duplicates {
+				if d.UserID == userID {
+					self++
+				} else {
+					others[d.UserID] = struct{}{}
+				}
+			}
+			check.Signals = append(check.Signals, models.SpamSignal{
+				Name:   "duplicate",
+				Score:  min(60, 25*len(others)+15*self),
+				Detail: fmt.Sprintf("近%d小时内有%d条相似内容（本人%d条，其他用户%d人）", cfg.DuplicateWindowHours, len(duplicates), self, len(others)),
+			})
+		}
+	}
+
+	// 链接密度
+	links := strings.Count(contentHTML, "<a ")
+	lower := strings.ToLower(content)
+	if n := strings.Count(lower, "http://") + strings.Count(lower, "https://") + strings.Count(lower, "www."); n > links {
+		links = n
+	}
+	if links > 0 {
+		score := min(25, links*8)
+		detail := fmt.Sprintf("包含%d个链接", links)
+		if len(text) < 40*links {
+			score += 10
+			detail += "，正文以链接为主"
+		}
+		check.Signals = append(check.Signals, models.SpamSignal{Name: "link_density", Score: score, Detail: detail})
+	}
+
+	// 发布频率
+	if recent >= 3 {
+		check.Signals = append(check.Signals, models.SpamSignal{
+			Name:   "velocity",
+			Score:  min(25, int(recent-2)*8),
+			Detail: fmt.Sprintf("%d分钟内已发布%d条内容", cfg.VelocityWindowMins, recent),
+		})
+	}
+
+	// 账号年龄
+	var ageScore int
+	switch {
+	case accountAge < 24*time.Hour:
+		ageScore = 20
+	case accountAge < 72*time.Hour:
+		ageScore = 10
+	case accountAge < 7*24*time.Hour:
+		ageScore = 5
+	}
+	if ageScore > 0 {
+		check.Signals = append(check.Signals, models.SpamSignal{
+			Name:   "account_age",
+			Score:  ageScore,
+			Detail: fmt.Sprintf("账号注册%.0f小时", accountAge.Hours()),
+		})
+	}
+
+	for _, signal := range check.Signals {
+		check.Score += signal.Score
+	}
+	check.Score = min(100, check.Score)
+	if cfg.HoldScore > 0 && check.Score >= cfg.HoldScore {
+		check.ReviewStatus = models.SpamReviewHeld
+	}
+	return check, nil
+}
+
+// Record 内容保存后记录评分，check 为 nil 时忽略
+func (s *SpamService) Record(check *models.SpamCheck, targetID uint) {
+	if check == nil {
+		return
+	}
+	check.TargetID = targetID
+	if err := s.db.Create(check).Error; err != nil {
+		log.Printf("保存垃圾内容评分失败 %s#%d: %v", check.TargetType, targetID, err)
+	}
+}
+
+// spamHoldReason 内容被自动拦截时的审核原因，未拦截返回空字符串
+func spamHoldReason(check *models.SpamCheck) string {
+	if check == nil || check.ReviewStatus != models.SpamReviewHeld {
+		return ""
+	}
+	return fmt.Sprintf("疑似垃圾内容（评分%d）", check.Score)
+}
+
+// ListChecks 评分记录列表（管理员），按时间倒序
+func (s *SpamService) ListChecks(req *models.SpamCheckListRequest) ([]models.SpamCheck, int64, error) {
+	query := s.db.Model(&models.SpamCheck{})
+	if req.TargetType != "" {
+		query = query.Where("target_type = ?", req.TargetType)
+	}
+	if req.ReviewStatus != "" {
+		query = query.Where("review_status = ?", req.ReviewStatus)
+	}
+	if req.MinScore > 0 {
+		query = query.Where("score >= ?", req.MinScore)
+	}
+	if req.UserID > 0 {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var checks []models.SpamCheck
+	if err := query.Preload("User").Order("id DESC").Offset((req.Page - 1) * req.Size).Limit(req.Size).Find(&checks).Error; err != nil {
+		return nil, 0, err
+	}
+	return checks, total, nil
+}
+
+// GetCheck 评分记录详情，附带评分时窗口内的近似重复内容
+func (s *SpamService) GetCheck(id uint) (*SpamCheckDetail, error) {
+	var check models.SpamCheck
+	if err := s.db.Preload("User").First(&check, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("评分记录不存在")
+		}
+		return nil, err
+	}
+
+	detail := &SpamCheckDetail{SpamCheck: &check, Duplicates: []models.SpamCheck{}}
+	if check.Simhash != 0 {
+		window := time.Duration(config.GetConfig().Spam.DuplicateWindowHours) * time.Hour
+		duplicates, err := s.findDuplicates(&check, check.CreatedAt.Add(-window), check.CreatedAt.Add(window), check.ID)
+		if err != nil {
+			return nil, err
+		}
+		detail.Duplicates = duplicates
+	}
+	return detail, nil
+}
+
+// Review 处理被拦截的内容：release 放行发布，confirm 确认为垃圾内容（评论驳回、帖子删除）
+func (s *SpamService) Review(id uint, action string, adminID uint) error {
+	var check models.SpamCheck
+	if err := s.db.First(&check, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("评分记录不存在")
+		}
+		return err
+	}
+	if check.ReviewStatus != models.SpamReviewHeld {
+		return errors.New("该内容不在待审核状态")
+	}
+
+	switch check.TargetType {
+	case models.ReportTargetComment:
+		// 评论走待审核队列，审核结果由 CommentService 回写评分记录
+		commentService := NewCommentService()
+		var err error
+		if action == "release" {
+			_, err = commentService.ApproveComments([]uint{check.TargetID}, adminID)
+		} else {
+			_, err = commentService.RejectComments([]uint{check.TargetID}, "疑似垃圾内容", adminID)
+		}
+		if err != nil {
+			return err
+		}
+		// 评论已在其他入口处理过时，队列操作不会命中，这里补记处理结果
+		return s.markReviewed(check.TargetType, []uint{check.TargetID}, spamReviewStatus(action), adminID)
+	case models.ReportTargetForumPost:
+		return s.reviewForumPost(&check, action, adminID)
+	default:
+		return errors.New("不支持的内容类型")
+	}
+}
+
+// reviewForumPost 放行或删除被拦截的帖子
+func (s *SpamService) reviewForumPost(check *models.SpamCheck, action string, adminID uint) error {
+	var post models.ForumPost
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&post, check.TargetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("帖子不存在")
+			}
+			return err
+		}
+		if post.Status == models.ForumPostStatusHeld {
+			status := models.ForumPostStatusPublished
+			if action != "release" {
+				status = models.ForumPostStatusDeleted
+			}
+			if err := tx.Model(&post).Update("status", status).Error; err != nil {
+				return err
+			}
+			post.Status = status
+		}
+		return s.markReviewedTx(tx, check.TargetType, []uint{check.TargetID}, spamReviewStatus(action), adminID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if post.Status == models.ForumPostStatusPublished {
+		NewForumService().indexPost(&post)
+		go NewFeedService(s.db).InvalidateFollowerFeeds(post.AuthorID)
+	} else if err := NewForumService().refundBounty(post.ID, "问题未通过审核，退还悬赏"); err != nil {
+		log.Printf("退还悬赏失败 post=%d: %v", post.ID, err)
+	}
+	NewCacheService().DeletePattern("forum:posts:*")
+	return nil
+}
+
+// markReviewed 回写被拦截内容的处理结果
+func (s *SpamService) markReviewed(targetType string, targetIDs []uint, status string, adminID uint) error {
+	return s.markReviewedTx(s.db, targetType, targetIDs, status, adminID)
+}
+
+func (s *SpamService) markReviewedTx(tx *gorm.DB, targetType string, targetIDs []uint, status string, adminID uint) error {
+	if len(targetIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.SpamCheck{}).
+		Where("target_type = ? AND target_id IN ? AND review_status = ?", targetType, targetIDs, models.SpamReviewHeld).
+		Updates(map[string]interface{}{
+			"review_status": status,
+			"reviewed_by":   adminID,
+			"reviewed_at":   time.Now(),
+		}).Error
+}
+
+// CleanupChecks 清理超过保留期且未被拦截的评分记录，被拦截的记录保留作为审核依据
+func (s *SpamService) CleanupChecks(retention time.Duration) (int64, error) {
+	result := s.db.Where("created_at < ? AND review_status = ?", time.Now().Add(-retention), models.SpamReviewNone).
+		Delete(&models.SpamCheck{})
+	return result.RowsAffected, result.Error
+}
+
+// findDuplicates 查找时间窗口内与 check 近似重复的内容，excludeID 为需要排除的记录
+func (s *SpamService) findDuplicates(check *models.SpamCheck, since, until time.Time, excludeID uint) ([]models.SpamCheck, error) {
+	var candidates []models.SpamCheck
+	err := s.db.Where("simhash <> 0 AND id <> ? AND created_at BETWEEN ? AND ?", excludeID, since, until).
+		Where("band0 = ? OR band1 = ? OR band2 = ? OR band3 = ?", check.Band0, check.Band1, check.Band2, check.Band3).
+		Order("id DESC").Limit(spamDuplicateCandidateLimit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates := candidates[:0]
+	for _, c := range candidates {
+		if spamNearDuplicate(c.Simhash, check.Simhash) {
+			duplicates = append(duplicates, c)
+		}
+	}
+	return duplicates, nil
+}
+
+func spamReviewStatus(action string) string {
+	if action == "release" {
+		return models.SpamReviewReleased
+	}
+	return models.SpamReviewConfirmed
+}
+
+// setSpamFingerprint 写入指纹及其四个 16 位分段
+func setSpamFingerprint(check *models.SpamCheck, fp uint64) {
+	check.Simhash = fp
+	check.Band0 = uint16(fp)
+	check.Band1 = uint16(fp >> 16)
+	check.Band2 = uint16(fp >> 32)
+	check.Band3 = uint16(fp >> 48)
+}
+
+// spamNearDuplicate 两个指纹的汉明距离是否不超过 spamMaxHammingDistance；
+// 距离不超过 3 时四个 16 位分段中至少有一段完全相同，因此按分段查询候选不会漏掉
+func spamNearDuplicate(a, b uint64) bool {
+	return bits.OnesCount64(a^b) <= spamMaxHammingDistance
+}
+
+// simhash 以字符 n-gram 为特征计算 64 位 simhash，结果为 0 时取 1（0 表示无指纹）
+func simhash(text []rune) uint64 {
+	var weights [64]int
+	h := fnv.New64a()
+	for i := 0; i+spamShingleSize <= len(text); i++ {
+		h.Reset()
+		h.Write([]byte(string(text[i : i+spamShingleSize])))
+		v := h.Sum64()
+		for b := 0; b < 64; b++ {
+			if v&(1<<b) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+
+	var fp uint64
+	for b := 0; b < 64; b++ {
+		if weights[b] > 0 {
+			fp |= 1 << b
+		}
+	}
+	if fp == 0 {
+		fp = 1
+	}
+	return fp
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"math/bits"
+	"testing"
+
+	"godad-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSimhash 测试 simhash 的稳定性，以及归一化后只差标点、空白和全角的内容指纹相同
+func TestSimhash(t *testing.T) {
+	original := normalizeSensitivePattern("宝宝晚上总是哭闹不肯睡觉，试过抱着哄和放白噪音都没有用，大家有什么好办法吗？孩子现在8个月大。")
+	edited := normalizeSensitivePattern("宝宝 晚上总是哭闹不肯睡觉!! 试过抱着哄和放白噪音都没有用……大家有什么好办法吗 孩子现在８个月大")
+	unrelated := normalizeSensitivePattern("周末带孩子去郊外露营，准备了帐篷、睡袋和很多零食，结果下了一整天的雨，只好在车里玩了一下午的桌游。")
+
+	assert.Equal(t, simhash(original), simhash(original))
+	assert.Equal(t, simhash(original), simhash(edited))
+	assert.False(t, spamNearDuplicate(simhash(original), simhash(unrelated)))
+
+	// 不足一个分片时没有特征，返回 1 而不是表示“无指纹”的 0
+	assert.Equal(t, uint64(1), simhash([]rune("a")))
+	assert.Equal(t, uint64(1), simhash(nil))
+}
+
+// TestSpamNearDuplicate 测试汉明距离阈值
+func TestSpamNearDuplicate(t *testing.T) {
+	const fp uint64 = 0x0123456789abcdef
+	testCases := []struct {
+		name     string
+		other    uint64
+		expected bool
+	}{
+		{name: "相同", other: fp, expected: true},
+		{name: "相差1位", other: fp ^ 1, expected: true},
+		{name: "相差3位", other: fp ^ (1 | 1<<20 | 1<<63), expected: true},
+		{name: "相差4位", other: fp ^ (1 | 1<<20 | 1<<40 | 1<<63), expected: false},
+		{name: "全部取反", other: ^fp, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, spamNearDuplicate(fp, tc.other))
+			assert.Equal(t, tc.expected, spamNearDuplicate(tc.other, fp))
+		})
+	}
+}
+
+// TestSetSpamFingerprint 测试分段写入，以及距离不超过阈值的指纹至少共享一个分段
+func TestSetSpamFingerprint(t *testing.T) {
+	check := &models.SpamCheck{}
+	setSpamFingerprint(check, 0x0123456789abcdef)
+	assert.Equal(t, uint64(0x0123456789abcdef), check.Simhash)
+	assert.Equal(t, uint16(0xcdef), check.Band0)
+	assert.Equal(t, uint16(0x89ab), check.Band1)
+	assert.Equal(t, uint16(0x4567), check.Band2)
+	assert.Equal(t, uint16(0x0123), check.Band3)
+
+	// 依次在 1~4 个分段中各翻转一位：距离不超过阈值时至少有一个分段相同，四段都翻转时分段全部不同
+	const fp uint64 = 0x0123456789abcdef
+	for _, mask := range []uint64{1, 1 | 1<<16, 1 | 1<<16 | 1<<32, 1 | 1<<16 | 1<<32 | 1<<48} {
+		a, b := &models.SpamCheck{}, &models.SpamCheck{}
+		setSpamFingerprint(a, fp)
+		setSpamFingerprint(b, fp^mask)
+		shared := a.Band0 == b.Band0 || a.Band1 == b.Band1 || a.Band2 == b.Band2 || a.Band3 == b.Band3
+		if bits.OnesCount64(mask) <= spamMaxHammingDistance {
+			assert.True(t, shared, "mask %x", mask)
+		} else {
+			assert.False(t, shared, "mask %x", mask)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"godad-backend/config"
+)
+
+// 存储驱动
+const (
+	StorageDriverOSS   = "oss"
+	StorageDriverLocal = "local"
+	StorageDriverS3    = "s3"
+)
+
+// ErrStorageObjectNotFound 对象不存在
+var ErrStorageObjectNotFound = errors.New("文件不存在")
+
+// StorageObject 存储对象的元信息
+type StorageObject struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+// Storage 文件存储后端
+// 对象键使用 "/" 分隔的相对路径（如 ContentImage/20240101/xxx.png），不以 "/" 开头
+type Storage interface {
+	// Put 写入对象，已存在时覆盖
+	Put(key string, reader io.Reader, size int64, contentType string) error
+	// Delete 删除对象，对象不存在时不报错
+	Delete(key string) error
+	// Stat 获取对象元信息，对象不存在时返回 ErrStorageObjectNotFound
+	Stat(key string) (*StorageObject, error)
+	// SignedURL 生成带有效期的临时访问地址
+	SignedURL(key string, expires time.Duration) (string, error)
+	// PublicURL 对象的公开访问地址
+	PublicURL(key string) string
+}
+
+var (
+	storage   Storage
+	storageMu sync.Mutex
+)
+
+// GetStorage 获取全局文件存储，首次调用时按配置创建
+func GetStorage() (Storage, error) {
+	storageMu.Lock()
+	defer storageMu.Unlock()
+
+	if storage == nil {
+		s, err := NewStorage(config.GetConfig())
+		if err != nil {
+			return nil, err
+		}
+		storage = s
+	}
+	return storage, nil
+}
+
+// SetStorage 替换全局文件存储实现
+func SetStorage(s Storage) {
+	storageMu.Lock()
+	storage = s
+	storageMu.Unlock()
+}
+
+// NewStorage 按 config.Storage.Driver 创建文件存储
+func NewStorage(cfg *config.Config) (Storage, error) {
+	switch strings.ToLower(cfg.Storage.Driver) {
+	case StorageDriverOSS:
+		return NewOSSStorage(cfg.OSS)
+	case StorageDriverLocal:
+		return NewLocalStorage(cfg.Storage.Local, cfg.JWT.Secret)
+	case StorageDriverS3:
+		return NewS3Storage(cfg.Storage.S3)
+	default:
+		return nil, fmt.Errorf("不支持的存储驱动: %s", cfg.Storage.Driver)
+	}
+}
+
+// storageSignedURLExpire 配置的签名URL默认有效期
+func storageSignedURLExpire() time.Duration {
+	seconds := config.GetConfig().Storage.SignedURLExpire
+	if seconds <= 0 {
+		seconds = 3600
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"godad-backend/config"
+)
+
+// LocalStorage 本地磁盘存储，文件通过 Gin 路由（URLPrefix）对外提供访问，仅用于开发与测试环境
+type LocalStorage struct {
+	root      string
+	urlPrefix string
+	baseURL   string
+	secret    []byte
+}
+
+// NewLocalStorage 创建本地磁盘存储，secret 用于签名临时访问地址
+func NewLocalStorage(cfg config.LocalStorageConfig, secret string) (*LocalStorage, error) {
+	root, err := filepath.Abs(cfg.Root)
+	if err != nil {
+		return nil, fmt.Errorf("解析存储目录失败: %v", err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("创建存储目录失败: %v", err)
+	}
+
+	prefix := "/" + strings.Trim(cfg.URLPrefix, "/")
+	if prefix == "/" {
+		return nil, errors.New("本地存储访问路径前缀不能为空")
+	}
+
+	return &LocalStorage{
+		root:      root,
+		urlPrefix: prefix,
+		baseURL:   strings.TrimRight(cfg.BaseURL, "/"),
+		secret:    []byte(secret),
+	}, nil
+}
+
+// URLPrefix 文件访问路由前缀
+func (s *LocalStorage) URLPrefix() string {
+	return s.urlPrefix
+}
+
+// FilePath 将对象键解析为磁盘路径，拒绝越出存储目录的键
+func (s *LocalStorage) FilePath(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "\\") {
+		return "", ErrStorageObjectNotFound
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned[1:])), nil
+}
+
+// Put 写入文件，先写临时文件再重命名，避免读到不完整的内容
+func (s *LocalStorage) Put(key string, reader io.Reader, size int64, contentType string) error {
+	target, err := s.FilePath(key)
+	if err != nil {
+		return fmt.Errorf("无效的文件路径: %s", key)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, reader); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("设置文件权限失败: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("保存文件失败: %v", err)
+	}
+	return nil
+}
+
+// Delete 删除文件
+func (s *LocalStorage) Delete(key string) error {
+	target, err := s.FilePath(key)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除文件失败: %v", err)
+	}
+	return nil
+}
+
+// Stat 获取文件信息
+func (s *LocalStorage) Stat(key string) (*StorageObject, error) {
+	target, err := s.FilePath(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrStorageObjectNotFound
+		}
+		return nil, fmt.Errorf("获取文件信息失败: %v", err)
+	}
+	if info.IsDir() {
+		return nil, ErrStorageObjectNotFound
+	}
+
+	return &StorageObject{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  mime.TypeByExtension(filepath.Ext(target)),
+		ETag:         fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+// SignedURL 生成带过期时间与 HMAC 签名的访问地址
+func (s *LocalStorage) SignedURL(key string, expires time.Duration) (string, error) {
+	if _, err := s.FilePath(key); err != nil {
+		return "", err
+	}
+	expiresAt := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expiresAt)
+	query.Set("signature", s.sign(key, expiresAt))
+	return s.PublicURL(key) + "?" + query.Encode(), nil
+}
+
+// VerifySignature 校验 SignedURL 生成的签名与有效期
+func (s *LocalStorage) VerifySignature(key, expires, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errors.New("无效的访问签名")
+	}
+	if time.Now().Unix() > expiresAt {
+		return errors.New("访问地址已过期")
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return errors.New("无效的访问签名")
+	}
+	return nil
+}
+
+// PublicURL 公开访问地址
+func (s *LocalStorage) PublicURL(key string) string {
+	return s.baseURL + s.urlPrefix + "/" + (&url.URL{Path: key}).EscapedPath()
+}
+
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"godad-backend/config"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// OSSStorage 阿里云 OSS 存储
+type OSSStorage struct {
+	bucket       *oss.Bucket
+	bucketName   string
+	endpoint     string
+	customDomain string
+}
+
+// NewOSSStorage 创建阿里云 OSS 存储
+func NewOSSStorage(cfg config.OSSConfig) (*OSSStorage, error) {
+	// 创建OSS客户端
+	client, err := oss.New(cfg.Endpoint, cfg.AccessKeyID, cfg.AccessKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("创建OSS客户端失败: %v", err)
+	}
+
+	// 获取存储桶
+	bucket, err := client.Bucket(cfg.BucketName)
+	if err != nil {
+		return nil, fmt.Errorf("获取OSS存储桶失败: %v", err)
+	}
+
+	return &OSSStorage{
+		bucket:       bucket,
+		bucketName:   cfg.BucketName,
+		endpoint:     cfg.Endpoint,
+		customDomain: cfg.CustomDomain,
+	}, nil
+}
+
+// Put 上传对象
+func (s *OSSStorage) Put(key string, reader io.Reader, size int64, contentType string) error {
+	options := []oss.Option{oss.ContentLength(size)}
+	if contentType != "" {
+		options = append(options, oss.ContentType(contentType))
+	}
+	if err := s.bucket.PutObject(key, reader, options...); err != nil {
+		return fmt.Errorf("上传文件到OSS失败: %v", err)
+	}
+	return nil
+}
+
+// Delete 删除对象
+func (s *OSSStorage) Delete(key string) error {
+	if err := s.bucket.DeleteObject(key); err != nil {
+		return fmt.Errorf("删除OSS文件失败: %v", err)
+	}
+	return nil
+}
+
+// Stat 获取对象元信息
+func (s *OSSStorage) Stat(key string) (*StorageObject, error) {
+	header, err := s.bucket.GetObjectDetailedMeta(key)
+	if err != nil {
+		var serviceErr oss.ServiceError
+		if errors.As(err, &serviceErr) && serviceErr.StatusCode == http.StatusNotFound {
+			return nil, ErrStorageObjectNotFound
+		}
+		return nil, fmt.Errorf("获取OSS文件信息失败: %v", err)
+	}
+	return storageObjectFromHeader(key, header), nil
+}
+
+// SignedURL 生成带签名的临时访问地址
+func (s *OSSStorage) SignedURL(key string, expires time.Duration) (string, error) {
+	signed, err := s.bucket.SignURL(key, oss.HTTPGet, int64(expires/time.Second))
+	if err != nil {
+		return "", fmt.Errorf("生成OSS签名地址失败: %v", err)
+	}
+	return signed, nil
+}
+
+// PublicURL 公开访问地址，配置了自定义域名时优先使用
+func (s *OSSStorage) PublicURL(key string) string {
+	if s.customDomain != "" {
+		return fmt.Sprintf("https://%s/%s", s.customDomain, key)
+	}
+	return fmt.Sprintf("https://%s.%s/%s", s.bucketName, s.endpoint, key)
+}
+
+// storageObjectFromHeader 从 HEAD 响应头解析对象元信息
+func storageObjectFromHeader(key string, header http.Header) *StorageObject {
+	size, _ := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	modified, _ := http.ParseTime(header.Get("Last-Modified"))
+	return &StorageObject{
+		Key:          key,
+		Size:         size,
+		ContentType:  header.Get("Content-Type"),
+		ETag:         strings.Trim(header.Get("ETag"), `"`),
+		LastModified: modified,
+	}
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"godad-backend/config"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3Service         = "s3"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3TimeFormat      = "20060102T150405Z"
+	s3DateFormat      = "20060102"
+	// s3MaxPresignExpire 签名地址最长有效期（SigV4 限制为 7 天）
+	s3MaxPresignExpire = 7 * 24 * time.Hour
+)
+
+// S3Storage S3 兼容存储（AWS S3、MinIO 等），使用 SigV4 签名直接调用 REST 接口
+type S3Storage struct {
+	endpoint  string
+	scheme    string
+	region    string
+	accessKey string
+	secretKey string
+	bucket    string
+	pathStyle bool
+	publicURL string
+	client    *http.Client
+}
+
+// NewS3Storage 创建 S3 兼容存储
+func NewS3Storage(cfg config.S3StorageConfig) (*S3Storage, error) {
+	if cfg.Endpoint == "" || cfg.BucketName == "" {
+		return nil, errors.New("S3 存储需要配置 Endpoint 与 BucketName")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("S3 存储需要配置访问密钥")
+	}
+
+	scheme := "http"
+	if cfg.UseSSL {
+		scheme = "https"
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	s := &S3Storage{
+		endpoint:  strings.TrimRight(cfg.Endpoint, "/"),
+		scheme:    scheme,
+		region:    region,
+		accessKey: cfg.AccessKeyID,
+		secretKey: cfg.SecretAccessKey,
+		bucket:    cfg.BucketName,
+		pathStyle: cfg.PathStyle,
+		publicURL: strings.TrimRight(cfg.PublicURL, "/"),
+		client:    &http.Client{Timeout: 60 * time.Second},
+	}
+	if s.publicURL == "" {
+		s.publicURL = strings.TrimRight(s.objectURL("").String(), "/")
+	}
+	return s, nil
+}
+
+// Put 上传对象
+func (s *S3Storage) Put(key string, reader io.Reader, size int64, contentType string) error {
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("读取上传内容失败: %v", err)
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(http.MethodPut, key, header, body)
+	if err != nil {
+		return fmt.Errorf("上传文件到S3失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("上传文件到S3失败: %s", s3ErrorMessage(resp))
+	}
+	return nil
+}
+
+// Delete 删除对象
+func (s *S3Storage) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, key, http.Header{}, nil)
+	if err != nil {
+		return fmt.Errorf("删除S3文件失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("删除S3文件失败: %s", s3ErrorMessage(resp))
+	}
+	return nil
+}
+
+// Stat 获取对象元信息
+func (s *S3Storage) Stat(key string) (*StorageObject, error) {
+	resp, err := s.do(http.MethodHead, key, http.Header{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取S3文件信息失败: %v", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return storageObjectFromHeader(key, resp.Header), nil
+	case http.StatusNotFound:
+		return nil, ErrStorageObjectNotFound
+	default:
+		return nil, fmt.Errorf("获取S3文件信息失败: HTTP %d", resp.StatusCode)
+	}
+}
+
+// SignedURL 生成预签名 GET 地址
+func (s *S3Storage) SignedURL(key string, expires time.Duration) (string, error) {
+	if expires <= 0 || expires > s3MaxPresignExpire {
+		return "", fmt.Errorf("签名有效期需在 1 秒到 %v 之间", s3MaxPresignExpire)
+	}
+
+	now := time.Now().UTC()
+	u := s.objectURL(key)
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.accessKey+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonical := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		s3CanonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(now, canonical))
+
+	u.RawQuery = s3CanonicalQuery(query)
+	return u.String(), nil
+}
+
+// PublicURL 公开访问地址（需要存储桶允许匿名读取）
+func (s *S3Storage) PublicURL(key string) string {
+	return s.publicURL + "/" + s3EscapePath(key)
+}
+
+// objectURL 对象的请求地址，路径风格为 endpoint/bucket/key，否则为 bucket.endpoint/key
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := &url.URL{Scheme: s.scheme, Host: s.endpoint, Path: "/" + key}
+	if s.pathStyle {
+		u.Path = "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + s.endpoint
+	}
+	u.RawPath = s3EscapePath(u.Path)
+	return u
+}
+
+// do 发送带 SigV4 签名的请求
+func (s *S3Storage) do(method, key string, header http.Header, body []byte) (*http.Response, error) {
+	now := time.Now().UTC()
+	u := s.objectURL(key)
+
+	payloadHash := sha256.Sum256(body)
+	header.Set("Host", u.Host)
+	header.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, s.scope(now), signedHeaders, s.signature(now, canonical)))
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Host = u.Host
+	header.Del("Host")
+	req.Header = header
+	req.ContentLength = int64(len(body))
+	return s.client.Do(req)
+}
+
+func (s *S3Storage) scope(t time.Time) string {
+	return t.Format(s3DateFormat) + "/" + s.region + "/" + s3Service + "/aws4_request"
+}
+
+// signature 计算 SigV4 签名
+func (s *S3Storage) signature(t time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		t.Format(s3TimeFormat),
+		s.scope(t),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := s3HMAC([]byte("AWS4"+s.secretKey), t.Format(s3DateFormat))
+	key = s3HMAC(key, s.region)
+	key = s3HMAC(key, s3Service)
+	key = s3HMAC(key, "aws4_request")
+	return hex.EncodeToString(s3HMAC(key, stringToSign))
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Escape 按 SigV4 规则编码：仅保留 RFC 3986 非保留字符
+func s3Escape(value string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (keepSlash && c == '/') {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func s3EscapePath(p string) string {
+	return s3Escape(p, true)
+}
+
+// s3CanonicalQuery 按键排序并编码查询参数
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, s3Escape(k, false)+"="+s3Escape(v, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3ErrorMessage 读取 S3 错误响应
+func s3ErrorMessage(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Sprintf("HTTP %d %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,578 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"godad-backend/config"
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// 订阅源格式
+const (
+	SyndicationFormatAtom = "atom"
+	SyndicationFormatRSS  = "rss"
+)
+
+// 站点地图分页类型
+const (
+	SitemapTypeArticles = "articles"
+	SitemapTypePosts    = "posts"
+)
+
+const (
+	syndicationItemLimit = 20               // 每个订阅源包含的条目数
+	syndicationCacheTTL  = 10 * time.Minute // 订阅源缓存时长
+	sitemapPageSize      = 5000             // 每个站点地图分页包含的地址数
+	sitemapCacheTTL      = time.Hour        // 站点地图缓存时长
+)
+
+// SyndicationDocument 生成好的 XML 文档及用于条件请求的校验信息
+type SyndicationDocument struct {
+	Body         string    `json:"body"`
+	ContentType  string    `json:"content_type"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// SyndicationService 生成 Atom/RSS 订阅源与 XML 站点地图
+// 文档按请求生成后整体缓存到 Redis，文章订阅源的缓存键以 articles: 开头，随文章缓存一起失效
+type SyndicationService struct {
+	db           *gorm.DB
+	cacheService *CacheService
+}
+
+// NewSyndicationService 创建订阅源服务
+func NewSyndicationService(db *gorm.DB) *SyndicationService {
+	return &SyndicationService{
+		db:           db,
+		cacheService: NewCacheService(),
+	}
+}
+
+// syndicationFeed 与输出格式无关的订阅源内容
+type syndicationFeed struct {
+	Title       string
+	Description string
+	Link        string // 对应的前端页面
+	SelfPath    string // 订阅源自身的路径，不含格式后缀
+	Items       []*syndicationItem
+}
+
+// syndicationItem 订阅源条目
+type syndicationItem struct {
+	Title      string
+	Link       string
+	Author     string
+	Summary    string
+	Content    string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// GetSiteFeed 全站最新文章订阅源
+func (s *SyndicationService) GetSiteFeed(format string) (*SyndicationDocument, error) {
+	return s.feedDocument("articles:syndication:site:"+format, format, func() (*syndicationFeed, error) {
+		items, err := s.articleItems(s.db)
+		if err != nil {
+			return nil, err
+		}
+		return &syndicationFeed{
+			Title:       "GoDad",
+			Description: "GoDad 育儿知识分享平台最新文章",
+			Link:        siteURL("/articles"),
+			SelfPath:    "/api/feeds",
+			Items:       items,
+		}, nil
+	})
+}
+
+// GetCategoryFeed 分类文章订阅源
+func (s *SyndicationService) GetCategoryFeed(slug, format string) (*SyndicationDocument, error) {
+	category, err := NewCategoryService().GetCategoryBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("articles:syndication:category:%d:%s", category.ID, format)
+	return s.feedDocument(key, format, func() (*syndicationFeed, error) {
+		items, err := s.articleItems(s.db.Where("category_id = ?", category.ID))
+		if err != nil {
+			return nil, err
+		}
+		return &syndicationFeed{
+			Title:       "GoDad - " + category.Name,
+			Description: category.Description,
+			Link:        siteURL("/articles?category=" + url.QueryEscape(category.Slug)),
+			SelfPath:    "/api/feeds/categories/" + url.PathEscape(category.Slug),
+			Items:       items,
+		}, nil
+	})
+}
+
+// GetAuthorFeed 作者文章订阅源
+func (s *SyndicationService) GetAuthorFeed(authorID uint, format string) (*SyndicationDocument, error) {
+	var author models.User
+	if err := s.db.Where("id = ? AND status = ?", authorID, 1).First(&author).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, fmt.Errorf("查询用户失败: %v", err)
+	}
+	key := fmt.Sprintf("articles:syndication:author:%d:%s", author.ID, format)
+	return s.feedDocument(key, format, func() (*syndicationFeed, error) {
+		items, err := s.articleItems(s.db.Where("author_id = ?", author.ID))
+		if err != nil {
+			return nil, err
+		}
+		return &syndicationFeed{
+			Title:       "GoDad - " + displayName(&author),
+			Description: author.Bio,
+			Link:        siteURL("/users/" + url.PathEscape(author.Username)),
+			SelfPath:    fmt.Sprintf("/api/feeds/authors/%d", author.ID),
+			Items:       items,
+		}, nil
+	})
+}
+
+// GetTopicFeed 论坛话题帖子订阅源，话题为 All 时包含全部帖子
+func (s *SyndicationService) GetTopicFeed(topic, format string) (*SyndicationDocument, error) {
+	if topic != models.TopicAll && !models.IsValidTopic(topic) {
+		return nil, errors.New("无效的话题分类")
+	}
+	key := fmt.Sprintf("forum:syndication:topic:%s:%s", topic, format)
+	return s.feedDocument(key, format, func() (*syndicationFeed, error) {
+		query := s.db.Preload("Author").Where("status = ?", 1)
+		if topic != models.TopicAll {
+			query = query.Where("topic = ?", topic)
+		}
+		var posts []*models.ForumPost
+		if err := query.Order("created_at DESC, id DESC").Limit(syndicationItemLimit).Find(&posts).Error; err != nil {
+			return nil, fmt.Errorf("查询帖子失败: %v", err)
+		}
+
+		items := make([]*syndicationItem, 0, len(posts))
+		for _, post := range posts {
+			content := post.ContentHTML
+			if content == "" {
+				if rendered, err := renderArticleContent(post.Content, post.ContentFormat); err == nil {
+					content = rendered.HTML
+				}
+			}
+			items = append(items, &syndicationItem{
+				Title:      post.Title,
+				Link:       siteURL(fmt.Sprintf("/community/posts/%d", post.ID)),
+				Author:     displayName(&post.Author),
+				Content:    content,
+				Categories: []string{post.Topic},
+				Published:  post.CreatedAt,
+				Updated:    post.UpdatedAt,
+			})
+		}
+		return &syndicationFeed{
+			Title:       "GoDad 社区 - " + topic,
+			Description: "GoDad 社区话题最新帖子",
+			Link:        siteURL("/community?topic=" + url.QueryEscape(topic)),
+			SelfPath:    "/api/feeds/topics/" + url.PathEscape(topic),
+			Items:       items,
+		}, nil
+	})
+}
+
+// articleItems 查询最新的已发布文章并转换为订阅源条目，query 携带额外的筛选条件
+func (s *SyndicationService) articleItems(query *gorm.DB) ([]*syndicationItem, error) {
+	var articles []*models.Article
+	if err := query.Preload("Author").Preload("Category").Preload("Tags").
+		Where("status = ?", models.ArticleStatusPublished).
+		Order("published_at DESC, id DESC").
+		Limit(syndicationItemLimit).
+		Find(&articles).Error; err != nil {
+		return nil, fmt.Errorf("查询文章失败: %v", err)
+	}
+
+	items := make([]*syndicationItem, 0, len(articles))
+	for _, article := range articles {
+		content := article.ContentHTML
+		if content == "" {
+			if rendered, err := renderArticleContent(article.Content, article.ContentFormat); err == nil {
+				content = rendered.HTML
+			}
+		}
+		published := article.CreatedAt
+		if article.PublishedAt != nil {
+			published = *article.PublishedAt
+		}
+		var categories []string
+		if article.Category.ID != 0 {
+			categories = append(categories, article.Category.Name)
+		}
+		for _, tag := range article.Tags {
+			categories = append(categories, tag.Name)
+		}
+		items = append(items, &syndicationItem{
+			Title:      article.Title,
+			Link:       siteURL(fmt.Sprintf("/articles/%d", article.ID)),
+			Author:     displayName(&article.Author),
+			Summary:    article.Summary,
+			Content:    content,
+			Categories: categories,
+			Published:  published,
+			Updated:    article.UpdatedAt,
+		})
+	}
+	return items, nil
+}
+
+// feedDocument 读取缓存的订阅源，未命中时生成并写入缓存
+func (s *SyndicationService) feedDocument(key, format string, build func() (*syndicationFeed, error)) (*SyndicationDocument, error) {
+	if format != SyndicationFormatAtom && format != SyndicationFormatRSS {
+		return nil, errors.New("不支持的订阅格式")
+	}
+	return s.cachedDocument(key, syndicationCacheTTL, func() (*SyndicationDocument, error) {
+		feed, err := build()
+		if err != nil {
+			return nil, err
+		}
+
+		// 以最近一次更新的条目作为订阅源的更新时间
+		var updated time.Time
+		for _, item := range feed.Items {
+			if item.Updated.After(updated) {
+				updated = item.Updated
+			}
+		}
+		if updated.IsZero() {
+			updated = time.Now()
+		}
+
+		var (
+			doc         interface{}
+			contentType string
+		)
+		if format == SyndicationFormatAtom {
+			doc, contentType = feed.atom(updated), "application/atom+xml; charset=utf-8"
+		} else {
+			doc, contentType = feed.rss(updated), "application/rss+xml; charset=utf-8"
+		}
+		return newSyndicationDocument(doc, contentType, updated)
+	})
+}
+
+// GetSitemapIndex 站点地图索引，按类型与分页列出各站点地图
+func (s *SyndicationService) GetSitemapIndex() (*SyndicationDocument, error) {
+	return s.cachedDocument("sitemap:index", sitemapCacheTTL, func() (*SyndicationDocument, error) {
+		index := &sitemapIndex{}
+		var lastModified time.Time
+		for _, kind := range []string{SitemapTypeArticles, SitemapTypePosts} {
+			var stats struct {
+				Total   int64
+				Updated *time.Time
+			}
+			if err := s.sitemapQuery(kind).Select("COUNT(*) AS total, MAX(updated_at) AS updated").Scan(&stats).Error; err != nil {
+				return nil, fmt.Errorf("统计站点地图失败: %v", err)
+			}
+			if stats.Updated != nil && stats.Updated.After(lastModified) {
+				lastModified = *stats.Updated
+			}
+			pages := (stats.Total + sitemapPageSize - 1) / sitemapPageSize
+			for page := int64(1); page <= pages; page++ {
+				index.Sitemaps = append(index.Sitemaps, sitemapEntry{
+					Loc: siteURL(fmt.Sprintf("/sitemaps/%s/%d.xml", kind, page)),
+				})
+			}
+		}
+		if lastModified.IsZero() {
+			lastModified = time.Now()
+		}
+		return newSyndicationDocument(index, "application/xml; charset=utf-8", lastModified)
+	})
+}
+
+// GetSitemapPage 站点地图分页，kind 为 articles 或 posts，page 从 1 开始
+func (s *SyndicationService) GetSitemapPage(kind string, page int) (*SyndicationDocument, error) {
+	if kind != SitemapTypeArticles && kind != SitemapTypePosts {
+		return nil, errors.New("站点地图不存在")
+	}
+	if page < 1 {
+		return nil, errors.New("站点地图不存在")
+	}
+
+	key := fmt.Sprintf("sitemap:%s:%d", kind, page)
+	return s.cachedDocument(key, sitemapCacheTTL, func() (*SyndicationDocument, error) {
+		var rows []struct {
+			ID        uint
+			UpdatedAt time.Time
+		}
+		if err := s.sitemapQuery(kind).Select("id, updated_at").
+			Order("id").Offset((page - 1) * sitemapPageSize).Limit(sitemapPageSize).
+			Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("查询站点地图失败: %v", err)
+		}
+		if len(rows) == 0 && page > 1 {
+			return nil, errors.New("站点地图不存在")
+		}
+
+		path := "/articles/%d"
+		if kind == SitemapTypePosts {
+			path = "/community/posts/%d"
+		}
+		urlSet := &sitemapURLSet{URLs: make([]sitemapEntry, 0, len(rows))}
+		var lastModified time.Time
+		for _, row := range rows {
+			urlSet.URLs = append(urlSet.URLs, sitemapEntry{
+				Loc:     siteURL(fmt.Sprintf(path, row.ID)),
+				LastMod: row.UpdatedAt.Format(time.RFC3339),
+			})
+			if row.UpdatedAt.After(lastModified) {
+				lastModified = row.UpdatedAt
+			}
+		}
+		if lastModified.IsZero() {
+			lastModified = time.Now()
+		}
+		return newSyndicationDocument(urlSet, "application/xml; charset=utf-8", lastModified)
+	})
+}
+
+// sitemapQuery 站点地图收录范围：已发布的文章与帖子
+func (s *SyndicationService) sitemapQuery(kind string) *gorm.DB {
+	if kind == SitemapTypePosts {
+		return s.db.Model(&models.ForumPost{}).Where("status = ?", 1)
+	}
+	return s.db.Model(&models.Article{}).Where("status = ?", models.ArticleStatusPublished)
+}
+
+// cachedDocument 读取缓存的文档，未命中时生成并写入缓存；缓存不可用时每次重新生成
+func (s *SyndicationService) cachedDocument(key string, ttl time.Duration, build func() (*SyndicationDocument, error)) (*SyndicationDocument, error) {
+	var doc SyndicationDocument
+	if err := s.cacheService.Get(key, &doc); err == nil {
+		return &doc, nil
+	}
+
+	built, err := build()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cacheService.SetWithExpire(key, built, ttl); err != nil && !errors.Is(err, ErrCacheDisabled) {
+		log.Printf("缓存订阅文档失败 (%s): %v", key, err)
+	}
+	return built, nil
+}
+
+// newSyndicationDocument 序列化 XML 并计算 ETag
+func newSyndicationDocument(doc interface{}, contentType string, lastModified time.Time) (*SyndicationDocument, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("生成XML失败: %v", err)
+	}
+	sum := sha256.Sum256(body)
+	return &SyndicationDocument{
+		Body:         xml.Header + string(body),
+		ContentType:  contentType,
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: lastModified.UTC().Truncate(time.Second),
+	}, nil
+}
+
+// siteURL 拼接前端站点地址
+func siteURL(path string) string {
+	base := ""
+	if cfg := config.GetConfig(); cfg != nil {
+		base = strings.TrimRight(cfg.Server.FrontendURL, "/")
+	}
+	return base + path
+}
+
+// displayName 用户展示名称，优先使用昵称
+func displayName(user *models.User) string {
+	if user.Nickname != "" {
+		return user.Nickname
+	}
+	return user.Username
+}
+
+// atom 转换为 Atom 1.0 文档
+func (f *syndicationFeed) atom(updated time.Time) *atomFeed {
+	feed := &atomFeed{
+		Title:    f.Title,
+		Subtitle: f.Description,
+		ID:       siteURL(f.SelfPath + "/" + SyndicationFormatAtom),
+		Updated:  updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: siteURL(f.SelfPath + "/" + SyndicationFormatAtom), Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+		},
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.Link,
+			Updated:   item.Updated.Format(time.RFC3339),
+			Published: item.Published.Format(time.RFC3339),
+			Links:     []atomLink{{Href: item.Link, Rel: "alternate", Type: "text/html"}},
+			Author:    &atomPerson{Name: item.Author},
+		}
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Body: item.Summary}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Body: item.Content}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+// rss 转换为 RSS 2.0 文档
+func (f *syndicationFeed) rss(updated time.Time) *rssDocument {
+	channel := rssChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		Description:   f.Description,
+		Language:      "zh-CN",
+		LastBuildDate: updated.Format(time.RFC1123Z),
+		AtomLink: rssAtomLink{
+			Href: siteURL(f.SelfPath + "/" + SyndicationFormatRSS),
+			Rel:  "self",
+			Type: "application/rss+xml",
+		},
+	}
+	if channel.Description == "" {
+		channel.Description = f.Title
+	}
+	for _, item := range f.Items {
+		description := item.Summary
+		if description == "" {
+			description = item.Content
+		}
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: "true", Value: item.Link},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+			Creator:     item.Author,
+			Categories:  item.Categories,
+			Description: description,
+			Content:     item.Content,
+		})
+	}
+	return &rssDocument{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		Channel:   channel,
+	}
+}
+
+// Atom 1.0 文档结构
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// RSS 2.0 文档结构
+type rssDocument struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	Language      string      `xml:"language"`
+	LastBuildDate string      `xml:"lastBuildDate"`
+	AtomLink      rssAtomLink `xml:"atom:link"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"content:encoded,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// 站点地图文档结构
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name       `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapEntry `xml:"url"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxArticleTags 每篇文章最多的标签数
+	maxArticleTags = 10
+	// maxTagNameLength 标签名称最大长度（字符）
+	maxTagNameLength = 30
+)
+
+var (
+	tagSlugInvalidChars = regexp.MustCompile(`[^a-z0-9\p{Han}]+`)
+	tagWhitespace       = regexp.MustCompile(`\s+`)
+	// reservedTagSlugs 与 /api/tags 下固定路由冲突的别名
+	reservedTagSlugs = map[string]bool{"suggest": true, "following": true}
+)
+
+// TagService 标签服务
+type TagService struct {
+	db *gorm.DB
+}
+
+// NewTagService 创建标签服务
+func NewTagService(db *gorm.DB) *TagService {
+	return &TagService{db: db}
+}
+
+// NormalizeTagNames 规范化用户输入的标签：去除首尾空白与 #、合并空白、忽略大小写去重
+func NormalizeTagNames(names []string) ([]string, error) {
+	result := make([]string, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(name), "#＃"))
+		name = tagWhitespace.ReplaceAllString(name, " ")
+		if name == "" {
+			continue
+		}
+		if utf8.RuneCountInString(name) > maxTagNameLength {
+			return nil, fmt.Errorf("标签长度不能超过%d个字符", maxTagNameLength)
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, name)
+	}
+	if len(result) > maxArticleTags {
+		return nil, fmt.Errorf("每篇文章最多%d个标签", maxArticleTags)
+	}
+	return result, nil
+}
+
+// SetArticleTags 在事务内替换文章的标签，不存在的标签自动创建，并刷新受影响标签的文章数
+func (s *TagService) SetArticleTags(tx *gorm.DB, articleID uint, names []string) error {
+	names, err := NormalizeTagNames(names)
+	if err != nil {
+		return err
+	}
+	tags, err := s.ensureTags(tx, names)
+	if err != nil {
+		return err
+	}
+
+	var oldIDs []uint
+	if err := tx.Model(&models.ArticleTag{}).Where("article_id = ?", articleID).Pluck("tag_id", &oldIDs).Error; err != nil {
+		return fmt.Errorf("查询文章标签失败: %v", err)
+	}
+
+	keep := make(map[uint]bool, len(tags))
+	var links []models.ArticleTag
+	for _, tag := range tags {
+		keep[tag.ID] = true
+		links = append(links, models.ArticleTag{ArticleID: articleID, TagID: tag.ID, CreatedAt: time.Now()})
+	}
+	affected := make([]uint, 0, len(oldIDs)+len(tags))
+	var removed []uint
+	for _, id := range oldIDs {
+		if !keep[id] {
+			removed = append(removed, id)
+		}
+		affected = append(affected, id)
+	}
+
+	if len(removed) > 0 {
+		if err := tx.Where("article_id = ? AND tag_id IN ?", articleID, removed).Delete(&models.ArticleTag{}).Error; err != nil {
+			return fmt.Errorf("移除文章标签失败: %v", err)
+		}
+	}
+	if len(links) > 0 {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error; err != nil {
+			return fmt.Errorf("保存文章标签失败: %v", err)
+		}
+		for _, tag := range tags {
+			affected = append(affected, tag.ID)
+		}
+	}
+	return refreshTagArticleCounts(tx, affected)
+}
+
+// ensureTags 按名称查找标签，不存在的创建
+func (s *TagService) ensureTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var existing []models.Tag
+	if err := tx.Where("name IN ?", names).Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+	byName := make(map[string]models.Tag, len(existing))
+	for _, tag := range existing {
+		byName[strings.ToLower(tag.Name)] = tag
+	}
+
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		if tag, ok := byName[strings.ToLower(name)]; ok {
+			tags = append(tags, tag)
+			continue
+		}
+		slug, err := s.generateUniqueSlug(tx, name)
+		if err != nil {
+			return nil, err
+		}
+		// 并发创建同名标签时以已存在的为准
+		tag := models.Tag{Name: name, Slug: slug}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag).Error; err != nil {
+			return nil, fmt.Errorf("创建标签失败: %v", err)
+		}
+		if tag.ID == 0 {
+			if err := tx.Where("name = ?", name).First(&tag).Error; err != nil {
+				return nil, fmt.Errorf("创建标签失败: %v", err)
+			}
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// generateUniqueSlug 由标签名生成唯一别名，规则与文章别名一致
+func (s *TagService) generateUniqueSlug(tx *gorm.DB, name string) (string, error) {
+	base := strings.Trim(tagSlugInvalidChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		base = "tag"
+	}
+
+	slug := base
+	for counter := 1; ; counter++ {
+		if !reservedTagSlugs[slug] {
+			var count int64
+			if err := tx.Model(&models.Tag{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+				return "", fmt.Errorf("检查标签别名失败: %v", err)
+			}
+			if count == 0 {
+				return slug, nil
+			}
+		}
+		slug = fmt.Sprintf("%s-%d", base, counter)
+	}
+}
+
+// refreshTagArticleCounts 按已发布文章重新计算标签的文章数
+func refreshTagArticleCounts(db *gorm.DB, tagIDs []uint) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	err := db.Exec(`
+		UPDATE tags SET article_count = (
+			SELECT COUNT(*) FROM article_tags at
+			JOIN articles a ON a.id = at.article_id
+			WHERE at.tag_id = tags.id AND a.status = ? AND a.deleted_at IS NULL
+		) WHERE id IN ?`, models.ArticleStatusPublished, tagIDs).Error
+	if err != nil {
+		return fmt.Errorf("更新标签文章数失败: %v", err)
+	}
+	return nil
+}
+
+// RefreshArticleTagCounts 文章状态变化（发布、下架、删除）后刷新其标签的文章数
+func (s *TagService) RefreshArticleTagCounts(articleID uint) error {
+	var tagIDs []uint
+	if err := s.db.Model(&models.ArticleTag{}).Where("article_id = ?", articleID).Pluck("tag_id", &tagIDs).Error; err != nil {
+		return fmt.Errorf("查询文章标签失败: %v", err)
+	}
+	return refreshTagArticleCounts(s.db, tagIDs)
+}
+
+// SuggestTags 标签自动补全：按前缀匹配，文章多的排前面
+func (s *TagService) SuggestTags(prefix string, limit int) ([]models.Tag, error) {
+	prefix = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(prefix), "#＃"))
+	tags := []models.Tag{}
+	if prefix == "" {
+		return tags, nil
+	}
+	if err := s.db.Where("name LIKE ?", escapeLike(prefix)+"%").
+		Order("article_count DESC, id ASC").
+		Limit(limit).
+		Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+	return tags, nil
+}
+
+// GetPopularTags 按文章数获取标签列表
+func (s *TagService) GetPopularTags(page, size int) ([]models.Tag, int64, error) {
+	var total int64
+	if err := s.db.Model(&models.Tag{}).Where("article_count > 0").Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询标签失败: %v", err)
+	}
+	var tags []models.Tag
+	if err := s.db.Where("article_count > 0").
+		Order("article_count DESC, id ASC").
+		Offset((page - 1) * size).Limit(size).
+		Find(&tags).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询标签失败: %v", err)
+	}
+	return tags, total, nil
+}
+
+// GetTagBySlug 根据别名获取标签
+func (s *TagService) GetTagBySlug(slug string) (*models.Tag, error) {
+	var tag models.Tag
+	if err := s.db.Where("slug = ?", slug).First(&tag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("标签不存在")
+		}
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+	return &tag, nil
+}
+
+// GetTagArticles 获取标签下已发布的文章，sort 为 hot（按热度）或 new（按发布时间）
+func (s *TagService) GetTagArticles(tagID uint, sort string, page, size int) ([]*models.Article, int64, error) {
+	query := s.db.Model(&models.Article{}).
+		Joins("JOIN article_tags ON article_tags.article_id = articles.id AND article_tags.tag_id = ?", tagID).
+		Where("articles.status = ?", models.ArticleStatusPublished)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询标签文章失败: %v", err)
+	}
+
+	order := "COALESCE(articles.published_at, articles.created_at) DESC"
+	if sort == "hot" {
+		// 与热门文章一致的热度公式
+		order = "(articles.view_count * 0.6 + articles.like_count * 0.3 + articles.comment_count * 0.1) DESC"
+	}
+
+	var articles []*models.Article
+	if err := query.Preload("Author").Preload("Category").Preload("Tags").
+		Order(order).Order("articles.id DESC").
+		Offset((page - 1) * size).Limit(size).
+		Find(&articles).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询标签文章失败: %v", err)
+	}
+	return articles, total, nil
+}
+
+// FollowTag 关注标签
+func (s *TagService) FollowTag(userID, tagID uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.TagFollow{UserID: userID, TagID: tagID})
+		if result.Error != nil {
+			return fmt.Errorf("关注标签失败: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("已关注该标签")
+		}
+		return tx.Model(&models.Tag{}).Where("id = ?", tagID).
+			UpdateColumn("follower_count", gorm.Expr("follower_count + 1")).Error
+	})
+	if err == nil {
+		NewFeedService(s.db).InvalidateFeed(userID)
+	}
+	return err
+}
+
+// UnfollowTag 取消关注标签
+func (s *TagService) UnfollowTag(userID, tagID uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND tag_id = ?", userID, tagID).Delete(&models.TagFollow{})
+		if result.Error != nil {
+			return fmt.Errorf("取消关注标签失败: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("未关注该标签")
+		}
+		return tx.Model(&models.Tag{}).Where("id = ? AND follower_count > 0", tagID).
+			UpdateColumn("follower_count", gorm.Expr("follower_count - 1")).Error
+	})
+	if err == nil {
+		NewFeedService(s.db).InvalidateFeed(userID)
+	}
+	return err
+}
+
+// IsFollowingTag 是否已关注标签
+func (s *TagService) IsFollowingTag(userID, tagID uint) bool {
+	if userID == 0 {
+		return false
+	}
+	var count int64
+	s.db.Model(&models.TagFollow{}).Where("user_id = ? AND tag_id = ?", userID, tagID).Count(&count)
+	return count > 0
+}
+
+// GetFollowedTagIDs 获取用户关注的标签ID
+func (s *TagService) GetFollowedTagIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	if err := s.db.Model(&models.TagFollow{}).Where("user_id = ?", userID).Pluck("tag_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("查询关注标签失败: %v", err)
+	}
+	return ids, nil
+}
+
+// GetFollowedTags 获取用户关注的标签，按关注时间倒序
+func (s *TagService) GetFollowedTags(userID uint) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	if err := s.db.Joins("JOIN tag_follows ON tag_follows.tag_id = tags.id AND tag_follows.user_id = ?", userID).
+		Order("tag_follows.created_at DESC").
+		Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("查询关注标签失败: %v", err)
+	}
+	return tags, nil
+}
+
+// GetFollowedTagArticles 获取用户关注的标签下的已发布文章，按发布时间倒序
+func (s *TagService) GetFollowedTagArticles(userID uint, page, size int) ([]*models.Article, int64, error) {
+	query := s.db.Model(&models.Article{}).
+		Where("articles.status = ?", models.ArticleStatusPublished).
+		Where("articles.id IN (?)", s.db.Table("article_tags").
+			Select("article_tags.article_id").
+			Joins("JOIN tag_follows ON tag_follows.tag_id = article_tags.tag_id").
+			Where("tag_follows.user_id = ?", userID))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询关注标签文章失败: %v", err)
+	}
+
+	var articles []*models.Article
+	if err := query.Preload("Author").Preload("Category").Preload("Tags").
+		Order("COALESCE(articles.published_at, articles.created_at) DESC").Order("articles.id DESC").
+		Offset((page - 1) * size).Limit(size).
+		Find(&articles).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询关注标签文章失败: %v", err)
+	}
+	return articles, total, nil
+}
@@ -0,0 +1,203 @@
+package services
+
+import "strings"
+
+// 差异行类型
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+const (
+	// diffContextLines 每个差异块前后保留的上下文行数
+	diffContextLines = 3
+	// diffMaxCells LCS 计算的最大矩阵规模，超出时中间部分按整体替换处理
+	diffMaxCells = 4_000_000
+)
+
+// DiffLine 差异行，OldLine/NewLine 为 1 起始的行号，0 表示该侧不存在
+type DiffLine struct {
+	Type    string `json:"type"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+	Text    string `json:"text"`
+}
+
+// DiffHunk 差异块（与 unified diff 的 @@ 块对应）
+type DiffHunk struct {
+	OldStart int        `json:"old_start"`
+	OldLines int        `json:"old_lines"`
+	NewStart int        `json:"new_start"`
+	NewLines int        `json:"new_lines"`
+	Lines    []DiffLine `json:"lines"`
+}
+
+// LineDiff 逐行比较两段文本
+type LineDiff struct {
+	Added   int        `json:"added"`
+	Removed int        `json:"removed"`
+	Hunks   []DiffHunk `json:"hunks"`
+}
+
+// DiffLines 计算两段文本的行级差异，结果按差异块分组并带有上下文
+func DiffLines(oldText, newText string) *LineDiff {
+	lines := diffLines(splitLines(oldText), splitLines(newText))
+
+	result := &LineDiff{Hunks: []DiffHunk{}}
+	for _, line := range lines {
+		switch line.Type {
+		case DiffInsert:
+			result.Added++
+		case DiffDelete:
+			result.Removed++
+		}
+	}
+	result.Hunks = groupHunks(lines)
+	return result
+}
+
+// splitLines 按行拆分，统一换行符，末尾换行不产生空行
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// diffLines 去掉公共前后缀后对中间部分做 LCS，输出完整的逐行差异
+func diffLines(a, b []string) []DiffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	result := make([]DiffLine, 0, len(a)+len(b)-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		result = append(result, DiffLine{Type: DiffEqual, OldLine: i + 1, NewLine: i + 1, Text: a[i]})
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	for _, line := range lcsDiff(midA, midB) {
+		if line.OldLine > 0 {
+			line.OldLine += prefix
+		}
+		if line.NewLine > 0 {
+			line.NewLine += prefix
+		}
+		result = append(result, line)
+	}
+
+	for i := 0; i < suffix; i++ {
+		oldIdx, newIdx := len(a)-suffix+i, len(b)-suffix+i
+		result = append(result, DiffLine{Type: DiffEqual, OldLine: oldIdx + 1, NewLine: newIdx + 1, Text: a[oldIdx]})
+	}
+	return result
+}
+
+// lcsDiff 基于最长公共子序列的差异计算，行号相对于传入切片
+func lcsDiff(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 || n*m > diffMaxCells {
+		return replaceAll(a, b)
+	}
+
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	width := m + 1
+	lcs := make([]int32, (n+1)*width)
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	result := make([]DiffLine, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, DiffLine{Type: DiffEqual, OldLine: i + 1, NewLine: j + 1, Text: a[i]})
+			i++
+			j++
+		case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+			result = append(result, DiffLine{Type: DiffDelete, OldLine: i + 1, Text: a[i]})
+			i++
+		default:
+			result = append(result, DiffLine{Type: DiffInsert, NewLine: j + 1, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		result = append(result, DiffLine{Type: DiffDelete, OldLine: i + 1, Text: a[i]})
+	}
+	for ; j < m; j++ {
+		result = append(result, DiffLine{Type: DiffInsert, NewLine: j + 1, Text: b[j]})
+	}
+	return result
+}
+
+// replaceAll 整体删除旧内容并插入新内容
+func replaceAll(a, b []string) []DiffLine {
+	result := make([]DiffLine, 0, len(a)+len(b))
+	for i, line := range a {
+		result = append(result, DiffLine{Type: DiffDelete, OldLine: i + 1, Text: line})
+	}
+	for j, line := range b {
+		result = append(result, DiffLine{Type: DiffInsert, NewLine: j + 1, Text: line})
+	}
+	return result
+}
+
+// groupHunks 将逐行差异按变更位置分组，每组前后保留 diffContextLines 行上下文
+func groupHunks(lines []DiffLine) []DiffHunk {
+	hunks := []DiffHunk{}
+	start, end := -1, -1
+	flush := func() {
+		if start < 0 {
+			return
+		}
+		hunk := DiffHunk{Lines: lines[start:end]}
+		for _, line := range hunk.Lines {
+			if line.Type != DiffInsert {
+				if hunk.OldStart == 0 {
+					hunk.OldStart = line.OldLine
+				}
+				hunk.OldLines++
+			}
+			if line.Type != DiffDelete {
+				if hunk.NewStart == 0 {
+					hunk.NewStart = line.NewLine
+				}
+				hunk.NewLines++
+			}
+		}
+		hunks = append(hunks, hunk)
+		start, end = -1, -1
+	}
+
+	for i, line := range lines {
+		if line.Type == DiffEqual {
+			continue
+		}
+		from := max(0, i-diffContextLines)
+		if start >= 0 && from > end {
+			flush()
+		}
+		if start < 0 {
+			start = from
+		}
+		end = min(len(lines), i+1+diffContextLines)
+	}
+	flush()
+	return hunks
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// numberedLines 生成 "line1\nline2\n..." 形式的文本，replace 中的行号替换为指定内容
+func numberedLines(n int, replace map[int]string) string {
+	lines := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		if text, ok := replace[i]; ok {
+			lines = append(lines, text)
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("line%d", i))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TestDiffLinesUnchanged 测试换行符与末尾换行的差异不计入变更
+func TestDiffLinesUnchanged(t *testing.T) {
+	diff := DiffLines("a\nb\nc\n", "a\r\nb\r\nc")
+	assert.Equal(t, 0, diff.Added)
+	assert.Equal(t, 0, diff.Removed)
+	assert.NotNil(t, diff.Hunks)
+	assert.Empty(t, diff.Hunks)
+
+	diff = DiffLines("", "")
+	assert.Empty(t, diff.Hunks)
+}
+
+// TestDiffLinesSingleChange 测试单行修改的差异块与上下文
+func TestDiffLinesSingleChange(t *testing.T) {
+	diff := DiffLines(numberedLines(10, nil), numberedLines(10, map[int]string{5: "changed"}))
+	assert.Equal(t, 1, diff.Added)
+	assert.Equal(t, 1, diff.Removed)
+	require.Len(t, diff.Hunks, 1)
+
+	hunk := diff.Hunks[0]
+	assert.Equal(t, 2, hunk.OldStart)
+	assert.Equal(t, 7, hunk.OldLines)
+	assert.Equal(t, 2, hunk.NewStart)
+	assert.Equal(t, 7, hunk.NewLines)
+	require.Len(t, hunk.Lines, 8)
+	assert.Equal(t, DiffLine{Type: DiffEqual, OldLine: 2, NewLine: 2, Text: "line2"}, hunk.Lines[0])
+	assert.Equal(t, DiffLine{Type: DiffDelete, OldLine: 5, Text: "line5"}, hunk.Lines[3])
+	assert.Equal(t, DiffLine{Type: DiffInsert, NewLine: 5, Text: "changed"}, hunk.Lines[4])
+	assert.Equal(t, DiffLine{Type: DiffEqual, OldLine: 8, NewLine: 8, Text: "line8"}, hunk.Lines[7])
+}
+
+// TestDiffLinesInsertAndDelete 测试插入与删除后两侧行号的错位
+func TestDiffLinesInsertAndDelete(t *testing.T) {
+	diff := DiffLines("a\nb\nc\nd", "a\nx\nb\nd")
+	assert.Equal(t, 1, diff.Added)
+	assert.Equal(t, 1, diff.Removed)
+	require.Len(t, diff.Hunks, 1)
+	assert.Equal(t, []DiffLine{
+		{Type: DiffEqual, OldLine: 1, NewLine: 1, Text: "a"},
+		{Type: DiffInsert, NewLine: 2, Text: "x"},
+		{Type: DiffEqual, OldLine: 2, NewLine: 3, Text: "b"},
+		{Type: DiffDelete, OldLine: 3, Text: "c"},
+		{Type: DiffEqual, OldLine: 4, NewLine: 4, Text: "d"},
+	}, diff.Hunks[0].Lines)
+}
+
+// TestDiffLinesHunkGrouping 测试上下文相连的变更合并为一个差异块，相距较远的分开
+func TestDiffLinesHunkGrouping(t *testing.T) {
+	testCases := []struct {
+		name    string
+		changed map[int]string
+		hunks   int
+	}{
+		{name: "上下文相接", changed: map[int]string{2: "x", 9: "y"}, hunks: 1},
+		{name: "上下文不相接", changed: map[int]string{2: "x", 10: "y"}, hunks: 2},
+		{name: "相距较远", changed: map[int]string{2: "x", 18: "y"}, hunks: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff := DiffLines(numberedLines(20, nil), numberedLines(20, tc.changed))
+			assert.Equal(t, 2, diff.Added)
+			assert.Equal(t, 2, diff.Removed)
+			assert.Len(t, diff.Hunks, tc.hunks)
+		})
+	}
+}
+
+// TestDiffLinesFromEmpty 测试旧内容为空时的差异块起始行
+func TestDiffLinesFromEmpty(t *testing.T) {
+	diff := DiffLines("", "a\nb")
+	assert.Equal(t, 2, diff.Added)
+	assert.Equal(t, 0, diff.Removed)
+	require.Len(t, diff.Hunks, 1)
+	assert.Equal(t, 0, diff.Hunks[0].OldStart)
+	assert.Equal(t, 0, diff.Hunks[0].OldLines)
+	assert.Equal(t, 1, diff.Hunks[0].NewStart)
+	assert.Equal(t, 2, diff.Hunks[0].NewLines)
+}
+
+// TestDiffLinesLargeFallback 测试超出 LCS 规模上限时中间部分整体替换
+func TestDiffLinesLargeFallback(t *testing.T) {
+	n := 2001
+	oldLines := make([]string, n)
+	newLines := make([]string, n)
+	for i := range oldLines {
+		oldLines[i] = fmt.Sprintf("old%d", i)
+		newLines[i] = fmt.Sprintf("new%d", i)
+	}
+	// 中间的公共行在规模超限时不再对齐
+	oldLines[n/2], newLines[n/2] = "common", "common"
+	require.Greater(t, n*n, diffMaxCells)
+
+	diff := DiffLines("head\n"+strings.Join(oldLines, "\n")+"\ntail", "head\n"+strings.Join(newLines, "\n")+"\ntail")
+	assert.Equal(t, n, diff.Added)
+	assert.Equal(t, n, diff.Removed)
+	require.Len(t, diff.Hunks, 1)
+	assert.Equal(t, 1, diff.Hunks[0].OldStart)
+	assert.Equal(t, n+2, diff.Hunks[0].OldLines)
+}
@@ -0,0 +1,449 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"godad-backend/config"
+	"godad-backend/models"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// 热度排行的内容类型
+const (
+	TrendingArticle   = "article"
+	TrendingForumPost = "forum_post"
+)
+
+// 互动事件
+const (
+	TrendingEventView     = "view"
+	TrendingEventLike     = "like"
+	TrendingEventComment  = "comment"
+	TrendingEventFavorite = "favorite"
+)
+
+// trendingWeights 各类互动贡献的热度
+var trendingWeights = map[string]float64{
+	TrendingEventView:     1,
+	TrendingEventLike:     5,
+	TrendingEventComment:  8,
+	TrendingEventFavorite: 10,
+}
+
+const (
+	// trendingEpochKey 热度基准时间；使用同一 hash tag，保证 Lua 脚本涉及的键在集群中位于同一槽
+	trendingEpochKey = "{trending}:epoch"
+	// trendingRebaseAfter 基准时间超过该时长后重新换算，避免分数溢出
+	trendingRebaseAfter = 7 * 24 * time.Hour
+	// trendingMinScore 热度低于该值的内容从排行中移除
+	trendingMinScore = 0.5
+	// trendingMaxMembers 每个排行最多保留的内容数
+	trendingMaxMembers = 5000
+	// trendingSeedWindow 排行为空时从数据库回填的互动时间范围
+	trendingSeedWindow = 7 * 24 * time.Hour
+	// trendingDedupeWindow 同一用户对同一内容的点赞、收藏只计一次热度的时间窗口
+	trendingDedupeWindow = 7 * 24 * time.Hour
+)
+
+// 指数衰减：分数 = Σ 权重 × 2^((事件时间 - 基准时间) / 半衰期)，读取时再除以 2^((当前时间 - 基准时间) / 半衰期)。
+// 所有内容共用同一个基准，衰减不改变相对顺序，因此有序集合可以只做增量更新，无需定期全量重算。
+var trendingIncrScript = redis.NewScript(`
+local epoch = tonumber(redis.call('GET', KEYS[2]))
+if not epoch then
+	epoch = tonumber(ARGV[3])
+	redis.call('SET', KEYS[2], ARGV[3])
+end
+local inc = tonumber(ARGV[2]) * math.pow(2, (tonumber(ARGV[3]) - epoch) / tonumber(ARGV[4]))
+return redis.call('ZINCRBY', KEYS[1], inc, ARGV[1])
+`)
+
+// trendingRebaseScript 将分数换算到新的基准时间（当前时间）
+var trendingRebaseScript = redis.NewScript(`
+local epoch = tonumber(redis.call('GET', KEYS[1]))
+if not epoch then
+	return 0
+end
+local factor = math.pow(2, -(tonumber(ARGV[1]) - epoch) / tonumber(ARGV[2]))
+for i = 2, #KEYS do
+	if redis.call('EXISTS', KEYS[i]) == 1 then
+		redis.call('ZUNIONSTORE', KEYS[i], 1, KEYS[i], 'WEIGHTS', factor)
+	end
+end
+redis.call('SET', KEYS[1], ARGV[1])
+return 1
+`)
+
+// TrendingArticleItem 热门文章
+type TrendingArticleItem struct {
+	Score   float64                 `json:"score"`
+	Article *models.ArticleResponse `json:"article"`
+}
+
+// TrendingForumPostItem 热门帖子
+type TrendingForumPostItem struct {
+	Score     float64                   `json:"score"`
+	ForumPost *models.ForumPostResponse `json:"forum_post"`
+}
+
+// TrendingService 基于互动事件与时间衰减的热度排行
+type TrendingService struct {
+	db           *gorm.DB
+	cacheService *CacheService
+	halfLife     time.Duration
+	hotThreshold float64
+}
+
+// NewTrendingService 创建热度排行服务
+func NewTrendingService(db *gorm.DB) *TrendingService {
+	cfg := config.GetConfig().Trending
+	halfLife := time.Duration(cfg.HalfLifeHours * float64(time.Hour))
+	if halfLife <= 0 {
+		halfLife = 24 * time.Hour
+	}
+	return &TrendingService{
+		db:           db,
+		cacheService: NewCacheService(),
+		halfLife:     halfLife,
+		hotThreshold: cfg.HotThreshold,
+	}
+}
+
+func trendingKey(targetType string) string {
+	return "{trending}:" + targetType
+}
+
+// RecordEvent 记录一次互动；未启用 Redis 时忽略
+func (s *TrendingService) RecordEvent(targetType string, targetID uint, event string) {
+	weight, ok := trendingWeights[event]
+	if !ok || !isCacheReady() || (targetType != TrendingArticle && targetType != TrendingForumPost) {
+		return
+	}
+	err := trendingIncrScript.Run(ctx, rdb,
+		[]string{trendingKey(targetType), trendingEpochKey},
+		strconv.FormatUint(uint64(targetID), 10), weight, time.Now().Unix(), s.halfLife.Seconds(),
+	).Err()
+	if err != nil {
+		log.Printf("记录热度失败 (%s:%d): %v", targetType, targetID, err)
+	}
+}
+
+// RecordUserEvent 记录一次用户互动，同一用户对同一内容的同类互动在去重窗口内只计一次，
+// 避免反复取消再点赞、收藏刷热度
+func (s *TrendingService) RecordUserEvent(targetType string, targetID uint, event string, userID uint) {
+	if !isCacheReady() {
+		return
+	}
+	key := fmt.Sprintf("{trending}:seen:%s:%s:%d:%d", event, targetType, targetID, userID)
+	first, err := rdb.SetNX(ctx, key, 1, trendingDedupeWindow).Result()
+	if err != nil {
+		log.Printf("检查热度去重失败 (%s:%d): %v", targetType, targetID, err)
+		return
+	}
+	if first {
+		s.RecordEvent(targetType, targetID, event)
+	}
+}
+
+// decayDivisor 当前时间相对基准时间的衰减系数，原始分数除以该值即为当前热度
+func (s *TrendingService) decayDivisor(now time.Time) (float64, error) {
+	epoch, err := rdb.Get(ctx, trendingEpochKey).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(2, float64(now.Unix()-epoch)/s.halfLife.Seconds()), nil
+}
+
+// GetTrendingArticles 热门文章；未启用 Redis 时退化为本周热门
+func (s *TrendingService) GetTrendingArticles(limit int) ([]TrendingArticleItem, error) {
+	items := []TrendingArticleItem{}
+	if !isCacheReady() {
+		articles, err := NewArticleService().GetHotArticles("week", limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, article := range articles {
+			items = append(items, TrendingArticleItem{Article: article.ToResponse(false)})
+		}
+		return items, nil
+	}
+
+	ids, scores, err := s.top(TrendingArticle, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	var articles []*models.Article
+	if err := s.db.Preload("Author").Preload("Category").Preload("Tags").
+		Where("id IN ? AND status = ?", ids, models.ArticleStatusPublished).
+		Find(&articles).Error; err != nil {
+		return nil, fmt.Errorf("查询热门文章失败: %v", err)
+	}
+	byID := make(map[uint]*models.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+	for i, id := range ids {
+		if article, ok := byID[id]; ok && len(items) < limit {
+			items = append(items, TrendingArticleItem{Score: scores[i], Article: article.ToResponse(false)})
+		}
+	}
+	return items, nil
+}
+
+// GetTrendingForumPosts 热门帖子；未启用 Redis 时按热门标记与回复数排序
+func (s *TrendingService) GetTrendingForumPosts(limit int) ([]TrendingForumPostItem, error) {
+	items := []TrendingForumPostItem{}
+	var posts []*models.ForumPost
+
+	if !isCacheReady() {
+		if err := s.db.Preload("Author").
+			Where("status = ? AND created_at >= ?", 1, time.Now().Add(-trendingSeedWindow)).
+			Order("is_hot DESC, reply_count DESC, id DESC").
+			Limit(limit).
+			Find(&posts).Error; err != nil {
+			return nil, fmt.Errorf("查询热门帖子失败: %v", err)
+		}
+		for _, post := range posts {
+			items = append(items, TrendingForumPostItem{ForumPost: post.ToResponse(false)})
+		}
+		return items, nil
+	}
+
+	ids, scores, err := s.top(TrendingForumPost, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return items, nil
+	}
+	if err := s.db.Preload("Author").Where("id IN ? AND status = ?", ids, 1).Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("查询热门帖子失败: %v", err)
+	}
+	byID := make(map[uint]*models.ForumPost, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+	for i, id := range ids {
+		if post, ok := byID[id]; ok && len(items) < limit {
+			items = append(items, TrendingForumPostItem{Score: scores[i], ForumPost: post.ToResponse(false)})
+		}
+	}
+	return items, nil
+}
+
+// top 读取排行前列的内容ID与当前热度；多取一些，弥补已删除或下架的内容
+func (s *TrendingService) top(targetType string, limit int) ([]uint, []float64, error) {
+	members, err := rdb.ZRevRangeWithScores(ctx, trendingKey(targetType), 0, int64(limit+limit/2)).Result()
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取热度排行失败: %v", err)
+	}
+	divisor, err := s.decayDivisor(time.Now())
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取热度排行失败: %v", err)
+	}
+
+	ids := make([]uint, 0, len(members))
+	scores := make([]float64, 0, len(members))
+	for _, m := range members {
+		id, err := strconv.ParseUint(fmt.Sprint(m.Member), 10, 32)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint(id))
+		scores = append(scores, math.Round(m.Score/divisor*100)/100)
+	}
+	return ids, scores, nil
+}
+
+// Refresh 维护热度排行：必要时换算基准时间、回填空排行、清理低热度内容，并按阈值维护论坛帖子的热门标记
+func (s *TrendingService) Refresh() (string, error) {
+	if !isCacheReady() {
+		return "未启用 Redis，跳过", nil
+	}
+	now := time.Now()
+
+	epoch, err := rdb.Get(ctx, trendingEpochKey).Int64()
+	if errors.Is(err, redis.Nil) {
+		epoch = now.Unix()
+		if err := rdb.SetNX(ctx, trendingEpochKey, epoch, 0).Err(); err != nil {
+			return "", fmt.Errorf("初始化热度基准时间失败: %v", err)
+		}
+	} else if err != nil {
+		return "", fmt.Errorf("读取热度基准时间失败: %v", err)
+	}
+	if now.Sub(time.Unix(epoch, 0)) > trendingRebaseAfter {
+		if err := trendingRebaseScript.Run(ctx, rdb,
+			[]string{trendingEpochKey, trendingKey(TrendingArticle), trendingKey(TrendingForumPost)},
+			now.Unix(), s.halfLife.Seconds(),
+		).Err(); err != nil {
+			return "", fmt.Errorf("换算热度基准时间失败: %v", err)
+		}
+	}
+
+	divisor, err := s.decayDivisor(now)
+	if err != nil {
+		return "", fmt.Errorf("读取热度基准时间失败: %v", err)
+	}
+
+	seeded := 0
+	for _, targetType := range []string{TrendingArticle, TrendingForumPost} {
+		key := trendingKey(targetType)
+		exists, err := rdb.Exists(ctx, key).Result()
+		if err != nil {
+			return "", fmt.Errorf("读取热度排行失败: %v", err)
+		}
+		if exists == 0 {
+			n, err := s.seed(targetType, now)
+			if err != nil {
+				return "", err
+			}
+			seeded += n
+		}
+
+		if err := rdb.ZRemRangeByScore(ctx, key, "-inf", "("+formatScore(trendingMinScore*divisor)).Err(); err != nil {
+			return "", fmt.Errorf("清理热度排行失败: %v", err)
+		}
+		if err := rdb.ZRemRangeByRank(ctx, key, 0, -trendingMaxMembers-1).Err(); err != nil {
+			return "", fmt.Errorf("清理热度排行失败: %v", err)
+		}
+	}
+
+	promoted, demoted, err := s.syncForumHot(divisor)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("回填 %d 条内容，标记 %d 个热门帖子，取消 %d 个热门帖子", seeded, promoted, demoted), nil
+}
+
+// seed 排行不存在时（首次启用或 Redis 数据丢失）按数据库中的互动记录回填，浏览量没有明细记录不参与
+func (s *TrendingService) seed(targetType string, now time.Time) (int, error) {
+	epoch, err := rdb.Get(ctx, trendingEpochKey).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("读取热度基准时间失败: %v", err)
+	}
+	since := now.Add(-trendingSeedWindow)
+	halfLife := s.halfLife.Seconds()
+
+	// decayed 按事件时间计算单条互动的原始分数
+	decayed := fmt.Sprintf("POW(2, (UNIX_TIMESTAMP(created_at) - %d) / %f)", epoch, halfLife)
+
+	var sources []string
+	var args []interface{}
+	switch targetType {
+	case TrendingArticle:
+		sources = []string{
+			fmt.Sprintf("SELECT target_id AS id, %f * %s AS score FROM likes WHERE target_type = ? AND created_at >= ?", trendingWeights[TrendingEventLike], decayed),
+			fmt.Sprintf("SELECT article_id AS id, %f * %s AS score FROM comments WHERE status = ? AND deleted_at IS NULL AND created_at >= ?", trendingWeights[TrendingEventComment], decayed),
+			fmt.Sprintf("SELECT article_id AS id, %f * %s AS score FROM favorites WHERE deleted_at IS NULL AND created_at >= ?", trendingWeights[TrendingEventFavorite], decayed),
+		}
+		args = []interface{}{TrendingArticle, since, models.CommentStatusNormal, since, since}
+	case TrendingForumPost:
+		sources = []string{
+			fmt.Sprintf("SELECT target_id AS id, %f * %s AS score FROM likes WHERE target_type = ? AND created_at >= ?", trendingWeights[TrendingEventLike], decayed),
+			fmt.Sprintf("SELECT post_id AS id, %f * %s AS score FROM forum_replies WHERE status = ? AND deleted_at IS NULL AND created_at >= ?", trendingWeights[TrendingEventComment], decayed),
+		}
+		args = []interface{}{TrendingForumPost, since, 1, since}
+	default:
+		return 0, nil
+	}
+
+	union := sources[0]
+	for _, source := range sources[1:] {
+		union += " UNION ALL " + source
+	}
+	var rows []struct {
+		ID    uint
+		Score float64
+	}
+	if err := s.db.Raw("SELECT id, SUM(score) AS score FROM ("+union+") e GROUP BY id ORDER BY score DESC LIMIT ?",
+		append(args, trendingMaxMembers)...).Scan(&rows).Error; err != nil {
+		return 0, fmt.Errorf("回填热度排行失败: %v", err)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	members := make([]redis.Z, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, redis.Z{Score: row.Score, Member: strconv.FormatUint(uint64(row.ID), 10)})
+	}
+	if err := rdb.ZAdd(ctx, trendingKey(targetType), members...).Err(); err != nil {
+		return 0, fmt.Errorf("回填热度排行失败: %v", err)
+	}
+	return len(rows), nil
+}
+
+// syncForumHot 按热度阈值维护帖子热门标记：达到阈值标记为热门，降到阈值一半以下才取消，避免来回切换
+// 管理员手动设置过的帖子不参与
+func (s *TrendingService) syncForumHot(divisor float64) (int64, int64, error) {
+	if s.hotThreshold <= 0 {
+		return 0, 0, nil
+	}
+	key := trendingKey(TrendingForumPost)
+
+	hotIDs, err := s.idsAbove(key, s.hotThreshold*divisor)
+	if err != nil {
+		return 0, 0, err
+	}
+	keepIDs, err := s.idsAbove(key, s.hotThreshold/2*divisor)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var promoted int64
+	if len(hotIDs) > 0 {
+		result := s.db.Model(&models.ForumPost{}).
+			Where("id IN ? AND is_hot = ? AND hot_manual = ?", hotIDs, false, false).
+			UpdateColumn("is_hot", true)
+		if result.Error != nil {
+			return 0, 0, fmt.Errorf("标记热门帖子失败: %v", result.Error)
+		}
+		promoted = result.RowsAffected
+	}
+
+	demote := s.db.Model(&models.ForumPost{}).Where("is_hot = ? AND hot_manual = ?", true, false)
+	if len(keepIDs) > 0 {
+		demote = demote.Where("id NOT IN ?", keepIDs)
+	}
+	result := demote.UpdateColumn("is_hot", false)
+	if result.Error != nil {
+		return 0, 0, fmt.Errorf("取消热门帖子失败: %v", result.Error)
+	}
+
+	if promoted > 0 || result.RowsAffected > 0 {
+		s.cacheService.DeletePattern("forum_post:*")
+		s.cacheService.DeletePattern("forum:posts:*")
+	}
+	return promoted, result.RowsAffected, nil
+}
+
+func (s *TrendingService) idsAbove(key string, rawScore float64) ([]uint, error) {
+	members, err := rdb.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: formatScore(rawScore), Max: "+inf"}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("读取热度排行失败: %v", err)
+	}
+	ids := make([]uint, 0, len(members))
+	for _, m := range members {
+		if id, err := strconv.ParseUint(m, 10, 32); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids, nil
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'g', -1, 64)
+}
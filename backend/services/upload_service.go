@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"log"
 	"mime/multipart"
 	"net/http"
 	"path/filepath"
@@ -14,35 +15,30 @@ import (
 	"godad-backend/config"
 	"godad-backend/models"
 
-	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 	"github.com/google/uuid"
 )
 
 // UploadService 上传服务
 type UploadService struct {
-	ossClient *oss.Client
-	bucket     *oss.Bucket
+	storage   Storage
+	processor *ImageProcessor
 }
 
-// NewUploadService 创建上传服务实例
+// NewUploadService 创建上传服务实例，文件存储后端由 config.Storage.Driver 决定
 func NewUploadService() (*UploadService, error) {
-	cfg := config.GetConfig()
-
-	// 创建OSS客户端
-	client, err := oss.New(cfg.OSS.Endpoint, cfg.OSS.AccessKeyID, cfg.OSS.AccessKeySecret)
+	storage, err := GetStorage()
 	if err != nil {
-		return nil, fmt.Errorf("创建OSS客户端失败: %v", err)
+		return nil, err
 	}
 
-	// 获取存储桶
-	bucket, err := client.Bucket(cfg.OSS.BucketName)
+	processor, err := NewImageProcessor(config.GetConfig().Image)
 	if err != nil {
-		return nil, fmt.Errorf("获取OSS存储桶失败: %v", err)
+		return nil, err
 	}
 
 	return &UploadService{
-		ossClient: client,
-		bucket:    bucket,
+		storage:   storage,
+		processor: processor,
 	}, nil
 }
 
@@ -81,31 +77,70 @@ func (s *UploadService) UploadImage(file *multipart.FileHeader, userID uint, usa
 		return nil, fmt.Errorf("检测到不安全的文件内容")
 	}
 
+	// 处理图片：校正方向、去除 EXIF/GPS 等元数据、重新编码并生成衍生尺寸
+	processed, err := s.processor.Process(fileContent)
+	if err != nil {
+		return nil, fmt.Errorf("图片处理失败: %v", err)
+	}
+	original := processed.Original
+	// 重新编码后扩展名可能变化（如 PNG 照片转为 JPEG）
+	storedName := strings.TrimSuffix(file.Filename, filepath.Ext(file.Filename)) + original.Ext
+
 	// 生成系统文件名（UUID）
-	systemName := s.generateSystemName(file.Filename)
+	systemName := s.generateSystemName(storedName)
 	
 	// 生成存储路径
 	var fileName string
 	if len(articleTitle) > 0 && articleTitle[0] != "" {
-		fileName = s.generateFileNameWithTitle(file.Filename, usage, articleTitle[0])
+		fileName = s.generateFileNameWithTitle(storedName, usage, articleTitle[0])
 	} else {
-		fileName = s.generateFileName(file.Filename, usage)
+		fileName = s.generateFileName(storedName, usage)
 	}
 
-	// 上传到OSS
-	err = s.bucket.PutObject(fileName, bytes.NewReader(fileContent))
+	// 上传到存储后端
+	err = s.storage.Put(fileName, bytes.NewReader(original.Data), int64(len(original.Data)), original.MimeType)
 	if err != nil {
-		return nil, fmt.Errorf("上传文件到OSS失败: %v", err)
+		return nil, err
 	}
+	storedKeys := []string{fileName}
 
 	// 生成文件URL
-	publicURL := s.generateFileURL(fileName)
+	publicURL := s.storage.PublicURL(fileName)
+
+	// 上传衍生尺寸，无需生成的尺寸直接使用原图，无法生成的尺寸不提供
+	variants := make(map[string]models.ImageVariant, len(processed.Variants))
+	basePath := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	for i, spec := range s.processor.Variants() {
+		if processed.Unavailable != nil && processed.Unavailable[i] {
+			continue
+		}
+		variant := processed.Variants[i]
+		if variant == nil {
+			variants[spec.Name] = models.ImageVariant{
+				URL:         publicURL,
+				Width:       original.Width,
+				Height:      original.Height,
+				StoragePath: fileName,
+			}
+			continue
+		}
+
+		variantPath := fmt.Sprintf("%s_%s%s", basePath, spec.Name, variant.Ext)
+		if err := s.storage.Put(variantPath, bytes.NewReader(variant.Data), int64(len(variant.Data)), variant.MimeType); err != nil {
+			s.deleteObjects(storedKeys)
+			return nil, err
+		}
+		storedKeys = append(storedKeys, variantPath)
+		variants[spec.Name] = models.ImageVariant{
+			URL:         s.storage.PublicURL(variantPath),
+			Width:       variant.Width,
+			Height:      variant.Height,
+			StoragePath: variantPath,
+		}
+	}
 
 	// 计算文件哈希
-	hash := fmt.Sprintf("%x", sha256.Sum256(fileContent))
-	
-	// 获取MIME类型
-	mimeType := http.DetectContentType(fileContent)
+	hash := fmt.Sprintf("%x", sha256.Sum256(original.Data))
 	
 	// 注释：根据需求，每次上传都应该创建新文件，即使内容相同
 	// 所以移除了文件去重检查逻辑
@@ -115,19 +150,25 @@ func (s *UploadService) UploadImage(file *multipart.FileHeader, userID uint, usa
 		UserID:      userID,
 		FileName:    file.Filename,
 		SystemName:  systemName,
-		FileSize:    file.Size,
-		FileType:    models.GetFileTypeFromMime(mimeType),
-		MimeType:    mimeType,
+		FileSize:    int64(len(original.Data)),
+		FileType:    models.GetFileTypeFromMime(original.MimeType),
+		MimeType:    original.MimeType,
 		FileHash:    hash,
 		StoragePath: fileName,
 		PublicURL:   publicURL,
+		Width:       original.Width,
+		Height:      original.Height,
 		Usage:       usage,
 		Status:      1,
 	}
+	if err := upload.SetVariants(variants); err != nil {
+		s.deleteObjects(storedKeys)
+		return nil, fmt.Errorf("保存图片尺寸信息失败: %v", err)
+	}
 
 	if err := config.GetDB().Create(upload).Error; err != nil {
 		// 如果数据库保存失败，删除已上传的文件
-		s.bucket.DeleteObject(fileName)
+		s.deleteObjects(storedKeys)
 		return nil, fmt.Errorf("保存上传记录失败: %v", err)
 	}
 
@@ -173,11 +214,18 @@ func (s *UploadService) DeleteFile(uploadID uint, userID uint) error {
 		return fmt.Errorf("文件不存在或无权限删除")
 	}
 
-	// 从OSS删除文件
-	err = s.bucket.DeleteObject(upload.StoragePath)
+	// 从存储后端删除文件（衍生尺寸失败只记录日志）
+	err = s.storage.Delete(upload.StoragePath)
 	if err != nil {
-		return fmt.Errorf("删除OSS文件失败: %v", err)
+		return err
+	}
+	var variantKeys []string
+	for _, variant := range upload.GetVariants() {
+		if variant.StoragePath != upload.StoragePath {
+			variantKeys = append(variantKeys, variant.StoragePath)
+		}
 	}
+	s.deleteObjects(variantKeys)
 
 	// 从数据库删除记录
 	err = config.GetDB().Delete(&upload).Error
@@ -188,6 +236,15 @@ func (s *UploadService) DeleteFile(uploadID uint, userID uint) error {
 	return nil
 }
 
+// deleteObjects 删除多个存储对象，失败只记录日志
+func (s *UploadService) deleteObjects(keys []string) {
+	for _, key := range keys {
+		if err := s.storage.Delete(key); err != nil {
+			log.Printf("删除存储文件 %s 失败: %v", key, err)
+		}
+	}
+}
+
 // GetUserUploads 获取用户上传文件列表
 func (s *UploadService) GetUserUploads(userID uint, usage string, page, size int) ([]*models.Upload, int64, error) {
 	var uploads []*models.Upload
@@ -226,6 +283,24 @@ func (s *UploadService) GetUploadByID(uploadID uint) (*models.Upload, error) {
 	return &upload, nil
 }
 
+// GetSignedURL 生成上传文件的临时访问地址（仅限上传者本人）
+func (s *UploadService) GetSignedURL(uploadID uint, userID uint, expires time.Duration) (string, time.Time, error) {
+	var upload models.Upload
+	err := config.GetDB().Where("id = ? AND user_id = ?", uploadID, userID).First(&upload).Error
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("文件不存在或无权限访问")
+	}
+
+	if expires <= 0 {
+		expires = storageSignedURLExpire()
+	}
+	signedURL, err := s.storage.SignedURL(upload.StoragePath, expires)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signedURL, time.Now().Add(expires), nil
+}
+
 // isValidImageType 验证图片类型
 func (s *UploadService) isValidImageType(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
@@ -326,19 +401,6 @@ func (s *UploadService) generateSystemName(originalName string) string {
 	return fmt.Sprintf("%s%s", uuid, ext)
 }
 
-// generateFileURL 生成文件URL
-func (s *UploadService) generateFileURL(fileName string) string {
-	cfg := config.GetConfig()
-	
-	// 如果配置了自定义域名，使用自定义域名
-	if cfg.OSS.CustomDomain != "" {
-		return fmt.Sprintf("https://%s/%s", cfg.OSS.CustomDomain, fileName)
-	}
-	
-	// 否则使用默认的OSS域名
-	return fmt.Sprintf("https://%s.%s/%s", cfg.OSS.BucketName, cfg.OSS.Endpoint, fileName)
-}
-
 // getFileExtension 获取文件扩展名
 func (s *UploadService) getFileExtension(filename string) string {
 	ext := filepath.Ext(filename)
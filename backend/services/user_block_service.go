@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"godad-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserBlockService 用户屏蔽服务
+type UserBlockService struct {
+	db *gorm.DB
+}
+
+// NewUserBlockService 创建用户屏蔽服务
+func NewUserBlockService(db *gorm.DB) *UserBlockService {
+	return &UserBlockService{db: db}
+}
+
+// BlockUser 屏蔽用户，同时取消对该用户的关注
+func (s *UserBlockService) BlockUser(userID, blockedID uint) error {
+	if userID == blockedID {
+		return errors.New("不能屏蔽自己")
+	}
+
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id = ?", blockedID).Count(&count).Error; err != nil {
+		return fmt.Errorf("查询用户失败: %v", err)
+	}
+	if count == 0 {
+		return errors.New("用户不存在")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserBlock{UserID: userID, BlockedID: blockedID})
+		if result.Error != nil {
+			return fmt.Errorf("屏蔽用户失败: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("已屏蔽该用户")
+		}
+		if err := tx.Where("follower_id = ? AND followee_id = ?", userID, blockedID).Delete(&models.Follow{}).Error; err != nil {
+			return fmt.Errorf("取消关注失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidate(userID)
+	return nil
+}
+
+// UnblockUser 取消屏蔽
+func (s *UserBlockService) UnblockUser(userID, blockedID uint) error {
+	result := s.db.Where("user_id = ? AND blocked_id = ?", userID, blockedID).Delete(&models.UserBlock{})
+	if result.Error != nil {
+		return fmt.Errorf("取消屏蔽失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("未屏蔽该用户")
+	}
+
+	s.invalidate(userID)
+	return nil
+}
+
+// GetBlockedUsers 获取屏蔽列表，按屏蔽时间倒序
+func (s *UserBlockService) GetBlockedUsers(userID uint, page, size int) ([]*models.UserBlock, int64, error) {
+	var total int64
+	query := s.db.Model(&models.UserBlock{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询屏蔽列表失败: %v", err)
+	}
+
+	var blocks []*models.UserBlock
+	if err := query.Preload("Blocked").
+		Order("created_at DESC").
+		Offset((page - 1) * size).Limit(size).
+		Find(&blocks).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询屏蔽列表失败: %v", err)
+	}
+	return blocks, total, nil
+}
+
+// GetBlockedUserIDs 获取用户屏蔽的所有用户ID
+func (s *UserBlockService) GetBlockedUserIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	if err := s.db.Model(&models.UserBlock{}).Where("user_id = ?", userID).Pluck("blocked_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("查询屏蔽列表失败: %v", err)
+	}
+	return ids, nil
+}
+
+// invalidate 屏蔽关系变化后清理依赖它的个人缓存
+func (s *UserBlockService) invalidate(userID uint) {
+	NewFeedService(s.db).InvalidateFeed(userID)
+	NewRecommendationService(s.db).InvalidateRecommendations(userID)
+}
@@ -99,7 +99,7 @@ func (s *UserService) Login(req *models.UserLoginRequest) (*models.User, error)
 
 	// 查找用户（支持用户名或邮箱登录）
 	var user models.User
-	query := s.DB.Where("status = ?", 1)
+	query := s.DB.Model(&models.User{})
 	if s.isEmail(req.Username) {
 		query = query.Where("email = ?", req.Username)
 	} else {
@@ -113,11 +113,24 @@ func (s *UserService) Login(req *models.UserLoginRequest) (*models.User, error)
 		return nil, fmt.Errorf("查询用户失败: %v", err)
 	}
 
+	// 仍在封禁期内的账号直接拒绝
+	if user.Status != 1 && (user.BannedUntil == nil || user.BannedUntil.After(time.Now())) {
+		return nil, errors.New("用户不存在或已被禁用")
+	}
+
 	// 验证密码
 	if !s.checkPassword(req.Password, user.Password) {
 		return nil, errors.New("密码错误")
 	}
 
+	// 限时封禁到期后，在密码验证通过时自动解除
+	if user.Status != 1 {
+		if err := s.DB.Model(&user).Updates(map[string]interface{}{"status": 1, "banned_until": nil}).Error; err != nil {
+			return nil, fmt.Errorf("解除封禁失败: %v", err)
+		}
+		s.cacheService.Delete(fmt.Sprintf("user:%d", user.ID))
+	}
+
 	// 更新用户信息
 	user.UpdatedAt = time.Now()
 
@@ -374,6 +387,36 @@ func (s *UserService) ChangePassword(userID uint, oldPassword, newPassword strin
 	return nil
 }
 
+// UpdateUserStatus 更新用户状态（1-正常 0-禁用），禁用时吊销该用户的所有会话
+func (s *UserService) UpdateUserStatus(userID uint, status int8) error {
+	if status != 0 && status != 1 {
+		return errors.New("参数错误")
+	}
+
+	// 管理员手动设置状态时清除限时封禁的到期时间
+	result := s.DB.Model(&models.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"status": status, "banned_until": nil})
+	if result.Error != nil {
+		return fmt.Errorf("更新用户状态失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// 状态未变化时同样返回成功，仅在用户不存在时报错
+		var count int64
+		s.DB.Model(&models.User{}).Where("id = ?", userID).Count(&count)
+		if count == 0 {
+			return errors.New("用户不存在")
+		}
+	}
+
+	// 清理用户缓存
+	s.cacheService.Delete(fmt.Sprintf("user:%d", userID))
+
+	if status == 0 {
+		return NewSessionService(s.DB).RevokeAllUserSessions(userID, models.SessionRevokeBanned, "")
+	}
+	return nil
+}
+
 // CheckNicknameExists 检查昵称是否已存在
 func (s *UserService) CheckNicknameExists(nickname string) (bool, error) {
 	if nickname == "" {
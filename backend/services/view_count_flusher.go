@@ -0,0 +1,270 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"godad-backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// viewCountFlushChunkSize 单条 UPDATE 语句最多涉及的文章数
+	viewCountFlushChunkSize = 500
+	// viewCountFlushRetention 批次记录保留时长，超过后清理
+	viewCountFlushRetention = 7 * 24 * time.Hour
+)
+
+// errViewCountBatchApplied 批次已经落库（上次回写后未来得及清理 Redis）
+var errViewCountBatchApplied = errors.New("view count batch already applied")
+
+// ViewCountFlusher 定期将 Redis 中的文章浏览量增量批量回写到 articles.view_count
+//
+// 回写流程：
+//  1. 将 pending 哈希原子地 RENAME 为带批次ID的 flushing 键，之后的新浏览量写入新的 pending 哈希
+//  2. 在同一事务中写入批次记录并批量更新 view_count
+//  3. 事务提交后删除 flushing 键
+//
+// 若进程在第 2、3 步之间退出，重启后会根据批次记录识别出已落库的 flushing 键并直接删除，避免重复计数；
+// 未落库的 flushing 键则会被重新回写。
+type ViewCountFlusher struct {
+	db       *gorm.DB
+	interval time.Duration
+
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	startMux sync.Mutex
+	running  bool
+}
+
+// NewViewCountFlusher 创建浏览量回写器
+func NewViewCountFlusher(db *gorm.DB, interval time.Duration) *ViewCountFlusher {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &ViewCountFlusher{
+		db:       db,
+		interval: interval,
+	}
+}
+
+// Start 启动后台回写协程
+func (f *ViewCountFlusher) Start() {
+	f.startMux.Lock()
+	defer f.startMux.Unlock()
+
+	if f.running {
+		return
+	}
+	f.running = true
+	f.stopCh = make(chan struct{})
+	f.doneCh = make(chan struct{})
+
+	go f.run()
+}
+
+// Stop 停止后台回写协程，并在退出前执行最后一次回写
+func (f *ViewCountFlusher) Stop() {
+	f.startMux.Lock()
+	defer f.startMux.Unlock()
+
+	if !f.running {
+		return
+	}
+	close(f.stopCh)
+	<-f.doneCh
+	f.running = false
+}
+
+func (f *ViewCountFlusher) run() {
+	defer close(f.doneCh)
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	// 启动时先处理上次遗留的批次
+	f.flushAndLog()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.flushAndLog()
+		case <-f.stopCh:
+			f.flushAndLog()
+			return
+		}
+	}
+}
+
+func (f *ViewCountFlusher) flushAndLog() {
+	if err := f.Flush(); err != nil {
+		log.Printf("浏览量回写失败: %v", err)
+	}
+}
+
+// Flush 立即执行一次回写（缓存未启用时直接返回）
+func (f *ViewCountFlusher) Flush() error {
+	if !isCacheReady() {
+		return nil
+	}
+
+	// 先处理遗留的 flushing 批次
+	leftovers, err := f.scanFlushingKeys()
+	if err != nil {
+		return err
+	}
+	for _, key := range leftovers {
+		if err := f.flushBatch(key); err != nil {
+			return err
+		}
+	}
+
+	exists, err := rdb.Exists(ctx, viewCountPendingKey).Result()
+	if err != nil {
+		return fmt.Errorf("检查浏览量增量失败: %v", err)
+	}
+	if exists == 0 {
+		return nil
+	}
+
+	batchKey := viewCountFlushingKeyPrefix + uuid.New().String()
+	if err := rdb.Rename(ctx, viewCountPendingKey, batchKey).Err(); err != nil {
+		return fmt.Errorf("切换浏览量批次失败: %v", err)
+	}
+
+	return f.flushBatch(batchKey)
+}
+
+// scanFlushingKeys 查找所有尚未清理的 flushing 批次键
+func (f *ViewCountFlusher) scanFlushingKeys() ([]string, error) {
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, err := rdb.Scan(ctx, cursor, viewCountFlushingKeyPrefix+"*", 100).Result()
+		if err != nil {
+			return nil, fmt.Errorf("查找遗留浏览量批次失败: %v", err)
+		}
+		keys = append(keys, batch...)
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	return keys, nil
+}
+
+// flushBatch 将一个 flushing 批次回写到数据库，成功后删除该批次键
+func (f *ViewCountFlusher) flushBatch(key string) error {
+	batchID := strings.TrimPrefix(key, viewCountFlushingKeyPrefix)
+
+	raw, err := rdb.HGetAll(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("读取浏览量批次失败: %v", err)
+	}
+
+	counts := make(map[uint]int64, len(raw))
+	var total int64
+	for field, value := range raw {
+		articleID, err := strconv.ParseUint(field, 10, 64)
+		if err != nil || articleID == 0 {
+			continue
+		}
+		delta, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || delta <= 0 {
+			continue
+		}
+		counts[uint(articleID)] = delta
+		total += delta
+	}
+
+	if len(counts) > 0 {
+		err = f.db.Transaction(func(tx *gorm.DB) error {
+			var applied int64
+			if err := tx.Model(&models.ViewCountFlush{}).Where("batch_id = ?", batchID).Count(&applied).Error; err != nil {
+				return err
+			}
+			if applied > 0 {
+				return errViewCountBatchApplied
+			}
+
+			record := &models.ViewCountFlush{
+				BatchID:      batchID,
+				ArticleCount: len(counts),
+				TotalViews:   total,
+			}
+			if err := tx.Create(record).Error; err != nil {
+				return err
+			}
+
+			return applyViewCountDeltas(tx, counts)
+		})
+		if err != nil && !errors.Is(err, errViewCountBatchApplied) {
+			return fmt.Errorf("回写浏览量批次 %s 失败: %v", batchID, err)
+		}
+		invalidateViewCountArticleCaches(counts)
+	}
+
+	if err := rdb.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("清理浏览量批次失败: %v", err)
+	}
+
+	// 清理过期的批次记录
+	f.db.Where("created_at < ?", time.Now().Add(-viewCountFlushRetention)).Delete(&models.ViewCountFlush{})
+
+	return nil
+}
+
+// invalidateViewCountArticleCaches 回写后删除文章详情缓存，避免缓存中的旧 view_count 叠加待回写增量后少计
+func invalidateViewCountArticleCaches(counts map[uint]int64) {
+	keys := make([]string, 0, len(counts))
+	for id := range counts {
+		keys = append(keys, fmt.Sprintf("article:%d", id))
+	}
+	for start := 0; start < len(keys); start += viewCountFlushChunkSize {
+		end := min(start+viewCountFlushChunkSize, len(keys))
+		if err := rdb.Del(ctx, keys[start:end]...).Err(); err != nil {
+			log.Printf("清除文章详情缓存失败: %v", err)
+		}
+	}
+}
+
+// applyViewCountDeltas 按批次使用 CASE 语句累加文章浏览量
+func applyViewCountDeltas(tx *gorm.DB, counts map[uint]int64) error {
+	ids := make([]uint, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for start := 0; start < len(ids); start += viewCountFlushChunkSize {
+		end := start + viewCountFlushChunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunk := ids[start:end]
+
+		var sb strings.Builder
+		args := make([]interface{}, 0, len(chunk)*2+1)
+		sb.WriteString("UPDATE articles SET view_count = view_count + CASE id")
+		for _, id := range chunk {
+			sb.WriteString(" WHEN ? THEN ?")
+			args = append(args, id, counts[id])
+		}
+		sb.WriteString(" ELSE 0 END WHERE id IN ?")
+		args = append(args, chunk)
+
+		if err := tx.Exec(sb.String(), args...).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
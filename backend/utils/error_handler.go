@@ -95,6 +95,7 @@ func (h *ErrorHandler) HandleServiceError(ctx *gin.Context, err error) {
 		"回复不存在":        CodeNotFound,
 		"分类不存在":        CodeNotFound,
 		"资源不存在":        CodeNotFound,
+		"会话不存在":        CodeNotFound,
 		"无权限":           CodeForbidden,
 		"无权限修改":        CodeForbidden,
 		"无权限删除":        CodeForbidden,